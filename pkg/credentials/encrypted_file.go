@@ -0,0 +1,166 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"filippo.io/age"
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+// ErrWrongPassphrase is returned when an encrypted credentials file can not be decrypted with a given passphrase
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted credentials file")
+
+// Work factors (log2 of scrypt N) of age passphrase encryption. A file sets the work factor it was written with,
+// so reading a file with a work factor above the max is refused instead of spending unbounded CPU & memory.
+const (
+	scryptWorkFactor    = 15
+	scryptMaxWorkFactor = 20
+)
+
+var _ Store = (*EncryptedFile)(nil)
+
+// EncryptedFile keeps credentials by key in a file encrypted with a passphrase in the age format,
+// so the file can be decrypted with the age CLI as well
+type EncryptedFile struct {
+	mutex      sync.Mutex
+	filePath   string
+	passphrase []byte
+}
+
+// NewEncryptedFile creates a store of credentials in an encrypted file, the file is created on first save
+func NewEncryptedFile(filePath string, passphrase []byte) *EncryptedFile {
+	return &EncryptedFile{filePath: filePath, passphrase: passphrase}
+}
+
+// GetCredentials returns credentials from the file
+func (f *EncryptedFile) GetCredentials(_ context.Context, ref Ref) (datatug.Credentials, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	items, err := f.read()
+	if err != nil {
+		return datatug.Credentials{}, err
+	}
+	return lookup(ref, func(key string) (datatug.Credentials, bool, error) {
+		credentials, found := items[key]
+		return credentials, found, nil
+	})
+}
+
+// SetCredentials adds or replaces credentials in the file
+func (f *EncryptedFile) SetCredentials(_ context.Context, id string, credentials datatug.Credentials) error {
+	if id == "" {
+		return errors.New("credentials ID is required")
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	items, err := f.read()
+	if err != nil {
+		return err
+	}
+	items[id] = credentials
+	return f.write(items)
+}
+
+// DeleteCredentials removes credentials from the file, deleting missing credentials is not an error
+func (f *EncryptedFile) DeleteCredentials(_ context.Context, id string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	items, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := items[id]; !ok {
+		return nil
+	}
+	delete(items, id)
+	return f.write(items)
+}
+
+func (f *EncryptedFile) read() (map[string]datatug.Credentials, error) {
+	items := make(map[string]datatug.Credentials)
+	file, err := os.Open(f.filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return items, nil
+		}
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	identity, err := age.NewScryptIdentity(string(f.passphrase))
+	if err != nil {
+		return nil, err
+	}
+	identity.SetMaxWorkFactor(scryptMaxWorkFactor)
+	r, err := age.Decrypt(file, identity)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, ErrWrongPassphrase
+		}
+		return nil, fmt.Errorf("failed to decrypt credentials file %v: %w", f.filePath, err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWrongPassphrase, err)
+	}
+	if err = json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted credentials: %w", err)
+	}
+	return items, nil
+}
+
+func (f *EncryptedFile) write(items map[string]datatug.Credentials) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	recipient, err := age.NewScryptRecipient(string(f.passphrase))
+	if err != nil {
+		return err
+	}
+	recipient.SetWorkFactor(scryptWorkFactor)
+	var content bytes.Buffer
+	w, err := age.Encrypt(&content, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(f.filePath), 0700); err != nil {
+		return err
+	}
+	// Write to a temp file & rename, so a failed write does not lose existing credentials
+	tmpFile, err := os.CreateTemp(filepath.Dir(f.filePath), "."+filepath.Base(f.filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmpFile.Name())
+	}()
+	if _, err = tmpFile.Write(content.Bytes()); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpFile.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), f.filePath)
+}
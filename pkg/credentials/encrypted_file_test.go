@@ -0,0 +1,83 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedFile(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "secrets", "credentials.json")
+	file := NewEncryptedFile(filePath, []byte("correct horse battery staple"))
+
+	_, err := file.GetCredentials(ctx, Ref{ID: "prod-db"})
+	assert.ErrorIs(t, err, ErrNotFound, "a missing file has no credentials")
+
+	require.NoError(t, file.SetCredentials(ctx, "prod-db", datatug.Credentials{Username: "sa", Password: "s3cr3t"}))
+	require.NoError(t, file.SetCredentials(ctx, "dev/sqlserver:localhost:1433", datatug.Credentials{Username: "dev"}))
+	assert.Error(t, file.SetCredentials(ctx, "", datatug.Credentials{}))
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "s3cr3t")
+	assert.NotContains(t, string(content), "prod-db", "IDs of credentials should be encrypted too")
+	if info, err := os.Stat(filePath); assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	reopened := NewEncryptedFile(filePath, []byte("correct horse battery staple"))
+	c, err := reopened.GetCredentials(ctx, Ref{ID: "prod-db"})
+	require.NoError(t, err)
+	assert.Equal(t, datatug.Credentials{Username: "sa", Password: "s3cr3t"}, c)
+
+	t.Run("wrong_passphrase", func(t *testing.T) {
+		_, err := NewEncryptedFile(filePath, []byte("wrong")).GetCredentials(ctx, Ref{ID: "prod-db"})
+		assert.ErrorIs(t, err, ErrWrongPassphrase)
+	})
+
+	t.Run("age_format", func(t *testing.T) {
+		identity, err := age.NewScryptIdentity("correct horse battery staple")
+		require.NoError(t, err)
+		file, err := os.Open(filePath)
+		require.NoError(t, err)
+		defer func() {
+			_ = file.Close()
+		}()
+		r, err := age.Decrypt(file, identity)
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "s3cr3t")
+	})
+
+	t.Run("work_factor_above_max", func(t *testing.T) {
+		content, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		// A file may demand an expensive key derivation, it should be refused before deriving the key
+		stanza := fmt.Sprintf(" %d\n", scryptWorkFactor)
+		require.Contains(t, string(content), stanza)
+		content = bytes.Replace(content, []byte(stanza), []byte(fmt.Sprintf(" %d\n", scryptMaxWorkFactor+10)), 1)
+		filePath := filepath.Join(t.TempDir(), "credentials.age")
+		require.NoError(t, os.WriteFile(filePath, content, 0600))
+
+		_, err = NewEncryptedFile(filePath, []byte("correct horse battery staple")).GetCredentials(ctx, Ref{ID: "prod-db"})
+		assert.ErrorContains(t, err, "work factor")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, file.DeleteCredentials(ctx, "prod-db"))
+		require.NoError(t, file.DeleteCredentials(ctx, "prod-db"))
+		_, err := file.GetCredentials(ctx, Ref{ID: "prod-db"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
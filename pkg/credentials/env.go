@@ -0,0 +1,53 @@
+package credentials
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+// DefaultEnvPrefix is a prefix of environment variables read by a provider created with an empty prefix
+const DefaultEnvPrefix = "DATATUG_CREDENTIALS_"
+
+var _ Provider = (*EnvProvider)(nil)
+
+// EnvProvider reads credentials from "{prefix}{KEY}_USERNAME" & "{prefix}{KEY}_PASSWORD" environment variables,
+// where KEY is a credentials key in upper case with non-alphanumeric characters replaced by "_",
+// e.g. DATATUG_CREDENTIALS_PROD_SQLSERVER_LOCALHOST_1433_PASSWORD for server "sqlserver:localhost:1433" of env "prod".
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider creates a provider of credentials from environment variables
+func NewEnvProvider(prefix string) EnvProvider {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	return EnvProvider{prefix: prefix}
+}
+
+// EnvVarName returns name of an environment variable for a field of credentials, e.g. "USERNAME" or "PASSWORD"
+func (p EnvProvider) EnvVarName(key, field string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	return p.prefix + name + "_" + field
+}
+
+// GetCredentials returns credentials from environment variables
+func (p EnvProvider) GetCredentials(_ context.Context, ref Ref) (datatug.Credentials, error) {
+	return lookup(ref, func(key string) (credentials datatug.Credentials, found bool, err error) {
+		username, hasUsername := os.LookupEnv(p.EnvVarName(key, "USERNAME"))
+		password, hasPassword := os.LookupEnv(p.EnvVarName(key, "PASSWORD"))
+		return datatug.Credentials{Username: username, Password: password}, hasUsername || hasPassword, nil
+	})
+}
@@ -0,0 +1,31 @@
+package credentials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := NewEnvProvider("")
+	assert.Equal(t, "DATATUG_CREDENTIALS_PROD_SQLSERVER_LOCALHOST_1433_PASSWORD", provider.EnvVarName("prod/sqlserver:localhost:1433", "PASSWORD"))
+
+	t.Setenv("DATATUG_CREDENTIALS_PROD_SQLSERVER_LOCALHOST_1433_USERNAME", "prod_user")
+	t.Setenv("DATATUG_CREDENTIALS_PROD_SQLSERVER_LOCALHOST_1433_PASSWORD", "prod_secret")
+	t.Setenv("TEST_REPORTS_DB_PASSWORD", "reports_secret")
+
+	c, err := provider.GetCredentials(ctx, Ref{Environment: "prod", Server: datatug.ServerRef{Driver: "sqlserver", Host: "localhost", Port: 1433}})
+	require.NoError(t, err)
+	assert.Equal(t, datatug.Credentials{Username: "prod_user", Password: "prod_secret"}, c)
+
+	c, err = NewEnvProvider("TEST_").GetCredentials(ctx, Ref{ID: "reports-db"})
+	require.NoError(t, err)
+	assert.Equal(t, datatug.Credentials{Password: "reports_secret"}, c)
+
+	_, err = provider.GetCredentials(ctx, Ref{Environment: "dev", Server: datatug.ServerRef{Driver: "sqlserver", Host: "localhost", Port: 1433}})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,118 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+// DefaultKeyringService is a service name of secrets stored by a keyring provider created with an empty service name
+const DefaultKeyringService = "datatug"
+
+// Keyring stores secrets of a service by account, Get returns ErrNotFound for missing secrets
+type Keyring interface {
+	Get(ctx context.Context, service, account string) (string, error)
+	Set(ctx context.Context, service, account, secret string) error
+	Delete(ctx context.Context, service, account string) error
+}
+
+var _ Store = (*KeyringProvider)(nil)
+
+// KeyringProvider keeps credentials in an OS keyring as JSON secrets with a credentials key as an account
+type KeyringProvider struct {
+	service string
+	keyring Keyring
+}
+
+// NewKeyringProvider creates a store of credentials in a keyring
+func NewKeyringProvider(service string, keyring Keyring) KeyringProvider {
+	if service == "" {
+		service = DefaultKeyringService
+	}
+	return KeyringProvider{service: service, keyring: keyring}
+}
+
+// GetCredentials returns credentials from the keyring
+func (p KeyringProvider) GetCredentials(ctx context.Context, ref Ref) (datatug.Credentials, error) {
+	return lookup(ref, func(key string) (credentials datatug.Credentials, found bool, err error) {
+		secret, err := p.keyring.Get(ctx, p.service, key)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return credentials, false, nil
+			}
+			return credentials, false, fmt.Errorf("failed to get credentials %v from keyring: %w", key, err)
+		}
+		if err = json.Unmarshal([]byte(secret), &credentials); err != nil {
+			return credentials, false, fmt.Errorf("failed to parse credentials %v from keyring: %w", key, err)
+		}
+		return credentials, true, nil
+	})
+}
+
+// SetCredentials adds or replaces credentials in the keyring
+func (p KeyringProvider) SetCredentials(ctx context.Context, id string, credentials datatug.Credentials) error {
+	if id == "" {
+		return errors.New("credentials ID is required")
+	}
+	secret, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	return p.keyring.Set(ctx, p.service, id, string(secret))
+}
+
+// DeleteCredentials removes credentials from the keyring, deleting missing credentials is not an error
+func (p KeyringProvider) DeleteCredentials(ctx context.Context, id string) error {
+	if err := p.keyring.Delete(ctx, p.service, id); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// SecretServiceKeyring returns a keyring of a freedesktop.org Secret Service (GNOME Keyring, KWallet, etc.)
+// accessed with the `secret-tool` command of libsecret
+func SecretServiceKeyring() Keyring {
+	return secretTool{}
+}
+
+type secretTool struct{}
+
+// runSecretTool is replaced in tests
+var runSecretTool = func(ctx context.Context, stdin string, args ...string) (stdout string, exitCode int, err error) {
+	cmd := exec.CommandContext(ctx, "secret-tool", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var out, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &stderr
+	if err = cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return out.String(), exitErr.ExitCode(), fmt.Errorf("secret-tool %v failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return "", -1, err
+	}
+	return out.String(), 0, nil
+}
+
+func (secretTool) Get(ctx context.Context, service, account string) (string, error) {
+	secret, exitCode, err := runSecretTool(ctx, "", "lookup", "service", service, "account", account)
+	if exitCode == 1 && secret == "" { // lookup exits with 1 if nothing is found
+		return "", ErrNotFound
+	}
+	return secret, err
+}
+
+func (secretTool) Set(ctx context.Context, service, account, secret string) error {
+	_, _, err := runSecretTool(ctx, secret, "store", "--label", service+": "+account, "service", service, "account", account)
+	return err
+}
+
+func (secretTool) Delete(ctx context.Context, service, account string) error {
+	_, _, err := runSecretTool(ctx, "", "clear", "service", service, "account", account)
+	return err
+}
@@ -0,0 +1,85 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeyring map[string]string
+
+func (k testKeyring) Get(_ context.Context, service, account string) (string, error) {
+	if secret, ok := k[service+"/"+account]; ok {
+		return secret, nil
+	}
+	return "", ErrNotFound
+}
+
+func (k testKeyring) Set(_ context.Context, service, account, secret string) error {
+	k[service+"/"+account] = secret
+	return nil
+}
+
+func (k testKeyring) Delete(_ context.Context, service, account string) error {
+	delete(k, service+"/"+account)
+	return nil
+}
+
+func TestKeyringProvider(t *testing.T) {
+	ctx := context.Background()
+	keyring := testKeyring{}
+	provider := NewKeyringProvider("", keyring)
+	require.NoError(t, provider.SetCredentials(ctx, "prod-db", datatug.Credentials{Username: "sa", Password: "s3cr3t"}))
+	assert.Equal(t, `{"username":"sa","password":"s3cr3t"}`, keyring["datatug/prod-db"])
+
+	c, err := provider.GetCredentials(ctx, Ref{ID: "prod-db"})
+	require.NoError(t, err)
+	assert.Equal(t, datatug.Credentials{Username: "sa", Password: "s3cr3t"}, c)
+
+	require.NoError(t, provider.DeleteCredentials(ctx, "prod-db"))
+	_, err = provider.GetCredentials(ctx, Ref{ID: "prod-db"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	keyring["datatug/broken"] = "not JSON"
+	_, err = provider.GetCredentials(ctx, Ref{ID: "broken"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestSecretServiceKeyring(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+	secrets := map[string]string{"datatug/prod-db": "secret"}
+	defer func(run func(context.Context, string, ...string) (string, int, error)) { runSecretTool = run }(runSecretTool)
+	runSecretTool = func(_ context.Context, stdin string, args ...string) (string, int, error) {
+		calls = append(calls, fmt.Sprint(args, " stdin=", stdin))
+		key := args[len(args)-3] + "/" + args[len(args)-1]
+		switch args[0] {
+		case "lookup":
+			if secret, ok := secrets[key]; ok {
+				return secret, 0, nil
+			}
+			return "", 1, fmt.Errorf("exit status 1")
+		case "store":
+			secrets[key] = stdin
+		case "clear":
+			delete(secrets, key)
+		}
+		return "", 0, nil
+	}
+
+	keyring := SecretServiceKeyring()
+	secret, err := keyring.Get(ctx, "datatug", "prod-db")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+	_, err = keyring.Get(ctx, "datatug", "dev-db")
+	assert.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, keyring.Set(ctx, "datatug", "dev-db", "dev"))
+	require.NoError(t, keyring.Delete(ctx, "datatug", "prod-db"))
+	assert.Equal(t, map[string]string{"datatug/dev-db": "dev"}, secrets)
+	assert.Equal(t, "[store --label datatug: dev-db service datatug account dev-db] stdin=dev", calls[2])
+}
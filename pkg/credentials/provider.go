@@ -0,0 +1,127 @@
+// Package credentials resolves usernames & passwords of DB servers from secret storages,
+// so project files reference credentials by ID instead of holding passwords.
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+// ErrNotFound is returned by a provider that has no credentials for a reference
+var ErrNotFound = errors.New("credentials not found")
+
+// Ref identifies credentials either by ID or by a server & an environment the server belongs to
+type Ref struct {
+	ID          string
+	Environment string
+	Server      datatug.ServerRef
+}
+
+// Keys returns keys to look up credentials by, in order of precedence: the ID if set,
+// otherwise the server key within the environment and then the server key for any environment
+func (v Ref) Keys() []string {
+	if v.ID != "" {
+		return []string{v.ID}
+	}
+	serverKey := ServerKey(v.Server)
+	if serverKey == "" {
+		return nil
+	}
+	if v.Environment == "" {
+		return []string{serverKey}
+	}
+	return []string{v.Environment + "/" + serverKey, serverKey}
+}
+
+// String returns a human-readable reference for error messages
+func (v Ref) String() string {
+	if keys := v.Keys(); len(keys) > 0 {
+		return keys[0]
+	}
+	return "<empty>"
+}
+
+// ServerKey returns a key of server credentials in form "{driver}:{host}:{port}", or "{driver}:{path}" for file DBs.
+// An alias of a driver is replaced with its registered name, e.g. "mssql:host" is keyed as "sqlserver:host".
+func ServerKey(server datatug.ServerRef) string {
+	if server.Host == "" && server.Path == "" {
+		return ""
+	}
+	server.Driver = datatug.DriverName(server.Driver)
+	if server.Host == "" {
+		return server.Driver + ":" + server.Path
+	}
+	key := server.Driver + ":" + server.Host
+	if server.Port > 0 {
+		key += ":" + strconv.Itoa(server.Port)
+	}
+	return key
+}
+
+// Provider resolves credentials, returns an error wrapping ErrNotFound if it has none for the reference
+type Provider interface {
+	GetCredentials(ctx context.Context, ref Ref) (datatug.Credentials, error)
+}
+
+// Store is a provider that can keep credentials
+type Store interface {
+	Provider
+	SetCredentials(ctx context.Context, id string, credentials datatug.Credentials) error
+	DeleteCredentials(ctx context.Context, id string) error
+}
+
+// Chain returns a provider that resolves credentials from the first provider that has them
+func Chain(providers ...Provider) Provider {
+	return chain(providers)
+}
+
+type chain []Provider
+
+func (providers chain) GetCredentials(ctx context.Context, ref Ref) (datatug.Credentials, error) {
+	for _, p := range providers {
+		credentials, err := p.GetCredentials(ctx, ref)
+		if err == nil {
+			return credentials, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return credentials, err
+		}
+	}
+	return datatug.Credentials{}, fmt.Errorf("%w: %v", ErrNotFound, ref)
+}
+
+// ForTarget returns credentials of a query target: embedded ones for targets not migrated yet,
+// otherwise credentials resolved by the target's credentials ID or by its server within the environment
+func ForTarget(ctx context.Context, provider Provider, environment string, target datatug.QueryDefTarget) (datatug.Credentials, error) {
+	if target.Password != "" {
+		return target.Credentials, nil
+	}
+	ref := Ref{
+		ID:          target.CredentialsID,
+		Environment: environment,
+		Server:      datatug.ServerRef{Driver: target.Driver, Host: target.Host, Port: target.Port},
+	}
+	credentials, err := provider.GetCredentials(ctx, ref)
+	if err != nil {
+		return credentials, err
+	}
+	if credentials.Username == "" {
+		credentials.Username = target.Username
+	}
+	return credentials, nil
+}
+
+// lookup returns credentials by the first of the reference keys found by get
+func lookup(ref Ref, get func(key string) (datatug.Credentials, bool, error)) (datatug.Credentials, error) {
+	for _, key := range ref.Keys() {
+		credentials, found, err := get(key)
+		if err != nil || found {
+			return credentials, err
+		}
+	}
+	return datatug.Credentials{}, fmt.Errorf("%w: %v", ErrNotFound, ref)
+}
@@ -0,0 +1,96 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapProvider map[string]datatug.Credentials
+
+func (p mapProvider) GetCredentials(_ context.Context, ref Ref) (datatug.Credentials, error) {
+	return lookup(ref, func(key string) (datatug.Credentials, bool, error) {
+		c, ok := p[key]
+		return c, ok, nil
+	})
+}
+
+type failingProvider struct{}
+
+func (failingProvider) GetCredentials(context.Context, Ref) (datatug.Credentials, error) {
+	return datatug.Credentials{}, errors.New("provider is not available")
+}
+
+func TestServerKey(t *testing.T) {
+	assert.Equal(t, "sqlserver:localhost:1433", ServerKey(datatug.ServerRef{Driver: "sqlserver", Host: "localhost", Port: 1433}))
+	assert.Equal(t, "postgres:db.example.com", ServerKey(datatug.ServerRef{Driver: "postgres", Host: "db.example.com"}))
+	assert.Equal(t, "sqlite3:/data/app.db", ServerKey(datatug.ServerRef{Driver: "sqlite3", Path: "/data/app.db"}))
+	assert.Empty(t, ServerKey(datatug.ServerRef{Driver: "sqlite3"}))
+	assert.Equal(t, "sqlserver:localhost:1433", ServerKey(datatug.ServerRef{Driver: "mssql", Host: "localhost", Port: 1433}))
+}
+
+func TestRef_Keys(t *testing.T) {
+	server := datatug.ServerRef{Driver: "sqlserver", Host: "localhost", Port: 1433}
+	assert.Equal(t, []string{"prod-db"}, Ref{ID: "prod-db", Environment: "prod", Server: server}.Keys())
+	assert.Equal(t, []string{"prod/sqlserver:localhost:1433", "sqlserver:localhost:1433"}, Ref{Environment: "prod", Server: server}.Keys())
+	assert.Equal(t, []string{"sqlserver:localhost:1433"}, Ref{Server: server}.Keys())
+	assert.Empty(t, Ref{}.Keys())
+	assert.Equal(t, "<empty>", Ref{}.String())
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	server := datatug.ServerRef{Driver: "sqlserver", Host: "localhost", Port: 1433}
+	provider := Chain(
+		mapProvider{"prod/sqlserver:localhost:1433": {Username: "prod_user"}},
+		mapProvider{"sqlserver:localhost:1433": {Username: "any_env_user"}},
+	)
+
+	t.Run("env_specific", func(t *testing.T) {
+		c, err := provider.GetCredentials(ctx, Ref{Environment: "prod", Server: server})
+		require.NoError(t, err)
+		assert.Equal(t, "prod_user", c.Username)
+	})
+	t.Run("any_env", func(t *testing.T) {
+		c, err := provider.GetCredentials(ctx, Ref{Environment: "dev", Server: server})
+		require.NoError(t, err)
+		assert.Equal(t, "any_env_user", c.Username)
+	})
+	t.Run("not_found", func(t *testing.T) {
+		_, err := provider.GetCredentials(ctx, Ref{ID: "unknown"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("error", func(t *testing.T) {
+		_, err := Chain(mapProvider{}, failingProvider{}, mapProvider{"x": {}}).GetCredentials(ctx, Ref{ID: "x"})
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestForTarget(t *testing.T) {
+	ctx := context.Background()
+	provider := mapProvider{
+		"prod-db":                      {Password: "by_id"},
+		"dev/sqlserver:localhost:1433": {Username: "dev_user", Password: "by_server"},
+	}
+	t.Run("by_id", func(t *testing.T) {
+		c, err := ForTarget(ctx, provider, "dev", datatug.QueryDefTarget{CredentialsID: "prod-db", Credentials: datatug.Credentials{Username: "reader"}})
+		require.NoError(t, err)
+		assert.Equal(t, datatug.Credentials{Username: "reader", Password: "by_id"}, c)
+	})
+	t.Run("by_server", func(t *testing.T) {
+		c, err := ForTarget(ctx, provider, "dev", datatug.QueryDefTarget{Driver: "sqlserver", Host: "localhost", Port: 1433})
+		require.NoError(t, err)
+		assert.Equal(t, datatug.Credentials{Username: "dev_user", Password: "by_server"}, c)
+	})
+	t.Run("embedded", func(t *testing.T) {
+		embedded := datatug.Credentials{Username: "sa", Password: "legacy"}
+		c, err := ForTarget(ctx, provider, "dev", datatug.QueryDefTarget{Credentials: embedded})
+		require.NoError(t, err)
+		assert.Equal(t, embedded, c)
+	})
+}
@@ -26,6 +26,11 @@ type Board struct {
 	Rows BoardRows `json:"rows,omitempty" firestore:"rows,omitempty"`
 }
 
+// TrimToBrief drops board rows
+func (v *Board) TrimToBrief() {
+	v.Rows = nil
+}
+
 // Validate returns error if failed
 func (v *Board) Validate() error {
 	if err := v.ValidateWithOptions(true); err != nil {
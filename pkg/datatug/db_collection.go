@@ -14,6 +14,14 @@ type CollectionInfo struct {
 	RecordsCount *int          `json:"recordsCount,omitempty"`
 }
 
+// TrimToBrief drops DDL, columns, indexes & references
+func (v *CollectionInfo) TrimToBrief() {
+	v.DDL = ""
+	v.Columns = nil
+	v.Indexes = nil
+	v.ReferencedBy = nil
+}
+
 // Validate returns error if not valid
 func (v CollectionInfo) Validate() error {
 	if err := v.DBCollectionKey.Validate(); err != nil {
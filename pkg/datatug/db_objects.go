@@ -105,6 +105,16 @@ type DbSchema struct {
 	Views  []*CollectionInfo `json:"views"`
 }
 
+// TrimToBrief keeps list of tables & views but drops their DDL, columns, etc.
+func (v *DbSchema) TrimToBrief() {
+	for _, t := range v.Tables {
+		t.TrimToBrief()
+	}
+	for _, t := range v.Views {
+		t.TrimToBrief()
+	}
+}
+
 // Validate returns error if not valid
 func (v DbSchema) Validate() error {
 	if err := v.ValidateWithOptions(false); err != nil {
@@ -18,7 +18,7 @@ func (v DbCatalogBase) Validate() error {
 	if v.Driver == "" {
 		return validation.NewErrRecordIsMissingRequiredField("driver")
 	}
-	if v.Driver == "sqlite3" && v.Path == "" {
+	if driver, ok := GetDriver(v.Driver); ok && driver.FileBased && v.Path == "" {
 		return validation.NewErrRecordIsMissingRequiredField("path")
 	}
 	return nil
@@ -58,14 +58,8 @@ func (v DbCatalogs) Validate() error {
 func (v DbCatalogs) GetTable(catalog, schema, name string) *CollectionInfo {
 	for _, c := range v {
 		if c.ID == catalog {
-			for _, s := range c.Schemas {
-				if s.ID == schema {
-					for _, t := range s.Tables {
-						if t.Name() == name {
-							return t
-						}
-					}
-				}
+			if t := c.GetTable(schema, name); t != nil {
+				return t
 			}
 		}
 	}
@@ -78,6 +72,27 @@ type DbCatalog struct {
 	Schemas DbSchemas
 }
 
+// GetTable returns a table of the catalog or nil if not found
+func (v DbCatalog) GetTable(schema, name string) *CollectionInfo {
+	for _, s := range v.Schemas {
+		if s.ID == schema {
+			for _, t := range s.Tables {
+				if t.Name() == name {
+					return t
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// TrimToBrief keeps list of schema objects but drops their DDL, columns, etc.
+func (v *DbCatalog) TrimToBrief() {
+	for _, schema := range v.Schemas {
+		schema.TrimToBrief()
+	}
+}
+
 // ProjDbServerSummary holds summary info about DB server
 type ProjDbServerSummary struct {
 	ProjectItem
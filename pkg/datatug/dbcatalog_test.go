@@ -56,3 +56,14 @@ func TestDbCatalog_Validate(t *testing.T) {
 		assert.Error(t, v.Validate())
 	})
 }
+
+func TestDbCatalog_GetTable(t *testing.T) {
+	orders := &CollectionInfo{DBCollectionKey: NewTableKey("orders", "dbo", "c1", nil)}
+	catalog := DbCatalog{Schemas: DbSchemas{
+		{ProjectItem: ProjectItem{ProjItemBrief: ProjItemBrief{ID: "sales"}}},
+		{ProjectItem: ProjectItem{ProjItemBrief: ProjItemBrief{ID: "dbo"}}, Tables: []*CollectionInfo{orders}},
+	}}
+	assert.Same(t, orders, catalog.GetTable("dbo", "orders"))
+	assert.Nil(t, catalog.GetTable("sales", "orders"))
+	assert.Nil(t, catalog.GetTable("dbo", "customers"))
+}
@@ -0,0 +1,120 @@
+package datatug
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DriverInfo describes a DB driver to the core: how it's named & how records referencing it are validated.
+// Drivers with connection params builders & schema providers are registered with dbdriver.Register.
+type DriverInfo struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	// DefaultPort is used to connect to a server that has no port set, 0 for file based drivers
+	DefaultPort int `json:"defaultPort,omitempty"`
+	// FileBased drivers address databases by a path instead of a host & a port, e.g. SQLite
+	FileBased bool `json:"fileBased,omitempty"`
+	// ValidateServer performs driver specific validation of a server in addition to common rules, optional
+	ValidateServer func(server ServerRef) error `json:"-"`
+}
+
+// Validate returns error if not valid
+func (v DriverInfo) Validate() error {
+	if strings.TrimSpace(v.Name) == "" {
+		return errors.New("driver name is required")
+	}
+	for _, alias := range v.Aliases {
+		if strings.TrimSpace(alias) == "" || strings.EqualFold(alias, v.Name) {
+			return fmt.Errorf("invalid alias of driver %v: %q", v.Name, alias)
+		}
+	}
+	if v.DefaultPort < 0 {
+		return fmt.Errorf("default port of driver %v should not be negative, got: %v", v.Name, v.DefaultPort)
+	}
+	if v.FileBased && v.DefaultPort != 0 {
+		return fmt.Errorf("file based driver %v can not have a default port", v.Name)
+	}
+	return nil
+}
+
+var drivers = struct {
+	sync.RWMutex
+	byName  map[string]DriverInfo
+	aliases map[string]string // lower-cased names & aliases to names
+}{
+	byName:  make(map[string]DriverInfo),
+	aliases: make(map[string]string),
+}
+
+func init() {
+	for _, driver := range []DriverInfo{
+		{Name: "sqlite3", Aliases: []string{"sqlite"}, FileBased: true},
+		{Name: "sqlserver", Aliases: []string{"mssql"}, DefaultPort: 1433},
+		{Name: "mysql", Aliases: []string{"mariadb"}, DefaultPort: 3306},
+		{Name: "oracle", DefaultPort: 1521},
+		{Name: "postgres", Aliases: []string{"postgresql", "pg"}, DefaultPort: 5432},
+	} {
+		if err := RegisterDriver(driver); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterDriver adds a driver or replaces a registered driver with the same name.
+// It's an error to use a name or an alias of another driver as an alias.
+func RegisterDriver(driver DriverInfo) error {
+	if err := driver.Validate(); err != nil {
+		return err
+	}
+	drivers.Lock()
+	defer drivers.Unlock()
+	for _, name := range append([]string{driver.Name}, driver.Aliases...) {
+		if registered, ok := drivers.aliases[strings.ToLower(name)]; ok && registered != driver.Name {
+			return fmt.Errorf("%q is already used by driver %v", name, registered)
+		}
+	}
+	if previous, ok := drivers.byName[driver.Name]; ok {
+		for _, alias := range previous.Aliases {
+			delete(drivers.aliases, strings.ToLower(alias))
+		}
+	}
+	drivers.byName[driver.Name] = driver
+	for _, name := range append([]string{driver.Name}, driver.Aliases...) {
+		drivers.aliases[strings.ToLower(name)] = driver.Name
+	}
+	return nil
+}
+
+// GetDriver returns a registered driver by a name or an alias, case-insensitive
+func GetDriver(name string) (driver DriverInfo, ok bool) {
+	drivers.RLock()
+	defer drivers.RUnlock()
+	if registered, isKnown := drivers.aliases[strings.ToLower(name)]; isKnown {
+		driver, ok = drivers.byName[registered]
+	}
+	return
+}
+
+// DriverName returns a registered name of a driver for its name or alias, e.g. "sqlserver" for "mssql",
+// an unknown name is returned as is. Servers are compared & keyed by registered names of their drivers.
+func DriverName(name string) string {
+	if driver, ok := GetDriver(name); ok {
+		return driver.Name
+	}
+	return name
+}
+
+// DriverNames returns sorted names of registered drivers
+func DriverNames() []string {
+	drivers.RLock()
+	defer drivers.RUnlock()
+	names := make([]string, 0, len(drivers.byName))
+	for name := range drivers.byName {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,73 @@
+package datatug
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriverInfo_Validate(t *testing.T) {
+	assert.NoError(t, DriverInfo{Name: "db2", Aliases: []string{"ibmdb2"}, DefaultPort: 50000}.Validate())
+	assert.Error(t, DriverInfo{}.Validate())
+	assert.Error(t, DriverInfo{Name: "db2", Aliases: []string{""}}.Validate())
+	assert.Error(t, DriverInfo{Name: "db2", Aliases: []string{"DB2"}}.Validate())
+	assert.Error(t, DriverInfo{Name: "db2", DefaultPort: -1}.Validate())
+	assert.Error(t, DriverInfo{Name: "duckdb", FileBased: true, DefaultPort: 1}.Validate())
+}
+
+func TestGetDriver(t *testing.T) {
+	driver, ok := GetDriver("PostgreSQL")
+	require.True(t, ok)
+	assert.Equal(t, "postgres", driver.Name)
+	assert.Equal(t, 5432, driver.DefaultPort)
+
+	driver, ok = GetDriver("sqlite")
+	require.True(t, ok)
+	assert.Equal(t, "sqlite3", driver.Name)
+	assert.True(t, driver.FileBased)
+
+	_, ok = GetDriver("unknown")
+	assert.False(t, ok)
+
+	assert.Subset(t, DriverNames(), []string{"mysql", "oracle", "postgres", "sqlite3", "sqlserver"})
+}
+
+func TestDriverName(t *testing.T) {
+	assert.Equal(t, "sqlserver", DriverName("sqlserver"))
+	assert.Equal(t, "sqlserver", DriverName("MSSQL"))
+	assert.Equal(t, "postgres", DriverName("pg"))
+	assert.Equal(t, "unknown", DriverName("unknown"))
+}
+
+func TestRegisterDriver(t *testing.T) {
+	errNoBalancer := errors.New("cockroach servers should be accessed via a load balancer")
+	cockroach := DriverInfo{
+		Name:        "cockroach",
+		Aliases:     []string{"crdb"},
+		DefaultPort: 26257,
+		ValidateServer: func(server ServerRef) error {
+			if server.Host != "lb" {
+				return errNoBalancer
+			}
+			return nil
+		},
+	}
+	require.NoError(t, RegisterDriver(cockroach))
+	assert.NoError(t, ServerRef{Driver: "crdb", Host: "lb"}.Validate())
+	assert.ErrorIs(t, ServerRef{Driver: "cockroach", Host: "node1"}.Validate(), errNoBalancer)
+
+	t.Run("alias_of_another_driver", func(t *testing.T) {
+		assert.Error(t, RegisterDriver(DriverInfo{Name: "yugabyte", Aliases: []string{"pg"}}))
+		assert.Error(t, RegisterDriver(DriverInfo{Name: "mssql"}))
+	})
+	t.Run("replace", func(t *testing.T) {
+		require.NoError(t, RegisterDriver(DriverInfo{Name: "cockroach", Aliases: []string{"cockroachdb"}, DefaultPort: 26257}))
+		_, ok := GetDriver("crdb")
+		assert.False(t, ok)
+		driver, ok := GetDriver("cockroachdb")
+		require.True(t, ok)
+		assert.Nil(t, driver.ValidateServer)
+	})
+}
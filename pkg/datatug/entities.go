@@ -46,6 +46,12 @@ type Entity struct {
 	Tables TableKeys    `json:"tables,omitempty" firestore:"tables,omitempty"`
 }
 
+// TrimToBrief drops entity fields & tables
+func (v *Entity) TrimToBrief() {
+	v.Fields = nil
+	v.Tables = nil
+}
+
 // Validate returns error if not valid
 func (v Entity) Validate() error {
 	if err := v.ValidateWithOptions(false); err != nil {
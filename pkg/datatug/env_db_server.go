@@ -25,10 +25,11 @@ func (v EnvDbServers) Validate() error {
 	return nil
 }
 
-// GetByServerRef returns *EnvDbServer by GetID
+// GetByServerRef returns *EnvDbServer by GetID, aliases of a driver reference the same server
 func (v EnvDbServers) GetByServerRef(serverRef ServerRef) *EnvDbServer {
+	driver := DriverName(serverRef.Driver)
 	for _, item := range v {
-		if item.Driver == serverRef.Driver && item.Host == serverRef.Host && item.Port == serverRef.Port {
+		if DriverName(item.Driver) == driver && item.Host == serverRef.Host && item.Port == serverRef.Port {
 			return item
 		}
 	}
@@ -39,12 +40,25 @@ func (v EnvDbServers) GetByServerRef(serverRef ServerRef) *EnvDbServer {
 type EnvDbServer struct {
 	ServerRef
 	Catalogs []string `json:"catalogs,omitempty"`
+	// SSHTunnel is set for servers reachable only through a bastion
+	SSHTunnel *SSHTunnel `json:"sshTunnel,omitempty"`
+	// Variables override variables of an environment & project
+	Variables
+	Revision string `json:"revision,omitempty" firestore:"-" yaml:"-"` // set by a store, see ProjItemBrief.Revision
 }
 
 func (v *EnvDbServer) GetID() string {
 	return fmt.Sprintf("%s:%d", v.Host, v.Port)
 }
 
+func (v *EnvDbServer) GetRevision() string {
+	return v.Revision
+}
+
+func (v *EnvDbServer) SetRevision(revision string) {
+	v.Revision = revision
+}
+
 func (v *EnvDbServer) SetID(id string) {
 	vals := strings.Split(id, ":")
 	v.Host = vals[0]
@@ -56,6 +70,14 @@ func (v *EnvDbServer) Validate() error {
 	if err := v.ServerRef.Validate(); err != nil {
 		return err
 	}
+	if v.SSHTunnel != nil {
+		if driver, _ := GetDriver(v.Driver); driver.FileBased {
+			return validation.NewErrBadRecordFieldValue("sshTunnel", "cannot be used with "+driver.Name)
+		}
+		if err := v.SSHTunnel.Validate(); err != nil {
+			return validation.NewErrBadRecordFieldValue("sshTunnel", err.Error())
+		}
+	}
 
 	for i, catalogID := range v.Catalogs {
 		if strings.TrimSpace(catalogID) == "" {
@@ -65,5 +87,5 @@ func (v *EnvDbServer) Validate() error {
 			return validation.NewErrBadRecordFieldValue("catalogs", fmt.Sprintf("duplicate value at indexes %v & %v: %v", prevIndex, i, catalogID))
 		}
 	}
-	return nil
+	return v.Variables.Validate()
 }
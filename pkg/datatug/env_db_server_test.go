@@ -27,6 +27,7 @@ func TestEnvDbServers_GetByServerRef(t *testing.T) {
 	v := EnvDbServers{s1}
 	assert.Equal(t, s1, v.GetByServerRef(ref))
 	assert.Nil(t, v.GetByServerRef(ServerRef{Driver: "mysql", Host: "other"}))
+	assert.Equal(t, s1, v.GetByServerRef(ServerRef{Driver: "mariadb", Host: "localhost", Port: 3306}), "an alias of the driver")
 }
 
 func TestEnvDbServer_Validate(t *testing.T) {
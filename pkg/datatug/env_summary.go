@@ -6,6 +6,7 @@ import "github.com/strongo/validation"
 type EnvironmentSummary struct {
 	ProjectItem
 	Servers EnvDbServers `json:"dbServers,omitempty"`
+	Variables
 	//Databases []EnvDb             `json:"databases,omitempty"`
 }
 
@@ -17,5 +18,8 @@ func (v EnvironmentSummary) Validate() error {
 	if err := v.Servers.Validate(); err != nil {
 		return validation.NewErrBadRecordFieldValue("servers", err.Error())
 	}
+	if err := v.Variables.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
@@ -31,6 +31,8 @@ func (v Environments) IDs() []string {
 type Environment struct {
 	ProjectItem
 	DbServers EnvDbServers `json:"dbServers"`
+	// Variables override project variables & are overridden by variables of servers
+	Variables
 }
 
 // Validate returns error if failed
@@ -41,6 +43,9 @@ func (v Environment) Validate() error {
 	if err := v.DbServers.Validate(); err != nil {
 		return err
 	}
+	if err := v.Variables.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,279 @@
+package datatug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/strongo/validation"
+)
+
+// ErrEnvironmentAlreadyExists is returned when an environment is cloned to an ID that is already taken
+var ErrEnvironmentAlreadyExists = errors.New("environment already exists")
+
+// ServerRewrite replaces host & port of servers of a cloned environment.
+// A zero FromPort matches any port & a zero ToPort keeps a port of a server.
+type ServerRewrite struct {
+	FromHost string `json:"fromHost"`
+	FromPort int    `json:"fromPort,omitempty"`
+	ToHost   string `json:"toHost"`
+	ToPort   int    `json:"toPort,omitempty"`
+}
+
+// Validate returns error if not valid
+func (v ServerRewrite) Validate() error {
+	if strings.TrimSpace(v.FromHost) == "" {
+		return validation.NewErrRecordIsMissingRequiredField("fromHost")
+	}
+	if strings.TrimSpace(v.ToHost) == "" {
+		return validation.NewErrRecordIsMissingRequiredField("toHost")
+	}
+	if v.FromPort < 0 {
+		return validation.NewErrBadRecordFieldValue("fromPort", "should not be negative, got: "+strconv.Itoa(v.FromPort))
+	}
+	if v.ToPort < 0 {
+		return validation.NewErrBadRecordFieldValue("toPort", "should not be negative, got: "+strconv.Itoa(v.ToPort))
+	}
+	return nil
+}
+
+// ServerRewrites is a slice of ServerRewrite
+type ServerRewrites []ServerRewrite
+
+// Validate returns error if not valid
+func (v ServerRewrites) Validate() error {
+	for i, item := range v {
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("invalid server rewrite at index %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Rewrite returns a server with host & port replaced by the first matching rewrite
+func (v ServerRewrites) Rewrite(server ServerRef) (ServerRef, bool) {
+	for _, rewrite := range v {
+		if !strings.EqualFold(rewrite.FromHost, server.Host) || rewrite.FromPort != 0 && rewrite.FromPort != server.Port {
+			continue
+		}
+		server.Host = rewrite.ToHost
+		if rewrite.ToPort != 0 {
+			server.Port = rewrite.ToPort
+		}
+		return server, true
+	}
+	return server, false
+}
+
+// CloneEnvironmentRequest defines a new environment to be created as a copy of an existing one
+type CloneEnvironmentRequest struct {
+	SourceID string `json:"sourceId"`
+	ID       string `json:"id"`
+	// Title of the new environment, a title of the source environment is used if empty
+	Title    string         `json:"title,omitempty"`
+	Rewrites ServerRewrites `json:"rewrites,omitempty"`
+}
+
+// Validate returns error if not valid
+func (v CloneEnvironmentRequest) Validate() error {
+	if strings.TrimSpace(v.SourceID) == "" {
+		return validation.NewErrRecordIsMissingRequiredField("sourceId")
+	}
+	if strings.TrimSpace(v.ID) == "" {
+		return validation.NewErrRecordIsMissingRequiredField("id")
+	}
+	if v.ID == v.SourceID {
+		return validation.NewErrBadRecordFieldValue("id", "should be different from sourceId")
+	}
+	return v.Rewrites.Validate()
+}
+
+// PromoteTargetsRequest selects queries which targets pointing to servers of one environment
+// are to be copied to targets pointing to servers of another environment
+type PromoteTargetsRequest struct {
+	FromEnvID string   `json:"fromEnvId"`
+	ToEnvID   string   `json:"toEnvId"`
+	QueryIDs  []string `json:"queryIds"`
+	// DryRun makes PromoteTargets return changes without saving them
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// Validate returns error if not valid
+func (v PromoteTargetsRequest) Validate() error {
+	if strings.TrimSpace(v.FromEnvID) == "" {
+		return validation.NewErrRecordIsMissingRequiredField("fromEnvId")
+	}
+	if strings.TrimSpace(v.ToEnvID) == "" {
+		return validation.NewErrRecordIsMissingRequiredField("toEnvId")
+	}
+	if v.FromEnvID == v.ToEnvID {
+		return validation.NewErrBadRecordFieldValue("toEnvId", "should be different from fromEnvId")
+	}
+	if len(v.QueryIDs) == 0 {
+		return validation.NewErrRecordIsMissingRequiredField("queryIds")
+	}
+	return nil
+}
+
+// TargetPromotion describes a change of a query target made or to be made by PromoteTargets
+type TargetPromotion struct {
+	QueryID string     `json:"queryId"`
+	Change  ChangeType `json:"change"`
+	// Source is a target of the source environment
+	Source QueryDefTarget `json:"source"`
+	// Previous is a target of the destination environment replaced by the promoted one, nil for added targets
+	Previous *QueryDefTarget `json:"previous,omitempty"`
+	// Target is a promoted target, empty for skipped ones
+	Target QueryDefTarget `json:"target,omitempty"`
+	// Skipped explains why a target of the source environment is not promoted
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// PromoteTargets copies targets of selected queries that point to servers of the source environment
+// to targets that point to matching servers of the destination environment. A destination server
+// matches if it's the only one with the driver of a target or the only one that lists a catalog of the target.
+// Credentials are not promoted: a replaced target keeps its credentials & an added target has none,
+// so they are resolved by server. Queries are saved in a transaction if the store supports it.
+// Use PromoteTargetsRequest.DryRun to preview changes.
+func PromoteTargets(ctx context.Context, store ProjectStore, request PromoteTargetsRequest) (promotions []TargetPromotion, err error) {
+	if err = request.Validate(); err != nil {
+		return nil, err
+	}
+	fromServers, err := loadEnvironmentServers(ctx, store, request.FromEnvID)
+	if err != nil {
+		return nil, err
+	}
+	toServers, err := loadEnvironmentServers(ctx, store, request.ToEnvID)
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]*QueryDefWithFolderPath, 0, len(request.QueryIDs))
+	for _, queryID := range request.QueryIDs {
+		query, err := store.LoadQuery(ctx, queryID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load query %v: %w", queryID, err)
+		}
+		queryPromotions := promoteQueryTargets(queryID, query, fromServers, toServers)
+		promotions = append(promotions, queryPromotions...)
+		if slices.ContainsFunc(queryPromotions, func(p TargetPromotion) bool {
+			return p.Change == ChangeTypeAdded || p.Change == ChangeTypeAltered
+		}) {
+			folderPath := RootSharedFolderName
+			if dir := path.Dir(queryID); dir != "." {
+				folderPath = dir
+			}
+			changed = append(changed, &QueryDefWithFolderPath{FolderPath: folderPath, QueryDef: *query})
+		}
+	}
+	if request.DryRun || len(changed) == 0 {
+		return promotions, nil
+	}
+	save := func(ctx context.Context) error {
+		for _, query := range changed {
+			if err := store.SaveQuery(ctx, query); err != nil {
+				return fmt.Errorf("failed to save query %v: %w", query.ID, err)
+			}
+		}
+		return nil
+	}
+	if transactor, ok := store.(ProjectTransactor); ok {
+		err = transactor.RunInTransaction(ctx, save)
+	} else {
+		err = save(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func loadEnvironmentServers(ctx context.Context, store ProjectStore, envID string) (EnvDbServers, error) {
+	env, err := store.LoadEnvironment(ctx, envID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load environment %v: %w", envID, err)
+	}
+	servers, err := store.LoadEnvDbServers(ctx, envID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load servers of environment %v: %w", envID, err)
+	}
+	for _, server := range env.DbServers {
+		if servers.GetByServerRef(server.ServerRef) == nil {
+			servers = append(servers, server)
+		}
+	}
+	return servers, nil
+}
+
+// promoteQueryTargets changes targets of the query & returns promotions of the targets
+func promoteQueryTargets(queryID string, query *QueryDef, fromServers, toServers EnvDbServers) (promotions []TargetPromotion) {
+	sourceTargets := slices.Clone(query.Targets)
+	for _, source := range sourceTargets {
+		if fromServers.GetByServerRef(source.serverRef()) == nil {
+			continue // not a target of the source environment
+		}
+		promotion := TargetPromotion{QueryID: queryID, Source: source}
+		toServer, reason := matchPromotionServer(source, toServers)
+		if toServer == nil {
+			promotion.Skipped = reason
+			promotions = append(promotions, promotion)
+			continue
+		}
+		target := source
+		target.Host, target.Port = toServer.Host, toServer.Port
+		target.CredentialsID, target.Credentials = "", Credentials{}
+		i := slices.IndexFunc(query.Targets, func(t QueryDefTarget) bool {
+			return t.serverRef() == toServer.ServerRef && t.Catalog == source.Catalog
+		})
+		if i < 0 {
+			promotion.Change = ChangeTypeAdded
+			query.Targets = append(query.Targets, target)
+		} else {
+			previous := query.Targets[i]
+			promotion.Previous = &previous
+			target.CredentialsID, target.Credentials = previous.CredentialsID, previous.Credentials
+			if target == previous {
+				promotion.Change = ChangeTypeUnchanged
+			} else {
+				promotion.Change = ChangeTypeAltered
+				query.Targets[i] = target
+			}
+		}
+		promotion.Target = target
+		promotions = append(promotions, promotion)
+	}
+	return promotions
+}
+
+// matchPromotionServer returns a server of the destination environment for a target or a reason why there is none
+func matchPromotionServer(source QueryDefTarget, toServers EnvDbServers) (*EnvDbServer, string) {
+	var candidates EnvDbServers
+	for _, server := range toServers {
+		if server.Driver == source.Driver {
+			candidates = append(candidates, server)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Sprintf("no %v servers in the destination environment", source.Driver)
+	}
+	if len(candidates) == 1 {
+		return candidates[0], ""
+	}
+	var withCatalog EnvDbServers
+	for _, server := range candidates {
+		if source.Catalog != "" && slices.Contains(server.Catalogs, source.Catalog) {
+			withCatalog = append(withCatalog, server)
+		}
+	}
+	if len(withCatalog) == 1 {
+		return withCatalog[0], ""
+	}
+	return nil, fmt.Sprintf("%v %v servers in the destination environment match catalog %q", len(withCatalog), source.Driver, source.Catalog)
+}
+
+func (v QueryDefTarget) serverRef() ServerRef {
+	return ServerRef{Driver: v.Driver, Host: v.Host, Port: v.Port}
+}
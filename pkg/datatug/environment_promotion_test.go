@@ -0,0 +1,94 @@
+package datatug
+
+import (
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerRewrites_Rewrite(t *testing.T) {
+	rewrites := ServerRewrites{
+		{FromHost: "dev-db", FromPort: 1433, ToHost: "stage-db", ToPort: 1533},
+		{FromHost: "dev-db", ToHost: "stage-db"},
+	}
+	for _, tt := range []struct {
+		name      string
+		server    ServerRef
+		expected  ServerRef
+		rewritten bool
+	}{
+		{name: "host_and_port", server: ServerRef{Driver: "sqlserver", Host: "dev-db", Port: 1433}, expected: ServerRef{Driver: "sqlserver", Host: "stage-db", Port: 1533}, rewritten: true},
+		{name: "any_port", server: ServerRef{Driver: "sqlserver", Host: "DEV-DB", Port: 1434}, expected: ServerRef{Driver: "sqlserver", Host: "stage-db", Port: 1434}, rewritten: true},
+		{name: "no_match", server: ServerRef{Driver: "sqlserver", Host: "other", Port: 1433}, expected: ServerRef{Driver: "sqlserver", Host: "other", Port: 1433}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, rewritten := rewrites.Rewrite(tt.server)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.rewritten, rewritten)
+		})
+	}
+}
+
+func TestCloneEnvironmentRequest_Validate(t *testing.T) {
+	test.IsValidRecord(t, "valid", CloneEnvironmentRequest{SourceID: "dev", ID: "stage", Rewrites: ServerRewrites{{FromHost: "a", ToHost: "b"}}})
+	test.IsInvalidRecord(t, "missing_source", CloneEnvironmentRequest{ID: "stage"})
+	test.IsInvalidRecord(t, "missing_id", CloneEnvironmentRequest{SourceID: "dev"})
+	test.IsInvalidRecord(t, "same_id", CloneEnvironmentRequest{SourceID: "dev", ID: "dev"})
+	test.IsInvalidRecord(t, "invalid_rewrite", CloneEnvironmentRequest{SourceID: "dev", ID: "stage", Rewrites: ServerRewrites{{FromHost: "a"}}})
+	test.IsInvalidRecord(t, "negative_port", CloneEnvironmentRequest{SourceID: "dev", ID: "stage", Rewrites: ServerRewrites{{FromHost: "a", ToHost: "b", ToPort: -1}}})
+}
+
+func TestPromoteTargetsRequest_Validate(t *testing.T) {
+	test.IsValidRecord(t, "valid", PromoteTargetsRequest{FromEnvID: "dev", ToEnvID: "stage", QueryIDs: []string{"q1"}})
+	test.IsInvalidRecord(t, "missing_from", PromoteTargetsRequest{ToEnvID: "stage", QueryIDs: []string{"q1"}})
+	test.IsInvalidRecord(t, "missing_to", PromoteTargetsRequest{FromEnvID: "dev", QueryIDs: []string{"q1"}})
+	test.IsInvalidRecord(t, "same_env", PromoteTargetsRequest{FromEnvID: "dev", ToEnvID: "dev", QueryIDs: []string{"q1"}})
+	test.IsInvalidRecord(t, "no_queries", PromoteTargetsRequest{FromEnvID: "dev", ToEnvID: "stage"})
+}
+
+func TestPromoteQueryTargets(t *testing.T) {
+	fromServers := EnvDbServers{
+		{ServerRef: ServerRef{Driver: "sqlserver", Host: "dev-db", Port: 1433}},
+		{ServerRef: ServerRef{Driver: "mysql", Host: "dev-mysql"}},
+		{ServerRef: ServerRef{Driver: "oracle", Host: "dev-oracle"}},
+	}
+	toServers := EnvDbServers{
+		{ServerRef: ServerRef{Driver: "sqlserver", Host: "stage-db", Port: 1433}},
+		{ServerRef: ServerRef{Driver: "mysql", Host: "stage-mysql-1"}, Catalogs: []string{"crm"}},
+		{ServerRef: ServerRef{Driver: "mysql", Host: "stage-mysql-2"}, Catalogs: []string{"billing"}},
+	}
+	existing := QueryDefTarget{Driver: "sqlserver", Host: "stage-db", Port: 1433, Catalog: "sales", CredentialsID: "stage-reader"}
+	query := newQueryDef(QueryTypeSQL, "SELECT 1")
+	query.Targets = []QueryDefTarget{
+		{Driver: "sqlserver", Host: "dev-db", Port: 1433, Catalog: "sales", Protocol: "tcp", CredentialsID: "dev-reader"},
+		{Driver: "mysql", Host: "dev-mysql", Catalog: "crm", Credentials: Credentials{Username: "dev"}},
+		{Driver: "oracle", Host: "dev-oracle", Catalog: "hr"},
+		{Driver: "sqlserver", Host: "unrelated", Catalog: "sales"},
+		existing,
+	}
+
+	promotions := promoteQueryTargets("q1", &query, fromServers, toServers)
+
+	if !assert.Len(t, promotions, 3) {
+		return
+	}
+	assert.Equal(t, ChangeTypeAltered, promotions[0].Change)
+	assert.Equal(t, &existing, promotions[0].Previous)
+	assert.Equal(t, QueryDefTarget{Driver: "sqlserver", Host: "stage-db", Port: 1433, Catalog: "sales", Protocol: "tcp", CredentialsID: "stage-reader"}, promotions[0].Target)
+
+	assert.Equal(t, ChangeTypeAdded, promotions[1].Change)
+	assert.Nil(t, promotions[1].Previous)
+	assert.Equal(t, QueryDefTarget{Driver: "mysql", Host: "stage-mysql-1", Catalog: "crm"}, promotions[1].Target, "credentials should not be promoted")
+
+	assert.NotEmpty(t, promotions[2].Skipped)
+	assert.Equal(t, "dev-oracle", promotions[2].Source.Host)
+
+	assert.Len(t, query.Targets, 6)
+	assert.Equal(t, promotions[0].Target, query.Targets[4])
+	assert.Equal(t, promotions[1].Target, query.Targets[5])
+
+	t.Run("unchanged", func(t *testing.T) {
+		assert.Equal(t, ChangeTypeUnchanged, promoteQueryTargets("q1", &query, fromServers, toServers)[0].Change)
+	})
+}
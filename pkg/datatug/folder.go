@@ -18,6 +18,8 @@ type Folder struct {
 	//Folders map[string]*FolderItem `json:"folders,omitempty" firestore:"folders,omitempty"`
 	//Boards  map[string]*FolderItem `json:"boards,omitempty" firestore:"boards,omitempty"`
 	//Queries map[string]*FolderItem `json:"queries,omitempty" firestore:"queries,omitempty"`
+
+	Revision string `json:"revision,omitempty" firestore:"-" yaml:"-"` // set by a store, see ProjItemBrief.Revision
 }
 
 func (v *Folder) GetID() string {
@@ -28,6 +30,14 @@ func (v *Folder) SetID(id string) {
 	v.Name = id
 }
 
+func (v *Folder) GetRevision() string {
+	return v.Revision
+}
+
+func (v *Folder) SetRevision(revision string) {
+	v.Revision = revision
+}
+
 // FolderBrief holds brief about a folder item
 type FolderBrief struct {
 	Title string `json:"title" firestore:"title"`
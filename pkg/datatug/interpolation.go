@@ -0,0 +1,114 @@
+package datatug
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownVar is returned when interpolated text references a variable that is not defined
+var ErrUnknownVar = errors.New("unknown variable")
+
+// ResolveVars merges variables of levels, variables of a later level override same-named variables of earlier levels.
+func ResolveVars(levels ...Variables) VarsByID {
+	vars := make(VarsByID)
+	for _, level := range levels {
+		for name, variable := range level.Vars {
+			vars[name] = variable
+		}
+	}
+	return vars
+}
+
+// ResolveVars returns variables in effect for a server of an environment: variables of the server override
+// variables of the environment that override variables of the project. Both env & server are optional.
+// Resolved values are validated against VarSettings of the project.
+func (p *Project) ResolveVars(env *Environment, server *EnvDbServer) (VarsByID, error) {
+	levels := []Variables{p.Variables}
+	if env != nil {
+		levels = append(levels, env.Variables)
+	}
+	if server != nil {
+		levels = append(levels, server.Variables)
+	}
+	vars := ResolveVars(levels...)
+	if err := ValidateVars(vars, p.VarSettings); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// Interpolate replaces ${name} references with values of variables, use $${ for a literal "${".
+// A reference to an undefined variable is an error that wraps ErrUnknownVar.
+func Interpolate(s string, vars VarsByID) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var result strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			result.WriteString(s)
+			return result.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			result.WriteString(s[:i-1])
+			result.WriteString("${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i+2:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed variable reference at: %v", s[i:])
+		}
+		name := s[i+2 : i+2+end]
+		variable, ok := vars[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %v", ErrUnknownVar, name)
+		}
+		result.WriteString(s[:i])
+		result.WriteString(variable.Value)
+		s = s[i+3+end:]
+	}
+}
+
+// Interpolate returns a copy of the query with variables interpolated into its text & targets
+func (v QueryDef) Interpolate(vars VarsByID) (query QueryDef, err error) {
+	query = v
+	if query.Text, err = Interpolate(v.Text, vars); err != nil {
+		return query, fmt.Errorf("failed to interpolate query text: %w", err)
+	}
+	if len(v.Targets) > 0 {
+		query.Targets = make([]QueryDefTarget, len(v.Targets))
+		for i, target := range v.Targets {
+			for _, field := range []*string{&target.Catalog, &target.Host, &target.Username, &target.Password} {
+				if *field, err = Interpolate(*field, vars); err != nil {
+					return query, fmt.Errorf("failed to interpolate target #%v: %w", i, err)
+				}
+			}
+			query.Targets[i] = target
+		}
+	}
+	return query, nil
+}
+
+// Interpolate returns a copy of the request with variables interpolated into its URL, headers & content
+func (v HTTPRequest) Interpolate(vars VarsByID) (request HTTPRequest, err error) {
+	request = v
+	if request.URL, err = Interpolate(v.URL, vars); err != nil {
+		return request, fmt.Errorf("failed to interpolate URL: %w", err)
+	}
+	if len(v.Headers) > 0 {
+		request.Headers = make(HTTPHeaders, len(v.Headers))
+		for i, header := range v.Headers {
+			if header.Value, err = Interpolate(header.Value, vars); err != nil {
+				return request, fmt.Errorf("failed to interpolate header %v: %w", header.Name, err)
+			}
+			request.Headers[i] = header
+		}
+	}
+	if request.Content, err = Interpolate(v.Content, vars); err != nil {
+		return request, fmt.Errorf("failed to interpolate content: %w", err)
+	}
+	return request, nil
+}
@@ -0,0 +1,115 @@
+package datatug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	vars := VarsByID{
+		"schema": {Type: "str", Value: "stage"},
+		"tenant": {Type: "int", Value: "42"},
+	}
+	for _, tt := range []struct {
+		name     string
+		s        string
+		expected string
+		err      error
+	}{
+		{name: "no_vars", s: "SELECT 1", expected: "SELECT 1"},
+		{name: "vars", s: "SELECT * FROM ${schema}.orders WHERE tenant_id = ${tenant}", expected: "SELECT * FROM stage.orders WHERE tenant_id = 42"},
+		{name: "adjacent", s: "${schema}${tenant}", expected: "stage42"},
+		{name: "escaped", s: "SELECT '$${schema}' AS ${schema}", expected: "SELECT '${schema}' AS stage"},
+		{name: "dollar", s: "SELECT $1, ${tenant}", expected: "SELECT $1, 42"},
+		{name: "unknown", s: "SELECT * FROM ${other}.orders", err: ErrUnknownVar},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := Interpolate(tt.s, vars)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+	t.Run("unclosed", func(t *testing.T) {
+		_, err := Interpolate("SELECT ${schema", vars)
+		assert.Error(t, err)
+	})
+}
+
+func TestProject_ResolveVars(t *testing.T) {
+	minTenant := 1
+	project := &Project{
+		Variables: Variables{Vars: VarsByID{
+			"schema": {Type: "str", Value: "public"},
+			"tenant": {Type: "int", Value: "1"},
+			"region": {Type: "str", Value: "eu"},
+		}},
+		VarSettings: VarSettings{
+			"tenant": {Type: "int", Min: &minTenant},
+		},
+	}
+	env := &Environment{Variables: Variables{Vars: VarsByID{
+		"schema": {Type: "str", Value: "stage"},
+		"tenant": {Type: "int", Value: "2"},
+	}}}
+	server := &EnvDbServer{Variables: Variables{Vars: VarsByID{
+		"tenant": {Type: "int", Value: "3"},
+	}}}
+
+	vars, err := project.ResolveVars(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, project.Vars, vars)
+
+	vars, err = project.ResolveVars(env, server)
+	require.NoError(t, err)
+	assert.Equal(t, VarsByID{
+		"schema": {Type: "str", Value: "stage"},
+		"tenant": {Type: "int", Value: "3"},
+		"region": {Type: "str", Value: "eu"},
+	}, vars)
+
+	server.Vars["tenant"] = VarInfo{Type: "int", Value: "0"}
+	_, err = project.ResolveVars(env, server)
+	assert.Error(t, err, "value less than min should fail validation")
+}
+
+func TestQueryDef_Interpolate(t *testing.T) {
+	vars := VarsByID{"schema": {Type: "str", Value: "stage"}, "host": {Type: "str", Value: "db.stage"}}
+	query := newQueryDef(QueryTypeSQL, "SELECT * FROM ${schema}.orders")
+	query.Targets = []QueryDefTarget{{Driver: "postgres", Host: "${host}", Catalog: "crm"}}
+
+	actual, err := query.Interpolate(vars)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM stage.orders", actual.Text)
+	assert.Equal(t, "db.stage", actual.Targets[0].Host)
+	assert.Equal(t, "${host}", query.Targets[0].Host, "original query should not be changed")
+
+	query.Targets[0].Catalog = "${catalog}"
+	_, err = query.Interpolate(vars)
+	assert.ErrorIs(t, err, ErrUnknownVar)
+}
+
+func TestHTTPRequest_Interpolate(t *testing.T) {
+	vars := VarsByID{"baseUrl": {Type: "str", Value: "https://stage.example.com"}, "tenant": {Type: "int", Value: "42"}}
+	request := HTTPRequest{
+		Method:  "POST",
+		URL:     "${baseUrl}/tenants/${tenant}",
+		Headers: HTTPHeaders{{Name: "X-Tenant", Value: "${tenant}"}},
+		Content: `{"tenant":${tenant}}`,
+	}
+	actual, err := request.Interpolate(vars)
+	require.NoError(t, err)
+	assert.Equal(t, "https://stage.example.com/tenants/42", actual.URL)
+	assert.Equal(t, HTTPHeaders{{Name: "X-Tenant", Value: "42"}}, actual.Headers)
+	assert.Equal(t, `{"tenant":42}`, actual.Content)
+	assert.Equal(t, "${tenant}", request.Headers[0].Value, "original request should not be changed")
+
+	request.Headers[0].Value = "${token}"
+	_, err = request.Interpolate(vars)
+	assert.ErrorIs(t, err, ErrUnknownVar)
+}
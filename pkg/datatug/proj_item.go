@@ -25,6 +25,9 @@ type ProjItemBrief struct {
 	// Document what is Folder? should it be moved somewhere?
 	Folder string `json:"folder,omitempty" firestore:"folder,omitempty" yaml:"folder,omitempty"` // TODO: document purpose and usage
 	ListOfTags
+	// Revision identifies stored version of an item, it's set by a store on load & save and is not persisted.
+	// If set on save the store checks that the stored item has not been changed since it was loaded.
+	Revision string `json:"revision,omitempty" firestore:"-" yaml:"-"`
 }
 
 func (v *ProjItemBrief) GetID() string {
@@ -35,6 +38,22 @@ func (v *ProjItemBrief) SetID(id string) {
 	v.ID = id
 }
 
+func (v *ProjItemBrief) GetTitle() string {
+	return v.Title
+}
+
+func (v *ProjItemBrief) GetFolder() string {
+	return v.Folder
+}
+
+func (v *ProjItemBrief) GetRevision() string {
+	return v.Revision
+}
+
+func (v *ProjItemBrief) SetRevision(revision string) {
+	v.Revision = revision
+}
+
 // ValidateWithOptions returns error if not valid
 func (v *ProjItemBrief) ValidateWithOptions(isTitleRequired bool) error {
 	if v.ID == "" {
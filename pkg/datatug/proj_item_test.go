@@ -1,6 +1,7 @@
 package datatug
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -105,3 +106,12 @@ func TestProjItemBrief_GetIDSetID(t *testing.T) {
 	v.SetID("i1")
 	assert.Equal(t, "i1", v.GetID())
 }
+
+func TestProjItemBrief_Revision(t *testing.T) {
+	v := ProjItemBrief{ID: "i1"}
+	v.SetRevision("r1")
+	assert.Equal(t, "r1", v.GetRevision())
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"i1","revision":"r1"}`, string(data))
+}
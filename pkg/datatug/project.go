@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/datatug/datatug-core/pkg/dtconfig"
 	"github.com/strongo/validation"
 )
 
@@ -44,6 +45,17 @@ type Project struct {
 
 	Actions    Actions            `json:"actions,omitempty" firestore:"actions,omitempty"`
 	Repository *ProjectRepository `json:"repository,omitempty" firestore:"repository,omitempty"`
+
+	// ItemsFormat is a format project items are written in, JSON if empty
+	ItemsFormat dtconfig.Format `json:"itemsFormat,omitempty" firestore:"itemsFormat,omitempty"`
+	// SQLQueryFiles makes SQL queries stored as .sql files with metadata in a leading comment
+	SQLQueryFiles bool `json:"sqlQueryFiles,omitempty" firestore:"sqlQueryFiles,omitempty"`
+	// SecretsPolicy defines what a store does with passwords & tokens found in project items on save
+	SecretsPolicy SecretsPolicy `json:"secretsPolicy,omitempty" firestore:"secretsPolicy,omitempty"`
+	// Variables are project defaults overridden by variables of environments & servers, see Project.ResolveVars
+	Variables
+	// VarSettings define types & constraints of variables
+	VarSettings VarSettings `json:"varSettings,omitempty" firestore:"varSettings,omitempty"`
 }
 
 func (p *Project) GetEnvironments(ctx context.Context) (environments Environments, err error) {
@@ -106,6 +118,21 @@ func (p *Project) Validate() error {
 	if l := len(p.Title); l > 100 {
 		return validation.NewErrBadRecordFieldValue("title", "too long title (max 100): "+strconv.Itoa(l))
 	}
+	if err := validateItemsFormat(p.ItemsFormat); err != nil {
+		return err
+	}
+	if err := p.SecretsPolicy.Validate(); err != nil {
+		return err
+	}
+	if err := p.Variables.Validate(); err != nil {
+		return err
+	}
+	if err := p.VarSettings.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateVars(p.Vars, p.VarSettings); err != nil {
+		return err
+	}
 
 	//log.Println("Validating environments...")
 	if err := p.Environments.Validate(); err != nil {
@@ -193,11 +220,27 @@ func (v *ProjectRepository) Validate() error {
 	return nil
 }
 
+// ProjectFormatVersion is a version of the layout & format of project files written by this version of DataTug.
+// Projects with an older version should be migrated by a store before use.
+const ProjectFormatVersion = 2
+
 // ProjectFile defines what to storage to project file
 type ProjectFile struct {
-	Created *ProjectCreated `json:"created,omitempty" firestore:"created,omitempty"`
+	// FormatVersion is 0 for projects created before the format got versioned
+	FormatVersion int             `json:"formatVersion,omitempty" firestore:"formatVersion,omitempty"`
+	Created       *ProjectCreated `json:"created,omitempty" firestore:"created,omitempty"`
 	ProjectItem
 	Repository *ProjectRepository `json:"repository,omitempty" firestore:"repository,omitempty"`
+	// ItemsFormat is a format project items are written in, JSON if empty
+	ItemsFormat dtconfig.Format `json:"itemsFormat,omitempty" firestore:"itemsFormat,omitempty"`
+	// SQLQueryFiles makes SQL queries stored as .sql files with metadata in a leading comment
+	SQLQueryFiles bool `json:"sqlQueryFiles,omitempty" firestore:"sqlQueryFiles,omitempty"`
+	// SecretsPolicy defines what a store does with passwords & tokens found in project items on save
+	SecretsPolicy SecretsPolicy `json:"secretsPolicy,omitempty" firestore:"secretsPolicy,omitempty"`
+	// Variables are project defaults overridden by variables of environments & servers, see Project.ResolveVars
+	Variables
+	// VarSettings define types & constraints of variables
+	VarSettings VarSettings `json:"varSettings,omitempty" firestore:"varSettings,omitempty"`
 	//DbModels     []*ProjDbModelBrief `json:"dbModels,omitempty" firestore:"dbModels,omitempty"`
 	//Entities     []*ProjEntityBrief  `json:"entities,omitempty" firestore:"entities,omitempty"`
 	//Environments []*ProjEnvBrief     `json:"environments,omitempty" firestore:"environments,omitempty"`
@@ -209,6 +252,21 @@ func (v ProjectFile) Validate() error {
 	//if err := v.ValidateWithOptions(); err != nil {
 	//	return err
 	//}
+	if v.FormatVersion < 0 {
+		return validation.NewErrBadRecordFieldValue("formatVersion", fmt.Sprintf("should not be negative, got: %v", v.FormatVersion))
+	}
+	if err := validateItemsFormat(v.ItemsFormat); err != nil {
+		return err
+	}
+	if err := v.SecretsPolicy.Validate(); err != nil {
+		return err
+	}
+	if err := v.Variables.Validate(); err != nil {
+		return err
+	}
+	if err := v.VarSettings.Validate(); err != nil {
+		return err
+	}
 	if v.Created == nil {
 		return validation.NewErrRecordIsMissingRequiredField("created")
 	}
@@ -247,3 +305,36 @@ type ProjectCreated struct {
 	//ByUsername string    `json:"byUsername,omitempty"`
 	At time.Time `json:"at" firestore:"at"`
 }
+
+func validateItemsFormat(format dtconfig.Format) error {
+	switch format {
+	case "", dtconfig.FormatJson, dtconfig.FormatYaml:
+		return nil
+	default:
+		return validation.NewErrBadRecordFieldValue("itemsFormat", fmt.Sprintf("expected 'json' or 'yaml', got: %v", format))
+	}
+}
+
+// SecretsPolicy defines what a store does with secrets found in project items on save.
+// A project without a policy, e.g. one created before policies were introduced, gets warnings
+// about found secrets in the log & its items are written as is.
+type SecretsPolicy string
+
+const (
+	// SecretsPolicyRefuse fails saving of an item with secrets, it's the default policy of new projects
+	SecretsPolicyRefuse SecretsPolicy = "refuse"
+	// SecretsPolicyStrip removes secrets from an item before writing it
+	SecretsPolicyStrip SecretsPolicy = "strip"
+	// SecretsPolicyAllow writes items as is
+	SecretsPolicyAllow SecretsPolicy = "allow"
+)
+
+// Validate returns error if not valid
+func (v SecretsPolicy) Validate() error {
+	switch v {
+	case "", SecretsPolicyRefuse, SecretsPolicyStrip, SecretsPolicyAllow:
+		return nil
+	default:
+		return validation.NewErrBadRecordFieldValue("secretsPolicy", fmt.Sprintf("expected 'refuse', 'strip' or 'allow', got: %v", v))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/datatug/datatug-core/pkg/dtconfig"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -178,4 +179,20 @@ func TestProjectFile_Validate(t *testing.T) {
 		v := ProjectFile{Created: &ProjectCreated{At: time.Now()}, ProjectItem: ProjectItem{Access: "unknown"}}
 		assert.Error(t, v.Validate())
 	})
+	t.Run("negative_format_version", func(t *testing.T) {
+		v := ProjectFile{FormatVersion: -1, Created: &ProjectCreated{At: time.Now()}, ProjectItem: ProjectItem{Access: "public"}}
+		assert.Error(t, v.Validate())
+	})
+	t.Run("items_format", func(t *testing.T) {
+		v := ProjectFile{ItemsFormat: dtconfig.FormatYaml, Created: &ProjectCreated{At: time.Now()}, ProjectItem: ProjectItem{Access: "public"}}
+		assert.NoError(t, v.Validate())
+		v.ItemsFormat = "xml"
+		assert.Error(t, v.Validate())
+	})
+	t.Run("secrets_policy", func(t *testing.T) {
+		v := ProjectFile{SecretsPolicy: SecretsPolicyStrip, Created: &ProjectCreated{At: time.Now()}, ProjectItem: ProjectItem{Access: "public"}}
+		assert.NoError(t, v.Validate())
+		v.SecretsPolicy = "ignore"
+		assert.Error(t, v.Validate())
+	})
 }
@@ -149,6 +149,12 @@ type QueryDef struct {
 	Recordsets []RecordsetDefinition `json:"recordsets,omitempty" yaml:"recordsets,omitempty"`
 }
 
+// TrimToBrief drops query text & recordsets definitions
+func (v *QueryDef) TrimToBrief() {
+	v.Text = ""
+	v.Recordsets = nil
+}
+
 // QueryDefTarget defines target of query
 type QueryDefTarget struct {
 	Driver   string `json:"driver,omitempty" yaml:"driver,omitempty"`
@@ -156,6 +162,9 @@ type QueryDefTarget struct {
 	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
 	Host     string `json:"host,omitempty" yaml:"host,omitempty"`
 	Port     int    `json:"port,omitempty" yaml:"port,omitempty"`
+	// CredentialsID references credentials kept by a credentials provider, so a password is not stored in a project.
+	// Embedded Credentials are supported for targets not migrated yet.
+	CredentialsID string `json:"credentialsId,omitempty" yaml:"credentialsId,omitempty"`
 	Credentials
 }
 
@@ -164,6 +173,11 @@ func (v QueryDef) Validate() error {
 	if err := v.ValidateWithOptions(true); err != nil {
 		return err
 	}
+	for i, target := range v.Targets {
+		if target.CredentialsID != "" && target.Password != "" {
+			return validation.NewErrBadRecordFieldValue(fmt.Sprintf("targets[%v].password", i), "should be empty if credentialsId is set")
+		}
+	}
 	switch v.Type {
 	case "":
 		return validation.NewErrRequestIsMissingRequiredField("type")
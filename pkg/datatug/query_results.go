@@ -0,0 +1,80 @@
+package datatug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/strongo/validation"
+)
+
+// DefaultMaxQueryResults is a number of results kept per query & environment if retention does not set it
+const DefaultMaxQueryResults = 10
+
+// ErrQueryResultNotFound is returned on an attempt to load a result that is not stored
+var ErrQueryResultNotFound = errors.New("query result not found")
+
+// QueryResultsStore keeps history of query results per query & environment
+type QueryResultsStore interface {
+	// SaveQueryResult stores a result & deletes results of the query in the same environment
+	// that are not kept by a retention policy
+	SaveQueryResult(ctx context.Context, queryID string, result *QueryResult) error
+	// ListQueryResults returns creation times of stored results, newest first
+	ListQueryResults(ctx context.Context, queryID, envID string) ([]time.Time, error)
+	// LoadQueryResult loads a result created at the given time, returns ErrQueryResultNotFound if there is none
+	LoadQueryResult(ctx context.Context, queryID, envID string, created time.Time) (*QueryResult, error)
+	// DeleteQueryResults deletes all stored results of a query in an environment
+	DeleteQueryResults(ctx context.Context, queryID, envID string) error
+}
+
+// QueryResultsRetention defines which results of a query are kept in an environment
+type QueryResultsRetention struct {
+	// MaxResults is a number of the newest results to keep, DefaultMaxQueryResults if 0
+	MaxResults int `json:"maxResults,omitempty"`
+	// MaxAge drops results older than that, results are kept regardless of age if 0
+	MaxAge time.Duration `json:"maxAge,omitempty"`
+}
+
+// Validate returns error if not valid
+func (v QueryResultsRetention) Validate() error {
+	if v.MaxResults < 0 {
+		return validation.NewErrBadRecordFieldValue("maxResults", fmt.Sprintf("should not be negative, got %v", v.MaxResults))
+	}
+	if v.MaxAge < 0 {
+		return validation.NewErrBadRecordFieldValue("maxAge", fmt.Sprintf("should not be negative, got %v", v.MaxAge))
+	}
+	return nil
+}
+
+// Expired returns creation times of results that should be deleted, the newest result is always kept
+func (v QueryResultsRetention) Expired(created []time.Time, now time.Time) (expired []time.Time) {
+	sorted := make([]time.Time, len(created))
+	copy(sorted, created)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].After(sorted[j]) })
+	maxResults := v.MaxResults
+	if maxResults == 0 {
+		maxResults = DefaultMaxQueryResults
+	}
+	for i, t := range sorted {
+		if i >= maxResults || i > 0 && v.MaxAge > 0 && now.Sub(t) > v.MaxAge {
+			expired = append(expired, t)
+		}
+	}
+	return expired
+}
+
+// ValidateQueryResultToStore checks a result can be stored for a query
+func ValidateQueryResultToStore(queryID string, result *QueryResult) error {
+	if queryID == "" {
+		return validation.NewErrRequestIsMissingRequiredField("queryID")
+	}
+	if result == nil {
+		return validation.NewErrRequestIsMissingRequiredField("result")
+	}
+	if result.EnvironmentID == "" {
+		return validation.NewErrRecordIsMissingRequiredField("env")
+	}
+	return result.Validate()
+}
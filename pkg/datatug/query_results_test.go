@@ -0,0 +1,35 @@
+package datatug
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryResultsRetention_Validate(t *testing.T) {
+	assert.NoError(t, QueryResultsRetention{}.Validate())
+	assert.NoError(t, QueryResultsRetention{MaxResults: 3, MaxAge: time.Hour}.Validate())
+	assert.Error(t, QueryResultsRetention{MaxResults: -1}.Validate())
+	assert.Error(t, QueryResultsRetention{MaxAge: -time.Hour}.Validate())
+}
+
+func TestQueryResultsRetention_Expired(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	created := []time.Time{ago(3 * time.Hour), ago(time.Minute), ago(2 * time.Hour), ago(time.Hour)}
+
+	assert.Empty(t, QueryResultsRetention{}.Expired(created, now), "default number of results should be kept")
+	assert.Equal(t, []time.Time{ago(2 * time.Hour), ago(3 * time.Hour)}, QueryResultsRetention{MaxResults: 2}.Expired(created, now))
+	assert.Equal(t, []time.Time{ago(2 * time.Hour), ago(3 * time.Hour)}, QueryResultsRetention{MaxAge: 90 * time.Minute}.Expired(created, now))
+	assert.Equal(t, []time.Time{ago(time.Hour), ago(2 * time.Hour), ago(3 * time.Hour)}, QueryResultsRetention{MaxAge: time.Second}.Expired(created, now),
+		"the newest result should be kept regardless of age")
+}
+
+func TestValidateQueryResultToStore(t *testing.T) {
+	result := &QueryResult{Created: time.Now(), EnvironmentID: "dev", Driver: "sqlite3", Target: "main"}
+	assert.NoError(t, ValidateQueryResultToStore("q1", result))
+	assert.Error(t, ValidateQueryResultToStore("", result))
+	assert.Error(t, ValidateQueryResultToStore("q1", nil))
+	assert.Error(t, ValidateQueryResultToStore("q1", &QueryResult{Created: time.Now(), Driver: "sqlite3", Target: "main"}))
+}
@@ -127,6 +127,13 @@ func TestQueryDef_Validate(t *testing.T) {
 		v.Targets = []QueryDefTarget{{Catalog: "c1"}}
 		test.IsInvalidRecord(t, "invalid", v)
 	})
+	t.Run("credentials_id", func(t *testing.T) {
+		v := newQueryDef("SQL", "SELECT 1")
+		v.Targets = []QueryDefTarget{{Driver: "sqlserver", CredentialsID: "prod-db", Credentials: Credentials{Username: "reader"}}}
+		test.IsValidRecord(t, "with_username", v)
+		v.Targets[0].Password = "secret"
+		test.IsInvalidRecord(t, "with_password", v)
+	})
 	t.Run("missing_type", func(t *testing.T) {
 		v := newQueryDef("SQL", "SELECT 1")
 		v.Type = ""
@@ -31,6 +31,12 @@ type RecordsetDefinition struct {
 	Errors     []string `json:"errors,omitempty"`
 }
 
+// TrimToBrief drops columns & JSON schema
+func (v *RecordsetDefinition) TrimToBrief() {
+	v.Columns = nil
+	v.JSONSchema = ""
+}
+
 // RecordsetColumnDefs is a slice of RecordsetColumnDef
 type RecordsetColumnDefs []RecordsetColumnDef
 
@@ -0,0 +1,509 @@
+package datatug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/strongo/validation"
+)
+
+// ErrNoPrimaryKey is returned on an attempt to diff recordsets by a primary key that is not defined
+var ErrNoPrimaryKey = errors.New("primary key is not defined")
+
+// ErrRowsNotSorted is returned by DiffSortedRows if rows are not sorted by key columns or have duplicate keys
+var ErrRowsNotSorted = errors.New("rows are not sorted by key columns")
+
+// RowDiff is a row that is added, deleted or altered between 2 recordsets
+type RowDiff struct {
+	Change ChangeType    `json:"change"`
+	Key    []interface{} `json:"key"`
+	// Old & New hold values of RecordsetDiffColumns.Columns, Old is nil for added rows & New is nil for deleted rows
+	Old []interface{} `json:"old,omitempty"`
+	New []interface{} `json:"new,omitempty"`
+	// Changes lists changed values of an altered row
+	Changes []ValueChange `json:"changes,omitempty"`
+}
+
+// ValueChange is a changed value of a column
+type ValueChange struct {
+	Column string      `json:"column"`
+	Old    interface{} `json:"old"`
+	New    interface{} `json:"new"`
+}
+
+// RecordsetDiffColumns describes columns of compared recordsets
+type RecordsetDiffColumns struct {
+	KeyColumns []string `json:"keyColumns"`
+	// Columns are compared columns that are present in both recordsets, names are taken from the "from" recordset
+	Columns []string `json:"columns"`
+	// AddedColumns are present only in the "to" recordset & DeletedColumns only in the "from" recordset
+	AddedColumns   []string `json:"addedColumns,omitempty"`
+	DeletedColumns []string `json:"deletedColumns,omitempty"`
+}
+
+// RecordsetDiffStats holds number of rows per change
+type RecordsetDiffStats struct {
+	Added     int `json:"added"`
+	Altered   int `json:"altered"`
+	Deleted   int `json:"deleted"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Count returns number of rows with the given change
+func (v RecordsetDiffStats) Count(change ChangeType) int {
+	switch change {
+	case ChangeTypeAdded:
+		return v.Added
+	case ChangeTypeAltered:
+		return v.Altered
+	case ChangeTypeDeleted:
+		return v.Deleted
+	default:
+		return v.Unchanged
+	}
+}
+
+func (v *RecordsetDiffStats) add(change ChangeType) {
+	switch change {
+	case ChangeTypeAdded:
+		v.Added++
+	case ChangeTypeAltered:
+		v.Altered++
+	case ChangeTypeDeleted:
+		v.Deleted++
+	default:
+		v.Unchanged++
+	}
+}
+
+// RecordsetDiffWriter receives a diff as it's computed: columns first, then changed rows & stats last
+type RecordsetDiffWriter interface {
+	WriteColumns(columns RecordsetDiffColumns) error
+	WriteRow(row RowDiff) error
+	WriteStats(stats RecordsetDiffStats) error
+}
+
+var _ RecordsetDiffWriter = (*RecordsetDiff)(nil)
+
+// RecordsetDiff holds differences between rows of 2 recordsets matched by key columns
+type RecordsetDiff struct {
+	RecordsetDiffColumns
+	Rows []RowDiff `json:"rows,omitempty"`
+	RecordsetDiffStats
+}
+
+// WriteColumns implements RecordsetDiffWriter, so a diff can be collected from DiffSortedRows
+func (v *RecordsetDiff) WriteColumns(columns RecordsetDiffColumns) error {
+	v.RecordsetDiffColumns = columns
+	return nil
+}
+
+// WriteRow implements RecordsetDiffWriter
+func (v *RecordsetDiff) WriteRow(row RowDiff) error {
+	v.Rows = append(v.Rows, row)
+	return nil
+}
+
+// WriteStats implements RecordsetDiffWriter
+func (v *RecordsetDiff) WriteStats(stats RecordsetDiffStats) error {
+	v.RecordsetDiffStats = stats
+	return nil
+}
+
+// WriteRecordsetDiff writes a computed diff to a writer, e.g. to render it
+func WriteRecordsetDiff(w RecordsetDiffWriter, diff RecordsetDiff) error {
+	if err := w.WriteColumns(diff.RecordsetDiffColumns); err != nil {
+		return err
+	}
+	for _, row := range diff.Rows {
+		if err := w.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return w.WriteStats(diff.RecordsetDiffStats)
+}
+
+// PrimaryKeyColumns returns columns of the primary key or ErrNoPrimaryKey
+func (v RecordsetBaseDef) PrimaryKeyColumns() ([]string, error) {
+	if v.PrimaryKey == nil || len(v.PrimaryKey.Columns) == 0 {
+		return nil, ErrNoPrimaryKey
+	}
+	return v.PrimaryKey.Columns, nil
+}
+
+// DiffRecordsetsByPrimaryKey compares 2 recordsets by the primary key of their definition, see DiffRecordsets
+func DiffRecordsetsByPrimaryKey(def RecordsetBaseDef, from, to Recordset) (RecordsetDiff, error) {
+	keyColumns, err := def.PrimaryKeyColumns()
+	if err != nil {
+		return RecordsetDiff{}, err
+	}
+	return DiffRecordsets(from, to, keyColumns)
+}
+
+// DiffRecordsets matches rows of 2 recordsets by key columns & compares values of columns present in both.
+// Columns are matched by name, case-insensitively if there is no exact match.
+// Values are compared after normalization, so the same number returned as int64 by a driver & as float64
+// by a JSON decoder is not a change. Strings in key columns are parsed as numbers or times only if DbType
+// of the column is numeric or date & time, so keys of text columns are matched as strings.
+// Altered & deleted rows are listed in order of the from recordset followed by added rows in order of the to recordset.
+// Both recordsets are held in memory, use DiffSortedRows for large recordsets.
+func DiffRecordsets(from, to Recordset, keyColumns []string) (diff RecordsetDiff, err error) {
+	columns, err := matchDiffColumns(from.Columns, to.Columns, keyColumns)
+	if err != nil {
+		return diff, err
+	}
+	w := newDiffRowsWriter(&diff, columns)
+	if err = w.begin(); err != nil {
+		return diff, err
+	}
+	fromRows, err := columns.indexRows("from", from.Rows, columns.from)
+	if err != nil {
+		return diff, err
+	}
+	toRows, err := columns.indexRows("to", to.Rows, columns.to)
+	if err != nil {
+		return diff, err
+	}
+	for _, row := range fromRows.rows {
+		toRow, ok := toRows.byKey[columns.keyOf(row)]
+		if !ok {
+			_ = w.deleted(row)
+		} else {
+			_ = w.matched(row, toRow)
+		}
+	}
+	for _, row := range toRows.rows {
+		if _, ok := fromRows.byKey[columns.keyOf(row)]; !ok {
+			_ = w.added(row)
+		}
+	}
+	return diff, w.end()
+}
+
+// RowsReader reads rows of a recordset one by one, Next returns io.EOF after the last row
+type RowsReader interface {
+	Columns() []RecordsetColumn
+	Next() ([]interface{}, error)
+}
+
+// NewRowsReader creates a reader of rows of a recordset held in memory
+func NewRowsReader(recordset Recordset) RowsReader {
+	return &recordsetRowsReader{recordset: recordset}
+}
+
+type recordsetRowsReader struct {
+	recordset Recordset
+	next      int
+}
+
+func (r *recordsetRowsReader) Columns() []RecordsetColumn {
+	return r.recordset.Columns
+}
+
+func (r *recordsetRowsReader) Next() ([]interface{}, error) {
+	if r.next >= len(r.recordset.Rows) {
+		return nil, io.EOF
+	}
+	r.next++
+	return r.recordset.Rows[r.next-1], nil
+}
+
+// DiffSortedRows compares rows of 2 readers sorted by key columns in ascending order, e.g. by "ORDER BY {primary key}".
+// Rows are merge-joined, so only the current row of each reader is held in memory, & changes are written
+// to w in order of keys as they are found. Keys are ordered after normalization as in DiffRecordsets,
+// so rows sorted by a collation that orders them differently fail with ErrRowsNotSorted.
+func DiffSortedRows(ctx context.Context, from, to RowsReader, keyColumns []string, w RecordsetDiffWriter) error {
+	columns, err := matchDiffColumns(from.Columns(), to.Columns(), keyColumns)
+	if err != nil {
+		return err
+	}
+	rows := newDiffRowsWriter(w, columns)
+	if err = rows.begin(); err != nil {
+		return err
+	}
+	fromCursor := &sortedRowsCursor{name: "from", reader: from, columns: columns, indexes: columns.from}
+	toCursor := &sortedRowsCursor{name: "to", reader: to, columns: columns, indexes: columns.to}
+	if err = fromCursor.next(); err != nil {
+		return err
+	}
+	if err = toCursor.next(); err != nil {
+		return err
+	}
+	for fromCursor.row != nil || toCursor.row != nil {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		var cmp int
+		switch {
+		case fromCursor.row == nil:
+			cmp = 1
+		case toCursor.row == nil:
+			cmp = -1
+		default:
+			cmp = compareKeys(fromCursor.key, toCursor.key)
+		}
+		switch {
+		case cmp < 0:
+			err = rows.deleted(fromCursor.row)
+		case cmp > 0:
+			err = rows.added(toCursor.row)
+		default:
+			err = rows.matched(fromCursor.row, toCursor.row)
+		}
+		if err != nil {
+			return err
+		}
+		if cmp <= 0 {
+			if err = fromCursor.next(); err != nil {
+				return err
+			}
+		}
+		if cmp >= 0 {
+			if err = toCursor.next(); err != nil {
+				return err
+			}
+		}
+	}
+	return rows.end()
+}
+
+// DiffQueryResults compares recordsets of 2 results of the same query, keyColumns are key columns of each recordset
+func DiffQueryResults(from, to *QueryResult, keyColumns [][]string) ([]RecordsetDiff, error) {
+	if len(from.Recordsets) != len(to.Recordsets) {
+		return nil, fmt.Errorf("results have different number of recordsets: %v and %v", len(from.Recordsets), len(to.Recordsets))
+	}
+	if len(keyColumns) != len(from.Recordsets) {
+		return nil, validation.NewErrBadRequestFieldValue("keyColumns", fmt.Sprintf("expected key columns for %v recordsets, got %v", len(from.Recordsets), len(keyColumns)))
+	}
+	diffs := make([]RecordsetDiff, len(from.Recordsets))
+	for i := range from.Recordsets {
+		var err error
+		if diffs[i], err = DiffRecordsets(from.Recordsets[i], to.Recordsets[i], keyColumns[i]); err != nil {
+			return nil, fmt.Errorf("failed to diff recordsets[%v]: %w", i, err)
+		}
+	}
+	return diffs, nil
+}
+
+// DiffStoredQueryResults loads 2 stored results of a query in an environment & compares them, see DiffQueryResults
+func DiffStoredQueryResults(ctx context.Context, store QueryResultsStore, queryID, envID string, from, to time.Time, keyColumns [][]string) ([]RecordsetDiff, error) {
+	fromResult, err := store.LoadQueryResult(ctx, queryID, envID, from)
+	if err != nil {
+		return nil, err
+	}
+	toResult, err := store.LoadQueryResult(ctx, queryID, envID, to)
+	if err != nil {
+		return nil, err
+	}
+	return DiffQueryResults(fromResult, toResult, keyColumns)
+}
+
+// diffRow holds values of compared columns
+type diffRow []interface{}
+
+// diffColumns maps compared columns to columns of both recordsets
+type diffColumns struct {
+	RecordsetDiffColumns
+	from, to []int       // indexes of compared columns in rows of the recordsets
+	keys     []int       // indexes of key columns in compared columns
+	keyKinds []valueKind // kinds of key columns by their DB types, see normalizeKeyValue
+}
+
+func matchDiffColumns(from, to []RecordsetColumn, keyColumns []string) (c diffColumns, err error) {
+	if len(keyColumns) == 0 {
+		return c, validation.NewErrRequestIsMissingRequiredField("keyColumns")
+	}
+	c.KeyColumns = keyColumns
+	matched := make([]bool, len(to))
+	for i, column := range from {
+		j := indexOfColumn(len(to), func(j int) string { return to[j].Name }, column.Name, matched)
+		if j < 0 {
+			c.DeletedColumns = append(c.DeletedColumns, column.Name)
+			continue
+		}
+		matched[j] = true
+		c.Columns = append(c.Columns, column.Name)
+		c.from = append(c.from, i)
+		c.to = append(c.to, j)
+	}
+	for j, column := range to {
+		if !matched[j] {
+			c.AddedColumns = append(c.AddedColumns, column.Name)
+		}
+	}
+	c.keys = make([]int, len(keyColumns))
+	c.keyKinds = make([]valueKind, len(keyColumns))
+	for i, name := range keyColumns {
+		if c.keys[i] = indexOfColumn(len(c.Columns), func(j int) string { return c.Columns[j] }, name, nil); c.keys[i] < 0 {
+			return c, validation.NewErrBadRequestFieldValue("keyColumns", fmt.Sprintf("column %v is missing in one of recordsets", name))
+		}
+		dbType := from[c.from[c.keys[i]]].DbType
+		if dbType == "" {
+			dbType = to[c.to[c.keys[i]]].DbType
+		}
+		c.keyKinds[i] = keyKindOfDbType(dbType)
+	}
+	return c, nil
+}
+
+// indexOfColumn finds a column that is not excluded by name, case-insensitively if there is no exact match
+func indexOfColumn(count int, nameOf func(i int) string, name string, excluded []bool) int {
+	for _, equal := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		for i := 0; i < count; i++ {
+			if (excluded == nil || !excluded[i]) && equal(nameOf(i), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (c diffColumns) values(row []interface{}, indexes []int) diffRow {
+	values := make(diffRow, len(indexes))
+	for i, j := range indexes {
+		if j < len(row) {
+			values[i] = row[j]
+		}
+	}
+	return values
+}
+
+func (c diffColumns) key(row diffRow) []interface{} {
+	key := make([]interface{}, len(c.keys))
+	for i, j := range c.keys {
+		key[i] = row[j]
+	}
+	return key
+}
+
+func (c diffColumns) normalizedKey(row diffRow) []normalizedValue {
+	key := make([]normalizedValue, len(c.keys))
+	for i, j := range c.keys {
+		key[i] = normalizeKeyValue(row[j], c.keyKinds[i])
+	}
+	return key
+}
+
+// keyOf returns normalized key values encoded to JSON, so the same key of different Go types is matched
+func (c diffColumns) keyOf(row diffRow) string {
+	key := c.normalizedKey(row)
+	s := make([]string, len(key))
+	for i, v := range key {
+		s[i] = v.String()
+	}
+	encoded, _ := json.Marshal(s)
+	return string(encoded)
+}
+
+type rowsByKey struct {
+	rows  []diffRow
+	byKey map[string]diffRow
+}
+
+func (c diffColumns) indexRows(name string, rows [][]interface{}, indexes []int) (result rowsByKey, err error) {
+	result.rows = make([]diffRow, len(rows))
+	result.byKey = make(map[string]diffRow, len(rows))
+	for i, row := range rows {
+		values := c.values(row, indexes)
+		key := c.keyOf(values)
+		if _, duplicate := result.byKey[key]; duplicate {
+			return result, fmt.Errorf("%v recordset has duplicate key %v at row %v", name, c.key(values), i)
+		}
+		result.rows[i] = values
+		result.byKey[key] = values
+	}
+	return result, nil
+}
+
+func compareKeys(a, b []normalizedValue) int {
+	for i := range a {
+		if cmp := a[i].compare(b[i]); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// sortedRowsCursor holds the current row of a reader & checks rows come in order of keys
+type sortedRowsCursor struct {
+	name    string
+	reader  RowsReader
+	columns diffColumns
+	indexes []int
+	row     diffRow // nil after the last row
+	key     []normalizedValue
+	count   int
+}
+
+func (c *sortedRowsCursor) next() error {
+	values, err := c.reader.Next()
+	if errors.Is(err, io.EOF) {
+		c.row, c.key = nil, nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %v row %v: %w", c.name, c.count, err)
+	}
+	row := c.columns.values(values, c.indexes)
+	key := c.columns.normalizedKey(row)
+	if c.row != nil && compareKeys(c.key, key) >= 0 {
+		return fmt.Errorf("%w: %v row %v has key %v after %v", ErrRowsNotSorted, c.name, c.count, c.columns.key(row), c.columns.key(c.row))
+	}
+	c.row, c.key = row, key
+	c.count++
+	return nil
+}
+
+// diffRowsWriter classifies matched rows, counts changes & writes them
+type diffRowsWriter struct {
+	w       RecordsetDiffWriter
+	columns diffColumns
+	stats   RecordsetDiffStats
+}
+
+func newDiffRowsWriter(w RecordsetDiffWriter, columns diffColumns) *diffRowsWriter {
+	return &diffRowsWriter{w: w, columns: columns}
+}
+
+func (d *diffRowsWriter) begin() error {
+	return d.w.WriteColumns(d.columns.RecordsetDiffColumns)
+}
+
+func (d *diffRowsWriter) write(row RowDiff) error {
+	d.stats.add(row.Change)
+	return d.w.WriteRow(row)
+}
+
+func (d *diffRowsWriter) added(row diffRow) error {
+	return d.write(RowDiff{Change: ChangeTypeAdded, Key: d.columns.key(row), New: row})
+}
+
+func (d *diffRowsWriter) deleted(row diffRow) error {
+	return d.write(RowDiff{Change: ChangeTypeDeleted, Key: d.columns.key(row), Old: row})
+}
+
+func (d *diffRowsWriter) matched(from, to diffRow) error {
+	var changes []ValueChange
+	for i, name := range d.columns.Columns {
+		if !valuesEqual(from[i], to[i]) {
+			changes = append(changes, ValueChange{Column: name, Old: from[i], New: to[i]})
+		}
+	}
+	if len(changes) == 0 {
+		d.stats.add(ChangeTypeUnchanged)
+		return nil
+	}
+	return d.write(RowDiff{Change: ChangeTypeAltered, Key: d.columns.key(from), Old: from, New: to, Changes: changes})
+}
+
+func (d *diffRowsWriter) end() error {
+	return d.w.WriteStats(d.stats)
+}
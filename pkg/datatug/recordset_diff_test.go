@@ -0,0 +1,246 @@
+package datatug
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDiffRecordset(columns []string, rows ...[]interface{}) Recordset {
+	recordset := Recordset{Rows: rows}
+	for _, name := range columns {
+		recordset.Columns = append(recordset.Columns, RecordsetColumn{Name: name})
+	}
+	return recordset
+}
+
+func TestDiffRecordsets(t *testing.T) {
+	t.Run("rows", func(t *testing.T) {
+		from := newDiffRecordset([]string{"id", "name", "qty"},
+			[]interface{}{1, "one", 10},
+			[]interface{}{2, "two", 20},
+			[]interface{}{3, "three", 30},
+		)
+		to := newDiffRecordset([]string{"qty", "id", "name"},
+			[]interface{}{10.0, 1.0, "one"}, // numbers decoded from JSON are float64
+			[]interface{}{25, 3, "three"},
+			[]interface{}{40, 4, "four"},
+		)
+		diff, err := DiffRecordsets(from, to, []string{"id"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name", "qty"}, diff.Columns)
+		assert.Equal(t, []RowDiff{
+			{Change: ChangeTypeDeleted, Key: []interface{}{2}, Old: []interface{}{2, "two", 20}},
+			{Change: ChangeTypeAltered, Key: []interface{}{3}, Old: []interface{}{3, "three", 30}, New: []interface{}{3, "three", 25},
+				Changes: []ValueChange{{Column: "qty", Old: 30, New: 25}}},
+			{Change: ChangeTypeAdded, Key: []interface{}{4}, New: []interface{}{4, "four", 40}},
+		}, diff.Rows)
+		assert.Equal(t, RecordsetDiffStats{Added: 1, Altered: 1, Deleted: 1, Unchanged: 1}, diff.RecordsetDiffStats)
+		assert.Equal(t, 1, diff.Count(ChangeTypeUnchanged))
+	})
+	t.Run("composite_key_and_column_set_differences", func(t *testing.T) {
+		from := newDiffRecordset([]string{"order", "line", "sku"}, []interface{}{1, 1, "a"}, []interface{}{1, 2, "b"})
+		to := newDiffRecordset([]string{"ORDER", "line", "price"}, []interface{}{1, 2, 9.99}, []interface{}{1, 1, 5})
+		diff, err := DiffRecordsets(from, to, []string{"order", "line"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order", "line"}, diff.Columns, "columns should be matched case-insensitively")
+		assert.Equal(t, []string{"price"}, diff.AddedColumns)
+		assert.Equal(t, []string{"sku"}, diff.DeletedColumns)
+		assert.Empty(t, diff.Rows)
+		assert.Equal(t, 2, diff.Unchanged)
+	})
+	t.Run("type_normalization", func(t *testing.T) {
+		created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+		from := newDiffRecordset([]string{"id", "amount", "active", "created", "data"},
+			[]interface{}{int64(1), "10.50", true, created, []byte("x")},
+		)
+		to := newDiffRecordset([]string{"id", "amount", "active", "created", "data"},
+			[]interface{}{"1", 10.5, 1.0, "2024-03-01T11:00:00+01:00", "x"},
+		)
+		to.Columns[0].DbType = "BIGINT"
+		diff, err := DiffRecordsets(from, to, []string{"id"})
+		require.NoError(t, err)
+		assert.Empty(t, diff.Rows)
+		assert.Equal(t, 1, diff.Unchanged)
+
+		t.Run("time_key", func(t *testing.T) {
+			from := newDiffRecordset([]string{"day"}, []interface{}{created})
+			to := newDiffRecordset([]string{"day"}, []interface{}{"2024-03-01T10:00:00Z"})
+			to.Columns[0].DbType = "TIMESTAMP WITH TIME ZONE"
+			diff, err := DiffRecordsets(from, to, []string{"day"})
+			require.NoError(t, err)
+			assert.Equal(t, 1, diff.Unchanged)
+		})
+	})
+	t.Run("text_keys", func(t *testing.T) {
+		from := newDiffRecordset([]string{"code", "name"},
+			[]interface{}{"10", "ten"},
+			[]interface{}{"2024-01-01", "date"},
+			[]interface{}{"2024-01-01T00:00:00Z", "timestamp"},
+			[]interface{}{"010", "padded"},
+		)
+		from.Columns[0].DbType = "VARCHAR(20)"
+		to := newDiffRecordset([]string{"code", "name"},
+			[]interface{}{"10.0", "ten"},
+			[]interface{}{"2024-01-01", "date"},
+		)
+		diff, err := DiffRecordsets(from, to, []string{"code"})
+		require.NoError(t, err, "keys that look like the same number or time should be distinct strings")
+		assert.Equal(t, RecordsetDiffStats{Added: 1, Deleted: 3, Unchanged: 1}, diff.RecordsetDiffStats)
+	})
+	t.Run("errors", func(t *testing.T) {
+		rs := newDiffRecordset([]string{"id"}, []interface{}{1})
+		_, err := DiffRecordsets(rs, rs, nil)
+		assert.Error(t, err)
+		_, err = DiffRecordsets(rs, newDiffRecordset([]string{"name"}), []string{"id"})
+		assert.Error(t, err, "key column should be present in both recordsets")
+		_, err = DiffRecordsets(newDiffRecordset([]string{"id"}, []interface{}{1}, []interface{}{1.0}), rs, []string{"id"})
+		assert.Error(t, err, "duplicate keys should be rejected")
+	})
+}
+
+func TestDiffRecordsetsByPrimaryKey(t *testing.T) {
+	from := newDiffRecordset([]string{"id", "name"}, []interface{}{1, "one"})
+	to := newDiffRecordset([]string{"id", "name"}, []interface{}{1, "uno"})
+	diff, err := DiffRecordsetsByPrimaryKey(RecordsetBaseDef{PrimaryKey: &UniqueKey{Name: "PK", Columns: []string{"id"}}}, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, diff.KeyColumns)
+	assert.Equal(t, 1, diff.Altered)
+
+	_, err = DiffRecordsetsByPrimaryKey(RecordsetBaseDef{}, from, to)
+	assert.ErrorIs(t, err, ErrNoPrimaryKey)
+}
+
+type failingRowsReader struct {
+	RowsReader
+	err error
+}
+
+func (r failingRowsReader) Next() ([]interface{}, error) {
+	return nil, r.err
+}
+
+func TestDiffSortedRows(t *testing.T) {
+	ctx := context.Background()
+	columns := []string{"id", "name"}
+
+	t.Run("same_as_in_memory_diff", func(t *testing.T) {
+		from := newDiffRecordset(columns, []interface{}{1, "one"}, []interface{}{2, "two"}, []interface{}{4, "four"}, []interface{}{5, "five"})
+		to := newDiffRecordset(columns, []interface{}{1, "one"}, []interface{}{3, "three"}, []interface{}{4, "FOUR"}, []interface{}{6, "six"})
+		var streamed RecordsetDiff
+		require.NoError(t, DiffSortedRows(ctx, NewRowsReader(from), NewRowsReader(to), []string{"id"}, &streamed))
+		assert.Equal(t, []ChangeType{ChangeTypeDeleted, ChangeTypeAdded, ChangeTypeAltered, ChangeTypeDeleted, ChangeTypeAdded},
+			changesOf(streamed.Rows), "rows should be written in order of keys")
+
+		inMemory, err := DiffRecordsets(from, to, []string{"id"})
+		require.NoError(t, err)
+		assert.Equal(t, inMemory.RecordsetDiffColumns, streamed.RecordsetDiffColumns)
+		assert.Equal(t, inMemory.RecordsetDiffStats, streamed.RecordsetDiffStats)
+		assert.ElementsMatch(t, inMemory.Rows, streamed.Rows)
+	})
+	t.Run("text_keys", func(t *testing.T) {
+		// sorted by ORDER BY code of a VARCHAR column
+		from := newDiffRecordset(columns, []interface{}{"10", "ten"}, []interface{}{"9", "nine"}, []interface{}{"abc", "letters"})
+		to := newDiffRecordset(columns, []interface{}{"10", "TEN"}, []interface{}{"abc", "letters"})
+		var diff RecordsetDiff
+		require.NoError(t, DiffSortedRows(ctx, NewRowsReader(from), NewRowsReader(to), []string{"id"}, &diff))
+		assert.Equal(t, RecordsetDiffStats{Altered: 1, Deleted: 1, Unchanged: 1}, diff.RecordsetDiffStats)
+
+		numbers := newDiffRecordset(columns, []interface{}{"9", "nine"}, []interface{}{"10", "ten"})
+		numbers.Columns[0].DbType = "NUMERIC(10,0)"
+		require.NoError(t, DiffSortedRows(ctx, NewRowsReader(numbers), NewRowsReader(numbers), []string{"id"}, &diff),
+			"strings of numeric columns should be ordered as numbers")
+	})
+	t.Run("one_side_empty", func(t *testing.T) {
+		var diff RecordsetDiff
+		require.NoError(t, DiffSortedRows(ctx, NewRowsReader(newDiffRecordset(columns)), NewRowsReader(newDiffRecordset(columns, []interface{}{1, "one"})), []string{"id"}, &diff))
+		assert.Equal(t, 1, diff.Added)
+	})
+	t.Run("not_sorted", func(t *testing.T) {
+		unsorted := newDiffRecordset(columns, []interface{}{2, "two"}, []interface{}{1, "one"})
+		var diff RecordsetDiff
+		err := DiffSortedRows(ctx, NewRowsReader(unsorted), NewRowsReader(newDiffRecordset(columns)), []string{"id"}, &diff)
+		assert.ErrorIs(t, err, ErrRowsNotSorted)
+		duplicates := newDiffRecordset(columns, []interface{}{1, "one"}, []interface{}{1, "uno"})
+		err = DiffSortedRows(ctx, NewRowsReader(newDiffRecordset(columns)), NewRowsReader(duplicates), []string{"id"}, &diff)
+		assert.ErrorIs(t, err, ErrRowsNotSorted)
+	})
+	t.Run("read_error", func(t *testing.T) {
+		readErr := errors.New("connection lost")
+		rs := newDiffRecordset(columns)
+		var diff RecordsetDiff
+		err := DiffSortedRows(ctx, NewRowsReader(rs), failingRowsReader{RowsReader: NewRowsReader(rs), err: readErr}, []string{"id"}, &diff)
+		assert.ErrorIs(t, err, readErr)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		rs := newDiffRecordset(columns, []interface{}{1, "one"})
+		var diff RecordsetDiff
+		assert.ErrorIs(t, DiffSortedRows(ctx, NewRowsReader(rs), NewRowsReader(rs), []string{"id"}, &diff), context.Canceled)
+	})
+}
+
+func changesOf(rows []RowDiff) (changes []ChangeType) {
+	for _, row := range rows {
+		changes = append(changes, row.Change)
+	}
+	return
+}
+
+func TestNewRowsReader(t *testing.T) {
+	reader := NewRowsReader(newDiffRecordset([]string{"id"}, []interface{}{1}))
+	assert.Equal(t, "id", reader.Columns()[0].Name)
+	row, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{1}, row)
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestWriteRecordsetDiff(t *testing.T) {
+	diff := RecordsetDiff{
+		RecordsetDiffColumns: RecordsetDiffColumns{KeyColumns: []string{"id"}, Columns: []string{"id"}},
+		Rows:                 []RowDiff{{Change: ChangeTypeAdded, Key: []interface{}{1}, New: []interface{}{1}}},
+		RecordsetDiffStats:   RecordsetDiffStats{Added: 1},
+	}
+	var copied RecordsetDiff
+	require.NoError(t, WriteRecordsetDiff(&copied, diff))
+	assert.Equal(t, diff, copied)
+}
+
+type queryResultsStoreStub struct {
+	QueryResultsStore
+	results map[time.Time]*QueryResult
+}
+
+func (s queryResultsStoreStub) LoadQueryResult(_ context.Context, _, _ string, created time.Time) (*QueryResult, error) {
+	if result, ok := s.results[created]; ok {
+		return result, nil
+	}
+	return nil, ErrQueryResultNotFound
+}
+
+func TestDiffStoredQueryResults(t *testing.T) {
+	ctx := context.Background()
+	t1, t2 := time.Unix(1, 0), time.Unix(2, 0)
+	columns := []string{"id", "name"}
+	store := queryResultsStoreStub{results: map[time.Time]*QueryResult{
+		t1: {Recordsets: []Recordset{newDiffRecordset(columns, []interface{}{1, "one"})}},
+		t2: {Recordsets: []Recordset{newDiffRecordset(columns, []interface{}{1, "uno"})}},
+	}}
+	diffs, err := DiffStoredQueryResults(ctx, store, "q1", "dev", t1, t2, [][]string{{"id"}})
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, []ValueChange{{Column: "name", Old: "one", New: "uno"}}, diffs[0].Rows[0].Changes)
+
+	_, err = DiffStoredQueryResults(ctx, store, "q1", "dev", t1, time.Unix(3, 0), [][]string{{"id"}})
+	assert.ErrorIs(t, err, ErrQueryResultNotFound)
+	_, err = DiffStoredQueryResults(ctx, store, "q1", "dev", t1, t2, nil)
+	assert.Error(t, err, "key columns are required for each recordset")
+}
@@ -0,0 +1,282 @@
+package datatug
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// valueKind defines order of values of different kinds, see normalizedValue.compare
+type valueKind int
+
+const (
+	valueKindNull valueKind = iota
+	valueKindNumber
+	valueKindTime
+	valueKindString
+	valueKindOther
+)
+
+// normalizedValue is a recordset value converted to a form that does not depend on a driver or a serialization format,
+// e.g. int64 from a DB driver & float64 decoded from JSON are the same number
+type normalizedValue struct {
+	kind   valueKind
+	number *big.Rat
+	time   time.Time
+	text   string // for strings & values of other kinds formatted with %v
+	// parsed is true for numbers & times parsed from strings
+	parsed bool
+}
+
+// reCanonicalNumber matches strings that are numbers formatted without leading or trailing zeros,
+// so codes like "007" or amounts like "10.50" are kept as strings
+var reCanonicalNumber = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d*[1-9])?$`)
+
+var reNumber = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999", "2006-01-02"}
+
+func normalizeValue(v interface{}) normalizedValue {
+	switch v := v.(type) {
+	case nil:
+		return normalizedValue{kind: valueKindNull}
+	case bool:
+		if v {
+			return numberValue(big.NewRat(1, 1))
+		}
+		return numberValue(new(big.Rat))
+	case int:
+		return numberValue(new(big.Rat).SetInt64(int64(v)))
+	case int8:
+		return numberValue(new(big.Rat).SetInt64(int64(v)))
+	case int16:
+		return numberValue(new(big.Rat).SetInt64(int64(v)))
+	case int32:
+		return numberValue(new(big.Rat).SetInt64(int64(v)))
+	case int64:
+		return numberValue(new(big.Rat).SetInt64(v))
+	case uint:
+		return numberValue(new(big.Rat).SetUint64(uint64(v)))
+	case uint8:
+		return numberValue(new(big.Rat).SetUint64(uint64(v)))
+	case uint16:
+		return numberValue(new(big.Rat).SetUint64(uint64(v)))
+	case uint32:
+		return numberValue(new(big.Rat).SetUint64(uint64(v)))
+	case uint64:
+		return numberValue(new(big.Rat).SetUint64(v))
+	case float32:
+		return floatValue(float64(v), 32)
+	case float64:
+		return floatValue(v, 64)
+	case json.Number:
+		if n, ok := new(big.Rat).SetString(string(v)); ok {
+			return numberValue(n)
+		}
+		return normalizedValue{kind: valueKindString, text: string(v)}
+	case []byte:
+		return normalizeString(string(v))
+	case string:
+		return normalizeString(v)
+	case time.Time:
+		return normalizedValue{kind: valueKindTime, time: v.UTC()}
+	default:
+		return normalizedValue{kind: valueKindOther, text: fmt.Sprintf("%v", v)}
+	}
+}
+
+func numberValue(n *big.Rat) normalizedValue {
+	return normalizedValue{kind: valueKindNumber, number: n}
+}
+
+// floatValue uses the shortest decimal representation of a float, so 0.1 equals to "0.1" rather than to its binary value
+func floatValue(f float64, bitSize int) normalizedValue {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return normalizedValue{kind: valueKindString, text: strconv.FormatFloat(f, 'g', -1, bitSize)}
+	}
+	n, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	return numberValue(n)
+}
+
+func normalizeString(s string) normalizedValue {
+	if reCanonicalNumber.MatchString(s) {
+		if n, ok := new(big.Rat).SetString(s); ok {
+			return normalizedValue{kind: valueKindNumber, number: n, parsed: true}
+		}
+	}
+	if len(s) >= len("2006-01-02") && s[0] >= '0' && s[0] <= '9' {
+		if t, ok := parseTime(s); ok {
+			return normalizedValue{kind: valueKindTime, time: t, parsed: true}
+		}
+	}
+	return normalizedValue{kind: valueKindString, text: s}
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+var numberDbTypes = map[string]bool{
+	"INT": true, "INTEGER": true, "TINYINT": true, "SMALLINT": true, "MEDIUMINT": true, "BIGINT": true,
+	"INT2": true, "INT4": true, "INT8": true, "SERIAL": true, "BIGSERIAL": true,
+	"DECIMAL": true, "NUMERIC": true, "NUMBER": true, "MONEY": true, "SMALLMONEY": true,
+	"FLOAT": true, "FLOAT4": true, "FLOAT8": true, "REAL": true, "DOUBLE": true,
+}
+
+var timeDbTypes = map[string]bool{
+	"DATE": true, "TIME": true, "TIMETZ": true, "DATETIME": true, "DATETIME2": true, "SMALLDATETIME": true,
+	"DATETIMEOFFSET": true, "TIMESTAMP": true, "TIMESTAMPTZ": true,
+}
+
+// keyKindOfDbType returns valueKindNumber or valueKindTime for columns of numeric or date & time DB types,
+// e.g. "BIGINT" or "TIMESTAMP WITH TIME ZONE", & valueKindString for other & unknown types
+func keyKindOfDbType(dbType string) valueKind {
+	if i := strings.IndexByte(dbType, '('); i >= 0 {
+		dbType = dbType[:i]
+	}
+	words := strings.Fields(strings.ToUpper(dbType))
+	if len(words) > 1 && words[0] == "UNSIGNED" {
+		words = words[1:]
+	}
+	switch {
+	case len(words) == 0:
+		return valueKindString
+	case numberDbTypes[words[0]]:
+		return valueKindNumber
+	case timeDbTypes[words[0]]:
+		return valueKindTime
+	default:
+		return valueKindString
+	}
+}
+
+// normalizeKeyValue normalizes a value of a key column. Unlike normalizeValue it parses a string as a number
+// or a time only if the column is of such a kind, so keys of text columns like "10" & "9" or "2024-01-01" &
+// "2024-01-01T00:00:00Z" are matched & ordered as strings, the same way a DB orders them.
+func normalizeKeyValue(v interface{}, kind valueKind) normalizedValue {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return normalizeValue(v)
+	}
+	switch kind {
+	case valueKindNumber:
+		if n, ok := parseNumber(s); ok {
+			return normalizedValue{kind: valueKindNumber, number: n, parsed: true}
+		}
+	case valueKindTime:
+		if t, ok := parseTime(s); ok {
+			return normalizedValue{kind: valueKindTime, time: t, parsed: true}
+		}
+	}
+	return normalizedValue{kind: valueKindString, text: s}
+}
+
+// compare orders values by kind & then by value
+func (v normalizedValue) compare(other normalizedValue) int {
+	if v.kind != other.kind {
+		return int(v.kind) - int(other.kind)
+	}
+	switch v.kind {
+	case valueKindNull:
+		return 0
+	case valueKindNumber:
+		return v.number.Cmp(other.number)
+	case valueKindTime:
+		return v.time.Compare(other.time)
+	default:
+		return strings.Compare(v.text, other.text)
+	}
+}
+
+// String returns a canonical representation that is equal for values that compare as equal
+func (v normalizedValue) String() string {
+	switch v.kind {
+	case valueKindNull:
+		return "null"
+	case valueKindNumber:
+		return "n:" + v.number.RatString()
+	case valueKindTime:
+		return "t:" + v.time.Format(time.RFC3339Nano)
+	case valueKindString:
+		return "s:" + v.text
+	default:
+		return "o:" + v.text
+	}
+}
+
+// valuesEqual compares recordset values after normalization,
+// a string that is not a canonical number, e.g. "10.50", equals to a number with the same value but not to a string
+func valuesEqual(a, b interface{}) bool {
+	na, nb := normalizeValue(a), normalizeValue(b)
+	if na.compare(nb) == 0 {
+		return true
+	}
+	switch {
+	case na.kind == valueKindString && nb.kind == valueKindNumber && !nb.parsed:
+		n, ok := parseNumber(na.text)
+		return ok && n.Cmp(nb.number) == 0
+	case na.kind == valueKindNumber && !na.parsed && nb.kind == valueKindString:
+		n, ok := parseNumber(nb.text)
+		return ok && n.Cmp(na.number) == 0
+	}
+	return false
+}
+
+func parseNumber(s string) (*big.Rat, bool) {
+	if !reNumber.MatchString(s) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// JSONNumberValue converts a number decoded from JSON with json.Decoder.UseNumber to a recordset value:
+// an integer that fits int64 is returned as int64 rather than float64 that can't hold values above 2^53,
+// e.g. of BIGINT keys, other numbers are returned as float64 or as json.Number if they don't fit it
+func JSONNumberValue(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n
+}
+
+// FormatRecordsetValue formats a value of a recordset for humans, e.g. for rendering of a diff.
+// NULL is returned for nil, callers that need to distinguish it from a "NULL" string should check for nil first.
+func FormatRecordsetValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return "0x" + hex.EncodeToString(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,77 @@
+package datatug
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValuesEqual(t *testing.T) {
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		a, b  interface{}
+		equal bool
+	}{
+		{nil, nil, true},
+		{1, int64(1), true},
+		{uint8(1), 1.0, true},
+		{float32(0.1), 0.1, true},
+		{0.1, "0.1", true},
+		{json.Number("42"), 42, true},
+		{"10.50", 10.5, true},
+		{10.5, "10.50", true},
+		{true, 1, true},
+		{false, 0, true},
+		{[]byte("abc"), "abc", true},
+		{created, "2024-03-01 10:00:00", true},
+		{created, created.In(time.FixedZone("CET", 3600)), true},
+		{"007", "7", false},
+		{"10.50", "10.5", false},
+		{"abc", "ABC", false},
+		{nil, "", false},
+		{nil, 0, false},
+		{1, 2, false},
+		{math.NaN(), 0, false},
+		{map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1}, true},
+	} {
+		assert.Equal(t, tt.equal, valuesEqual(tt.a, tt.b), "%#v == %#v", tt.a, tt.b)
+	}
+}
+
+func TestNormalizedValue_compare(t *testing.T) {
+	ordered := []interface{}{nil, -1, 0.5, "2", 10, "2024-01-01", "a", "b"}
+	for i := 1; i < len(ordered); i++ {
+		assert.Negative(t, normalizeValue(ordered[i-1]).compare(normalizeValue(ordered[i])), "%#v < %#v", ordered[i-1], ordered[i])
+	}
+}
+
+func TestKeyKindOfDbType(t *testing.T) {
+	for dbType, expected := range map[string]valueKind{
+		"":                         valueKindString,
+		"VARCHAR(10)":              valueKindString,
+		"uuid":                     valueKindString,
+		"INTERVAL":                 valueKindString,
+		"bigint":                   valueKindNumber,
+		"DECIMAL(10, 2)":           valueKindNumber,
+		"UNSIGNED BIGINT":          valueKindNumber,
+		"INT UNSIGNED":             valueKindNumber,
+		"DOUBLE PRECISION":         valueKindNumber,
+		"DATETIME2":                valueKindTime,
+		"TIMESTAMP WITH TIME ZONE": valueKindTime,
+	} {
+		assert.Equal(t, expected, keyKindOfDbType(dbType), dbType)
+	}
+}
+
+func TestFormatRecordsetValue(t *testing.T) {
+	assert.Equal(t, "NULL", FormatRecordsetValue(nil))
+	assert.Equal(t, "abc", FormatRecordsetValue([]byte("abc")))
+	assert.Equal(t, "0xff00", FormatRecordsetValue([]byte{0xff, 0}))
+	assert.Equal(t, "0.1", FormatRecordsetValue(float32(0.1)))
+	assert.Equal(t, "1e+21", FormatRecordsetValue(1e21))
+	assert.Equal(t, "2024-03-01T10:00:00Z", FormatRecordsetValue(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "42", FormatRecordsetValue(42))
+}
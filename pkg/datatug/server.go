@@ -69,26 +69,33 @@ func (v ServerRef) GetID() string {
 
 // Validate returns error if not valid
 func (v ServerRef) Validate() error {
-	switch v.Driver {
-	case "":
+	if v.Driver == "" {
 		return validation.NewErrRecordIsMissingRequiredField("driver")
-	case "sqlite3":
+	}
+	driver, ok := GetDriver(v.Driver)
+	if !ok {
+		return validation.NewErrBadRecordFieldValue("driver", fmt.Sprintf("unexpected value: %v", v.Driver))
+	}
+	if driver.FileBased {
 		if v.Host != "" {
-			return validation.NewErrBadRecordFieldValue("host", "cannot be used with sqlite3, got: "+v.Host)
+			return validation.NewErrBadRecordFieldValue("host", fmt.Sprintf("cannot be used with %v, got: %v", driver.Name, v.Host))
 		}
 		if v.Port != 0 {
-			return validation.NewErrBadRecordFieldValue("port", "cannot be used with sqlite3, got: "+strconv.Itoa(v.Port))
+			return validation.NewErrBadRecordFieldValue("port", fmt.Sprintf("cannot be used with %v, got: %v", driver.Name, v.Port))
+		}
+		if v.Path == "" {
+			return validation.NewErrRecordIsMissingRequiredField("path")
+		}
+	} else {
+		if v.Host == "" {
+			return validation.NewErrRecordIsMissingRequiredField("host")
+		}
+		if v.Port < 0 {
+			return validation.NewErrBadRecordFieldValue("port", "should be positive")
 		}
-	case "sqlserver", "mysql", "oracle":
-		//
-	default:
-		return validation.NewErrBadRecordFieldValue("driver", fmt.Sprintf("unexpected value: %v", v.Driver))
-	}
-	if v.Host == "" {
-		return validation.NewErrRecordIsMissingRequiredField("host")
 	}
-	if v.Port < 0 {
-		return validation.NewErrBadRecordFieldValue("port", "should be positive")
+	if driver.ValidateServer != nil {
+		return driver.ValidateServer(v)
 	}
 	return nil
 }
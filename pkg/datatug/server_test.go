@@ -55,9 +55,17 @@ func TestServerReference_Validate(t *testing.T) {
 	t.Run("sqlite_with_port", func(t *testing.T) {
 		assert.Error(t, ServerRef{Driver: "sqlite3", Port: 123}.Validate())
 	})
+	t.Run("sqlite_with_path", func(t *testing.T) {
+		assert.NoError(t, ServerRef{Driver: "sqlite3", Path: "/data"}.Validate())
+		assert.Error(t, ServerRef{Driver: "sqlite3"}.Validate())
+	})
 	t.Run("unknown_driver", func(t *testing.T) {
 		assert.Error(t, ServerRef{Driver: "unknown", Host: "localhost"}.Validate())
 	})
+	t.Run("alias", func(t *testing.T) {
+		assert.NoError(t, ServerRef{Driver: "pg", Host: "localhost"}.Validate())
+		assert.Error(t, ServerRef{Driver: "sqlite", Host: "localhost"}.Validate())
+	})
 	t.Run("missing_host", func(t *testing.T) {
 		assert.Error(t, ServerRef{Driver: "mysql"}.Validate())
 	})
@@ -0,0 +1,81 @@
+package datatug
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/strongo/validation"
+)
+
+// DefaultSSHPort is used to connect to a bastion with no port set
+const DefaultSSHPort = 22
+
+// SSHTunnel defines a bastion host a DB server is reachable through.
+// A bastion authenticates a user with a private key file or with keys of an SSH agent.
+type SSHTunnel struct {
+	Host string `json:"host"`
+	Port int    `json:"port,omitempty"` // DefaultSSHPort if not set
+	User string `json:"user"`
+	// KeyPath is a path to an unencrypted private key file, "~" is expanded to a home directory
+	KeyPath string `json:"keyPath,omitempty"`
+	// UseAgent authenticates with keys of an agent listening on SSH_AUTH_SOCK
+	UseAgent bool `json:"useAgent,omitempty"`
+	// KnownHostsPath is a path to a file with a public key of the bastion, ~/.ssh/known_hosts by default
+	KnownHostsPath string `json:"knownHostsPath,omitempty"`
+	// LocalPort is a port of the local end of the tunnel, a free port is used if not set
+	LocalPort int `json:"localPort,omitempty"`
+}
+
+// Address returns a "host:port" of the bastion
+func (v SSHTunnel) Address() string {
+	port := v.Port
+	if port == 0 {
+		port = DefaultSSHPort
+	}
+	return v.Host + ":" + strconv.Itoa(port)
+}
+
+// Validate returns error if not valid
+func (v SSHTunnel) Validate() error {
+	if strings.TrimSpace(v.Host) == "" {
+		return validation.NewErrRecordIsMissingRequiredField("host")
+	}
+	if strings.TrimSpace(v.User) == "" {
+		return validation.NewErrRecordIsMissingRequiredField("user")
+	}
+	if v.KeyPath == "" && !v.UseAgent {
+		return validation.NewErrBadRecordFieldValue("keyPath", "either a key path or an agent should be used for authentication")
+	}
+	for _, port := range []struct {
+		name  string
+		value int
+	}{
+		{"port", v.Port},
+		{"localPort", v.LocalPort},
+	} {
+		if port.value < 0 || port.value > 65535 {
+			return validation.NewErrBadRecordFieldValue(port.name, "should be in range 0-65535, got: "+strconv.Itoa(port.value))
+		}
+	}
+	return nil
+}
+
+// Interpolate returns a copy of the tunnel with variables interpolated into its host, user & paths
+func (v SSHTunnel) Interpolate(vars VarsByID) (tunnel SSHTunnel, err error) {
+	tunnel = v
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"host", &tunnel.Host},
+		{"user", &tunnel.User},
+		{"keyPath", &tunnel.KeyPath},
+		{"knownHostsPath", &tunnel.KnownHostsPath},
+	} {
+		if *field.value, err = Interpolate(*field.value, vars); err != nil {
+			return v, fmt.Errorf("failed to interpolate SSH tunnel %v: %w", field.name, err)
+		}
+	}
+	return tunnel, nil
+}
@@ -0,0 +1,44 @@
+package datatug
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHTunnel_Validate(t *testing.T) {
+	valid := SSHTunnel{Host: "bastion", User: "tunnel", KeyPath: "~/.ssh/id_ed25519"}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, SSHTunnel{Host: "bastion", User: "tunnel", UseAgent: true, LocalPort: 15432}.Validate())
+
+	for name, tunnel := range map[string]SSHTunnel{
+		"missing_host":   {User: "tunnel", UseAgent: true},
+		"missing_user":   {Host: "bastion", UseAgent: true},
+		"no_auth":        {Host: "bastion", User: "tunnel"},
+		"bad_port":       {Host: "bastion", User: "tunnel", UseAgent: true, Port: -1},
+		"bad_local_port": {Host: "bastion", User: "tunnel", UseAgent: true, LocalPort: 70000},
+	} {
+		assert.Error(t, tunnel.Validate(), name)
+	}
+}
+
+func TestSSHTunnel_Address(t *testing.T) {
+	assert.Equal(t, "bastion:22", SSHTunnel{Host: "bastion"}.Address())
+	assert.Equal(t, "bastion:2222", SSHTunnel{Host: "bastion", Port: 2222}.Address())
+}
+
+func TestSSHTunnel_Interpolate(t *testing.T) {
+	tunnel := SSHTunnel{Host: "bastion.${env}", User: "${user}", KeyPath: "~/.ssh/${env}"}
+	interpolated, err := tunnel.Interpolate(VarsByID{"env": {Type: "str", Value: "prod"}, "user": {Type: "str", Value: "ops"}})
+	assert.NoError(t, err)
+	assert.Equal(t, SSHTunnel{Host: "bastion.prod", User: "ops", KeyPath: "~/.ssh/prod"}, interpolated)
+	_, err = tunnel.Interpolate(nil)
+	assert.ErrorIs(t, err, ErrUnknownVar)
+}
+
+func TestEnvDbServer_Validate_SSHTunnel(t *testing.T) {
+	tunnel := &SSHTunnel{Host: "bastion", User: "tunnel", UseAgent: true}
+	assert.NoError(t, (&EnvDbServer{ServerRef: ServerRef{Driver: "postgres", Host: "db"}, SSHTunnel: tunnel}).Validate())
+	assert.Error(t, (&EnvDbServer{ServerRef: ServerRef{Driver: "postgres", Host: "db"}, SSHTunnel: &SSHTunnel{Host: "bastion"}}).Validate())
+	assert.Error(t, (&EnvDbServer{ServerRef: ServerRef{Driver: "sqlite3", Path: "/data"}, SSHTunnel: tunnel}).Validate())
+}
@@ -48,6 +48,13 @@ type ProjectStore interface {
 	EnvDbCatalogStore
 	ProjDbDriversStore
 	RecordsetDefinitionsStore
+	QueryResultsStore
+}
+
+// ProjectTransactor is implemented by project stores that can apply multiple changes all-or-nothing
+type ProjectTransactor interface {
+	// RunInTransaction runs f and if it returns an error reverts changes of queries, boards, entities & folders made by f
+	RunInTransaction(ctx context.Context, f func(ctx context.Context) error) error
 }
 
 type EnvironmentsStore interface {
@@ -57,6 +64,8 @@ type EnvironmentsStore interface {
 	SaveEnvironment(ctx context.Context, env *Environment) error
 	SaveEnvironments(ctx context.Context, envs Environments) error
 	DeleteEnvironment(ctx context.Context, id string) error
+	// CloneEnvironment creates an environment as a copy of another one including its servers & catalog snapshots
+	CloneEnvironment(ctx context.Context, request CloneEnvironmentRequest) (*Environment, error)
 }
 
 type EnvDbServersStore interface {
@@ -147,4 +156,9 @@ type RecordsetDefinitionsStore interface {
 	LoadRecordsetDefinitions(ctx context.Context, o ...StoreOption) ([]*RecordsetDefinition, error)
 	LoadRecordsetDefinition(ctx context.Context, id string, o ...StoreOption) (*RecordsetDefinition, error)
 	LoadRecordsetData(ctx context.Context, id string) (Recordset, error)
+	// LoadRecordsetDataFile loads rows of a single data file of a recordset, e.g. to copy data files one by one
+	LoadRecordsetDataFile(ctx context.Context, id, fileName string) (Recordset, error)
+	SaveRecordsetDefinition(ctx context.Context, def *RecordsetDefinition) error
+	// SaveRecordsetData writes rows to a data file of a recordset, the file should be listed by the recordset definition
+	SaveRecordsetData(ctx context.Context, id, fileName string, recordset Recordset) error
 }
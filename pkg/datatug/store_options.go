@@ -1,5 +1,11 @@
 package datatug
 
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
 func GetStoreOptions(opts ...StoreOption) (o StoreOptions) {
 	for _, opt := range opts {
 		opt(&o)
@@ -8,13 +14,41 @@ func GetStoreOptions(opts ...StoreOption) (o StoreOptions) {
 }
 
 type StoreOptions struct {
-	depth int
+	depth         int
+	tags          []string
+	limit         int
+	cursor        string
+	folderPrefix  string
+	titleContains string
+	modifiedSince time.Time
+	briefsOnly    bool
 }
 
 func (o StoreOptions) ToSlice() (options []StoreOption) {
 	if o.depth != 0 {
 		options = append(options, Depth(o.depth))
 	}
+	if len(o.tags) > 0 {
+		options = append(options, Tags(o.tags...))
+	}
+	if o.limit != 0 {
+		options = append(options, Limit(o.limit))
+	}
+	if o.cursor != "" {
+		options = append(options, Cursor(o.cursor))
+	}
+	if o.folderPrefix != "" {
+		options = append(options, FolderPrefix(o.folderPrefix))
+	}
+	if o.titleContains != "" {
+		options = append(options, TitleContains(o.titleContains))
+	}
+	if !o.modifiedSince.IsZero() {
+		options = append(options, ModifiedSince(o.modifiedSince))
+	}
+	if o.briefsOnly {
+		options = append(options, BriefsOnly())
+	}
 	return
 }
 
@@ -22,10 +56,86 @@ func (o StoreOptions) Depth() int {
 	return o.depth
 }
 
+// Tags returns tags that loaded items are filtered by
+func (o StoreOptions) Tags() []string {
+	return o.tags
+}
+
+// Limit returns max number of items to load, 0 means no limit
+func (o StoreOptions) Limit() int {
+	return o.limit
+}
+
+// Cursor returns ID of an item after which loading of a page starts
+func (o StoreOptions) Cursor() string {
+	return o.cursor
+}
+
+// FolderPrefix returns folder path that loaded items should be in
+func (o StoreOptions) FolderPrefix() string {
+	return o.folderPrefix
+}
+
+// TitleContains returns a case-insensitive substring that titles of loaded items should contain
+func (o StoreOptions) TitleContains() string {
+	return o.titleContains
+}
+
+// ModifiedSince returns time after which loaded items should have been modified, zero time means no filter
+func (o StoreOptions) ModifiedSince() time.Time {
+	return o.modifiedSince
+}
+
+// BriefsOnly returns true if heavy payload of loaded items should be skipped
+func (o StoreOptions) BriefsOnly() bool {
+	return o.briefsOnly
+}
+
+// MatchTags returns true if item tags contain all tags of the filter
+func (o StoreOptions) MatchTags(itemTags []string) bool {
+	return ListOfTags{Tags: itemTags}.HasTags(o.tags...)
+}
+
+// HasItemFilters returns true if items are filtered by their content, see MatchItem
+func (o StoreOptions) HasItemFilters() bool {
+	return len(o.tags) > 0 || o.folderPrefix != "" || o.titleContains != ""
+}
+
+// MatchItem returns true if an item passes tags, folder prefix & title filters.
+// Items that do not expose a filtered property do not pass the filter.
+func (o StoreOptions) MatchItem(item any) bool {
+	if len(o.tags) > 0 {
+		if v, ok := item.(interface{ GetTags() []string }); !ok || !o.MatchTags(v.GetTags()) {
+			return false
+		}
+	}
+	if o.folderPrefix != "" {
+		v, ok := item.(interface{ GetFolder() string })
+		if !ok {
+			return false
+		}
+		prefix := strings.Trim(o.folderPrefix, FoldersPathSeparator)
+		folder := strings.Trim(v.GetFolder(), FoldersPathSeparator)
+		if folder != prefix && !strings.HasPrefix(folder, prefix+FoldersPathSeparator) {
+			return false
+		}
+	}
+	if o.titleContains != "" {
+		v, ok := item.(interface{ GetTitle() string })
+		if !ok || !strings.Contains(strings.ToLower(v.GetTitle()), strings.ToLower(o.titleContains)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Next returns options for loading nested items, pagination applies only to the top level items
 func (o StoreOptions) Next() StoreOptions {
 	if o.depth > 0 {
 		o.depth--
 	}
+	o.limit = 0
+	o.cursor = ""
 	return o
 }
 
@@ -36,3 +146,63 @@ func Depth(depth int) StoreOption {
 		op.depth = depth
 	}
 }
+
+// Tags filters loaded project items to ones that have all given tags, repeated options are combined
+func Tags(tags ...string) StoreOption {
+	return func(op *StoreOptions) {
+		for _, tag := range tags {
+			if !slices.Contains(op.tags, tag) {
+				op.tags = append(op.tags, tag)
+			}
+		}
+	}
+}
+
+// Limit sets max number of items to load
+func Limit(limit int) StoreOption {
+	return func(op *StoreOptions) {
+		op.limit = limit
+	}
+}
+
+// Cursor starts loading after an item with the given ID, items are ordered by ID.
+// To load the next page pass ID of the last item of the previous page.
+func Cursor(afterID string) StoreOption {
+	return func(op *StoreOptions) {
+		op.cursor = afterID
+	}
+}
+
+// FolderPrefix filters loaded project items to ones in the given folder or its subfolders
+func FolderPrefix(folderPath string) StoreOption {
+	return func(op *StoreOptions) {
+		op.folderPrefix = folderPath
+	}
+}
+
+// TitleContains filters loaded project items to ones with a title containing the given substring, ignoring case
+func TitleContains(substr string) StoreOption {
+	return func(op *StoreOptions) {
+		op.titleContains = substr
+	}
+}
+
+// ModifiedSince filters loaded project items to ones modified at or after the given time
+func ModifiedSince(t time.Time) StoreOption {
+	return func(op *StoreOptions) {
+		op.modifiedSince = t
+	}
+}
+
+// BriefsOnly skips heavy payload of loaded items, see BriefTrimmer.
+// Items loaded with this option are meant for listing, a store rejects saving them back (see storage.ErrBriefItem).
+func BriefsOnly() StoreOption {
+	return func(op *StoreOptions) {
+		op.briefsOnly = true
+	}
+}
+
+// BriefTrimmer is implemented by project items that have heavy payload not needed for listing of items
+type BriefTrimmer interface {
+	TrimToBrief()
+}
@@ -2,6 +2,7 @@ package datatug
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +37,96 @@ func TestGetStoreOptions(t *testing.T) {
 		assert.Equal(t, 20, opts.depth)
 	})
 }
+
+func TestTags(t *testing.T) {
+	opts := GetStoreOptions(Tags("a", "b"), Tags("b", "c"))
+	assert.Equal(t, []string{"a", "b", "c"}, opts.Tags())
+	assert.True(t, opts.MatchTags([]string{"c", "b", "a", "d"}))
+	assert.False(t, opts.MatchTags([]string{"a", "b"}))
+	assert.True(t, GetStoreOptions().MatchTags(nil), "no filter matches everything")
+	assert.Equal(t, opts, GetStoreOptions(opts.ToSlice()...))
+	assert.Equal(t, opts.Tags(), opts.Next().Tags(), "tags filter applies to nested items")
+}
+
+func TestStoreOptions_Pagination(t *testing.T) {
+	opts := GetStoreOptions(Depth(2), Limit(10), Cursor("b1"))
+	assert.Equal(t, 10, opts.Limit())
+	assert.Equal(t, "b1", opts.Cursor())
+	next := opts.Next()
+	assert.Equal(t, 1, next.Depth())
+	assert.Equal(t, 0, next.Limit(), "pagination should not apply to nested items")
+	assert.Equal(t, "", next.Cursor())
+}
+
+func TestStoreOptions_ToSlice(t *testing.T) {
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	opts := GetStoreOptions(
+		Depth(1), Tags("a"), Limit(5), Cursor("c"),
+		FolderPrefix("~/reports"), TitleContains("daily"), ModifiedSince(since), BriefsOnly(),
+	)
+	assert.Equal(t, opts, GetStoreOptions(opts.ToSlice()...))
+	assert.Empty(t, GetStoreOptions().ToSlice())
+}
+
+func TestStoreOptions_MatchItem(t *testing.T) {
+	item := &ProjItemBrief{ID: "q1", Title: "Daily Sales", Folder: "~/reports/sales", ListOfTags: ListOfTags{Tags: []string{"finance"}}}
+	tests := []struct {
+		name string
+		opts []StoreOption
+		want bool
+	}{
+		{name: "no_filters", want: true},
+		{name: "tags", opts: []StoreOption{Tags("finance")}, want: true},
+		{name: "missing_tag", opts: []StoreOption{Tags("finance", "crm")}, want: false},
+		{name: "folder", opts: []StoreOption{FolderPrefix("~/reports")}, want: true},
+		{name: "same_folder", opts: []StoreOption{FolderPrefix("~/reports/sales/")}, want: true},
+		{name: "partial_folder_name", opts: []StoreOption{FolderPrefix("~/rep")}, want: false},
+		{name: "title", opts: []StoreOption{TitleContains("SALES")}, want: true},
+		{name: "other_title", opts: []StoreOption{TitleContains("weekly")}, want: false},
+		{name: "all", opts: []StoreOption{Tags("finance"), FolderPrefix("~/reports"), TitleContains("daily")}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetStoreOptions(tt.opts...).MatchItem(item))
+		})
+	}
+	t.Run("item_without_properties", func(t *testing.T) {
+		assert.True(t, GetStoreOptions().MatchItem(struct{}{}))
+		assert.False(t, GetStoreOptions(TitleContains("x")).MatchItem(struct{}{}))
+		assert.False(t, GetStoreOptions(FolderPrefix("~")).MatchItem(struct{}{}))
+		assert.False(t, GetStoreOptions(Tags("x")).MatchItem(struct{}{}))
+	})
+}
+
+func TestBriefTrimmer(t *testing.T) {
+	t.Run("QueryDef", func(t *testing.T) {
+		q := &QueryDef{Text: "select 1", Recordsets: []RecordsetDefinition{{}}}
+		q.TrimToBrief()
+		assert.Empty(t, q.Text)
+		assert.Nil(t, q.Recordsets)
+	})
+	t.Run("Entity", func(t *testing.T) {
+		e := &Entity{Fields: EntityFields{{ID: "f1"}}, Tables: TableKeys{{}}}
+		e.TrimToBrief()
+		assert.Nil(t, e.Fields)
+		assert.Nil(t, e.Tables)
+	})
+	t.Run("DbCatalog", func(t *testing.T) {
+		table := &CollectionInfo{DDL: "create table t1", Columns: TableColumns{{}}, Indexes: []*Index{{}}, ReferencedBy: ReferencedBys{{}}}
+		view := &CollectionInfo{DDL: "create view v1"}
+		c := &DbCatalog{Schemas: DbSchemas{{Tables: []*CollectionInfo{table}, Views: []*CollectionInfo{view}}}}
+		c.TrimToBrief()
+		assert.Len(t, c.Schemas[0].Tables, 1, "list of tables should be kept")
+		assert.Empty(t, table.DDL)
+		assert.Nil(t, table.Columns)
+		assert.Nil(t, table.Indexes)
+		assert.Nil(t, table.ReferencedBy)
+		assert.Empty(t, view.DDL)
+	})
+	t.Run("RecordsetDefinition", func(t *testing.T) {
+		r := &RecordsetDefinition{Columns: RecordsetColumnDefs{{}}, JSONSchema: "{}"}
+		r.TrimToBrief()
+		assert.Nil(t, r.Columns)
+		assert.Empty(t, r.JSONSchema)
+	})
+}
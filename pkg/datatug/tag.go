@@ -2,11 +2,14 @@ package datatug
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/strongo/validation"
 )
 
-// ListOfTags mixing
+// ListOfTags mixing. Tags are matched case-insensitively, e.g. "Finance" & "finance" is the same tag,
+// the same way search matches them.
 type ListOfTags struct {
 	Tags []string `json:"tags,omitempty" firestore:"tags,omitempty"`
 }
@@ -33,3 +36,18 @@ func (v ListOfTags) Validate() error {
 	}
 	return nil
 }
+
+// GetTags returns tags
+func (v ListOfTags) GetTags() []string {
+	return v.Tags
+}
+
+// HasTags returns true if the list contains all given tags ignoring case
+func (v ListOfTags) HasTags(tags ...string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(v.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return false
+		}
+	}
+	return true
+}
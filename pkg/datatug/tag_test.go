@@ -52,3 +52,16 @@ func TestListOfTags_Validate(t *testing.T) { // TODO: test error type & text
 		}
 	})
 }
+
+func TestListOfTags_HasTags(t *testing.T) {
+	v := ListOfTags{Tags: []string{"a", "b"}}
+	if !v.HasTags() || !v.HasTags("a") || !v.HasTags("b", "a") || !v.HasTags("A") {
+		t.Error("expected to have tags")
+	}
+	if v.HasTags("c") || v.HasTags("a", "c") {
+		t.Error("expected not to have tags")
+	}
+	if got := v.GetTags(); len(got) != 2 {
+		t.Errorf("unexpected tags: %v", got)
+	}
+}
@@ -23,7 +23,7 @@ func (v VarSetting) Validate() error {
 	}
 	if v.ValuePattern != "" {
 		if _, err := regexp.Compile(v.ValuePattern); err != nil {
-			return validation.NewErrBadRecordFieldValue("valuePattern", "not a valida regular expression")
+			return validation.NewErrBadRecordFieldValue("valuePattern", "not a valid regular expression")
 		}
 	}
 	if v.Min != nil && v.Max != nil {
@@ -87,3 +87,69 @@ func (v Variables) Validate() error {
 	}
 	return nil
 }
+
+// ValidateValue returns error if a value of a variable does not match the setting.
+// Min & Max limit a value of an int variable and a length of a str variable.
+func (v VarSetting) ValidateValue(value string) error {
+	n := len([]rune(value))
+	switch v.Type {
+	case "int":
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return validation.NewErrBadRecordFieldValue("value", fmt.Sprintf("invalid value for int variable: %v", err))
+		}
+		n = i
+	case "str":
+	default:
+		return validateVarType(v.Type)
+	}
+	if v.Min != nil && n < *v.Min {
+		return validation.NewErrBadRecordFieldValue("value", fmt.Sprintf("expected to be at least %v, got: %v", *v.Min, n))
+	}
+	if v.Max != nil && n > *v.Max {
+		return validation.NewErrBadRecordFieldValue("value", fmt.Sprintf("expected to be at most %v, got: %v", *v.Max, n))
+	}
+	if v.ValuePattern != "" {
+		pattern, err := regexp.Compile(v.ValuePattern)
+		if err != nil {
+			return validation.NewErrBadRecordFieldValue("valuePattern", "not a valid regular expression")
+		}
+		if !pattern.MatchString(value) {
+			return validation.NewErrBadRecordFieldValue("value", fmt.Sprintf("does not match pattern %v: %v", v.ValuePattern, value))
+		}
+	}
+	return nil
+}
+
+// VarSettings defines variables by name
+type VarSettings map[string]VarSetting
+
+// Validate validates record
+func (v VarSettings) Validate() error {
+	for name, setting := range v {
+		if err := ValidateName(name); err != nil {
+			return err
+		}
+		if err := setting.Validate(); err != nil {
+			return fmt.Errorf("invalid setting of variable %v: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateVars returns error if variables do not match settings, variables without settings are not checked
+func ValidateVars(vars VarsByID, settings VarSettings) error {
+	for name, variable := range vars {
+		setting, ok := settings[name]
+		if !ok {
+			continue
+		}
+		if variable.Type != "" && variable.Type != setting.Type {
+			return validation.NewErrBadRecordFieldValue("vars."+name, fmt.Sprintf("expected to be of type %v, got: %v", setting.Type, variable.Type))
+		}
+		if err := setting.ValidateValue(variable.Value); err != nil {
+			return fmt.Errorf("invalid value of variable %v: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -108,3 +108,46 @@ func TestVariables_Validate(t *testing.T) {
 		}
 	})
 }
+
+func TestVarSetting_ValidateValue(t *testing.T) {
+	one, three := 1, 3
+	for _, tt := range []struct {
+		name    string
+		setting VarSetting
+		value   string
+		valid   bool
+	}{
+		{name: "str", setting: VarSetting{Type: "str"}, value: "abc", valid: true},
+		{name: "str_pattern", setting: VarSetting{Type: "str", ValuePattern: "^[a-z]+$"}, value: "abc", valid: true},
+		{name: "str_not_matching_pattern", setting: VarSetting{Type: "str", ValuePattern: "^[a-z]+$"}, value: "ABC"},
+		{name: "str_too_short", setting: VarSetting{Type: "str", Min: &one}, value: ""},
+		{name: "str_too_long", setting: VarSetting{Type: "str", Max: &three}, value: "abcd"},
+		{name: "int", setting: VarSetting{Type: "int", Min: &one, Max: &three}, value: "3", valid: true},
+		{name: "int_not_a_number", setting: VarSetting{Type: "int"}, value: "x"},
+		{name: "int_less_than_min", setting: VarSetting{Type: "int", Min: &one}, value: "0"},
+		{name: "int_greater_than_max", setting: VarSetting{Type: "int", Max: &three}, value: "4"},
+		{name: "unknown_type", setting: VarSetting{Type: "unknown"}, value: "1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.setting.ValidateValue(tt.value)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestVarSettings_Validate(t *testing.T) {
+	assert.NoError(t, VarSettings{"tenant": {Type: "int"}}.Validate())
+	assert.Error(t, VarSettings{"": {Type: "int"}}.Validate())
+	assert.Error(t, VarSettings{"tenant": {Type: "unknown"}}.Validate())
+}
+
+func TestValidateVars(t *testing.T) {
+	settings := VarSettings{"tenant": {Type: "int"}}
+	assert.NoError(t, ValidateVars(VarsByID{"tenant": {Type: "int", Value: "1"}, "other": {Type: "str", Value: "x"}}, settings))
+	assert.Error(t, ValidateVars(VarsByID{"tenant": {Type: "str", Value: "1"}}, settings), "type mismatch")
+	assert.Error(t, ValidateVars(VarsByID{"tenant": {Type: "int", Value: "x"}}, settings), "invalid value")
+}
@@ -0,0 +1,134 @@
+// Package datatug2html renders DataTug models as HTML
+package datatug2html
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+// RecordsetDiffClass is a CSS class of a table of a recordset diff,
+// rows have "added", "altered" or "deleted" classes & changed cells have "changed" class
+const RecordsetDiffClass = "datatug-recordset-diff"
+
+var changeClasses = map[datatug.ChangeType]string{
+	datatug.ChangeTypeAdded:   "added",
+	datatug.ChangeTypeAltered: "altered",
+	datatug.ChangeTypeDeleted: "deleted",
+}
+
+var changeSymbols = map[datatug.ChangeType]string{
+	datatug.ChangeTypeAdded:   "+",
+	datatug.ChangeTypeAltered: "~",
+	datatug.ChangeTypeDeleted: "-",
+}
+
+// NewRecordsetDiffWriter creates a writer that renders a recordset diff as an HTML table as it's computed,
+// so it can be used with datatug.DiffSortedRows for large recordsets
+func NewRecordsetDiffWriter(w io.Writer) datatug.RecordsetDiffWriter {
+	return &recordsetDiffWriter{w: w}
+}
+
+// RecordsetDiffToHTML renders a recordset diff as an HTML table
+func RecordsetDiffToHTML(w io.Writer, diff datatug.RecordsetDiff) error {
+	return datatug.WriteRecordsetDiff(NewRecordsetDiffWriter(w), diff)
+}
+
+type recordsetDiffWriter struct {
+	w             io.Writer
+	columns       datatug.RecordsetDiffColumns
+	headerWritten bool
+}
+
+func (r *recordsetDiffWriter) write(s string) error {
+	if _, err := io.WriteString(r.w, s); err != nil {
+		return fmt.Errorf("failed to write recordset diff: %w", err)
+	}
+	return nil
+}
+
+func (r *recordsetDiffWriter) WriteColumns(columns datatug.RecordsetDiffColumns) error {
+	r.columns = columns
+	var s strings.Builder
+	if len(columns.AddedColumns) > 0 {
+		s.WriteString("<p>Added columns: " + codeList(columns.AddedColumns) + "</p>\n")
+	}
+	if len(columns.DeletedColumns) > 0 {
+		s.WriteString("<p>Deleted columns: " + codeList(columns.DeletedColumns) + "</p>\n")
+	}
+	return r.write(s.String())
+}
+
+// writeHeader opens a table before the first row, so there is no empty table if rows are unchanged
+func (r *recordsetDiffWriter) writeHeader() error {
+	r.headerWritten = true
+	var s strings.Builder
+	s.WriteString(`<table class="` + RecordsetDiffClass + `">` + "\n<thead><tr><th></th>")
+	for _, column := range r.columns.Columns {
+		s.WriteString("<th>" + html.EscapeString(column) + "</th>")
+	}
+	s.WriteString("</tr></thead>\n<tbody>\n")
+	return r.write(s.String())
+}
+
+func (r *recordsetDiffWriter) WriteRow(row datatug.RowDiff) error {
+	if !r.headerWritten {
+		if err := r.writeHeader(); err != nil {
+			return err
+		}
+	}
+	values := row.New
+	if row.Change == datatug.ChangeTypeDeleted {
+		values = row.Old
+	}
+	var s strings.Builder
+	s.WriteString(`<tr class="` + changeClasses[row.Change] + `"><td>` + changeSymbols[row.Change] + "</td>")
+	for i := range r.columns.Columns {
+		if row.Change == datatug.ChangeTypeAltered && isChanged(row, r.columns.Columns[i]) {
+			s.WriteString(`<td class="changed"><del>` + htmlCell(row.Old[i]) + "</del> <ins>" + htmlCell(values[i]) + "</ins></td>")
+		} else {
+			s.WriteString("<td>" + htmlCell(values[i]) + "</td>")
+		}
+	}
+	s.WriteString("</tr>\n")
+	return r.write(s.String())
+}
+
+func (r *recordsetDiffWriter) WriteStats(stats datatug.RecordsetDiffStats) error {
+	var s strings.Builder
+	if r.headerWritten {
+		s.WriteString("</tbody>\n</table>\n")
+	} else {
+		s.WriteString("<p><i>No changed rows</i></p>\n")
+	}
+	_, _ = fmt.Fprintf(&s, "<p>Added: %v, Altered: %v, Deleted: %v, Unchanged: %v</p>\n",
+		stats.Added, stats.Altered, stats.Deleted, stats.Unchanged)
+	return r.write(s.String())
+}
+
+func isChanged(row datatug.RowDiff, column string) bool {
+	for _, change := range row.Changes {
+		if change.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+func htmlCell(v interface{}) string {
+	if v == nil {
+		return `<i class="null">NULL</i>`
+	}
+	return html.EscapeString(datatug.FormatRecordsetValue(v))
+}
+
+func codeList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "<code>" + html.EscapeString(name) + "</code>"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,41 @@
+package datatug2html
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordsetDiffToHTML(t *testing.T) {
+	from := datatug.Recordset{
+		Columns: []datatug.RecordsetColumn{{Name: "id"}, {Name: "name"}},
+		Rows:    [][]interface{}{{1, "<b>one</b>"}, {2, "two"}},
+	}
+	to := datatug.Recordset{
+		Columns: []datatug.RecordsetColumn{{Name: "id"}, {Name: "name"}, {Name: "qty"}},
+		Rows:    [][]interface{}{{1, nil, 5}, {3, "three", 7}},
+	}
+	diff, err := datatug.DiffRecordsets(from, to, []string{"id"})
+	require.NoError(t, err)
+	var buffer bytes.Buffer
+	require.NoError(t, RecordsetDiffToHTML(&buffer, diff))
+	assert.Equal(t, "<p>Added columns: <code>qty</code></p>\n"+
+		`<table class="datatug-recordset-diff">`+"\n"+
+		"<thead><tr><th></th><th>id</th><th>name</th></tr></thead>\n<tbody>\n"+
+		`<tr class="altered"><td>~</td><td>1</td><td class="changed"><del>&lt;b&gt;one&lt;/b&gt;</del> <ins><i class="null">NULL</i></ins></td></tr>`+"\n"+
+		`<tr class="deleted"><td>-</td><td>2</td><td>two</td></tr>`+"\n"+
+		`<tr class="added"><td>+</td><td>3</td><td>three</td></tr>`+"\n"+
+		"</tbody>\n</table>\n"+
+		"<p>Added: 1, Altered: 1, Deleted: 1, Unchanged: 0</p>\n", buffer.String())
+}
+
+func TestNewRecordsetDiffWriter(t *testing.T) {
+	rs := datatug.Recordset{Columns: []datatug.RecordsetColumn{{Name: "id"}}, Rows: [][]interface{}{{1}}}
+	var buffer bytes.Buffer
+	require.NoError(t, datatug.DiffSortedRows(context.Background(), datatug.NewRowsReader(rs), datatug.NewRowsReader(rs), []string{"id"}, NewRecordsetDiffWriter(&buffer)))
+	assert.Equal(t, "<p><i>No changed rows</i></p>\n<p>Added: 0, Altered: 0, Deleted: 0, Unchanged: 1</p>\n", buffer.String())
+}
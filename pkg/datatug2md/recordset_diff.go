@@ -0,0 +1,131 @@
+package datatug2md
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+var changeSymbols = map[datatug.ChangeType]string{
+	datatug.ChangeTypeAdded:   "+",
+	datatug.ChangeTypeAltered: "~",
+	datatug.ChangeTypeDeleted: "-",
+}
+
+var markdownCellEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;",
+	"\r\n", "<br>", "\n", "<br>",
+)
+
+// NewRecordsetDiffWriter creates a writer that renders a recordset diff as a Markdown table as it's computed,
+// so it can be used with datatug.DiffSortedRows for large recordsets
+func NewRecordsetDiffWriter(w io.Writer) datatug.RecordsetDiffWriter {
+	return &recordsetDiffWriter{w: w}
+}
+
+// RecordsetDiffToMarkdown renders a recordset diff as a Markdown table
+func RecordsetDiffToMarkdown(w io.Writer, diff datatug.RecordsetDiff) error {
+	return datatug.WriteRecordsetDiff(NewRecordsetDiffWriter(w), diff)
+}
+
+type recordsetDiffWriter struct {
+	w             io.Writer
+	columns       datatug.RecordsetDiffColumns
+	headerWritten bool
+}
+
+func (r *recordsetDiffWriter) write(s string) error {
+	if _, err := io.WriteString(r.w, s); err != nil {
+		return fmt.Errorf("failed to write recordset diff: %w", err)
+	}
+	return nil
+}
+
+func (r *recordsetDiffWriter) WriteColumns(columns datatug.RecordsetDiffColumns) error {
+	r.columns = columns
+	var s strings.Builder
+	if len(columns.AddedColumns) > 0 {
+		s.WriteString("Added columns: " + codeList(columns.AddedColumns) + "\n\n")
+	}
+	if len(columns.DeletedColumns) > 0 {
+		s.WriteString("Deleted columns: " + codeList(columns.DeletedColumns) + "\n\n")
+	}
+	return r.write(s.String())
+}
+
+// writeHeader writes a table header before the first row, so there is no empty table if rows are unchanged
+func (r *recordsetDiffWriter) writeHeader() error {
+	r.headerWritten = true
+	var s strings.Builder
+	s.WriteString("| |")
+	for _, column := range r.columns.Columns {
+		s.WriteString(" " + markdownCellEscaper.Replace(column) + " |")
+	}
+	s.WriteString("\n|---|")
+	for range r.columns.Columns {
+		s.WriteString("---|")
+	}
+	s.WriteString("\n")
+	return r.write(s.String())
+}
+
+func (r *recordsetDiffWriter) WriteRow(row datatug.RowDiff) error {
+	if !r.headerWritten {
+		if err := r.writeHeader(); err != nil {
+			return err
+		}
+	}
+	values := row.New
+	if row.Change == datatug.ChangeTypeDeleted {
+		values = row.Old
+	}
+	var s strings.Builder
+	s.WriteString("| " + changeSymbols[row.Change] + " |")
+	for i := range r.columns.Columns {
+		cell := markdownCell(values[i])
+		if row.Change == datatug.ChangeTypeAltered && isChanged(row, r.columns.Columns[i]) {
+			cell = "~~" + markdownCell(row.Old[i]) + "~~ → " + cell
+		}
+		s.WriteString(" " + cell + " |")
+	}
+	s.WriteString("\n")
+	return r.write(s.String())
+}
+
+func (r *recordsetDiffWriter) WriteStats(stats datatug.RecordsetDiffStats) error {
+	var s strings.Builder
+	if r.headerWritten {
+		s.WriteString("\n")
+	} else {
+		s.WriteString("*No changed rows*\n\n")
+	}
+	_, _ = fmt.Fprintf(&s, "**Added**: %v, **Altered**: %v, **Deleted**: %v, **Unchanged**: %v\n",
+		stats.Added, stats.Altered, stats.Deleted, stats.Unchanged)
+	return r.write(s.String())
+}
+
+func isChanged(row datatug.RowDiff, column string) bool {
+	for _, change := range row.Changes {
+		if change.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+func markdownCell(v interface{}) string {
+	if v == nil {
+		return "*NULL*"
+	}
+	return markdownCellEscaper.Replace(datatug.FormatRecordsetValue(v))
+}
+
+func codeList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "`" + name + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,39 @@
+package datatug2md
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordsetDiffToMarkdown(t *testing.T) {
+	t.Run("changes", func(t *testing.T) {
+		from := datatug.Recordset{
+			Columns: []datatug.RecordsetColumn{{Name: "id"}, {Name: "name"}, {Name: "sku"}},
+			Rows:    [][]interface{}{{1, "a|b", "x"}, {2, "two", "y"}, {3, nil, "z"}},
+		}
+		to := datatug.Recordset{
+			Columns: []datatug.RecordsetColumn{{Name: "id"}, {Name: "name"}},
+			Rows:    [][]interface{}{{1, "a|b"}, {3, "*three*"}, {4, "line 1\nline 2"}},
+		}
+		diff, err := datatug.DiffRecordsets(from, to, []string{"id"})
+		require.NoError(t, err)
+		var buffer bytes.Buffer
+		require.NoError(t, RecordsetDiffToMarkdown(&buffer, diff))
+		assert.Equal(t, "Deleted columns: `sku`\n\n"+
+			"| | id | name |\n"+
+			"|---|---|---|\n"+
+			"| - | 2 | two |\n"+
+			`| ~ | 3 | ~~*NULL*~~ → \*three\* |`+"\n"+
+			"| + | 4 | line 1<br>line 2 |\n"+
+			"\n**Added**: 1, **Altered**: 1, **Deleted**: 1, **Unchanged**: 1\n", buffer.String())
+	})
+	t.Run("no_changes", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, RecordsetDiffToMarkdown(&buffer, datatug.RecordsetDiff{RecordsetDiffStats: datatug.RecordsetDiffStats{Unchanged: 2}}))
+		assert.Equal(t, "*No changed rows*\n\n**Added**: 0, **Altered**: 0, **Deleted**: 0, **Unchanged**: 2\n", buffer.String())
+	})
+}
@@ -2,9 +2,11 @@ package dbconnection
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/datatug/datatug-core/pkg/datatug"
 	"github.com/strongo/validation"
 )
 
@@ -20,6 +22,7 @@ type GeneralParams struct {
 	user     string
 	password string
 	path     string
+	options  map[string]string // driver specific options, e.g. sslmode
 }
 
 // Mode returns mode
@@ -67,6 +70,53 @@ func (v GeneralParams) User() string {
 	return v.user
 }
 
+// Password returns password
+func (v GeneralParams) Password() string {
+	return v.password
+}
+
+// Options returns a copy of driver specific options
+func (v GeneralParams) Options() map[string]string {
+	if len(v.options) == 0 {
+		return nil
+	}
+	options := make(map[string]string, len(v.options))
+	for name, value := range v.options {
+		options[name] = value
+	}
+	return options
+}
+
+// Validate returns error if not valid
+func (v GeneralParams) Validate() error {
+	if v.driver == "" {
+		return validation.NewErrRecordIsMissingRequiredField("driver")
+	}
+	if err := validateMode(v.mode); err != nil {
+		return err
+	}
+	if v.port < 0 || v.port > 65535 {
+		return validation.NewErrBadRecordFieldValue("port", "should be in range 0-65535, got: "+strconv.Itoa(v.port))
+	}
+	if driver, ok := datatug.GetDriver(v.driver); ok && driver.FileBased {
+		if v.path == "" {
+			return validation.NewErrRecordIsMissingRequiredField("path")
+		}
+	} else if v.server == "" && v.path == "" { // a path is to a socket, e.g. for MySQL
+		return validation.NewErrRecordIsMissingRequiredField("server")
+	}
+	return nil
+}
+
+func validateMode(mode Mode) error {
+	switch mode {
+	case "", ModeReadOnly, ModeReadWrite:
+		return nil
+	default:
+		return validation.NewErrBadRecordFieldValue("mode", fmt.Sprintf("unsupported value, expected [%v, %v] but got: %v", ModeReadOnly, ModeReadWrite, mode))
+	}
+}
+
 // NewConnectionString creates new connection parameters
 func NewConnectionString(driver, server, user, password, database string, options ...string) (connectionString GeneralParams, err error) {
 	connectionString = GeneralParams{
@@ -96,31 +146,70 @@ func NewConnectionString(driver, server, user, password, database string, option
 				err = validation.NewErrBadRequestFieldValue("mode", fmt.Sprintf("unsupported value, expected [%v, %v] but got: %v", ModeReadOnly, ModeReadWrite, v))
 				return
 			}
+		default:
+			if connectionString.options == nil {
+				connectionString.options = make(map[string]string)
+			}
+			connectionString.options[name] = v
 		}
 	}
 
 	return
 }
 
+// Interpolate returns a copy of the params with variables interpolated into server, catalog, user, password & path
+func (v GeneralParams) Interpolate(vars datatug.VarsByID) (params GeneralParams, err error) {
+	params = v
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"server", &params.server},
+		{"catalog", &params.catalog},
+		{"user", &params.user},
+		{"password", &params.password},
+		{"path", &params.path},
+	} {
+		if *field.value, err = datatug.Interpolate(*field.value, vars); err != nil {
+			return v, fmt.Errorf("failed to interpolate %v: %w", field.name, err)
+		}
+	}
+	return params, nil
+}
+
 // String serializes connection parameters to a string
 func (v GeneralParams) String() string {
 	connectionParams := make([]string, 0, 8)
-	connectionParams = append(connectionParams, "server="+v.server)
+	connectionParams = append(connectionParams, "server="+quoteValue(v.server))
 	//connectionParams = append(connectionParams, fmt.Sprintf("ServerSPN=MSSQLSvc/%v:1433", v.server))
 	if v.port != 0 {
 		connectionParams = append(connectionParams, "port="+strconv.Itoa(v.port))
 	}
 	if v.user != "" {
-		connectionParams = append(connectionParams, "user id="+v.user)
+		connectionParams = append(connectionParams, "user id="+quoteValue(v.user))
 		if v.password != "" {
-			connectionParams = append(connectionParams, "password="+v.password)
+			connectionParams = append(connectionParams, "password="+quoteValue(v.password))
 		}
 	} else {
 		connectionParams = append(connectionParams, "trusted_connection=yes")
 	}
 
 	if v.catalog != "" {
-		connectionParams = append(connectionParams, "database="+v.catalog)
+		connectionParams = append(connectionParams, "database="+quoteValue(v.catalog))
+	}
+	if v.path != "" {
+		connectionParams = append(connectionParams, "path="+quoteValue(v.path))
+	}
+	if v.mode != "" {
+		connectionParams = append(connectionParams, "mode="+v.mode)
+	}
+	names := make([]string, 0, len(v.options))
+	for name := range v.options {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		connectionParams = append(connectionParams, name+"="+quoteValue(v.options[name]))
 	}
 	//return fmt.Sprintf(
 	//	"sqlserver://%v?Connection+Timeout=30&Database=%v&Integrated+Security=SSPI&TrustServerCertificate=true&encrypt=true",
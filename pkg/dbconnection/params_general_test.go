@@ -1,6 +1,11 @@
 package dbconnection
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
 
 func TestGeneralParams_Catalog(t *testing.T) {
 	expected := "TestCatalog"
@@ -90,3 +95,30 @@ func TestGeneralParams_ConnectionString(t *testing.T) {
 		t.Error("ConnectionString() should return same as String()")
 	}
 }
+
+func TestGeneralParams_Interpolate(t *testing.T) {
+	params, err := NewConnectionString("sqlserver", "${host}", "${user}", "${password}", "crm_${tenant}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := datatug.VarsByID{
+		"host":     {Type: "str", Value: "db.stage"},
+		"user":     {Type: "str", Value: "reader"},
+		"password": {Type: "str", Value: "s3cr3t"},
+		"tenant":   {Type: "str", Value: "acme"},
+	}
+	interpolated, err := params.Interpolate(vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "server=db.stage;user id=reader;password=s3cr3t;database=crm_acme"; interpolated.String() != expected {
+		t.Errorf("expected %v, got %v", expected, interpolated.String())
+	}
+	if params.Server() != "${host}" {
+		t.Error("original params should not be changed")
+	}
+	delete(vars, "tenant")
+	if _, err = params.Interpolate(vars); !errors.Is(err, datatug.ErrUnknownVar) {
+		t.Errorf("expected ErrUnknownVar, got: %v", err)
+	}
+}
@@ -1,5 +1,11 @@
 package dbconnection
 
+import (
+	"net/url"
+
+	"github.com/strongo/validation"
+)
+
 // DriverSQLite3 defines SQLite driver name
 const DriverSQLite3 = "sqlite3"
 
@@ -15,6 +21,7 @@ type SQLite3ConnectionParams struct {
 	catalog string
 	path    string
 	mode    Mode
+	options url.Values // URI parameters other than mode, e.g. cache=shared
 }
 
 // Driver returns driver
@@ -52,9 +59,39 @@ func (v SQLite3ConnectionParams) User() string {
 	return ""
 }
 
-// String serializes to string
+// Options returns a copy of URI parameters other than mode
+func (v SQLite3ConnectionParams) Options() url.Values {
+	if len(v.options) == 0 {
+		return nil
+	}
+	options := make(url.Values, len(v.options))
+	for name, values := range v.options {
+		options[name] = append([]string(nil), values...)
+	}
+	return options
+}
+
+// Validate returns error if not valid
+func (v SQLite3ConnectionParams) Validate() error {
+	if v.path == "" {
+		return validation.NewErrRecordIsMissingRequiredField("path")
+	}
+	return validateMode(v.mode)
+}
+
+// String serializes to a SQLite URI, e.g. "file:/data/app.db?mode=ro"
 func (v SQLite3ConnectionParams) String() string {
 	s := "file:" + v.path
+	query := v.Options()
+	if v.mode != "" {
+		if query == nil {
+			query = make(url.Values, 1)
+		}
+		query.Set("mode", v.mode)
+	}
+	if len(query) > 0 {
+		s += "?" + query.Encode()
+	}
 	return s
 }
 
@@ -32,7 +32,7 @@ func TestNewSQLite3ConnectionParams(t *testing.T) {
 		t.Errorf("expected empty user, got %v", params.User())
 	}
 
-	expectedString := "file:" + path
+	expectedString := "file:" + path + "?mode=ro"
 	if params.String() != expectedString {
 		t.Errorf("expected string %v, got %v", expectedString, params.String())
 	}
@@ -0,0 +1,76 @@
+package dbdriver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PlaceholderStyle defines how parameters are referenced in SQL statements
+type PlaceholderStyle int
+
+const (
+	// PlaceholderQuestion references parameters by position as "?", e.g. MySQL & SQLite
+	PlaceholderQuestion PlaceholderStyle = iota
+	// PlaceholderDollar references parameters as "$1", "$2", ..., e.g. PostgreSQL
+	PlaceholderDollar
+	// PlaceholderAtP references parameters as "@p1", "@p2", ..., e.g. SQL Server
+	PlaceholderAtP
+	// PlaceholderColon references parameters as ":1", ":2", ..., e.g. Oracle
+	PlaceholderColon
+)
+
+// Dialect holds SQL syntax specifics of a driver
+type Dialect struct {
+	// QuoteStart & QuoteEnd enclose identifiers, e.g. `"` or `[` & `]`
+	QuoteStart   string
+	QuoteEnd     string
+	Placeholders PlaceholderStyle
+	// SupportsSchemas is false for drivers where objects of a catalog are not grouped by schemas, e.g. MySQL
+	SupportsSchemas bool
+
+	// HashComments is true if "#" starts a line comment, e.g. MySQL
+	HashComments bool
+	// BackslashEscapes is true if a backslash escapes quotes in strings, e.g. MySQL
+	BackslashEscapes bool
+	// ExecutableComments is true if "/*! ... */" comments are executed, e.g. MySQL
+	ExecutableComments bool
+	// DollarQuotes is true if strings can be quoted by $tag$, e.g. PostgreSQL
+	DollarQuotes bool
+	// BatchSeparator is a line that separates batches of statements, e.g. "GO" for SQL Server
+	BatchSeparator string
+
+	// ReadOnlyTx is true if a driver supports sql.TxOptions.ReadOnly
+	ReadOnlyTx bool
+	// ReadOnlyTxStatement makes a transaction read-only if a driver does not support sql.TxOptions.ReadOnly
+	ReadOnlyTxStatement string
+}
+
+// QuoteIdentifier encloses an identifier in quotes escaping quotes it contains
+func (v Dialect) QuoteIdentifier(name string) string {
+	return v.QuoteStart + strings.ReplaceAll(name, v.QuoteEnd, v.QuoteEnd+v.QuoteEnd) + v.QuoteEnd
+}
+
+// QuoteName quotes & joins parts of a qualified name, e.g. schema & table
+func (v Dialect) QuoteName(parts ...string) string {
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			quoted = append(quoted, v.QuoteIdentifier(part))
+		}
+	}
+	return strings.Join(quoted, ".")
+}
+
+// Placeholder returns a reference to a parameter by its 1-based position
+func (v Dialect) Placeholder(i int) string {
+	switch v.Placeholders {
+	case PlaceholderDollar:
+		return "$" + strconv.Itoa(i)
+	case PlaceholderAtP:
+		return "@p" + strconv.Itoa(i)
+	case PlaceholderColon:
+		return ":" + strconv.Itoa(i)
+	default:
+		return "?"
+	}
+}
@@ -0,0 +1,35 @@
+package dbdriver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialect_QuoteIdentifier(t *testing.T) {
+	sqlServer := Dialect{QuoteStart: "[", QuoteEnd: "]"}
+	assert.Equal(t, "[Order Details]", sqlServer.QuoteIdentifier("Order Details"))
+	assert.Equal(t, "[a]]b]", sqlServer.QuoteIdentifier("a]b"))
+	postgres := Dialect{QuoteStart: `"`, QuoteEnd: `"`}
+	assert.Equal(t, `"say ""hi"""`, postgres.QuoteIdentifier(`say "hi"`))
+}
+
+func TestDialect_QuoteName(t *testing.T) {
+	dialect := Dialect{QuoteStart: "`", QuoteEnd: "`"}
+	assert.Equal(t, "`sales`.`orders`", dialect.QuoteName("sales", "orders"))
+	assert.Equal(t, "`orders`", dialect.QuoteName("", "orders"))
+}
+
+func TestDialect_Placeholder(t *testing.T) {
+	for _, tt := range []struct {
+		style    PlaceholderStyle
+		expected string
+	}{
+		{PlaceholderQuestion, "?"},
+		{PlaceholderDollar, "$2"},
+		{PlaceholderAtP, "@p2"},
+		{PlaceholderColon, ":2"},
+	} {
+		assert.Equal(t, tt.expected, Dialect{Placeholders: tt.style}.Placeholder(2))
+	}
+}
@@ -0,0 +1,161 @@
+// Package dbdriver is a registry of DB drivers: a driver defines how to connect to servers,
+// how to read their schemas & SQL dialect specifics, so a new database is supported by registering a driver.
+package dbdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dbconnection"
+	"github.com/datatug/datatug-core/pkg/schemer"
+	"github.com/datatug/datatug-core/pkg/sshtunnel"
+)
+
+// ErrNoSchemaProvider is returned when schema of servers of a driver can't be read
+var ErrNoSchemaProvider = errors.New("driver has no schema provider")
+
+// ParamsRequest defines what connection params are built for
+type ParamsRequest struct {
+	Server      datatug.ServerRef
+	Catalog     string
+	Credentials datatug.Credentials
+	Mode        dbconnection.Mode
+	// SSHTunnel is set for servers reachable only through a bastion, it's used by Connect
+	SSHTunnel *datatug.SSHTunnel
+}
+
+// ParamsBuilder builds connection params, a server port is defaulted before it's called
+type ParamsBuilder func(request ParamsRequest) (dbconnection.Params, error)
+
+// SchemaProviderFactory creates a schema provider for a server
+type SchemaProviderFactory func(params dbconnection.Params) (schemer.SchemaProvider, error)
+
+// Driver is a plugin that adds support of a database
+type Driver struct {
+	datatug.DriverInfo
+	Dialect Dialect
+	// NewParams is optional, by default dbconnection.GeneralParams are built
+	NewParams ParamsBuilder
+	// NewSchemaProvider is optional, ErrNoSchemaProvider is returned by SchemaProvider if not set
+	NewSchemaProvider SchemaProviderFactory
+}
+
+// Params returns connection params for a server using the port of the driver if the server has none
+func (v Driver) Params(request ParamsRequest) (dbconnection.Params, error) {
+	if request.Server.Port == 0 && !v.FileBased {
+		request.Server.Port = v.DefaultPort
+	}
+	if v.NewParams != nil {
+		return v.NewParams(request)
+	}
+	var options []string
+	if request.Server.Port > 0 {
+		options = append(options, "port="+strconv.Itoa(request.Server.Port))
+	}
+	if request.Server.Path != "" {
+		options = append(options, "path="+request.Server.Path)
+	}
+	if request.Mode != "" {
+		options = append(options, "mode="+request.Mode)
+	}
+	return dbconnection.NewConnectionString(v.Name, request.Server.Host, request.Credentials.Username, request.Credentials.Password, request.Catalog, options...)
+}
+
+// Connect returns connection params for a server opening an SSH tunnel if the request has one,
+// so schema scanning, query execution & health checks connect to the local end of the tunnel.
+// The tunnel is closed by the returned closer or on cancellation of the context.
+func (v Driver) Connect(ctx context.Context, request ParamsRequest, options ...sshtunnel.Option) (dbconnection.Params, io.Closer, error) {
+	if request.SSHTunnel != nil && v.FileBased {
+		return nil, nil, fmt.Errorf("SSH tunnel can not be used with driver %v", v.Name)
+	}
+	server := datatug.EnvDbServer{ServerRef: request.Server, SSHTunnel: request.SSHTunnel}
+	local, tunnel, err := sshtunnel.OpenForServer(ctx, server, v.DefaultPort, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.Server = local
+	params, err := v.Params(request)
+	if err != nil {
+		_ = tunnel.Close()
+		return nil, nil, err
+	}
+	return params, tunnel, nil
+}
+
+// SchemaProvider returns a schema provider for a server
+func (v Driver) SchemaProvider(params dbconnection.Params) (schemer.SchemaProvider, error) {
+	if v.NewSchemaProvider == nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoSchemaProvider, v.Name)
+	}
+	return v.NewSchemaProvider(params)
+}
+
+var registry = struct {
+	sync.RWMutex
+	drivers map[string]Driver
+}{
+	drivers: make(map[string]Driver),
+}
+
+func init() {
+	sqlite := builtIn(dbconnection.DriverSQLite3, Dialect{QuoteStart: `"`, QuoteEnd: `"`, Placeholders: PlaceholderQuestion})
+	sqlite.NewParams = func(request ParamsRequest) (dbconnection.Params, error) {
+		return dbconnection.NewSQLite3ConnectionParams(request.Server.Path, request.Catalog, request.Mode), nil
+	}
+	for _, driver := range []Driver{
+		sqlite,
+		builtIn("sqlserver", Dialect{QuoteStart: "[", QuoteEnd: "]", Placeholders: PlaceholderAtP, SupportsSchemas: true,
+			BatchSeparator: "GO"}),
+		builtIn("mysql", Dialect{QuoteStart: "`", QuoteEnd: "`", Placeholders: PlaceholderQuestion,
+			HashComments: true, BackslashEscapes: true, ExecutableComments: true, ReadOnlyTx: true}),
+		builtIn("oracle", Dialect{QuoteStart: `"`, QuoteEnd: `"`, Placeholders: PlaceholderColon, SupportsSchemas: true,
+			ReadOnlyTxStatement: "SET TRANSACTION READ ONLY"}),
+		builtIn("postgres", Dialect{QuoteStart: `"`, QuoteEnd: `"`, Placeholders: PlaceholderDollar, SupportsSchemas: true,
+			DollarQuotes: true, ReadOnlyTx: true}),
+	} {
+		if err := Register(driver); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// builtIn returns a driver for info registered by the datatug package
+func builtIn(name string, dialect Dialect) Driver {
+	info, ok := datatug.GetDriver(name)
+	if !ok {
+		panic("driver is not registered by the datatug package: " + name)
+	}
+	return Driver{DriverInfo: info, Dialect: dialect}
+}
+
+// Register adds a driver or replaces a registered driver with the same name,
+// the driver info is registered with datatug.RegisterDriver so servers of the driver pass validation
+func Register(driver Driver) error {
+	if driver.Dialect.QuoteStart == "" || driver.Dialect.QuoteEnd == "" {
+		return fmt.Errorf("dialect of driver %v has no identifier quotes", driver.Name)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if err := datatug.RegisterDriver(driver.DriverInfo); err != nil {
+		return err
+	}
+	registry.drivers[driver.Name] = driver
+	return nil
+}
+
+// Get returns a registered driver by a name or an alias, case-insensitive
+func Get(name string) (driver Driver, ok bool) {
+	info, ok := datatug.GetDriver(name)
+	if !ok {
+		return
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	driver, ok = registry.drivers[info.Name]
+	return
+}
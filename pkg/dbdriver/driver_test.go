@@ -0,0 +1,145 @@
+package dbdriver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dbconnection"
+	"github.com/datatug/datatug-core/pkg/schemer"
+	"github.com/datatug/datatug-core/pkg/test/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSchemaProvider embeds the interface, only IsBulkProvider is expected to be called
+type stubSchemaProvider struct {
+	schemer.SchemaProvider
+}
+
+func (stubSchemaProvider) IsBulkProvider() bool {
+	return true
+}
+
+func TestGet(t *testing.T) {
+	driver, ok := Get("MSSQL")
+	require.True(t, ok)
+	assert.Equal(t, "sqlserver", driver.Name)
+	assert.Equal(t, "[dbo].[Orders]", driver.Dialect.QuoteName("dbo", "Orders"))
+	assert.Equal(t, "@p1", driver.Dialect.Placeholder(1))
+
+	for _, name := range datatug.DriverNames() {
+		_, ok = Get(name)
+		assert.True(t, ok, name)
+	}
+
+	_, ok = Get("unknown")
+	assert.False(t, ok)
+}
+
+func TestDriver_Params(t *testing.T) {
+	t.Run("default_port", func(t *testing.T) {
+		driver, _ := Get("postgres")
+		params, err := driver.Params(ParamsRequest{
+			Server:      datatug.ServerRef{Driver: "pg", Host: "db"},
+			Catalog:     "sales",
+			Credentials: datatug.Credentials{Username: "reader", Password: "secret"},
+			Mode:        dbconnection.ModeReadOnly,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "postgres", params.Driver())
+		assert.Equal(t, "db", params.Server())
+		assert.Equal(t, 5432, params.Port())
+		assert.Equal(t, "sales", params.Catalog())
+		assert.Equal(t, "reader", params.User())
+		assert.Equal(t, dbconnection.ModeReadOnly, params.Mode())
+	})
+	t.Run("explicit_port", func(t *testing.T) {
+		driver, _ := Get("mysql")
+		params, err := driver.Params(ParamsRequest{Server: datatug.ServerRef{Driver: "mysql", Host: "db", Port: 3307}})
+		require.NoError(t, err)
+		assert.Equal(t, 3307, params.Port())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		driver, _ := Get("sqlite")
+		params, err := driver.Params(ParamsRequest{Server: datatug.ServerRef{Driver: "sqlite3", Path: "/data/orders.db"}, Mode: dbconnection.ModeReadWrite})
+		require.NoError(t, err)
+		require.IsType(t, dbconnection.SQLite3ConnectionParams{}, params)
+		assert.Equal(t, "/data/orders.db", params.(dbconnection.SQLite3ConnectionParams).Path())
+		assert.Equal(t, 0, params.Port())
+	})
+}
+
+func TestDriver_Connect(t *testing.T) {
+	ctx := context.Background()
+	t.Run("without_tunnel", func(t *testing.T) {
+		driver, _ := Get("sqlserver")
+		params, closer, err := driver.Connect(ctx, ParamsRequest{Server: datatug.ServerRef{Driver: "sqlserver", Host: "db"}})
+		require.NoError(t, err)
+		assert.Equal(t, "db", params.Server())
+		assert.Equal(t, 1433, params.Port())
+		assert.NoError(t, closer.Close())
+	})
+	t.Run("ssh_tunnel", func(t *testing.T) {
+		signer, keyPath := sshtest.NewKey(t)
+		bastion := sshtest.NewServer(t, signer.PublicKey())
+		driver, _ := Get("postgres")
+		params, closer, err := driver.Connect(ctx, ParamsRequest{
+			Server:    datatug.ServerRef{Driver: "postgres", Host: "db.internal"},
+			Catalog:   "sales",
+			SSHTunnel: &datatug.SSHTunnel{Host: bastion.Host(), Port: bastion.Port(), User: "tunnel", KeyPath: keyPath, KnownHostsPath: bastion.WriteKnownHosts(t)},
+		})
+		require.NoError(t, err)
+		defer func() { _ = closer.Close() }()
+		assert.Equal(t, "127.0.0.1", params.Server())
+		assert.NotEqual(t, 5432, params.Port())
+		assert.Equal(t, "sales", params.Catalog())
+	})
+	t.Run("file_based", func(t *testing.T) {
+		driver, _ := Get("sqlite3")
+		_, _, err := driver.Connect(ctx, ParamsRequest{
+			Server:    datatug.ServerRef{Driver: "sqlite3", Path: "/data"},
+			SSHTunnel: &datatug.SSHTunnel{Host: "bastion", User: "tunnel", UseAgent: true},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestDriver_SchemaProvider(t *testing.T) {
+	driver, _ := Get("oracle")
+	_, err := driver.SchemaProvider(nil)
+	assert.ErrorIs(t, err, ErrNoSchemaProvider)
+}
+
+func TestRegister(t *testing.T) {
+	var connected dbconnection.Params
+	duckdb := Driver{
+		DriverInfo: datatug.DriverInfo{Name: "duckdb", Aliases: []string{"duck"}, FileBased: true},
+		Dialect:    Dialect{QuoteStart: `"`, QuoteEnd: `"`, Placeholders: PlaceholderDollar, SupportsSchemas: true},
+		NewSchemaProvider: func(params dbconnection.Params) (schemer.SchemaProvider, error) {
+			connected = params
+			return stubSchemaProvider{}, nil
+		},
+	}
+	require.NoError(t, Register(duckdb))
+
+	server := datatug.ServerRef{Driver: "duck", Path: "/data/analytics.duckdb"}
+	assert.NoError(t, server.Validate(), "registered driver should be known to validation")
+	assert.Error(t, datatug.ServerRef{Driver: "duck", Host: "localhost"}.Validate())
+
+	driver, ok := Get("duck")
+	require.True(t, ok)
+	params, err := driver.Params(ParamsRequest{Server: server})
+	require.NoError(t, err)
+	provider, err := driver.SchemaProvider(params)
+	require.NoError(t, err)
+	assert.True(t, provider.IsBulkProvider())
+	assert.Equal(t, "duckdb", connected.Driver())
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.Error(t, Register(Driver{DriverInfo: datatug.DriverInfo{Name: "nodialect"}}))
+		assert.Error(t, Register(Driver{DriverInfo: datatug.DriverInfo{Name: "pg2", Aliases: []string{"pg"}}, Dialect: duckdb.Dialect}))
+		_, ok := Get("nodialect")
+		assert.False(t, ok)
+	})
+}
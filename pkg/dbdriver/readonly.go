@@ -0,0 +1,80 @@
+package dbdriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/datatug/datatug-core/pkg/dbconnection"
+)
+
+// CheckStatement returns ErrNotReadOnly if SQL can modify data or schema & the mode is read-only
+func (v Driver) CheckStatement(mode dbconnection.Mode, sql string) error {
+	if mode != dbconnection.ModeReadOnly {
+		return nil
+	}
+	return v.Dialect.CheckReadOnly(sql)
+}
+
+// Tx is a transaction that rejects SQL modifying data or schema before it's executed if the transaction is read-only
+type Tx struct {
+	tx       *sql.Tx
+	driver   Driver
+	readOnly bool
+}
+
+// BeginTx starts a transaction in the mode. A read-only transaction is also made read-only by the driver
+// with sql.TxOptions.ReadOnly or Dialect.ReadOnlyTxStatement if supported,
+// so functions with side effects that can't be detected by Dialect.CheckReadOnly fail as well.
+func (v Driver) BeginTx(ctx context.Context, db *sql.DB, mode dbconnection.Mode) (*Tx, error) {
+	readOnly := mode == dbconnection.ModeReadOnly
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly && v.Dialect.ReadOnlyTx})
+	if err != nil {
+		return nil, err
+	}
+	if readOnly && v.Dialect.ReadOnlyTxStatement != "" {
+		if _, err = tx.ExecContext(ctx, v.Dialect.ReadOnlyTxStatement); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("failed to make transaction read-only: %w", err)
+		}
+	}
+	return &Tx{tx: tx, driver: v, readOnly: readOnly}, nil
+}
+
+// ReadOnly returns true if the transaction rejects SQL modifying data or schema
+func (tx *Tx) ReadOnly() bool {
+	return tx.readOnly
+}
+
+// QueryContext checks & executes a query that returns rows
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := tx.check(query); err != nil {
+		return nil, err
+	}
+	return tx.tx.QueryContext(ctx, query, args...)
+}
+
+// ExecContext checks & executes a query that doesn't return rows
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if err := tx.check(query); err != nil {
+		return nil, err
+	}
+	return tx.tx.ExecContext(ctx, query, args...)
+}
+
+// Commit commits the transaction
+func (tx *Tx) Commit() error {
+	return tx.tx.Commit()
+}
+
+// Rollback aborts the transaction
+func (tx *Tx) Rollback() error {
+	return tx.tx.Rollback()
+}
+
+func (tx *Tx) check(query string) error {
+	if !tx.readOnly {
+		return nil
+	}
+	return tx.driver.Dialect.CheckReadOnly(query)
+}
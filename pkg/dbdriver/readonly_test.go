@@ -0,0 +1,169 @@
+package dbdriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/dbconnection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDriver is a database/sql driver that records transactions & statements without executing them
+type recordingDriver struct {
+	mutex      sync.Mutex
+	txReadOnly []bool
+	executed   []string
+	results    map[string]staticRows // rows returned by queries, no rows by default
+}
+
+func (d *recordingDriver) log(query string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.executed = append(d.executed, query)
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.driver.mutex.Lock()
+	defer c.driver.mutex.Unlock()
+	c.driver.txReadOnly = append(c.driver.txReadOnly, opts.ReadOnly)
+	return c, nil
+}
+
+func (c *recordingConn) Commit() error {
+	return nil
+}
+
+func (c *recordingConn) Rollback() error {
+	return nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.log(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.driver.log(query)
+	rows := c.driver.results[query]
+	return &rows, nil
+}
+
+type staticRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *staticRows) Columns() []string {
+	return r.columns
+}
+
+func (r *staticRows) Close() error {
+	return nil
+}
+
+func (r *staticRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func openRecordingDB(t *testing.T) (*sql.DB, *recordingDriver) {
+	d := &recordingDriver{}
+	db := sql.OpenDB(connector{d})
+	t.Cleanup(func() { _ = db.Close() })
+	return db, d
+}
+
+type connector struct {
+	driver *recordingDriver
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c connector) Driver() driver.Driver {
+	return c.driver
+}
+
+func TestDriver_BeginTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("read_only_tx_option", func(t *testing.T) {
+		db, recorded := openRecordingDB(t)
+		postgres, _ := Get("postgres")
+		tx, err := postgres.BeginTx(ctx, db, dbconnection.ModeReadOnly)
+		require.NoError(t, err)
+		assert.True(t, tx.ReadOnly())
+
+		rows, err := tx.QueryContext(ctx, "SELECT * FROM orders WHERE id = $1", 1)
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+		_, err = tx.ExecContext(ctx, "DELETE FROM orders")
+		assert.ErrorIs(t, err, ErrNotReadOnly)
+		_, err = tx.QueryContext(ctx, "WITH x AS (UPDATE orders SET total = 0 RETURNING *) SELECT * FROM x")
+		assert.ErrorIs(t, err, ErrNotReadOnly)
+		require.NoError(t, tx.Rollback())
+
+		assert.Equal(t, []bool{true}, recorded.txReadOnly)
+		assert.Equal(t, []string{"SELECT * FROM orders WHERE id = $1"}, recorded.executed, "rejected statements should not reach the driver")
+	})
+
+	t.Run("read_only_tx_statement", func(t *testing.T) {
+		db, recorded := openRecordingDB(t)
+		oracle, _ := Get("oracle")
+		tx, err := oracle.BeginTx(ctx, db, dbconnection.ModeReadOnly)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		assert.Equal(t, []bool{false}, recorded.txReadOnly)
+		assert.Equal(t, []string{"SET TRANSACTION READ ONLY"}, recorded.executed)
+	})
+
+	t.Run("read_write", func(t *testing.T) {
+		db, recorded := openRecordingDB(t)
+		mysql, _ := Get("mysql")
+		tx, err := mysql.BeginTx(ctx, db, dbconnection.ModeReadWrite)
+		require.NoError(t, err)
+		assert.False(t, tx.ReadOnly())
+		_, err = tx.ExecContext(ctx, "DELETE FROM orders")
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		assert.Equal(t, []bool{false}, recorded.txReadOnly)
+		assert.Equal(t, []string{"DELETE FROM orders"}, recorded.executed)
+	})
+}
+
+func TestDriver_CheckStatement(t *testing.T) {
+	sqlServer, _ := Get("sqlserver")
+	assert.NoError(t, sqlServer.CheckStatement(dbconnection.ModeReadWrite, "DROP TABLE t"))
+	assert.ErrorIs(t, sqlServer.CheckStatement(dbconnection.ModeReadOnly, "DROP TABLE t"), ErrNotReadOnly)
+	assert.NoError(t, sqlServer.CheckStatement(dbconnection.ModeReadOnly, "SELECT * FROM t"))
+}
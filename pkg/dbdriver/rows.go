@@ -0,0 +1,54 @@
+package dbdriver
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+var _ datatug.RowsReader = (*RowsReader)(nil)
+
+// RowsReader reads rows of a query one by one, e.g. to diff large recordsets with datatug.DiffSortedRows
+type RowsReader struct {
+	rows    *sql.Rows
+	columns []datatug.RecordsetColumn
+}
+
+// NewRowsReader creates a reader of rows, the caller is responsible for closing the rows
+func NewRowsReader(rows *sql.Rows) (*RowsReader, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	columns := make([]datatug.RecordsetColumn, len(columnTypes))
+	for i, columnType := range columnTypes {
+		columns[i] = datatug.RecordsetColumn{Name: columnType.Name(), DbType: columnType.DatabaseTypeName()}
+	}
+	return &RowsReader{rows: rows, columns: columns}, nil
+}
+
+// Columns returns columns of the rows
+func (r *RowsReader) Columns() []datatug.RecordsetColumn {
+	return r.columns
+}
+
+// Next scans the next row, returns io.EOF after the last row
+func (r *RowsReader) Next() ([]interface{}, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	values := make([]interface{}, len(r.columns))
+	pointers := make([]interface{}, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := r.rows.Scan(pointers...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,38 @@
+package dbdriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowsReader(t *testing.T) {
+	ctx := context.Background()
+	db, recorded := openRecordingDB(t)
+	recorded.results = map[string]staticRows{
+		"SELECT id, name FROM t ORDER BY id": {
+			columns: []string{"id", "name"},
+			values:  [][]driver.Value{{int64(1), []byte("one")}, {int64(2), "two"}},
+		},
+	}
+	rows, err := db.QueryContext(ctx, "SELECT id, name FROM t ORDER BY id")
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+
+	reader, err := NewRowsReader(rows)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, []string{reader.Columns()[0].Name, reader.Columns()[1].Name})
+
+	var diff datatug.RecordsetDiff
+	old := datatug.Recordset{Columns: reader.Columns(), Rows: [][]interface{}{{1, "one"}, {2, "deux"}}}
+	require.NoError(t, datatug.DiffSortedRows(ctx, datatug.NewRowsReader(old), reader, []string{"id"}, &diff))
+	assert.Equal(t, datatug.RecordsetDiffStats{Altered: 1, Unchanged: 1}, diff.RecordsetDiffStats)
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,275 @@
+package dbdriver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ErrNotReadOnly is returned for SQL that can modify data or schema when it's executed in read-only mode
+var ErrNotReadOnly = errors.New("SQL is not allowed in read-only mode")
+
+// StatementKind classifies a statement by its first keyword
+type StatementKind string
+
+const (
+	// StatementQuery reads data, e.g. SELECT, WITH, SHOW or EXPLAIN
+	StatementQuery StatementKind = "query"
+	// StatementDML modifies data, e.g. INSERT, UPDATE, DELETE or MERGE
+	StatementDML StatementKind = "dml"
+	// StatementDDL modifies schema or permissions, e.g. CREATE, ALTER, DROP, TRUNCATE or GRANT
+	StatementDDL StatementKind = "ddl"
+	// StatementOther is any other statement, e.g. SET, USE, BEGIN, EXEC or CALL
+	StatementOther StatementKind = "other"
+)
+
+var statementKinds = map[string]StatementKind{
+	"SELECT": StatementQuery, "WITH": StatementQuery, "SHOW": StatementQuery, "DESCRIBE": StatementQuery,
+	"DESC": StatementQuery, "EXPLAIN": StatementQuery, "VALUES": StatementQuery, "TABLE": StatementQuery,
+	"PRAGMA": StatementQuery,
+	"INSERT": StatementDML, "UPDATE": StatementDML, "DELETE": StatementDML, "MERGE": StatementDML,
+	"REPLACE": StatementDML, "UPSERT": StatementDML, "COPY": StatementDML, "LOAD": StatementDML,
+	"CREATE": StatementDDL, "ALTER": StatementDDL, "DROP": StatementDDL, "TRUNCATE": StatementDDL,
+	"RENAME": StatementDDL, "COMMENT": StatementDDL, "GRANT": StatementDDL, "REVOKE": StatementDDL,
+}
+
+// writeKeywords make a query modify data or schema wherever they are, e.g. in a CTE or in SELECT ... INTO.
+// Words that are common column names, like COMMENT, are checked only as first keywords.
+var writeKeywords = []string{
+	"INSERT", "UPDATE", "DELETE", "MERGE", "REPLACE", "UPSERT",
+	"CREATE", "ALTER", "DROP", "TRUNCATE", "GRANT", "REVOKE",
+	"INTO", "EXEC", "EXECUTE", "CALL",
+}
+
+// readPragmaPrefixes are SQLite pragmas that take arguments & only read schema
+var readPragmaPrefixes = []string{"TABLE_", "INDEX_", "FOREIGN_KEY_"}
+
+// Statement is a statement of an SQL script
+type Statement struct {
+	Text string
+	// Keyword is the first keyword of the statement, upper-cased
+	Keyword string
+	Kind    StatementKind
+	// Writes lists keywords that make the statement modify data or schema, e.g. DELETE in a CTE of a query
+	Writes []string
+}
+
+// IsReadOnly returns true for queries that don't modify data or schema
+func (v Statement) IsReadOnly() bool {
+	return v.Kind == StatementQuery && len(v.Writes) == 0
+}
+
+type token struct {
+	text string // upper-cased for words
+	word bool
+}
+
+// ParseStatements splits an SQL script into statements & classifies them.
+// Comments, string literals & quoted identifiers are skipped by rules of the dialect,
+// so keywords in them don't affect classification.
+func (v Dialect) ParseStatements(sql string) (statements []Statement, err error) {
+	var (
+		tokens       []token
+		start        int
+		execComments int // depth of MySQL executable comments: /*! ... */
+	)
+	flush := func(end int) {
+		if len(tokens) > 0 {
+			statements = append(statements, classify(strings.TrimSpace(sql[start:end]), tokens))
+		}
+		tokens = nil
+	}
+	add := func(t token, at int) {
+		if len(tokens) == 0 {
+			start = at // leading comments are not a part of a statement text
+		}
+		tokens = append(tokens, t)
+	}
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case v.BatchSeparator != "" && (i == 0 || sql[i-1] == '\n') && isBatchSeparator(sql[i:], v.BatchSeparator):
+			flush(i)
+			i = lineEnd(sql, i)
+		case c == ';':
+			flush(i)
+			i++
+		case strings.HasPrefix(sql[i:], "--") || c == '#' && v.HashComments:
+			i = lineEnd(sql, i)
+		case strings.HasPrefix(sql[i:], "/*!") && v.ExecutableComments:
+			execComments++
+			i += 3
+			for i < len(sql) && sql[i] >= '0' && sql[i] <= '9' { // optional server version
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "*/") && execComments > 0:
+			execComments--
+			i += 2
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += 2 + end + 2
+		case c == '\'':
+			backslash := v.BackslashEscapes || i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i < 2 || !isWordChar(sql[i-2]))
+			if i, err = skipQuoted(sql, i, '\'', backslash); err != nil {
+				return nil, err
+			}
+		case c == '"':
+			if i, err = skipQuoted(sql, i, '"', v.BackslashEscapes); err != nil {
+				return nil, err
+			}
+		case v.QuoteStart != "" && v.QuoteStart != `"` && strings.HasPrefix(sql[i:], v.QuoteStart):
+			if i, err = skipQuoted(sql, i, v.QuoteEnd[0], false); err != nil {
+				return nil, err
+			}
+		case c == '$' && v.DollarQuotes && dollarTag(sql[i:]) != "":
+			tag := dollarTag(sql[i:])
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated %v quoted string", tag)
+			}
+			i += len(tag) + end + len(tag)
+		case isWordChar(c):
+			j := i
+			for j < len(sql) && isWordChar(sql[j]) && !(sql[j] == '#' && v.HashComments) {
+				j++
+			}
+			add(token{text: strings.ToUpper(sql[i:j]), word: true}, i)
+			i = j
+		case c <= ' ':
+			i++
+		default:
+			add(token{text: string(c)}, i)
+			i++
+		}
+	}
+	if execComments > 0 {
+		return nil, errors.New("unterminated executable comment")
+	}
+	flush(len(sql))
+	return statements, nil
+}
+
+// CheckReadOnly returns ErrNotReadOnly unless SQL is a single statement that only reads data.
+// It can't detect functions with side effects, so read-only transactions should be used as well, see Driver.BeginTx.
+func (v Dialect) CheckReadOnly(sql string) error {
+	statements, err := v.ParseStatements(sql)
+	if err != nil {
+		return fmt.Errorf("%w: failed to parse: %v", ErrNotReadOnly, err)
+	}
+	if len(statements) > 1 {
+		return fmt.Errorf("%w: multiple statements", ErrNotReadOnly)
+	}
+	for _, statement := range statements {
+		switch {
+		case statement.Kind != StatementQuery:
+			return fmt.Errorf("%w: %v statement", ErrNotReadOnly, statement.Keyword)
+		case len(statement.Writes) > 0:
+			return fmt.Errorf("%w: %v in %v statement", ErrNotReadOnly, strings.Join(statement.Writes, ", "), statement.Keyword)
+		}
+	}
+	return nil
+}
+
+func classify(text string, tokens []token) (statement Statement) {
+	statement.Text = text
+	statement.Kind = StatementOther
+	first := slices.IndexFunc(tokens, func(t token) bool { return t.word })
+	if first < 0 {
+		return
+	}
+	statement.Keyword = tokens[first].text
+	if kind, ok := statementKinds[statement.Keyword]; ok {
+		statement.Kind = kind
+	}
+	if statement.Kind != StatementQuery {
+		return
+	}
+	for i := first + 1; i < len(tokens); i++ {
+		t := tokens[i]
+		if !t.word || !slices.Contains(writeKeywords, t.text) || slices.Contains(statement.Writes, t.text) {
+			continue
+		}
+		if t.text == "REPLACE" && i+1 < len(tokens) && tokens[i+1].text == "(" {
+			continue // REPLACE(s, from, to) function
+		}
+		statement.Writes = append(statement.Writes, t.text)
+	}
+	if statement.Keyword == "PRAGMA" && !isReadPragma(tokens[first+1:]) {
+		statement.Writes = append(statement.Writes, "PRAGMA")
+	}
+	return
+}
+
+// isReadPragma checks a SQLite pragma does not set a value: PRAGMA name = value or PRAGMA name(value)
+func isReadPragma(tokens []token) bool {
+	if slices.ContainsFunc(tokens, func(t token) bool { return t.text == "=" }) {
+		return false
+	}
+	if !slices.ContainsFunc(tokens, func(t token) bool { return t.text == "(" }) {
+		return true
+	}
+	var name string // the last word before "(", e.g. "table_info" in "main.table_info(t)"
+	for _, t := range tokens {
+		if t.text == "(" {
+			break
+		}
+		if t.word {
+			name = t.text
+		}
+	}
+	return slices.ContainsFunc(readPragmaPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) })
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || c == '@' || c == '#' || c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+func lineEnd(s string, i int) int {
+	if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+		return i + end + 1
+	}
+	return len(s)
+}
+
+func isBatchSeparator(s, separator string) bool {
+	line := s[:lineEnd(s, 0)]
+	return strings.EqualFold(strings.TrimSpace(line), separator)
+}
+
+// skipQuoted returns an index after a quoted string or identifier that starts at i,
+// a closing quote is escaped by doubling it or by a backslash if enabled
+func skipQuoted(s string, i int, closing byte, backslash bool) (int, error) {
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case backslash && s[j] == '\\':
+			j++
+		case s[j] == closing:
+			if j+1 < len(s) && s[j+1] == closing {
+				j++
+				continue
+			}
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted text starting at %v", i)
+}
+
+// dollarTag returns a PostgreSQL dollar quote tag like "$$" or "$body$" a string starts with
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == '$':
+			return s[:j+1]
+		case c == '_' || unicode.IsLetter(rune(c)) || j > 1 && unicode.IsDigit(rune(c)):
+			continue
+		default:
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package dbdriver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialectOf(t *testing.T, name string) Dialect {
+	t.Helper()
+	driver, ok := Get(name)
+	require.True(t, ok, name)
+	return driver.Dialect
+}
+
+func TestDialect_ParseStatements(t *testing.T) {
+	t.Run("split", func(t *testing.T) {
+		statements, err := dialectOf(t, "postgres").ParseStatements(`
+			-- a comment; with a semicolon
+			SELECT ';' AS "a;b" FROM t;
+			/* DELETE FROM t; */ UPDATE t SET x = 1;
+			;
+			CREATE TABLE t2 (id int)`)
+		require.NoError(t, err)
+		require.Len(t, statements, 3)
+		assert.Equal(t, `SELECT ';' AS "a;b" FROM t`, statements[0].Text)
+		assert.Equal(t, StatementQuery, statements[0].Kind)
+		assert.True(t, statements[0].IsReadOnly())
+		assert.Equal(t, "UPDATE", statements[1].Keyword)
+		assert.Equal(t, StatementDML, statements[1].Kind)
+		assert.Equal(t, StatementDDL, statements[2].Kind)
+	})
+	t.Run("sqlserver_batches", func(t *testing.T) {
+		statements, err := dialectOf(t, "sqlserver").ParseStatements("SELECT 1 AS [go]\nGO\n  go  \nEXEC sp_who\nGO")
+		require.NoError(t, err)
+		require.Len(t, statements, 2)
+		assert.Equal(t, "SELECT 1 AS [go]", statements[0].Text)
+		assert.Equal(t, StatementOther, statements[1].Kind)
+	})
+	t.Run("cte_with_writes", func(t *testing.T) {
+		statements, err := dialectOf(t, "postgres").ParseStatements("WITH gone AS (DELETE FROM t RETURNING *) SELECT * FROM gone")
+		require.NoError(t, err)
+		require.Len(t, statements, 1)
+		assert.Equal(t, StatementQuery, statements[0].Kind)
+		assert.Equal(t, []string{"DELETE"}, statements[0].Writes)
+		assert.False(t, statements[0].IsReadOnly())
+	})
+	t.Run("errors", func(t *testing.T) {
+		for _, sql := range []string{"SELECT 'x", "SELECT 1 /* x", `SELECT "x`, "SELECT $$x"} {
+			_, err := dialectOf(t, "postgres").ParseStatements(sql)
+			assert.Error(t, err, sql)
+		}
+	})
+}
+
+func TestDialect_CheckReadOnly(t *testing.T) {
+	for _, tt := range []struct {
+		driver   string
+		sql      string
+		readOnly bool
+	}{
+		{"postgres", "SELECT * FROM orders WHERE note = 'DELETE FROM orders'", true},
+		{"postgres", `SELECT "update", "drop" FROM t`, true},
+		{"postgres", "select replace(name, 'a', 'b') from t -- drop table t", true},
+		{"postgres", "SELECT $body$ DROP TABLE t; $body$, $1::int", true},
+		{"postgres", `SELECT E'it\'s; DROP TABLE t'`, true},
+		{"postgres", "(SELECT 1) UNION (SELECT 2);", true},
+		{"postgres", "EXPLAIN SELECT * FROM t", true},
+		{"postgres", "  ", true},
+		{"postgres", "EXPLAIN ANALYZE DELETE FROM t", false},
+		{"postgres", "WITH x AS (INSERT INTO t VALUES (1) RETURNING id) SELECT * FROM x", false},
+		{"postgres", "SELECT * INTO backup FROM t", false},
+		{"postgres", "SELECT * FROM t FOR UPDATE", false},
+		{"postgres", "SELECT 1; SELECT 2", false},
+		{"postgres", "TRUNCATE t", false},
+		{"postgres", "SET search_path = x", false},
+		{"postgres", "SELECT 'unterminated", false},
+		{"mysql", "SELECT * FROM `delete` # DROP TABLE t", true},
+		{"mysql", `SELECT 'it\'s; DELETE FROM t', "a\"; DROP TABLE t"`, true},
+		{"mysql", "SHOW TABLES", true},
+		{"mysql", "SELECT 1 /*!50000 ; DROP TABLE t */", false},
+		{"mysql", "REPLACE INTO t VALUES (1)", false},
+		{"mysql", "SELECT * FROM t INTO OUTFILE '/tmp/t.csv'", false},
+		{"sqlserver", "SELECT [Order Details].* FROM [Order Details]", true},
+		{"sqlserver", "SELECT 1\nGO\nSELECT 2", false},
+		{"sqlserver", "EXEC sp_rename 't', 't2'", false},
+		{"sqlserver", "MERGE t USING s ON t.id = s.id WHEN MATCHED THEN UPDATE SET x = 1;", false},
+		{"sqlserver", "SELECT * FROM #tmp", true},
+		{"oracle", "SELECT * FROM dual", true},
+		{"oracle", "CALL proc()", false},
+		{"sqlite3", "PRAGMA table_info(orders)", true},
+		{"sqlite3", "PRAGMA foreign_keys", true},
+		{"sqlite3", "PRAGMA journal_mode = WAL", false},
+		{"sqlite3", "PRAGMA main.cache_size(100)", false},
+		{"sqlite3", "ALTER TABLE t ADD COLUMN c", false},
+	} {
+		err := dialectOf(t, tt.driver).CheckReadOnly(tt.sql)
+		if tt.readOnly {
+			assert.NoError(t, err, "%v: %v", tt.driver, tt.sql)
+		} else {
+			assert.ErrorIs(t, err, ErrNotReadOnly, "%v: %v", tt.driver, tt.sql)
+		}
+	}
+}
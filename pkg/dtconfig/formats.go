@@ -3,5 +3,6 @@ package dtconfig
 type Format string
 
 const (
+	FormatJson Format = "json"
 	FormatYaml Format = "yaml"
 )
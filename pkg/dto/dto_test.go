@@ -72,3 +72,20 @@ func TestCreateProjectRequest_Validate(t *testing.T) {
 		assert.NotNil(t, v.Validate())
 	})
 }
+
+func TestUpdateQuery_QueryToSave(t *testing.T) {
+	v := UpdateQuery{
+		ProjectItemRef: ProjectItemRef{ID: "q1", Revision: "r2"},
+		Query: datatug.QueryDefWithFolderPath{
+			QueryDef: datatug.QueryDef{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "q1", Revision: "r1"}},
+			},
+		},
+	}
+	query := v.QueryToSave()
+	assert.Equal(t, "r2", query.Revision)
+	assert.Equal(t, "r1", v.Query.Revision, "request should not be modified")
+
+	v.Revision = ""
+	assert.Equal(t, "r1", v.QueryToSave().Revision)
+}
@@ -6,6 +6,8 @@ import "github.com/strongo/validation"
 type ProjectItemRef struct {
 	ProjectRef
 	ID string
+	// Revision is an expected revision of the item, if set a store fails to save a changed item
+	Revision string `json:"revision,omitempty"`
 }
 
 // Validate returns error if not valid
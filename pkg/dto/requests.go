@@ -61,3 +61,12 @@ type UpdateQuery struct {
 	ProjectItemRef
 	Query datatug.QueryDefWithFolderPath `json:"query"`
 }
+
+// QueryToSave returns query with the expected revision from the request
+func (v UpdateQuery) QueryToSave() *datatug.QueryDefWithFolderPath {
+	query := v.Query
+	if v.Revision != "" {
+		query.Revision = v.Revision
+	}
+	return &query
+}
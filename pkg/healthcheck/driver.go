@@ -0,0 +1,102 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/dbconnection"
+)
+
+// Driver checks DB servers of a database driver
+type Driver interface {
+	// Ping returns error if a server is not reachable with the connection params
+	Ping(ctx context.Context, params dbconnection.Params) error
+	// Catalogs returns names of catalogs (databases) of a server
+	Catalogs(ctx context.Context, params dbconnection.Params) ([]string, error)
+}
+
+// sqliteFileHeader is a header every SQLite 3 database file starts with
+var sqliteFileHeader = []byte("SQLite format 3\x00")
+
+// SQLiteFileDriver checks SQLite servers without opening databases: a server is a directory with database files
+// or a single database file, catalogs are names of database files without extensions
+type SQLiteFileDriver struct{}
+
+var _ Driver = SQLiteFileDriver{}
+
+func sqlitePath(params dbconnection.Params) (string, error) {
+	withPath, ok := params.(interface{ Path() string })
+	if !ok || withPath.Path() == "" {
+		return "", errors.New("SQLite connection params have no path")
+	}
+	return withPath.Path(), nil
+}
+
+// Ping checks the path of a server exists
+func (SQLiteFileDriver) Ping(_ context.Context, params dbconnection.Params) error {
+	p, err := sqlitePath(params)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(p)
+	return err
+}
+
+// Catalogs returns names of SQLite database files of a server directory
+func (SQLiteFileDriver) Catalogs(_ context.Context, params dbconnection.Params) (catalogs []string, err error) {
+	p, err := sqlitePath(params)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{catalogName(p)}, nil
+	}
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		isDB, err := isSQLiteFile(filepath.Join(p, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if isDB {
+			catalogs = append(catalogs, catalogName(entry.Name()))
+		}
+	}
+	return catalogs, nil
+}
+
+func catalogName(filePath string) string {
+	name := filepath.Base(filePath)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func isSQLiteFile(filePath string) (bool, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+	header := make([]byte, len(sqliteFileHeader))
+	if _, err = io.ReadFull(f, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read %v: %w", filePath, err)
+	}
+	return bytes.Equal(header, sqliteFileHeader), nil
+}
@@ -0,0 +1,241 @@
+// Package healthcheck verifies servers of an environment are reachable & have the catalogs they are expected to have.
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/credentials"
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dbconnection"
+	"github.com/datatug/datatug-core/pkg/dbdriver"
+	"github.com/datatug/datatug-core/pkg/parallel"
+	"github.com/datatug/datatug-core/pkg/sshtunnel"
+)
+
+// Status of a checked environment, server or catalog
+type Status string
+
+const (
+	// StatusOK means a server is reachable & a catalog exists
+	StatusOK Status = "ok"
+	// StatusSkipped means a server is not checked as there is no driver for it
+	StatusSkipped Status = "skipped"
+	// StatusDegraded means a server is reachable but some of its catalogs are missing or can't be listed
+	StatusDegraded Status = "degraded"
+	// StatusMissing means a catalog does not exist on a server
+	StatusMissing Status = "missing"
+	// StatusUnreachable means a server did not respond to a ping
+	StatusUnreachable Status = "unreachable"
+	// StatusError means a server is misconfigured, e.g. its credentials or variables can't be resolved
+	StatusError Status = "error"
+)
+
+// severity orders statuses of servers, an environment gets a status of its worst server
+var severity = []Status{StatusOK, StatusSkipped, StatusDegraded, StatusMissing, StatusUnreachable, StatusError}
+
+func worst(a, b Status) Status {
+	if slices.Index(severity, b) > slices.Index(severity, a) {
+		return b
+	}
+	return a
+}
+
+// EnvironmentReport is a result of an environment check structured like datatug.EnvironmentSummary
+type EnvironmentReport struct {
+	ID        string         `json:"id"`
+	Title     string         `json:"title,omitempty"`
+	Status    Status         `json:"status"`
+	CheckedAt time.Time      `json:"checkedAt"`
+	Servers   []ServerReport `json:"dbServers,omitempty"`
+}
+
+// ServerReport is a result of a check of a datatug.EnvDbServer
+type ServerReport struct {
+	datatug.ServerRef
+	Status Status `json:"status"`
+	// Latency is a duration of a ping
+	Latency  time.Duration   `json:"latency,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Catalogs []CatalogReport `json:"catalogs,omitempty"`
+}
+
+// CatalogReport is a result of a check of a catalog listed by a datatug.EnvDbServer
+type CatalogReport struct {
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DefaultTimeout limits time of a check of a server
+const DefaultTimeout = 10 * time.Second
+
+// Checker checks environments using drivers registered by driver names of servers
+type Checker struct {
+	drivers       map[string]Driver
+	credentials   credentials.Provider
+	timeout       time.Duration
+	tunnelOptions []sshtunnel.Option
+}
+
+// Option configures a Checker
+type Option func(c *Checker)
+
+// WithDriver registers a driver for servers with the driver name or alias, e.g. "sqlserver" or "mssql"
+func WithDriver(name string, driver Driver) Option {
+	return func(c *Checker) {
+		c.drivers[datatug.DriverName(name)] = driver
+	}
+}
+
+// WithCredentials sets a provider of credentials of servers, without it servers are checked with no credentials
+func WithCredentials(provider credentials.Provider) Option {
+	return func(c *Checker) {
+		c.credentials = provider
+	}
+}
+
+// WithTimeout limits time of a check of a server, DefaultTimeout is used by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		c.timeout = timeout
+	}
+}
+
+// WithTunnelOptions configures SSH tunnels opened to servers reachable only through a bastion
+func WithTunnelOptions(options ...sshtunnel.Option) Option {
+	return func(c *Checker) {
+		c.tunnelOptions = append(c.tunnelOptions, options...)
+	}
+}
+
+// NewChecker creates a checker, SQLite servers are checked with SQLiteFileDriver unless another driver is registered
+func NewChecker(options ...Option) *Checker {
+	c := &Checker{
+		drivers: map[string]Driver{dbconnection.DriverSQLite3: SQLiteFileDriver{}},
+		timeout: DefaultTimeout,
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+// CheckEnvironment checks servers of an environment in parallel. Connection params of a server are resolved
+// with variables of the project (optional), the environment & the server and with credentials of the server.
+func (c *Checker) CheckEnvironment(ctx context.Context, project *datatug.Project, env *datatug.Environment) EnvironmentReport {
+	report := EnvironmentReport{
+		ID:        env.ID,
+		Title:     env.Title,
+		Status:    StatusOK,
+		CheckedAt: time.Now(),
+		Servers:   make([]ServerReport, len(env.DbServers)),
+	}
+	workers := make([]func() error, len(env.DbServers))
+	for i, server := range env.DbServers {
+		workers[i] = func() error {
+			report.Servers[i] = c.CheckServer(ctx, project, env, server)
+			return nil
+		}
+	}
+	_ = parallel.Run(workers...)
+	for _, server := range report.Servers {
+		report.Status = worst(report.Status, server.Status)
+	}
+	return report
+}
+
+// CheckServer pings a server of an environment & checks it has catalogs listed by the server
+func (c *Checker) CheckServer(ctx context.Context, project *datatug.Project, env *datatug.Environment, server *datatug.EnvDbServer) (report ServerReport) {
+	report.ServerRef = server.ServerRef
+	failed := func(status Status, err error) ServerReport {
+		report.Status, report.Error = status, err.Error()
+		return report
+	}
+	driver, ok := c.drivers[datatug.DriverName(server.Driver)]
+	if !ok {
+		report.Status, report.Error = StatusSkipped, "no health check driver for "+server.Driver
+		return report
+	}
+	var vars datatug.VarsByID
+	if project != nil {
+		var err error
+		if vars, err = project.ResolveVars(env, server); err != nil {
+			return failed(StatusError, fmt.Errorf("failed to resolve variables: %w", err))
+		}
+	} else {
+		vars = datatug.ResolveVars(env.Variables, server.Variables)
+	}
+	resolved := server.ServerRef
+	for _, field := range []*string{&resolved.Host, &resolved.Path} {
+		var err error
+		if *field, err = datatug.Interpolate(*field, vars); err != nil {
+			return failed(StatusError, fmt.Errorf("failed to interpolate server address: %w", err))
+		}
+	}
+	var serverCredentials datatug.Credentials
+	if c.credentials != nil {
+		var err error
+		serverCredentials, err = c.credentials.GetCredentials(ctx, credentials.Ref{Environment: env.ID, Server: resolved})
+		if err != nil && !errors.Is(err, credentials.ErrNotFound) {
+			return failed(StatusError, fmt.Errorf("failed to get credentials: %w", err))
+		}
+	}
+	var tunnel *datatug.SSHTunnel
+	if server.SSHTunnel != nil {
+		interpolated, err := server.SSHTunnel.Interpolate(vars)
+		if err != nil {
+			return failed(StatusError, err)
+		}
+		tunnel = &interpolated
+	}
+	dbDriver, ok := dbdriver.Get(resolved.Driver)
+	if !ok {
+		return failed(StatusError, fmt.Errorf("unknown driver: %v", resolved.Driver))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	params, closer, err := dbDriver.Connect(ctx, dbdriver.ParamsRequest{
+		Server:      resolved,
+		Credentials: serverCredentials,
+		Mode:        dbconnection.ModeReadOnly,
+		SSHTunnel:   tunnel,
+	}, c.tunnelOptions...)
+	if err != nil {
+		return failed(StatusUnreachable, err)
+	}
+	defer func() { _ = closer.Close() }()
+	started := time.Now()
+	if err = driver.Ping(ctx, params); err != nil {
+		return failed(StatusUnreachable, err)
+	}
+	report.Latency = time.Since(started)
+	report.Status = StatusOK
+	if len(server.Catalogs) == 0 {
+		return report
+	}
+	existing, err := driver.Catalogs(ctx, params)
+	report.Catalogs = make([]CatalogReport, len(server.Catalogs))
+	for i, catalogID := range server.Catalogs {
+		catalog := CatalogReport{ID: catalogID, Status: StatusOK}
+		switch {
+		case err != nil:
+			catalog.Status, catalog.Error = StatusError, err.Error()
+		case !slices.ContainsFunc(existing, func(name string) bool { return strings.EqualFold(name, catalogID) }):
+			catalog.Status = StatusMissing
+		}
+		if catalog.Status != StatusOK {
+			report.Status = StatusDegraded
+		}
+		report.Catalogs[i] = catalog
+	}
+	if err != nil {
+		report.Error = fmt.Sprintf("failed to list catalogs: %v", err)
+	}
+	return report
+}
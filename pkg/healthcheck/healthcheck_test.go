@@ -0,0 +1,235 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/credentials"
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dbconnection"
+	"github.com/datatug/datatug-core/pkg/sshtunnel"
+	"github.com/datatug/datatug-core/pkg/test/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+type stubDriver struct {
+	pingErr     error
+	catalogs    []string
+	catalogsErr error
+	params      []dbconnection.Params
+}
+
+func (d *stubDriver) Ping(ctx context.Context, params dbconnection.Params) error {
+	d.params = append(d.params, params)
+	if d.pingErr != nil {
+		return d.pingErr
+	}
+	return ctx.Err()
+}
+
+func (d *stubDriver) Catalogs(_ context.Context, _ dbconnection.Params) ([]string, error) {
+	return d.catalogs, d.catalogsErr
+}
+
+type blockingDriver struct{}
+
+func (blockingDriver) Ping(ctx context.Context, _ dbconnection.Params) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (blockingDriver) Catalogs(context.Context, dbconnection.Params) ([]string, error) {
+	return nil, nil
+}
+
+// tcpDriver pings servers by opening TCP connections
+type tcpDriver struct{}
+
+func (tcpDriver) Ping(ctx context.Context, params dbconnection.Params) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(params.Server(), strconv.Itoa(params.Port())))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (tcpDriver) Catalogs(context.Context, dbconnection.Params) ([]string, error) {
+	return nil, nil
+}
+
+type stubCredentials map[string]datatug.Credentials
+
+func (s stubCredentials) GetCredentials(_ context.Context, ref credentials.Ref) (datatug.Credentials, error) {
+	for _, key := range ref.Keys() {
+		if c, ok := s[key]; ok {
+			return c, nil
+		}
+	}
+	return datatug.Credentials{}, credentials.ErrNotFound
+}
+
+func writeSQLiteFile(t *testing.T, dir, name string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), append([]byte("SQLite format 3\x00"), make([]byte, 84)...), 0644))
+}
+
+func newEnv(servers ...*datatug.EnvDbServer) *datatug.Environment {
+	return &datatug.Environment{
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "dev", Title: "Development"}},
+		DbServers:   servers,
+	}
+}
+
+func TestSQLiteFileDriver(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeSQLiteFile(t, dir, "orders.db")
+	writeSQLiteFile(t, dir, "users.sqlite")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a database"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.db"), nil, 0644))
+
+	driver := SQLiteFileDriver{}
+	params := dbconnection.NewSQLite3ConnectionParams(dir, "", dbconnection.ModeReadOnly)
+	require.NoError(t, driver.Ping(ctx, params))
+	catalogs, err := driver.Catalogs(ctx, params)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders", "users"}, catalogs)
+
+	t.Run("file", func(t *testing.T) {
+		catalogs, err := driver.Catalogs(ctx, dbconnection.NewSQLite3ConnectionParams(filepath.Join(dir, "orders.db"), "", dbconnection.ModeReadOnly))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders"}, catalogs)
+	})
+	t.Run("missing", func(t *testing.T) {
+		assert.Error(t, driver.Ping(ctx, dbconnection.NewSQLite3ConnectionParams(filepath.Join(dir, "missing"), "", dbconnection.ModeReadOnly)))
+	})
+}
+
+func TestChecker_CheckEnvironment(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeSQLiteFile(t, dir, "orders.db")
+
+	sqlServer := &stubDriver{catalogs: []string{"Sales"}}
+	mysql := &stubDriver{pingErr: errors.New("connection refused")}
+	checker := NewChecker(
+		WithDriver("sqlserver", sqlServer),
+		WithDriver("mysql", mysql),
+		WithCredentials(stubCredentials{"dev/sqlserver:dev-db:1433": {Username: "reader", Password: "p1"}}),
+	)
+	project := &datatug.Project{Variables: datatug.Variables{Vars: datatug.VarsByID{
+		"dataDir": {Type: "str", Value: dir},
+		"host":    {Type: "str", Value: "dev-db"},
+	}}}
+	env := newEnv(
+		&datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "sqlite3", Path: "${dataDir}"}, Catalogs: []string{"orders", "users"}},
+		&datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "${host}", Port: 1433}, Catalogs: []string{"sales"}},
+		&datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "mysql", Host: "dev-mysql"}},
+		&datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "oracle", Host: "dev-oracle"}},
+	)
+
+	report := checker.CheckEnvironment(ctx, project, env)
+
+	assert.Equal(t, "dev", report.ID)
+	assert.Equal(t, StatusUnreachable, report.Status)
+	require.Len(t, report.Servers, 4)
+
+	sqlite := report.Servers[0]
+	assert.Equal(t, StatusDegraded, sqlite.Status)
+	assert.Equal(t, []CatalogReport{{ID: "orders", Status: StatusOK}, {ID: "users", Status: StatusMissing}}, sqlite.Catalogs)
+
+	sqlServerReport := report.Servers[1]
+	assert.Equal(t, StatusOK, sqlServerReport.Status, sqlServerReport.Error)
+	assert.Equal(t, []CatalogReport{{ID: "sales", Status: StatusOK}}, sqlServerReport.Catalogs)
+	assert.Greater(t, sqlServerReport.Latency, time.Duration(0))
+	require.Len(t, sqlServer.params, 1)
+	assert.Equal(t, "dev-db", sqlServer.params[0].Server())
+	assert.Equal(t, 1433, sqlServer.params[0].Port())
+	assert.Equal(t, "reader", sqlServer.params[0].User())
+
+	assert.Equal(t, StatusUnreachable, report.Servers[2].Status)
+	assert.Equal(t, "connection refused", report.Servers[2].Error)
+
+	assert.Equal(t, StatusSkipped, report.Servers[3].Status)
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"dbServers":[{"driver":"sqlite3","path":"${dataDir}","status":"degraded"`)
+	})
+}
+
+func TestChecker_CheckServer(t *testing.T) {
+	ctx := context.Background()
+	env := newEnv()
+
+	t.Run("unknown_variable", func(t *testing.T) {
+		checker := NewChecker(WithDriver("sqlserver", &stubDriver{}))
+		report := checker.CheckServer(ctx, nil, env, &datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "${host}"}})
+		assert.Equal(t, StatusError, report.Status)
+		assert.Contains(t, report.Error, "host")
+	})
+
+	t.Run("alias_and_default_port", func(t *testing.T) {
+		driver := &stubDriver{}
+		checker := NewChecker(WithDriver("mssql", driver))
+		report := checker.CheckServer(ctx, nil, env, &datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "db"}})
+		assert.Equal(t, StatusOK, report.Status, report.Error)
+		require.Len(t, driver.params, 1)
+		assert.Equal(t, 1433, driver.params[0].Port())
+		assert.Equal(t, dbconnection.ModeReadOnly, driver.params[0].Mode())
+	})
+
+	t.Run("catalogs_not_listed", func(t *testing.T) {
+		checker := NewChecker(WithDriver("sqlserver", &stubDriver{catalogsErr: errors.New("permission denied")}))
+		report := checker.CheckServer(ctx, nil, env, &datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "db"}, Catalogs: []string{"sales"}})
+		assert.Equal(t, StatusDegraded, report.Status)
+		assert.Equal(t, []CatalogReport{{ID: "sales", Status: StatusError, Error: "permission denied"}}, report.Catalogs)
+	})
+
+	t.Run("ssh_tunnel", func(t *testing.T) {
+		signer, keyPath := sshtest.NewKey(t)
+		bastion := sshtest.NewServer(t, signer.PublicKey())
+		echoHost, echoPort, err := net.SplitHostPort(sshtest.NewEchoServer(t))
+		require.NoError(t, err)
+		port, err := strconv.Atoi(echoPort)
+		require.NoError(t, err)
+		server := &datatug.EnvDbServer{
+			ServerRef: datatug.ServerRef{Driver: "postgres", Host: echoHost, Port: port},
+			SSHTunnel: &datatug.SSHTunnel{Host: bastion.Host(), Port: bastion.Port(), User: "${sshUser}", KeyPath: keyPath},
+			Variables: datatug.Variables{Vars: datatug.VarsByID{"sshUser": {Type: "str", Value: "tunnel"}}},
+		}
+		checker := NewChecker(
+			WithDriver("postgres", tcpDriver{}),
+			WithTunnelOptions(sshtunnel.WithHostKeyCallback(ssh.FixedHostKey(bastion.HostKey.PublicKey()))),
+		)
+		report := checker.CheckServer(ctx, nil, env, server)
+		assert.Equal(t, StatusOK, report.Status, report.Error)
+		assert.Equal(t, []string{net.JoinHostPort(echoHost, echoPort)}, bastion.Forwarded())
+		assert.Equal(t, echoHost, report.Host, "report should reference the server, not the tunnel")
+
+		t.Run("unauthorized_key", func(t *testing.T) {
+			_, unauthorizedKeyPath := sshtest.NewKey(t)
+			server.SSHTunnel = &datatug.SSHTunnel{Host: bastion.Host(), Port: bastion.Port(), User: "tunnel", KeyPath: unauthorizedKeyPath}
+			report := checker.CheckServer(ctx, nil, env, server)
+			assert.Equal(t, StatusUnreachable, report.Status)
+		})
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		checker := NewChecker(WithDriver("sqlserver", blockingDriver{}), WithTimeout(10*time.Millisecond))
+		report := checker.CheckServer(ctx, nil, env, &datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "db"}})
+		assert.Equal(t, StatusUnreachable, report.Status)
+		assert.Contains(t, report.Error, context.DeadlineExceeded.Error())
+	})
+}
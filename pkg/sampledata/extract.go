@@ -0,0 +1,522 @@
+// Package sampledata extracts rows of a table together with rows they reference, so the sample can be loaded
+// into another environment without violating foreign keys.
+package sampledata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/strongo/validation"
+)
+
+// DefaultMaxRows limits number of rows of a sample if a request does not set a limit
+const DefaultMaxRows = 10000
+
+// DataFileName is a name of a data file of a recordset written by Sample.Save
+const DataFileName = "data.json"
+
+// ErrTooManyRows is returned if a sample exceeds the limit on number of rows
+var ErrTooManyRows = errors.New("too many rows")
+
+// Request defines what rows to extract
+type Request struct {
+	Catalog *datatug.DbCatalog
+	Schema  string
+	Table   string
+	// Where is an SQL condition that selects the starting rows, e.g. "id IN (?, ?)"
+	Where string
+	Args  []interface{}
+	// ChildLevels is how many levels of referencing (child) rows to extract, parents are always extracted
+	ChildLevels int
+	// MaxRows limits total number of rows of all tables, DefaultMaxRows is used if 0
+	MaxRows int
+}
+
+// Validate returns error if not valid
+func (v Request) Validate() error {
+	if v.Catalog == nil {
+		return validation.NewErrRequestIsMissingRequiredField("catalog")
+	}
+	if v.Table == "" {
+		return validation.NewErrRequestIsMissingRequiredField("table")
+	}
+	if strings.TrimSpace(v.Where) == "" {
+		return validation.NewErrRequestIsMissingRequiredField("where")
+	}
+	if v.ChildLevels < 0 {
+		return validation.NewErrBadRequestFieldValue("childLevels", fmt.Sprintf("should not be negative, got: %v", v.ChildLevels))
+	}
+	if v.MaxRows < 0 {
+		return validation.NewErrBadRequestFieldValue("maxRows", fmt.Sprintf("should not be negative, got: %v", v.MaxRows))
+	}
+	return nil
+}
+
+// Sample holds extracted rows of tables
+type Sample struct {
+	// Tables are in order of loading: referenced tables go before tables that reference them
+	Tables []*TableSample
+}
+
+// TableSample holds extracted rows of a table
+type TableSample struct {
+	Table     *datatug.CollectionInfo
+	Recordset datatug.Recordset
+}
+
+// RowsCount returns total number of rows of all tables
+func (v Sample) RowsCount() (count int) {
+	for _, t := range v.Tables {
+		count += len(t.Recordset.Rows)
+	}
+	return
+}
+
+// Extract selects rows of a table that match a condition & all rows they reference by foreign keys,
+// recursively, & optionally rows that reference them up to Request.ChildLevels levels.
+// NULL foreign keys are not followed.
+func Extract(ctx context.Context, source Source, request Request) (*Sample, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	table := request.Catalog.GetTable(request.Schema, request.Table)
+	if table == nil {
+		return nil, fmt.Errorf("table %s is not found in catalog %s", qualifiedName(request.Schema, request.Table), request.Catalog.ID)
+	}
+	e := extractor{
+		source:  source,
+		catalog: request.Catalog,
+		maxRows: request.MaxRows,
+		tables:  make(map[string]*tableRows),
+	}
+	if e.maxRows == 0 {
+		e.maxRows = DefaultMaxRows
+	}
+	recordset, err := source.SelectWhere(ctx, table, request.Where, request.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select rows of %s: %w", tableID(table), err)
+	}
+	start := e.getTable(table)
+	rows, err := e.add(start, recordset)
+	if err != nil {
+		return nil, err
+	}
+	var queue []pending
+	if len(rows) > 0 {
+		queue = append(queue, pending{t: start, rows: rows, childLevels: request.ChildLevels})
+	}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p := queue[0]
+		queue = queue[1:]
+		parents, err := e.selectParents(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, parents...)
+		if p.childLevels > 0 {
+			children, err := e.selectChildren(ctx, p)
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, children...)
+		}
+	}
+	return e.sample(), nil
+}
+
+// pending are rows added to a sample which references are not followed yet
+type pending struct {
+	t           *tableRows
+	rows        [][]interface{}
+	childLevels int
+}
+
+type tableRows struct {
+	table   *datatug.CollectionInfo
+	columns []datatug.RecordsetColumn
+	rows    [][]interface{}
+	keys    map[string]bool
+	// keyIndexes are indexes of primary key columns, all columns identify a row if a table has no primary key
+	keyIndexes []int
+}
+
+type extractor struct {
+	source    Source
+	catalog   *datatug.DbCatalog
+	maxRows   int
+	rowsCount int
+	tables    map[string]*tableRows
+	order     []*tableRows // in order tables are discovered
+}
+
+func (e *extractor) getTable(table *datatug.CollectionInfo) *tableRows {
+	id := tableID(table)
+	t := e.tables[id]
+	if t == nil {
+		t = &tableRows{table: table, keys: make(map[string]bool)}
+		e.tables[id] = t
+		e.order = append(e.order, t)
+	}
+	return t
+}
+
+// add appends rows that are not in the sample yet & returns them
+func (e *extractor) add(t *tableRows, recordset datatug.Recordset) (added [][]interface{}, err error) {
+	if len(recordset.Rows) == 0 {
+		return nil, nil
+	}
+	if t.columns == nil {
+		t.columns = recordset.Columns
+		if t.keyIndexes, err = columnIndexes(t, primaryKeyOrAllColumns(t)); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range recordset.Rows {
+		key := rowKey(row, t.keyIndexes)
+		if t.keys[key] {
+			continue
+		}
+		t.keys[key] = true
+		t.rows = append(t.rows, row)
+		added = append(added, row)
+	}
+	e.rowsCount += len(added)
+	if e.rowsCount > e.maxRows {
+		return nil, fmt.Errorf("%w: sample exceeds limit of %v rows at table %s", ErrTooManyRows, e.maxRows, tableID(t.table))
+	}
+	return added, nil
+}
+
+// selectParents selects rows referenced by foreign keys of the pending rows
+func (e *extractor) selectParents(ctx context.Context, p pending) (parents []pending, err error) {
+	for _, fk := range p.t.table.ForeignKeys {
+		parent := e.catalog.GetTable(fk.RefTable.Schema(), fk.RefTable.Name())
+		if parent == nil {
+			return nil, fmt.Errorf("table %s referenced by foreign key %s is not found in catalog %s",
+				qualifiedName(fk.RefTable.Schema(), fk.RefTable.Name()), fk.Name, e.catalog.ID)
+		}
+		pkColumns, err := parent.PrimaryKeyColumns()
+		if err != nil {
+			return nil, fmt.Errorf("table %s referenced by foreign key %s: %w", tableID(parent), fk.Name, err)
+		}
+		if len(pkColumns) != len(fk.Columns) {
+			return nil, fmt.Errorf("foreign key %s of %s has %v columns but primary key of %s has %v",
+				fk.Name, tableID(p.t.table), len(fk.Columns), tableID(parent), len(pkColumns))
+		}
+		fkIndexes, err := columnIndexes(p.t, fk.Columns)
+		if err != nil {
+			return nil, err
+		}
+		parentRows := e.getTable(parent)
+		keys := missingKeys(p.rows, fkIndexes, parentRows)
+		if len(keys) == 0 {
+			continue
+		}
+		recordset, err := e.source.SelectByKeys(ctx, parent, pkColumns, keys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select rows of %s referenced by foreign key %s: %w", tableID(parent), fk.Name, err)
+		}
+		added, err := e.add(parentRows, recordset)
+		if err != nil {
+			return nil, err
+		}
+		if len(added) > 0 {
+			parents = append(parents, pending{t: parentRows, rows: added})
+		}
+	}
+	return parents, nil
+}
+
+// selectChildren selects rows that reference the pending rows by foreign keys
+func (e *extractor) selectChildren(ctx context.Context, p pending) (children []pending, err error) {
+	if len(p.t.table.ReferencedBy) == 0 {
+		return nil, nil
+	}
+	pkColumns, err := p.t.table.PrimaryKeyColumns()
+	if err != nil {
+		return nil, fmt.Errorf("rows referencing %s can't be selected: %w", tableID(p.t.table), err)
+	}
+	pkIndexes, err := columnIndexes(p.t, pkColumns)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([][]interface{}, len(p.rows))
+	for i, row := range p.rows {
+		keys[i] = keyValues(row, pkIndexes)
+	}
+	for _, refBy := range p.t.table.ReferencedBy {
+		child := e.catalog.GetTable(refBy.Schema(), refBy.Name())
+		if child == nil {
+			return nil, fmt.Errorf("table %s referencing %s is not found in catalog %s",
+				qualifiedName(refBy.Schema(), refBy.Name()), tableID(p.t.table), e.catalog.ID)
+		}
+		childRows := e.getTable(child)
+		for _, fk := range refBy.ForeignKeys {
+			if len(fk.Columns) != len(pkColumns) {
+				return nil, fmt.Errorf("foreign key %s of %s has %v columns but primary key of %s has %v",
+					fk.Name, tableID(child), len(fk.Columns), tableID(p.t.table), len(pkColumns))
+			}
+			recordset, err := e.source.SelectByKeys(ctx, child, fk.Columns, keys)
+			if err != nil {
+				return nil, fmt.Errorf("failed to select rows of %s referencing %s by foreign key %s: %w",
+					tableID(child), tableID(p.t.table), fk.Name, err)
+			}
+			added, err := e.add(childRows, recordset)
+			if err != nil {
+				return nil, err
+			}
+			if len(added) > 0 {
+				children = append(children, pending{t: childRows, rows: added, childLevels: p.childLevels - 1})
+			}
+		}
+	}
+	return children, nil
+}
+
+// missingKeys returns distinct non-NULL foreign key values that are not in the sample of the referenced table
+func missingKeys(rows [][]interface{}, fkIndexes []int, parent *tableRows) (keys [][]interface{}) {
+	requested := make(map[string]bool)
+	for _, row := range rows {
+		key := keyValues(row, fkIndexes)
+		if hasNull(key) {
+			continue
+		}
+		s := valuesKey(key)
+		if parent.keys[s] || requested[s] {
+			continue
+		}
+		requested[s] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// sample orders tables & rows of self-referencing tables so parents are loaded before children
+func (e *extractor) sample() *Sample {
+	sample := &Sample{Tables: make([]*TableSample, 0, len(e.order))}
+	for _, t := range e.loadOrder() {
+		if len(t.rows) == 0 {
+			continue
+		}
+		sample.Tables = append(sample.Tables, &TableSample{
+			Table:     t.table,
+			Recordset: datatug.Recordset{Columns: t.columns, Rows: e.rowsInLoadOrder(t)},
+		})
+	}
+	return sample
+}
+
+// loadOrder sorts tables topologically keeping order of discovery where possible,
+// tables of a cycle of foreign keys are ordered as discovered
+func (e *extractor) loadOrder() []*tableRows {
+	ordered := make([]*tableRows, 0, len(e.order))
+	added := make(map[*tableRows]bool, len(e.order))
+	isReady := func(t *tableRows) bool {
+		for _, fk := range t.table.ForeignKeys {
+			parent := e.tables[qualifiedName(fk.RefTable.Schema(), fk.RefTable.Name())]
+			if parent != nil && parent != t && !added[parent] {
+				return false
+			}
+		}
+		return true
+	}
+	for len(ordered) < len(e.order) {
+		var next *tableRows
+		for _, t := range e.order {
+			if !added[t] && isReady(t) {
+				next = t
+				break
+			}
+		}
+		if next == nil { // a cycle
+			for _, t := range e.order {
+				if !added[t] {
+					next = t
+					break
+				}
+			}
+		}
+		added[next] = true
+		ordered = append(ordered, next)
+	}
+	return ordered
+}
+
+// rowsInLoadOrder puts rows referenced by foreign keys of a table to itself before rows that reference them
+func (e *extractor) rowsInLoadOrder(t *tableRows) [][]interface{} {
+	var selfRefs [][]int
+	for _, fk := range t.table.ForeignKeys {
+		if e.tables[qualifiedName(fk.RefTable.Schema(), fk.RefTable.Name())] == t {
+			if indexes, err := columnIndexes(t, fk.Columns); err == nil {
+				selfRefs = append(selfRefs, indexes)
+			}
+		}
+	}
+	if len(selfRefs) == 0 {
+		return t.rows
+	}
+	ordered := make([][]interface{}, 0, len(t.rows))
+	loaded := make(map[string]bool, len(t.rows))
+	remaining := t.rows
+	for len(remaining) > 0 {
+		var deferred [][]interface{}
+		for _, row := range remaining {
+			key := rowKey(row, t.keyIndexes)
+			isReady := true
+			for _, fkIndexes := range selfRefs {
+				ref := keyValues(row, fkIndexes)
+				if s := valuesKey(ref); !hasNull(ref) && s != key && t.keys[s] && !loaded[s] {
+					isReady = false
+					break
+				}
+			}
+			if isReady {
+				loaded[key] = true
+				ordered = append(ordered, row)
+			} else {
+				deferred = append(deferred, row)
+			}
+		}
+		if len(deferred) == len(remaining) { // a cycle
+			return append(ordered, deferred...)
+		}
+		remaining = deferred
+	}
+	return ordered
+}
+
+// Save writes tables of a sample as project recordsets with IDs "{name}.{schema}.{table}"
+// & returns a changeset definition that references the recordsets in order of loading.
+func (v Sample) Save(ctx context.Context, store datatug.RecordsetDefinitionsStore, name string) (changeset datatug.ChangesetDef, err error) {
+	if strings.TrimSpace(name) == "" {
+		return changeset, validation.NewErrRequestIsMissingRequiredField("name")
+	}
+	changeset.ID = name
+	changeset.Title = "Sample data: " + name
+	for _, t := range v.Tables {
+		def := recordsetDefinition(name, t)
+		if err := store.SaveRecordsetDefinition(ctx, def); err != nil {
+			return changeset, fmt.Errorf("failed to save definition of recordset %s: %w", def.ID, err)
+		}
+		if err := store.SaveRecordsetData(ctx, def.ID, DataFileName, t.Recordset); err != nil {
+			return changeset, fmt.Errorf("failed to save data of recordset %s: %w", def.ID, err)
+		}
+		changeset.Datasets = append(changeset.Datasets, datatug.ChangesetRefToDataset{ID: def.ID, Required: true})
+	}
+	return changeset, nil
+}
+
+// RecordsetID returns ID of a recordset a table of a sample is saved to
+func RecordsetID(name string, table *datatug.CollectionInfo) string {
+	return name + "." + tableID(table)
+}
+
+func recordsetDefinition(name string, t *TableSample) *datatug.RecordsetDefinition {
+	def := &datatug.RecordsetDefinition{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: RecordsetID(name, t.Table), Title: tableID(t.Table)},
+		},
+		Columns: make(datatug.RecordsetColumnDefs, len(t.Recordset.Columns)),
+		Type:    "recordset",
+		Files:   []string{DataFileName},
+	}
+	if t.Table.PrimaryKey != nil {
+		pk := *t.Table.PrimaryKey
+		def.PrimaryKey = &pk
+	}
+	for i, column := range t.Recordset.Columns {
+		columnDef := datatug.RecordsetColumnDef{Name: column.Name, Type: column.DbType}
+		for _, c := range t.Table.Columns {
+			if c.Name == column.Name {
+				columnDef.Type = c.DbType
+				columnDef.Required = !c.IsNullable
+				break
+			}
+		}
+		if columnDef.Type == "" {
+			columnDef.Type = "unknown"
+		}
+		def.Columns[i] = columnDef
+	}
+	return def
+}
+
+func primaryKeyOrAllColumns(t *tableRows) []string {
+	if columns, err := t.table.PrimaryKeyColumns(); err == nil {
+		return columns
+	}
+	columns := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		columns[i] = c.Name
+	}
+	return columns
+}
+
+func columnIndexes(t *tableRows, columns []string) ([]int, error) {
+	indexes := make([]int, len(columns))
+	for i, name := range columns {
+		indexes[i] = -1
+		for j, c := range t.columns {
+			if strings.EqualFold(c.Name, name) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, fmt.Errorf("rows of %s have no column %s", tableID(t.table), name)
+		}
+	}
+	return indexes, nil
+}
+
+func keyValues(row []interface{}, indexes []int) []interface{} {
+	values := make([]interface{}, len(indexes))
+	for i, index := range indexes {
+		values[i] = row[index]
+	}
+	return values
+}
+
+func hasNull(values []interface{}) bool {
+	for _, v := range values {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func rowKey(row []interface{}, indexes []int) string {
+	return valuesKey(keyValues(row, indexes))
+}
+
+// valuesKey formats values of a key, so a foreign key value matches a primary key value of another type, e.g. int32 & int64
+func valuesKey(values []interface{}) string {
+	formatted := make([]*string, len(values))
+	for i, v := range values {
+		if v != nil {
+			s := datatug.FormatRecordsetValue(v)
+			formatted[i] = &s
+		}
+	}
+	key, _ := json.Marshal(formatted)
+	return string(key)
+}
+
+func tableID(table *datatug.CollectionInfo) string {
+	return qualifiedName(table.Schema(), table.Name())
+}
+
+func qualifiedName(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+	return schema + "." + name
+}
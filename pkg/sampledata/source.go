@@ -0,0 +1,128 @@
+package sampledata
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dbdriver"
+)
+
+// Source reads rows of tables of a catalog
+type Source interface {
+	// SelectWhere returns rows of a table that match an SQL condition
+	SelectWhere(ctx context.Context, table *datatug.CollectionInfo, condition string, args ...interface{}) (datatug.Recordset, error)
+	// SelectByKeys returns rows of a table with values of the columns equal to any of the keys
+	SelectByKeys(ctx context.Context, table *datatug.CollectionInfo, columns []string, keys [][]interface{}) (datatug.Recordset, error)
+}
+
+// Querier runs queries, e.g. *sql.DB, *sql.Tx or *dbdriver.Tx in read-only mode
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// maxKeysPerQuery limits number of keys selected by a single query, so it is within limits on number of parameters
+const maxKeysPerQuery = 100
+
+// NewSQLSource creates a source that reads rows with SELECT statements in the SQL dialect of a driver
+func NewSQLSource(q Querier, dialect dbdriver.Dialect) Source {
+	return sqlSource{q: q, dialect: dialect}
+}
+
+type sqlSource struct {
+	q       Querier
+	dialect dbdriver.Dialect
+}
+
+func (s sqlSource) SelectWhere(ctx context.Context, table *datatug.CollectionInfo, condition string, args ...interface{}) (datatug.Recordset, error) {
+	return s.query(ctx, selectWhereSQL(s.dialect, table, condition), args)
+}
+
+func (s sqlSource) SelectByKeys(ctx context.Context, table *datatug.CollectionInfo, columns []string, keys [][]interface{}) (recordset datatug.Recordset, err error) {
+	for start := 0; start < len(keys); start += maxKeysPerQuery {
+		end := min(start+maxKeysPerQuery, len(keys))
+		query, args := selectByKeysSQL(s.dialect, table, columns, keys[start:end])
+		batch, err := s.query(ctx, query, args)
+		if err != nil {
+			return recordset, err
+		}
+		recordset.Columns = batch.Columns
+		recordset.Rows = append(recordset.Rows, batch.Rows...)
+	}
+	return recordset, nil
+}
+
+func (s sqlSource) query(ctx context.Context, query string, args []interface{}) (recordset datatug.Recordset, err error) {
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return recordset, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	reader, err := dbdriver.NewRowsReader(rows)
+	if err != nil {
+		return recordset, err
+	}
+	recordset.Columns = reader.Columns()
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return recordset, nil
+		}
+		if err != nil {
+			return recordset, fmt.Errorf("failed to read rows: %w", err)
+		}
+		for i, v := range row {
+			// drivers return text as bytes, e.g. MySQL, & bytes would be saved to JSON as base64
+			if b, ok := v.([]byte); ok && utf8.Valid(b) {
+				row[i] = string(b)
+			}
+		}
+		recordset.Rows = append(recordset.Rows, row)
+	}
+}
+
+func selectWhereSQL(dialect dbdriver.Dialect, table *datatug.CollectionInfo, condition string) string {
+	return fmt.Sprintf("SELECT * FROM %s WHERE (%s)", tableName(dialect, table), condition)
+}
+
+// selectByKeysSQL uses IN for single column keys & ORs conditions on all columns for composite keys
+func selectByKeysSQL(dialect dbdriver.Dialect, table *datatug.CollectionInfo, columns []string, keys [][]interface{}) (string, []interface{}) {
+	args := make([]interface{}, 0, len(keys)*len(columns))
+	placeholder := func(v interface{}) string {
+		args = append(args, v)
+		return dialect.Placeholder(len(args))
+	}
+	var where string
+	if len(columns) == 1 {
+		placeholders := make([]string, len(keys))
+		for i, key := range keys {
+			placeholders[i] = placeholder(key[0])
+		}
+		where = fmt.Sprintf("%s IN (%s)", dialect.QuoteIdentifier(columns[0]), strings.Join(placeholders, ", "))
+	} else {
+		conditions := make([]string, len(keys))
+		for i, key := range keys {
+			equals := make([]string, len(columns))
+			for j, column := range columns {
+				equals[j] = dialect.QuoteIdentifier(column) + " = " + placeholder(key[j])
+			}
+			conditions[i] = "(" + strings.Join(equals, " AND ") + ")"
+		}
+		where = strings.Join(conditions, " OR ")
+	}
+	return selectWhereSQL(dialect, table, where), args
+}
+
+func tableName(dialect dbdriver.Dialect, table *datatug.CollectionInfo) string {
+	if dialect.SupportsSchemas {
+		return dialect.QuoteName(table.Schema(), table.Name())
+	}
+	return dialect.QuoteIdentifier(table.Name())
+}
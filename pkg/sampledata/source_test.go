@@ -0,0 +1,26 @@
+package sampledata
+
+import (
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dbdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectByKeysSQL(t *testing.T) {
+	table := &datatug.CollectionInfo{DBCollectionKey: datatug.NewTableKey("order_lines", "dbo", "shop", nil)}
+	postgres := dbdriver.Dialect{QuoteStart: `"`, QuoteEnd: `"`, Placeholders: dbdriver.PlaceholderDollar, SupportsSchemas: true}
+
+	query, args := selectByKeysSQL(postgres, table, []string{"order_id"}, [][]interface{}{{10}, {11}})
+	assert.Equal(t, `SELECT * FROM "dbo"."order_lines" WHERE ("order_id" IN ($1, $2))`, query)
+	assert.Equal(t, []interface{}{10, 11}, args)
+
+	query, args = selectByKeysSQL(postgres, table, []string{"order_id", "line"}, [][]interface{}{{10, 1}, {11, 2}})
+	assert.Equal(t, `SELECT * FROM "dbo"."order_lines" WHERE (("order_id" = $1 AND "line" = $2) OR ("order_id" = $3 AND "line" = $4))`, query)
+	assert.Equal(t, []interface{}{10, 1, 11, 2}, args)
+
+	mysql := dbdriver.Dialect{QuoteStart: "`", QuoteEnd: "`", Placeholders: dbdriver.PlaceholderQuestion}
+	query, _ = selectByKeysSQL(mysql, table, []string{"order_id"}, [][]interface{}{{10}})
+	assert.Equal(t, "SELECT * FROM `order_lines` WHERE (`order_id` IN (?))", query, "schema should be omitted if not supported")
+}
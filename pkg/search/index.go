@@ -0,0 +1,361 @@
+// Package search implements a project-wide in-memory full text index
+// over queries, boards, entities and DB schema objects.
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/datatug/datatug-core/pkg/dto"
+)
+
+// ItemType defines type of indexed item
+type ItemType string
+
+const (
+	ItemTypeQuery  ItemType = "query"
+	ItemTypeBoard  ItemType = "board"
+	ItemTypeEntity ItemType = "entity"
+	ItemTypeTable  ItemType = "table"
+	ItemTypeView   ItemType = "view"
+	ItemTypeColumn ItemType = "column"
+)
+
+// Weights of document fields, a match in a heavier field ranks higher
+const (
+	WeightText  = 1.0 // e.g. query text, widget SQL
+	WeightTag   = 2.0
+	WeightTitle = 3.0
+	WeightName  = 3.0 // e.g. ID of an item, name of a table or a column
+)
+
+// Rank factors of a term match
+const (
+	exactMatchFactor  = 1.0
+	prefixMatchFactor = 0.5 // plus up to 0.5 depending on how much of the term is matched
+	fuzzyMatchFactor  = 0.4 // divided by 1 + edit distance
+)
+
+// ItemRef identifies an indexed item
+type ItemRef struct {
+	Type ItemType `json:"type"`
+	// ID of the item, for queries includes folder path, for tables & views is "{schema}.{name}",
+	// for columns is "{schema}.{table}.{column}"
+	ID      string `json:"id"`
+	EnvID   string `json:"env,omitempty"`     // set for schema objects of environment catalogs
+	Catalog string `json:"catalog,omitempty"` // set for schema objects
+}
+
+// ProjectItemRef references an indexed item of a project in API requests,
+// type & environment are kept as IDs of schema objects are unique only within a catalog of an environment
+type ProjectItemRef struct {
+	dto.ProjectItemRef
+	Type    ItemType `json:"type"`
+	EnvID   string   `json:"env,omitempty"`
+	Catalog string   `json:"catalog,omitempty"`
+}
+
+// ProjectItemRef returns reference to the item that can be used in API requests
+func (v ItemRef) ProjectItemRef(projectRef dto.ProjectRef) ProjectItemRef {
+	return ProjectItemRef{
+		ProjectItemRef: dto.ProjectItemRef{ProjectRef: projectRef, ID: v.ID},
+		Type:           v.Type,
+		EnvID:          v.EnvID,
+		Catalog:        v.Catalog,
+	}
+}
+
+// Field is a searchable text of a document
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Document is an item to be indexed
+type Document struct {
+	ItemRef
+	Title  string
+	Folder string
+	Tags   []string
+	Fields []Field
+}
+
+// Query defines search request
+type Query struct {
+	Text   string     // all words should match, the last word is matched as a prefix
+	Types  []ItemType // if set only items of given types are returned
+	Tags   []string   // if set items should have all given tags
+	Folder string     // if set only items in the folder or its subfolders are returned
+	EnvID  string     // if set only schema objects of the environment are returned
+	Fuzzy  bool       // if true words with typos are matched
+	Limit  int        // max number of results, 0 for no limit
+}
+
+// Result is a ranked search hit
+type Result struct {
+	ItemRef
+	Title  string   `json:"title,omitempty"`
+	Folder string   `json:"folder,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Score  float64  `json:"score"`
+}
+
+// Index is an inverted index of project items, it's safe for concurrent use
+type Index struct {
+	mutex    sync.RWMutex
+	docs     map[ItemRef]*Document
+	postings map[string]map[ItemRef]float64 // term => item => max weight of fields containing the term
+	terms    []string                       // sorted terms for prefix lookups, nil if outdated
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[ItemRef]*Document),
+		postings: make(map[string]map[ItemRef]float64),
+	}
+}
+
+// Len returns number of indexed items
+func (idx *Index) Len() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	return len(idx.docs)
+}
+
+// Add indexes documents replacing previously indexed documents with same refs
+func (idx *Index) Add(docs ...Document) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	for _, doc := range docs {
+		idx.add(doc)
+	}
+}
+
+func (idx *Index) add(doc Document) {
+	idx.remove(doc.ItemRef)
+	d := doc
+	idx.docs[doc.ItemRef] = &d
+	weights := make(map[string]float64)
+	addTerms := func(text string, weight float64) {
+		for _, term := range tokenize(text) {
+			weights[term] = max(weights[term], weight)
+		}
+	}
+	addTerms(doc.Title, WeightTitle)
+	for _, tag := range doc.Tags {
+		addTerms(tag, WeightTag)
+	}
+	for _, f := range doc.Fields {
+		addTerms(f.Text, f.Weight)
+	}
+	for term, weight := range weights {
+		items, ok := idx.postings[term]
+		if !ok {
+			items = make(map[ItemRef]float64)
+			idx.postings[term] = items
+			idx.terms = nil
+		}
+		items[doc.ItemRef] = weight
+	}
+}
+
+// Remove removes items from the index
+func (idx *Index) Remove(refs ...ItemRef) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	for _, ref := range refs {
+		idx.remove(ref)
+	}
+}
+
+// RemoveWhere removes items matching a predicate
+func (idx *Index) RemoveWhere(match func(ref ItemRef) bool) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	for ref := range idx.docs {
+		if match(ref) {
+			idx.remove(ref)
+		}
+	}
+}
+
+func (idx *Index) remove(ref ItemRef) {
+	if _, ok := idx.docs[ref]; !ok {
+		return
+	}
+	delete(idx.docs, ref)
+	for term, items := range idx.postings {
+		if _, ok := items[ref]; !ok {
+			continue
+		}
+		delete(items, ref)
+		if len(items) == 0 {
+			delete(idx.postings, term)
+			idx.terms = nil
+		}
+	}
+}
+
+// Search returns items matching the query ordered by score, best first.
+// If query text is empty all items matching filters are returned.
+func (idx *Index) Search(q Query) (results []Result) {
+	idx.rLockWithSortedTerms()
+	defer idx.mutex.RUnlock()
+
+	terms := queryTerms(q.Text)
+	var scores map[ItemRef]float64
+	if len(terms) == 0 {
+		scores = make(map[ItemRef]float64, len(idx.docs))
+		for ref := range idx.docs {
+			scores[ref] = 0
+		}
+	} else {
+		for i, term := range terms {
+			isLast := i == len(terms)-1
+			termScores := idx.matchTerm(term, isLast, q.Fuzzy)
+			if scores == nil {
+				scores = termScores
+				continue
+			}
+			for ref, score := range scores {
+				if termScore, ok := termScores[ref]; ok {
+					scores[ref] = score + termScore
+				} else {
+					delete(scores, ref)
+				}
+			}
+		}
+	}
+	for ref, score := range scores {
+		doc := idx.docs[ref]
+		if !q.matches(doc) {
+			continue
+		}
+		results = append(results, Result{
+			ItemRef: ref,
+			Title:   doc.Title,
+			Folder:  doc.Folder,
+			Tags:    doc.Tags,
+			Score:   score,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		if a.EnvID != b.EnvID {
+			return a.EnvID < b.EnvID
+		}
+		return a.Catalog < b.Catalog
+	})
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results
+}
+
+// matchTerm returns best score of a query term for each matching item
+func (idx *Index) matchTerm(term string, isPrefix, isFuzzy bool) map[ItemRef]float64 {
+	scores := make(map[ItemRef]float64)
+	collect := func(indexed string, factor float64) {
+		for ref, weight := range idx.postings[indexed] {
+			scores[ref] = max(scores[ref], factor*weight)
+		}
+	}
+	collect(term, exactMatchFactor)
+	if isPrefix {
+		terms := idx.terms
+		for i := sort.SearchStrings(terms, term); i < len(terms) && strings.HasPrefix(terms[i], term); i++ {
+			if terms[i] != term {
+				collect(terms[i], prefixMatchFactor+0.5*float64(len(term))/float64(len(terms[i])))
+			}
+		}
+	}
+	if isFuzzy {
+		if edits := maxEdits(term); edits > 0 {
+			for indexed := range idx.postings {
+				if d := editDistance(term, indexed, edits); d > 0 && d <= edits {
+					collect(indexed, fuzzyMatchFactor/float64(1+d))
+				}
+			}
+		}
+	}
+	return scores
+}
+
+// rLockWithSortedTerms read locks the index rebuilding sorted terms under a write lock first if they are outdated,
+// so searches run concurrently
+func (idx *Index) rLockWithSortedTerms() {
+	for {
+		idx.mutex.RLock()
+		if idx.terms != nil {
+			return
+		}
+		idx.mutex.RUnlock()
+		idx.mutex.Lock()
+		idx.sortedTerms()
+		idx.mutex.Unlock()
+	}
+}
+
+func (idx *Index) sortedTerms() []string {
+	if idx.terms == nil {
+		idx.terms = make([]string, 0, len(idx.postings))
+		for term := range idx.postings {
+			idx.terms = append(idx.terms, term)
+		}
+		sort.Strings(idx.terms)
+	}
+	return idx.terms
+}
+
+func (q Query) matches(doc *Document) bool {
+	if len(q.Types) > 0 && !containsType(q.Types, doc.Type) {
+		return false
+	}
+	if q.EnvID != "" && doc.EnvID != q.EnvID {
+		return false
+	}
+	if q.Folder != "" {
+		folder := strings.Trim(q.Folder, "/")
+		if doc.Folder != folder && !strings.HasPrefix(doc.Folder, folder+"/") {
+			return false
+		}
+	}
+	for _, tag := range q.Tags {
+		if !hasTag(doc.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsType(types []ItemType, t ItemType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
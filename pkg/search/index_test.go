@@ -0,0 +1,151 @@
+package search
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex() *Index {
+	idx := NewIndex()
+	idx.Add(
+		Document{
+			ItemRef: ItemRef{Type: ItemTypeQuery, ID: "reports/daily_sales"},
+			Title:   "Daily sales",
+			Folder:  "reports",
+			Tags:    []string{"finance"},
+			Fields:  []Field{{Text: "select * from Orders", Weight: WeightText}},
+		},
+		Document{
+			ItemRef: ItemRef{Type: ItemTypeBoard, ID: "customers"},
+			Title:   "Customers overview",
+			Tags:    []string{"crm", "finance"},
+			Fields:  []Field{{Text: "select * from Customers", Weight: WeightText}},
+		},
+		Document{
+			ItemRef: ItemRef{Type: ItemTypeTable, ID: "dbo.Customers", EnvID: "dev", Catalog: "northwind"},
+			Title:   "Customers",
+		},
+		Document{
+			ItemRef: ItemRef{Type: ItemTypeTable, ID: "dbo.Customers", EnvID: "prod", Catalog: "northwind"},
+			Title:   "Customers",
+		},
+	)
+	return idx
+}
+
+func resultIDs(results []Result) (ids []string) {
+	for _, r := range results {
+		id := string(r.Type) + ":" + r.ID
+		if r.EnvID != "" {
+			id += "@" + r.EnvID
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := newTestIndex()
+	assert.Equal(t, 4, idx.Len())
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{
+			name:  "ranked_by_field_weight",
+			query: Query{Text: "customers"},
+			want:  []string{"board:customers", "table:dbo.Customers@dev", "table:dbo.Customers@prod"},
+		},
+		{name: "all_words_should_match", query: Query{Text: "customers orders"}, want: nil},
+		{name: "prefix_of_last_word", query: Query{Text: "daily sal"}, want: []string{"query:reports/daily_sales"}},
+		{name: "no_prefix_for_not_last_word", query: Query{Text: "dai sales"}, want: nil},
+		{name: "no_fuzzy_by_default", query: Query{Text: "custmers"}, want: nil},
+		{name: "fuzzy", query: Query{Text: "custmers", Fuzzy: true, Types: []ItemType{ItemTypeBoard}}, want: []string{"board:customers"}},
+		{name: "type_filter", query: Query{Text: "customers", Types: []ItemType{ItemTypeTable}}, want: []string{"table:dbo.Customers@dev", "table:dbo.Customers@prod"}},
+		{name: "env_filter", query: Query{Text: "customers", EnvID: "prod"}, want: []string{"table:dbo.Customers@prod"}},
+		{name: "tag_filter", query: Query{Tags: []string{"Finance"}}, want: []string{"board:customers", "query:reports/daily_sales"}},
+		{name: "tag_search", query: Query{Text: "crm"}, want: []string{"board:customers"}},
+		{name: "folder_filter", query: Query{Folder: "/reports/"}, want: []string{"query:reports/daily_sales"}},
+		{name: "folder_prefix_is_not_a_match", query: Query{Folder: "rep"}, want: nil},
+		{name: "limit", query: Query{Text: "customers", Limit: 1}, want: []string{"board:customers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resultIDs(idx.Search(tt.query)))
+		})
+	}
+}
+
+func TestIndex_Search_ExactBeforePrefix(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(
+		Document{ItemRef: ItemRef{Type: ItemTypeTable, ID: "orders_archive"}, Title: "OrdersArchive"},
+		Document{ItemRef: ItemRef{Type: ItemTypeTable, ID: "order"}, Title: "Order"},
+		Document{ItemRef: ItemRef{Type: ItemTypeTable, ID: "orders"}, Title: "Orders"},
+	)
+	results := idx.Search(Query{Text: "order"})
+	require.Len(t, results, 3)
+	assert.Equal(t, "order", results[0].ID)
+	assert.Equal(t, "orders", results[1].ID, "closer completion should rank higher")
+	assert.Greater(t, results[0].Score, results[1].Score)
+}
+
+func TestIndex_AddReplacesAndRemove(t *testing.T) {
+	idx := newTestIndex()
+	ref := ItemRef{Type: ItemTypeBoard, ID: "customers"}
+	idx.Add(Document{ItemRef: ref, Title: "Clients"})
+	assert.Equal(t, 4, idx.Len())
+	assert.Equal(t, []string{"board:customers"}, resultIDs(idx.Search(Query{Text: "clients"})))
+	assert.Empty(t, idx.Search(Query{Text: "crm"}), "terms of replaced document should be removed")
+
+	idx.Remove(ref)
+	assert.Equal(t, 3, idx.Len())
+	assert.Empty(t, idx.Search(Query{Text: "clients"}))
+	_, hasTerm := idx.postings["clients"]
+	assert.False(t, hasTerm)
+
+	idx.RemoveWhere(func(ref ItemRef) bool {
+		return ref.EnvID == "dev"
+	})
+	assert.Equal(t, []string{"table:dbo.Customers@prod"}, resultIDs(idx.Search(Query{Text: "customers"})))
+}
+
+func TestIndex_Search_Concurrent(t *testing.T) {
+	idx := newTestIndex()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NotEmpty(t, idx.Search(Query{Text: "cust"}))
+		}()
+		go func(i int) {
+			defer wg.Done()
+			idx.Add(Document{ItemRef: ItemRef{Type: ItemTypeQuery, ID: "q" + strconv.Itoa(i)}, Title: "Query " + strconv.Itoa(i)})
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, idx.Search(Query{Text: "query"}), 10)
+}
+
+func TestItemRef_ProjectItemRef(t *testing.T) {
+	projectRef := dto.ProjectRef{StoreID: "local", ProjectID: "p1"}
+	ref := ItemRef{Type: ItemTypeQuery, ID: "reports/daily"}.ProjectItemRef(projectRef)
+	assert.Equal(t, ProjectItemRef{
+		ProjectItemRef: dto.ProjectItemRef{ProjectRef: projectRef, ID: "reports/daily"},
+		Type:           ItemTypeQuery,
+	}, ref)
+	assert.NoError(t, ref.Validate())
+
+	ref = ItemRef{Type: ItemTypeTable, ID: "dbo.Customers", EnvID: "dev", Catalog: "northwind"}.ProjectItemRef(projectRef)
+	assert.Equal(t, ItemTypeTable, ref.Type)
+	assert.Equal(t, "dev", ref.EnvID)
+	assert.Equal(t, "northwind", ref.Catalog)
+}
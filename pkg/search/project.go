@@ -0,0 +1,258 @@
+package search
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+// IndexProject indexes items of a loaded project: queries, boards, entities
+// and schema objects of catalogs of DB servers referenced by the project.
+// Catalogs referenced by environments are indexed once more with ID of the environment.
+// Previously indexed items are removed, so items that are no longer in the project are not found.
+func (idx *Index) IndexProject(p *datatug.Project) {
+	var docs []Document
+	if p.Queries != nil {
+		docs = appendQueriesFolderDocs(docs, "", p.Queries)
+	}
+	for _, board := range p.Boards {
+		docs = append(docs, BoardDocument(board))
+	}
+	for _, entity := range p.Entities {
+		docs = append(docs, EntityDocument(entity))
+	}
+	for _, driver := range p.DbDrivers {
+		for _, server := range driver.Servers {
+			for _, catalog := range server.Catalogs {
+				docs = append(docs, CatalogDocuments("", catalog)...)
+			}
+		}
+	}
+	for _, env := range p.Environments {
+		for _, envServer := range env.DbServers {
+			docs = appendEnvCatalogsDocs(docs, p.DbDrivers, env.ID, envServer)
+		}
+	}
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.docs = make(map[ItemRef]*Document, len(docs))
+	idx.postings = make(map[string]map[ItemRef]float64)
+	idx.terms = nil
+	for _, doc := range docs {
+		idx.add(doc)
+	}
+}
+
+// appendEnvCatalogsDocs adds schema objects of catalogs of an environment DB server,
+// schemas of the catalogs are kept by the DB server of the project
+func appendEnvCatalogsDocs(docs []Document, drivers datatug.ProjDbDrivers, envID string, envServer *datatug.EnvDbServer) []Document {
+	for _, driver := range drivers {
+		server := driver.Servers.GetProjDbServer(envServer.ServerRef)
+		if server == nil {
+			continue
+		}
+		for _, catalogID := range envServer.Catalogs {
+			if catalog := server.Catalogs.GetByID(catalogID); catalog != nil {
+				docs = append(docs, CatalogDocuments(envID, catalog)...)
+			}
+		}
+	}
+	return docs
+}
+
+func appendQueriesFolderDocs(docs []Document, folderPath string, folder *datatug.QueriesFolder) []Document {
+	for _, query := range folder.Items {
+		docs = append(docs, QueryDocument(folderPath, query))
+	}
+	for _, subFolder := range folder.Folders {
+		docs = appendQueriesFolderDocs(docs, path.Join(folderPath, subFolder.ID), subFolder)
+	}
+	return docs
+}
+
+// QueryID returns ID of a query that includes folder path, the root shared folder "~" is omitted
+// as queries are loaded & deleted by IDs relative to it, e.g. "reports/q1" for "~/reports" folder
+func QueryID(folderPath, queryID string) string {
+	if folderPath = queriesFolderPath(folderPath); folderPath == "" {
+		return queryID
+	}
+	return path.Join(folderPath, queryID)
+}
+
+// queriesFolderPath trims slashes & the root shared folder from a folder path of a query
+func queriesFolderPath(folderPath string) string {
+	folderPath = strings.Trim(folderPath, "/")
+	if folderPath == datatug.RootSharedFolderName {
+		return ""
+	}
+	return strings.TrimPrefix(folderPath, datatug.RootSharedFolderName+"/")
+}
+
+// QueryDocument creates a document for a query
+func QueryDocument(folderPath string, query *datatug.QueryDef) Document {
+	return Document{
+		ItemRef: ItemRef{Type: ItemTypeQuery, ID: QueryID(folderPath, query.ID)},
+		Title:   query.Title,
+		Folder:  folderOf(folderPath, query.Folder),
+		Tags:    query.Tags,
+		Fields: []Field{
+			{Text: query.ID, Weight: WeightName},
+			{Text: query.Text, Weight: WeightText},
+		},
+	}
+}
+
+// BoardDocument creates a document for a board including titles & SQL of its widgets
+func BoardDocument(board *datatug.Board) Document {
+	doc := Document{
+		ItemRef: ItemRef{Type: ItemTypeBoard, ID: board.ID},
+		Title:   board.Title,
+		Folder:  folderOf("", board.Folder),
+		Tags:    board.Tags,
+		Fields:  []Field{{Text: board.ID, Weight: WeightName}},
+	}
+	for _, row := range board.Rows {
+		for _, card := range row.Cards {
+			doc.Fields = append(doc.Fields, Field{Text: card.Title, Weight: WeightText})
+			if card.Widget != nil {
+				for _, text := range widgetTexts(card.Widget.Data) {
+					doc.Fields = append(doc.Fields, Field{Text: text, Weight: WeightText})
+				}
+			}
+		}
+	}
+	return doc
+}
+
+// widgetTexts returns titles & queries of a widget and its nested widgets.
+// Widget data can be a typed widget definition or a generic map decoded from JSON.
+func widgetTexts(data any) (texts []string) {
+	if data == nil {
+		return nil
+	}
+	var generic any
+	if b, err := json.Marshal(data); err != nil || json.Unmarshal(b, &generic) != nil {
+		return nil
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, value := range v {
+				if s, ok := value.(string); ok && (key == "title" || key == "query") {
+					texts = append(texts, s)
+					continue
+				}
+				walk(value)
+			}
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(generic)
+	return texts
+}
+
+// EntityDocument creates a document for an entity including its fields
+func EntityDocument(entity *datatug.Entity) Document {
+	doc := Document{
+		ItemRef: ItemRef{Type: ItemTypeEntity, ID: entity.ID},
+		Title:   entity.Title,
+		Folder:  folderOf("", entity.Folder),
+		Tags:    entityTags(entity),
+		Fields:  []Field{{Text: entity.ID, Weight: WeightName}},
+	}
+	for _, field := range entity.Fields {
+		doc.Fields = append(doc.Fields,
+			Field{Text: field.ID, Weight: WeightText},
+			Field{Text: field.Title, Weight: WeightText},
+		)
+	}
+	for _, table := range entity.Tables {
+		doc.Fields = append(doc.Fields, Field{Text: table.Name(), Weight: WeightText})
+	}
+	return doc
+}
+
+// entityTags merges tags of the entity brief & tags of the entity itself
+func entityTags(entity *datatug.Entity) []string {
+	tags := append([]string{}, entity.ProjItemBrief.Tags...)
+	for _, tag := range entity.ListOfTags.Tags {
+		if !hasTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// CatalogDocuments creates documents for tables, views & columns of a DB catalog.
+// The envID is empty for catalogs that are not bound to an environment.
+func CatalogDocuments(envID string, catalog *datatug.DbCatalog) (docs []Document) {
+	for _, schema := range catalog.Schemas {
+		for _, t := range schema.Tables {
+			docs = appendCollectionDocs(docs, envID, catalog.ID, schema.ID, ItemTypeTable, t)
+		}
+		for _, v := range schema.Views {
+			docs = appendCollectionDocs(docs, envID, catalog.ID, schema.ID, ItemTypeView, v)
+		}
+	}
+	return docs
+}
+
+func appendCollectionDocs(docs []Document, envID, catalog, schema string, itemType ItemType, c *datatug.CollectionInfo) []Document {
+	name := c.Name()
+	id := name
+	if schema != "" {
+		id = schema + "." + name
+	}
+	docs = append(docs, Document{
+		ItemRef: ItemRef{Type: itemType, ID: id, EnvID: envID, Catalog: catalog},
+		Title:   name,
+		Fields:  []Field{{Text: schema, Weight: WeightText}},
+	})
+	for _, col := range c.Columns {
+		docs = append(docs, Document{
+			ItemRef: ItemRef{Type: ItemTypeColumn, ID: id + "." + col.Name, EnvID: envID, Catalog: catalog},
+			Title:   col.Name,
+			Fields:  []Field{{Text: name, Weight: WeightText}},
+		})
+	}
+	return docs
+}
+
+// IndexCatalog replaces indexed schema objects of a catalog
+func (idx *Index) IndexCatalog(envID string, catalog *datatug.DbCatalog) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.removeCatalog(envID, catalog.ID)
+	for _, doc := range CatalogDocuments(envID, catalog) {
+		idx.add(doc)
+	}
+}
+
+// RemoveCatalog removes indexed schema objects of a catalog
+func (idx *Index) RemoveCatalog(envID, catalogID string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.removeCatalog(envID, catalogID)
+}
+
+func (idx *Index) removeCatalog(envID, catalogID string) {
+	for ref := range idx.docs {
+		if ref.Catalog == catalogID && ref.EnvID == envID {
+			idx.remove(ref)
+		}
+	}
+}
+
+// folderOf returns folder path of an item, the folder of the queries tree takes precedence
+func folderOf(folderPath, itemFolder string) string {
+	if folderPath = queriesFolderPath(folderPath); folderPath != "" {
+		return folderPath
+	}
+	return strings.Trim(itemFolder, "/")
+}
@@ -0,0 +1,194 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCatalog() *datatug.DbCatalog {
+	customers := &datatug.CollectionInfo{
+		DBCollectionKey: datatug.NewTableKey("Customers", "dbo", "northwind", nil),
+		TableProps:      datatug.TableProps{DbType: "BASE TABLE"},
+		Columns: datatug.TableColumns{
+			{DbColumnProps: datatug.DbColumnProps{Name: "CustomerID", DbType: "nchar"}},
+			{DbColumnProps: datatug.DbColumnProps{Name: "CompanyName", DbType: "nvarchar"}},
+		},
+	}
+	invoices := &datatug.CollectionInfo{
+		DBCollectionKey: datatug.NewViewKey("Invoices", "dbo", "northwind", nil),
+		TableProps:      datatug.TableProps{DbType: "VIEW"},
+	}
+	catalog := &datatug.DbCatalog{
+		Schemas: datatug.DbSchemas{
+			{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "dbo"}},
+				Tables:      []*datatug.CollectionInfo{customers},
+				Views:       []*datatug.CollectionInfo{invoices},
+			},
+		},
+	}
+	catalog.ID = "northwind"
+	catalog.Driver = "sqlserver"
+	return catalog
+}
+
+func TestIndex_IndexProject(t *testing.T) {
+	project := &datatug.Project{
+		Queries: &datatug.QueriesFolder{
+			Items: datatug.QueryDefs{
+				{
+					ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "top_customers", Title: "Top customers"}},
+					Type:        datatug.QueryTypeSQL,
+					Text:        "SELECT TOP 10 * FROM Customers",
+				},
+			},
+			Folders: datatug.QueryFolders{
+				{
+					ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "reports"}},
+					Items: datatug.QueryDefs{
+						{
+							ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{
+								ID: "daily", Title: "Daily report", ListOfTags: datatug.ListOfTags{Tags: []string{"finance"}},
+							}},
+							Type: datatug.QueryTypeSQL,
+							Text: "SELECT * FROM Orders WHERE OrderDate = @date",
+						},
+					},
+				},
+			},
+		},
+		Boards: datatug.Boards{
+			{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "sales", Title: "Sales"}},
+				Rows: datatug.BoardRows{
+					{
+						Cards: datatug.BoardCards{
+							{
+								ID:    "c1",
+								Title: "Revenue",
+								Widget: &datatug.BoardWidget{
+									Name: "SQL",
+									Data: &datatug.SQLWidgetDef{SQL: datatug.SQLWidgetSettings{Query: "select sum(Amount) from Payments"}},
+								},
+							},
+							{
+								ID:    "c2",
+								Title: "Tabs",
+								Widget: &datatug.BoardWidget{
+									Name: "tabs",
+									Data: map[string]any{"tabs": []any{
+										map[string]any{"title": "Shippers", "widget": map[string]any{"sql": map[string]any{"query": "select * from Shippers"}}},
+									}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Entities: datatug.Entities{
+			{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "customer", Title: "Customer"}},
+				ListOfTags:  datatug.ListOfTags{Tags: []string{"crm"}},
+				Fields:      datatug.EntityFields{{ID: "email", Type: "str", Title: "E-mail address"}},
+			},
+		},
+		DbDrivers: datatug.ProjDbDrivers{
+			{
+				Servers: datatug.ProjDbServers{
+					{Catalogs: datatug.DbCatalogs{newTestCatalog()}},
+				},
+			},
+		},
+	}
+	idx := NewIndex()
+	idx.IndexProject(project)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "query_text", query: Query{Text: "orderdate"}, want: []string{"query:reports/daily"}},
+		{name: "query_folder", query: Query{Folder: "reports"}, want: []string{"query:reports/daily"}},
+		{name: "query_tag", query: Query{Tags: []string{"finance"}}, want: []string{"query:reports/daily"}},
+		{name: "board_widget_sql", query: Query{Text: "payments"}, want: []string{"board:sales"}},
+		{name: "nested_widget", query: Query{Text: "shippers"}, want: []string{"board:sales"}},
+		{name: "entity_field", query: Query{Text: "e-mail"}, want: []string{"entity:customer"}},
+		{name: "entity_tag", query: Query{Tags: []string{"crm"}}, want: []string{"entity:customer"}},
+		{name: "column", query: Query{Text: "company", Types: []ItemType{ItemTypeColumn}}, want: []string{"column:dbo.Customers.CompanyName"}},
+		{name: "view", query: Query{Text: "invoices"}, want: []string{"view:dbo.Invoices"}},
+		{
+			name:  "table_columns_query_and_entity",
+			query: Query{Text: "customer"},
+			want: []string{
+				"column:dbo.Customers.CustomerID", // exact match of a camel case part in a name
+				"entity:customer",
+				"query:top_customers", // same prefix match in a title as the table, ties are ordered by type
+				"table:dbo.Customers",
+				"column:dbo.Customers.CompanyName",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resultIDs(idx.Search(tt.query)))
+		})
+	}
+}
+
+func TestIndex_IndexProject_EnvironmentCatalogs(t *testing.T) {
+	catalog := newTestCatalog()
+	catalog.ID = "northwind"
+	server := datatug.ServerRef{Driver: "sqlserver", Host: "localhost", Port: 1433}
+	project := &datatug.Project{
+		DbDrivers: datatug.ProjDbDrivers{
+			{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "sqlserver"}},
+				Servers:     datatug.ProjDbServers{{Server: server, Catalogs: datatug.DbCatalogs{catalog}}},
+			},
+		},
+		Environments: datatug.Environments{
+			{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "dev"}},
+				DbServers:   datatug.EnvDbServers{{ServerRef: server, Catalogs: []string{"northwind", "unknown"}}},
+			},
+			{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "prod"}},
+				DbServers:   datatug.EnvDbServers{{ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "other"}, Catalogs: []string{"northwind"}}},
+			},
+		},
+	}
+	idx := NewIndex()
+	idx.IndexProject(project)
+	assert.Equal(t, []string{"table:dbo.Customers", "table:dbo.Customers@dev"},
+		resultIDs(idx.Search(Query{Text: "customers", Types: []ItemType{ItemTypeTable}})))
+	assert.Equal(t, []string{"table:dbo.Customers@dev"},
+		resultIDs(idx.Search(Query{Text: "customers", Types: []ItemType{ItemTypeTable}, EnvID: "dev"})))
+}
+
+func TestIndex_IndexCatalog(t *testing.T) {
+	idx := NewIndex()
+	catalog := newTestCatalog()
+	idx.IndexCatalog("dev", catalog)
+	idx.IndexCatalog("prod", catalog)
+	assert.Equal(t, 8, idx.Len())
+
+	catalog.Schemas[0].Views = nil
+	idx.IndexCatalog("dev", catalog)
+	assert.Equal(t, 7, idx.Len())
+	assert.Equal(t, []string{"view:dbo.Invoices@prod"}, resultIDs(idx.Search(Query{Text: "invoices"})))
+
+	idx.RemoveCatalog("prod", "northwind")
+	assert.Equal(t, 3, idx.Len())
+}
+
+func TestQueryID(t *testing.T) {
+	assert.Equal(t, "q1", QueryID("", "q1"))
+	assert.Equal(t, "q1", QueryID("~", "q1"))
+	assert.Equal(t, "reports/q1", QueryID("/reports/", "q1"))
+	assert.Equal(t, "reports/q1", QueryID("~/reports", "q1"))
+	assert.Equal(t, "q1", QueryID(".", "q1"))
+}
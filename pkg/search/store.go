@@ -0,0 +1,194 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+)
+
+// IndexProjectStore loads queries, boards, entities & catalogs of all environments
+// from a project store and adds them to the index
+func (idx *Index) IndexProjectStore(ctx context.Context, store datatug.ProjectStore) error {
+	if err := idx.indexQueriesFolder(ctx, store, ""); err != nil {
+		return err
+	}
+	boards, err := store.LoadBoards(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load boards: %w", err)
+	}
+	entities, err := store.LoadEntities(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load entities: %w", err)
+	}
+	docs := make([]Document, 0, len(boards)+len(entities))
+	for _, board := range boards {
+		docs = append(docs, BoardDocument(board))
+	}
+	for _, entity := range entities {
+		docs = append(docs, EntityDocument(entity))
+	}
+	idx.Add(docs...)
+
+	environments, err := store.LoadEnvironments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load environments: %w", err)
+	}
+	for _, env := range environments {
+		catalogs, err := store.LoadEnvDbCatalogs(ctx, env.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load DB catalogs of environment %s: %w", env.ID, err)
+		}
+		for _, catalog := range catalogs {
+			idx.IndexCatalog(env.ID, catalog)
+		}
+	}
+	return nil
+}
+
+func (idx *Index) indexQueriesFolder(ctx context.Context, store datatug.QueriesStore, folderPath string) error {
+	folder, err := store.LoadQueries(ctx, folderPath)
+	if err != nil {
+		return fmt.Errorf("failed to load queries of folder %q: %w", folderPath, err)
+	}
+	if folder == nil {
+		return nil
+	}
+	docs := make([]Document, 0, len(folder.Items))
+	for _, query := range folder.Items {
+		docs = append(docs, QueryDocument(folderPath, query))
+	}
+	idx.Add(docs...)
+	for _, subFolder := range folder.Folders {
+		if err = idx.indexQueriesFolder(ctx, store, path.Join(folderPath, subFolder.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ datatug.ProjectStore = (*IndexingStore)(nil)
+
+// IndexingStore is a project store that keeps a search index up to date with saved & deleted items
+type IndexingStore struct {
+	datatug.ProjectStore
+	index *Index
+}
+
+// NewIndexingStore wraps a project store so changes made through it are reflected in the index
+func NewIndexingStore(store datatug.ProjectStore, index *Index) *IndexingStore {
+	return &IndexingStore{ProjectStore: store, index: index}
+}
+
+// Index returns search index maintained by the store
+func (s *IndexingStore) Index() *Index {
+	return s.index
+}
+
+func (s *IndexingStore) SaveProject(ctx context.Context, p *datatug.Project) error {
+	if err := s.ProjectStore.SaveProject(ctx, p); err != nil {
+		return err
+	}
+	s.index.IndexProject(p)
+	return nil
+}
+
+func (s *IndexingStore) SaveQuery(ctx context.Context, query *datatug.QueryDefWithFolderPath) error {
+	if err := s.ProjectStore.SaveQuery(ctx, query); err != nil {
+		return err
+	}
+	s.index.Add(QueryDocument(query.FolderPath, &query.QueryDef))
+	return nil
+}
+
+func (s *IndexingStore) DeleteQuery(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteQuery(ctx, id); err != nil {
+		return err
+	}
+	s.index.Remove(ItemRef{Type: ItemTypeQuery, ID: QueryID(path.Dir(id), path.Base(id))})
+	return nil
+}
+
+func (s *IndexingStore) SaveBoard(ctx context.Context, board *datatug.Board) error {
+	if err := s.ProjectStore.SaveBoard(ctx, board); err != nil {
+		return err
+	}
+	s.index.Add(BoardDocument(board))
+	return nil
+}
+
+func (s *IndexingStore) DeleteBoard(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteBoard(ctx, id); err != nil {
+		return err
+	}
+	s.index.Remove(ItemRef{Type: ItemTypeBoard, ID: id})
+	return nil
+}
+
+func (s *IndexingStore) SaveEntity(ctx context.Context, entity *datatug.Entity) error {
+	if err := s.ProjectStore.SaveEntity(ctx, entity); err != nil {
+		return err
+	}
+	s.index.Add(EntityDocument(entity))
+	return nil
+}
+
+func (s *IndexingStore) DeleteEntity(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteEntity(ctx, id); err != nil {
+		return err
+	}
+	s.index.Remove(ItemRef{Type: ItemTypeEntity, ID: id})
+	return nil
+}
+
+func (s *IndexingStore) DeleteEnvironment(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteEnvironment(ctx, id); err != nil {
+		return err
+	}
+	s.index.RemoveWhere(func(ref ItemRef) bool {
+		return ref.EnvID == id
+	})
+	return nil
+}
+
+func (s *IndexingStore) CloneEnvironment(ctx context.Context, request datatug.CloneEnvironmentRequest) (*datatug.Environment, error) {
+	env, err := s.ProjectStore.CloneEnvironment(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	catalogs, err := s.ProjectStore.LoadEnvDbCatalogs(ctx, env.ID)
+	if err != nil {
+		return env, fmt.Errorf("failed to load DB catalogs of cloned environment %s: %w", env.ID, err)
+	}
+	for _, catalog := range catalogs {
+		s.index.IndexCatalog(env.ID, catalog)
+	}
+	return env, nil
+}
+
+func (s *IndexingStore) SaveEnvDbCatalog(ctx context.Context, envID, serverID, catalogID string, catalog *datatug.DbCatalog) error {
+	if err := s.ProjectStore.SaveEnvDbCatalog(ctx, envID, serverID, catalogID, catalog); err != nil {
+		return err
+	}
+	s.index.IndexCatalog(envID, catalog)
+	return nil
+}
+
+func (s *IndexingStore) SaveEnvDbCatalogs(ctx context.Context, envID, serverID, catalogID string, catalogs datatug.DbCatalogs) error {
+	if err := s.ProjectStore.SaveEnvDbCatalogs(ctx, envID, serverID, catalogID, catalogs); err != nil {
+		return err
+	}
+	for _, catalog := range catalogs {
+		s.index.IndexCatalog(envID, catalog)
+	}
+	return nil
+}
+
+func (s *IndexingStore) DeleteEnvDbCatalog(ctx context.Context, envID, serverID, catalogID string) error {
+	if err := s.ProjectStore.DeleteEnvDbCatalog(ctx, envID, serverID, catalogID); err != nil {
+		return err
+	}
+	s.index.RemoveCatalog(envID, catalogID)
+	return nil
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage/filestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexingStore(t *testing.T) {
+	ctx := context.Background()
+	projectPath := t.TempDir()
+	store := NewIndexingStore(filestore.NewProjectStore("p1", projectPath), NewIndex())
+
+	board := &datatug.Board{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "sales", Title: "Sales"}}}
+	require.NoError(t, store.SaveBoard(ctx, board))
+	entity := &datatug.Entity{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "customer", Title: "Customer"}}}
+	require.NoError(t, store.SaveEntity(ctx, entity))
+	query := &datatug.QueryDefWithFolderPath{
+		QueryDef: datatug.QueryDef{
+			ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "q1", Title: "Sales by customer"}},
+			Type:        datatug.QueryTypeSQL,
+		},
+	}
+	require.NoError(t, store.SaveQuery(ctx, query))
+	require.NoError(t, store.SaveEnvironment(ctx, &datatug.Environment{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "dev"}}}))
+	require.NoError(t, store.SaveEnvDbCatalog(ctx, "dev", "db1", "northwind", newTestCatalog()))
+
+	want := []string{"board:sales", "query:q1"}
+	assert.Equal(t, want, resultIDs(store.Index().Search(Query{Text: "sales"})))
+	assert.Equal(t, []string{"table:dbo.Customers@dev"}, resultIDs(store.Index().Search(Query{Text: "customers", Types: []ItemType{ItemTypeTable}})))
+
+	t.Run("IndexProjectStore", func(t *testing.T) {
+		idx := NewIndex()
+		require.NoError(t, idx.IndexProjectStore(ctx, store))
+		assert.Equal(t, store.Index().Len(), idx.Len())
+		assert.Equal(t, want, resultIDs(idx.Search(Query{Text: "sales"})))
+		columns := idx.Search(Query{Text: "company"})
+		require.Len(t, columns, 1)
+		assert.Equal(t, ItemTypeColumn, columns[0].Type)
+		assert.Equal(t, "CompanyName", columns[0].Title)
+		assert.Equal(t, "dev", columns[0].EnvID)
+		assert.Equal(t, "northwind", columns[0].Catalog)
+	})
+
+	t.Run("deletes", func(t *testing.T) {
+		require.NoError(t, store.DeleteBoard(ctx, "sales"))
+		require.NoError(t, store.DeleteQuery(ctx, "q1"))
+		assert.Empty(t, store.Index().Search(Query{Text: "sales"}))
+		require.NoError(t, store.DeleteEntity(ctx, "customer"))
+		assert.Empty(t, store.Index().Search(Query{Types: []ItemType{ItemTypeEntity}}))
+		require.NoError(t, store.DeleteEnvDbCatalog(ctx, "dev", "db1", "northwind"))
+		assert.Equal(t, 0, store.Index().Len())
+	})
+
+	t.Run("query_in_folder", func(t *testing.T) {
+		query := &datatug.QueryDefWithFolderPath{
+			FolderPath: "~/reports",
+			QueryDef: datatug.QueryDef{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "q2", Title: "Monthly revenue"}},
+				Type:        datatug.QueryTypeSQL,
+			},
+		}
+		require.NoError(t, store.SaveQuery(ctx, query))
+		assert.Equal(t, []string{"query:reports/q2"}, resultIDs(store.Index().Search(Query{Text: "revenue"})))
+		require.NoError(t, store.DeleteQuery(ctx, "reports/q2"))
+		assert.Empty(t, store.Index().Search(Query{Text: "revenue"}))
+	})
+}
+
+func TestIndexingStore_SaveProject(t *testing.T) {
+	ctx := context.Background()
+	store := NewIndexingStore(filestore.NewProjectStore("p1", t.TempDir()), NewIndex())
+	project := &datatug.Project{
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "p1", Title: "P1"}, Access: "private"},
+		Created:     &datatug.ProjectCreated{At: time.Now()},
+		Boards:      datatug.Boards{{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "sales", Title: "Sales"}}}},
+	}
+	require.NoError(t, store.SaveProject(ctx, project))
+	assert.Equal(t, []string{"board:sales"}, resultIDs(store.Index().Search(Query{Text: "sales"})))
+
+	project.Boards = datatug.Boards{{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "costs", Title: "Costs"}}}}
+	require.NoError(t, store.SaveProject(ctx, project))
+	assert.Empty(t, store.Index().Search(Query{Text: "sales"}), "items removed from the project should not be found")
+	assert.Equal(t, 1, store.Index().Len())
+}
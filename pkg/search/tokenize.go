@@ -0,0 +1,96 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// words splits text into words of letters & digits
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokenize splits text into lower case terms to be indexed.
+// Besides whole words it emits parts of camel case words, so "CustomerID" is found by "customer" & "id".
+func tokenize(text string) (terms []string) {
+	for _, word := range words(text) {
+		terms = append(terms, strings.ToLower(word))
+		if parts := splitCamelCase(word); len(parts) > 1 {
+			for _, part := range parts {
+				terms = append(terms, strings.ToLower(part))
+			}
+		}
+	}
+	return terms
+}
+
+// queryTerms splits search text into lower case terms, camel case words are not split
+func queryTerms(text string) (terms []string) {
+	for _, word := range words(text) {
+		terms = append(terms, strings.ToLower(word))
+	}
+	return terms
+}
+
+// splitCamelCase splits a word like "HTTPServerID2" into "HTTP", "Server", "ID2"
+func splitCamelCase(word string) (parts []string) {
+	runes := []rune(word)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		isBoundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if isBoundary {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// editDistance returns Levenshtein distance between 2 strings or max+1 if the distance exceeds max
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, cur = cur, prev
+	}
+	if prev[len(rb)] > max {
+		return max + 1
+	}
+	return prev[len(rb)]
+}
+
+// maxEdits returns number of typos tolerated by fuzzy matching of a term
+func maxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
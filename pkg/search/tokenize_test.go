@@ -0,0 +1,60 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "select * from Customers", want: []string{"select", "from", "customers"}},
+		{text: "CustomerID", want: []string{"customerid", "customer", "id"}},
+		{text: "order_items.unit_price", want: []string{"order", "items", "unit", "price"}},
+		{text: "HTTPServer", want: []string{"httpserver", "http", "server"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, tokenize(tt.text))
+		})
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	assert.Equal(t, []string{"customerid", "orders"}, queryTerms(" CustomerID, orders "))
+}
+
+func TestSplitCamelCase(t *testing.T) {
+	assert.Equal(t, []string{"get", "User", "ID"}, splitCamelCase("getUserID"))
+	assert.Equal(t, []string{"XML", "Http", "Request"}, splitCamelCase("XMLHttpRequest"))
+	assert.Equal(t, []string{"lower"}, splitCamelCase("lower"))
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{a: "customer", b: "customer", max: 2, want: 0},
+		{a: "customer", b: "custmer", max: 2, want: 1},
+		{a: "customer", b: "cusotmer", max: 2, want: 2},
+		{a: "customer", b: "order", max: 2, want: 3},
+		{a: "abc", b: "abcdef", max: 1, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, editDistance(tt.a, tt.b, tt.max))
+		})
+	}
+}
+
+func TestMaxEdits(t *testing.T) {
+	assert.Equal(t, 0, maxEdits("abc"))
+	assert.Equal(t, 1, maxEdits("abcd"))
+	assert.Equal(t, 2, maxEdits("abcdefgh"))
+}
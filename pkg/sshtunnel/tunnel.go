@@ -0,0 +1,303 @@
+// Package sshtunnel forwards connections to DB servers reachable only through a bastion host.
+package sshtunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Tunnel listens on a local port & forwards connections to a remote address through a bastion
+type Tunnel struct {
+	client   *ssh.Client
+	listener net.Listener
+	remote   string
+
+	closeOnce sync.Once
+	closeErr  error
+	done      chan struct{}
+
+	mutex sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// Option configures how a tunnel is opened
+type Option func(o *options)
+
+type options struct {
+	hostKeyCallback ssh.HostKeyCallback
+	agentSocket     string
+}
+
+// WithHostKeyCallback verifies a bastion with a callback instead of a known hosts file
+func WithHostKeyCallback(callback ssh.HostKeyCallback) Option {
+	return func(o *options) {
+		o.hostKeyCallback = callback
+	}
+}
+
+// WithAgentSocket sets a path to a socket of an SSH agent, SSH_AUTH_SOCK is used by default
+func WithAgentSocket(path string) Option {
+	return func(o *options) {
+		o.agentSocket = path
+	}
+}
+
+// Open connects to a bastion & starts forwarding connections to the remote "host:port".
+// The tunnel is closed by Close or on cancellation of the context.
+func Open(ctx context.Context, config datatug.SSHTunnel, remote string, opts ...Option) (*Tunnel, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SSH tunnel: %w", err)
+	}
+	o := options{agentSocket: os.Getenv("SSH_AUTH_SOCK")}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	clientConfig, closeAgent, err := newClientConfig(config, o)
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	client, err := dial(ctx, config.Address(), clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to bastion %v: %w", config.Address(), err)
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(config.LocalPort)))
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to listen on local port: %w", err)
+	}
+	t := &Tunnel{
+		client:   client,
+		listener: listener,
+		remote:   remote,
+		done:     make(chan struct{}),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	t.wg.Add(1)
+	go t.accept()
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = t.Close()
+		case <-t.done:
+		}
+	}()
+	return t, nil
+}
+
+// OpenForServer opens a tunnel to a server if it has one & returns a reference to the server
+// that points to the local end of the tunnel. A server without a tunnel is returned as is with a no-op close.
+func OpenForServer(ctx context.Context, server datatug.EnvDbServer, defaultPort int, opts ...Option) (datatug.ServerRef, io.Closer, error) {
+	if server.SSHTunnel == nil {
+		return server.ServerRef, noTunnel{}, nil
+	}
+	port := server.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	t, err := Open(ctx, *server.SSHTunnel, net.JoinHostPort(server.Host, strconv.Itoa(port)), opts...)
+	if err != nil {
+		return server.ServerRef, nil, err
+	}
+	local := server.ServerRef
+	local.Host, local.Port = t.Host(), t.Port()
+	return local, t, nil
+}
+
+type noTunnel struct{}
+
+func (noTunnel) Close() error {
+	return nil
+}
+
+// LocalAddr returns an address connections to the remote address should be made to
+func (t *Tunnel) LocalAddr() net.Addr {
+	return t.listener.Addr()
+}
+
+// Host returns a host of the local end of the tunnel
+func (t *Tunnel) Host() string {
+	return t.listener.Addr().(*net.TCPAddr).IP.String()
+}
+
+// Port returns a port of the local end of the tunnel
+func (t *Tunnel) Port() int {
+	return t.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Done is closed when the tunnel is closed
+func (t *Tunnel) Done() <-chan struct{} {
+	return t.done
+}
+
+// Close stops listening, closes forwarded connections & disconnects from the bastion
+func (t *Tunnel) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+		t.closeErr = t.listener.Close()
+		t.mutex.Lock()
+		for conn := range t.conns {
+			_ = conn.Close()
+		}
+		t.mutex.Unlock()
+		if err := t.client.Close(); err != nil && t.closeErr == nil && !errors.Is(err, net.ErrClosed) {
+			t.closeErr = err
+		}
+		t.wg.Wait()
+	})
+	return t.closeErr
+}
+
+func (t *Tunnel) accept() {
+	defer t.wg.Done()
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			return // closed
+		}
+		t.wg.Add(1)
+		go t.forward(local)
+	}
+}
+
+func (t *Tunnel) forward(local net.Conn) {
+	defer t.wg.Done()
+	if !t.track(local) {
+		return
+	}
+	defer t.untrack(local)
+	remote, err := t.client.Dial("tcp", t.remote)
+	if err != nil {
+		return
+	}
+	if !t.track(remote) {
+		return
+	}
+	defer t.untrack(remote)
+	copied := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		copied <- struct{}{}
+	}
+	go pipe(remote, local)
+	go pipe(local, remote)
+	<-copied // either side is closed, the deferred untrack closes both
+}
+
+// track registers a connection to be closed with the tunnel, it's closed at once if the tunnel is closed
+func (t *Tunnel) track(conn net.Conn) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	select {
+	case <-t.done:
+		_ = conn.Close()
+		return false
+	default:
+		t.conns[conn] = struct{}{}
+		return true
+	}
+}
+
+func (t *Tunnel) untrack(conn net.Conn) {
+	t.mutex.Lock()
+	delete(t.conns, conn)
+	t.mutex.Unlock()
+	_ = conn.Close()
+}
+
+func dial(ctx context.Context, address string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	// the handshake is not context aware, so it's interrupted by closing the connection
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		_ = conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return ssh.NewClient(sshConn, channels, requests), nil
+}
+
+func newClientConfig(config datatug.SSHTunnel, o options) (clientConfig *ssh.ClientConfig, closeAgent func(), err error) {
+	closeAgent = func() {}
+	clientConfig = &ssh.ClientConfig{User: config.User, HostKeyCallback: o.hostKeyCallback}
+	if clientConfig.HostKeyCallback == nil {
+		if clientConfig.HostKeyCallback, err = knownHostsCallback(config.KnownHostsPath); err != nil {
+			return nil, closeAgent, err
+		}
+	}
+	if config.KeyPath != "" {
+		signer, err := readPrivateKey(config.KeyPath)
+		if err != nil {
+			return nil, closeAgent, err
+		}
+		clientConfig.Auth = append(clientConfig.Auth, ssh.PublicKeys(signer))
+	}
+	if config.UseAgent {
+		if o.agentSocket == "" {
+			return nil, closeAgent, errors.New("SSH agent is not running: SSH_AUTH_SOCK is not set")
+		}
+		conn, err := net.Dial("unix", o.agentSocket)
+		if err != nil {
+			return nil, closeAgent, fmt.Errorf("failed to connect to SSH agent: %w", err)
+		}
+		closeAgent = func() { _ = conn.Close() }
+		clientConfig.Auth = append(clientConfig.Auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+	return clientConfig, closeAgent, nil
+}
+
+func knownHostsCallback(path string) (ssh.HostKeyCallback, error) {
+	if path == "" {
+		path = filepath.Join("~", ".ssh", "known_hosts")
+	}
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+func readPrivateKey(path string) (ssh.Signer, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %v: %w", path, err)
+	}
+	return signer, nil
+}
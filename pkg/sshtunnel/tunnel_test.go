@@ -0,0 +1,173 @@
+package sshtunnel
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/test/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func assertEcho(t *testing.T, address string) {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(reply))
+}
+
+func newBastion(t *testing.T) (server *sshtest.Server, config datatug.SSHTunnel) {
+	signer, keyPath := sshtest.NewKey(t)
+	server = sshtest.NewServer(t, signer.PublicKey())
+	return server, datatug.SSHTunnel{
+		Host:           server.Host(),
+		Port:           server.Port(),
+		User:           "tunnel",
+		KeyPath:        keyPath,
+		KnownHostsPath: server.WriteKnownHosts(t),
+	}
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	echo := sshtest.NewEchoServer(t)
+
+	t.Run("key_file", func(t *testing.T) {
+		bastion, config := newBastion(t)
+		tunnel, err := Open(ctx, config, echo)
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.1", tunnel.Host())
+		assertEcho(t, tunnel.LocalAddr().String())
+		assertEcho(t, tunnel.LocalAddr().String())
+		assert.Equal(t, []string{echo, echo}, bastion.Forwarded())
+
+		require.NoError(t, tunnel.Close())
+		_, err = net.DialTimeout("tcp", tunnel.LocalAddr().String(), time.Second)
+		assert.Error(t, err, "local port should be closed")
+		assert.NoError(t, tunnel.Close(), "second close should be a no-op")
+	})
+
+	t.Run("agent", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "agent") // short path as unix socket paths are limited in length
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		socket := filepath.Join(dir, "agent.sock")
+		bastion := sshtest.NewServer(t, serveAgent(t, socket))
+
+		tunnel, err := Open(ctx, datatug.SSHTunnel{Host: bastion.Host(), Port: bastion.Port(), User: "tunnel", UseAgent: true}, echo,
+			WithAgentSocket(socket),
+			WithHostKeyCallback(ssh.FixedHostKey(bastion.HostKey.PublicKey())),
+		)
+		require.NoError(t, err)
+		defer func() { _ = tunnel.Close() }()
+		assertEcho(t, tunnel.LocalAddr().String())
+	})
+
+	t.Run("closed_on_context_cancellation", func(t *testing.T) {
+		_, config := newBastion(t)
+		ctx, cancel := context.WithCancel(ctx)
+		tunnel, err := Open(ctx, config, echo)
+		require.NoError(t, err)
+		cancel()
+		select {
+		case <-tunnel.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("tunnel is not closed on context cancellation")
+		}
+	})
+
+	t.Run("unknown_host_key", func(t *testing.T) {
+		_, config := newBastion(t)
+		other, _ := newBastion(t)
+		config.KnownHostsPath = other.WriteKnownHosts(t)
+		_, err := Open(ctx, config, echo)
+		assert.Error(t, err)
+	})
+
+	t.Run("unauthorized_key", func(t *testing.T) {
+		_, config := newBastion(t)
+		_, config.KeyPath = sshtest.NewKey(t)
+		_, err := Open(ctx, config, echo)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid_config", func(t *testing.T) {
+		_, err := Open(ctx, datatug.SSHTunnel{Host: "bastion"}, echo)
+		assert.Error(t, err)
+	})
+}
+
+func TestOpenForServer(t *testing.T) {
+	ctx := context.Background()
+	echo := sshtest.NewEchoServer(t)
+	echoHost, echoPort, err := net.SplitHostPort(echo)
+	require.NoError(t, err)
+
+	t.Run("without_tunnel", func(t *testing.T) {
+		server := datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "postgres", Host: "db"}}
+		ref, closer, err := OpenForServer(ctx, server, 5432)
+		require.NoError(t, err)
+		assert.Equal(t, server.ServerRef, ref)
+		assert.NoError(t, closer.Close())
+	})
+
+	t.Run("with_tunnel", func(t *testing.T) {
+		bastion, config := newBastion(t)
+		server := datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "postgres", Host: echoHost}, SSHTunnel: &config}
+		port, err := strconv.Atoi(echoPort)
+		require.NoError(t, err)
+		ref, closer, err := OpenForServer(ctx, server, port)
+		require.NoError(t, err)
+		defer func() { _ = closer.Close() }()
+		assert.Equal(t, "postgres", ref.Driver)
+		assert.Equal(t, "127.0.0.1", ref.Host)
+		assert.NotEqual(t, port, ref.Port)
+		assertEcho(t, net.JoinHostPort(ref.Host, strconv.Itoa(ref.Port)))
+		assert.Equal(t, []string{echo}, bastion.Forwarded(), "default port should be used for a server without a port")
+	})
+}
+
+// serveAgent serves an agent with a new key on a unix socket & returns the key
+func serveAgent(t *testing.T, socket string) ssh.PublicKey {
+	t.Helper()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: privateKey}))
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	signers, err := keyring.Signers()
+	require.NoError(t, err)
+	return signers[0].PublicKey()
+}
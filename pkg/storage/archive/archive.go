@@ -0,0 +1,80 @@
+// Package archive exports a DataTug project into a single portable zip archive
+// and imports it into any storage.Store implementation.
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FormatVersion is a version of the archive layout written by Export.
+// Import accepts archives of this and earlier versions.
+const FormatVersion = 1
+
+// ManifestFileName is a name of the archive entry that describes the archive
+const ManifestFileName = "manifest.json"
+
+// ErrUnsupportedFormatVersion is returned by Import for archives written by a newer version of DataTug
+var ErrUnsupportedFormatVersion = errors.New("unsupported archive format version")
+
+// ItemKind defines type of archived project item
+type ItemKind string
+
+const (
+	ItemKindProject       ItemKind = "project"
+	ItemKindQuery         ItemKind = "query"
+	ItemKindBoard         ItemKind = "board"
+	ItemKindEntity        ItemKind = "entity"
+	ItemKindEnvironment   ItemKind = "environment"
+	ItemKindEnvDbServer   ItemKind = "envDbServer"
+	ItemKindEnvDbCatalog  ItemKind = "envDbCatalog"
+	ItemKindDbDriver      ItemKind = "dbDriver"
+	ItemKindDbModel       ItemKind = "dbModel"
+	ItemKindRecordset     ItemKind = "recordset"
+	ItemKindRecordsetData ItemKind = "recordsetData"
+)
+
+// Manifest describes content of an archive
+type Manifest struct {
+	FormatVersion int           `json:"formatVersion"`
+	ProjectID     string        `json:"projectID"`
+	Title         string        `json:"title,omitempty"`
+	ExportedAt    time.Time     `json:"exportedAt"`
+	Options       ExportOptions `json:"options"`
+	Entries       []Entry       `json:"entries"`
+}
+
+// Validate returns error if not valid
+func (v Manifest) Validate() error {
+	if v.FormatVersion < 1 {
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormatVersion, v.FormatVersion)
+	}
+	if v.FormatVersion > FormatVersion {
+		return fmt.Errorf("%w: %v, max supported is %v", ErrUnsupportedFormatVersion, v.FormatVersion, FormatVersion)
+	}
+	if v.ProjectID == "" {
+		return errors.New("manifest is missing project ID")
+	}
+	return nil
+}
+
+// Entry is a file of an archive holding a single project item as JSON
+type Entry struct {
+	Kind       ItemKind `json:"kind"`
+	Path       string   `json:"path"`
+	ID         string   `json:"id,omitempty"`
+	EnvID      string   `json:"env,omitempty"`        // set for environment servers & catalogs
+	ServerID   string   `json:"server,omitempty"`     // set for environment catalogs
+	FolderPath string   `json:"folderPath,omitempty"` // set for queries
+	FileName   string   `json:"file,omitempty"`       // set for recordset data, a data file listed by the recordset
+}
+
+// ExportOptions defines what is left out of an archive
+type ExportOptions struct {
+	ExcludeCatalogs      bool `json:"excludeCatalogs,omitempty"`      // DB catalog snapshots of environments & project DB servers
+	ExcludeRecordsetData bool `json:"excludeRecordsetData,omitempty"` // rows of recordsets, definitions are still exported
+	// ExcludeCredentials leaves out usernames & passwords of query targets, SSH tunnels of environment DB servers
+	// & values of variables with secrets, e.g. "DB_PASSWORD", see storage.IsSecretVariableName
+	ExcludeCredentials bool `json:"excludeCredentials,omitempty"`
+}
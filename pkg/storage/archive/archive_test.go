@@ -0,0 +1,318 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dto"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/datatug/datatug-core/pkg/storage/filestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStoreID = "test_store"
+
+func newTestCatalog() *datatug.DbCatalog {
+	catalog := &datatug.DbCatalog{
+		Schemas: datatug.DbSchemas{
+			{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "dbo"}},
+				Tables: []*datatug.CollectionInfo{
+					{
+						DBCollectionKey: datatug.NewTableKey("Customers", "dbo", "northwind", nil),
+						TableProps:      datatug.TableProps{DbType: "BASE TABLE"},
+						Columns: datatug.TableColumns{
+							{DbColumnProps: datatug.DbColumnProps{Name: "CustomerID", DbType: "nchar"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	catalog.ID = "northwind"
+	catalog.Driver = "sqlserver"
+	return catalog
+}
+
+// newTestProject creates a project with an item of each kind & returns its store & dir path
+func newTestProject(t *testing.T, ctx context.Context) (*filestore.FsStore, datatug.ProjectStore, string) {
+	rootDir := t.TempDir()
+	store, err := filestore.NewRepoStore(testStoreID, rootDir)
+	require.NoError(t, err)
+	summary, err := store.CreateProject(ctx, dto.CreateProjectRequest{StoreID: testStoreID, Title: "Sales"})
+	require.NoError(t, err)
+	projectPath := path.Join(rootDir, filestore.ProjectsDirName, summary.ID)
+	ps := store.GetProjectStore(summary.ID)
+	// Credentials of query targets are kept to test ExportOptions.ExcludeCredentials
+	project, err := ps.LoadProject(ctx)
+	require.NoError(t, err)
+	project.SecretsPolicy = datatug.SecretsPolicyAllow
+	require.NoError(t, ps.SaveProject(ctx, project))
+
+	query := &datatug.QueryDefWithFolderPath{
+		QueryDef: datatug.QueryDef{
+			ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "top_customers", Title: "Top customers"}},
+			Type:        datatug.QueryTypeSQL,
+			Text:        "SELECT TOP 10 * FROM Customers",
+			Targets: []datatug.QueryDefTarget{
+				{Driver: "sqlserver", Host: "localhost", Credentials: datatug.Credentials{Username: "sa", Password: "secret"}},
+			},
+		},
+	}
+	require.NoError(t, ps.SaveQuery(ctx, query))
+	board := &datatug.Board{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "overview", Title: "Overview"}}}
+	require.NoError(t, ps.SaveBoard(ctx, board))
+	entity := &datatug.Entity{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "customer", Title: "Customer"}}}
+	require.NoError(t, ps.SaveEntity(ctx, entity))
+	require.NoError(t, ps.SaveEnvironment(ctx, &datatug.Environment{
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "dev"}},
+		Variables:   datatug.Variables{Vars: map[string]datatug.VarInfo{"API_TOKEN": {Type: "str", Value: "secret"}}},
+	}))
+	server := &datatug.EnvDbServer{
+		ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "localhost", Port: 1433},
+		Catalogs:  []string{"northwind", "no_snapshot"},
+		SSHTunnel: &datatug.SSHTunnel{Host: "bastion", User: "deploy", KeyPath: "~/.ssh/secret_key"},
+		Variables: datatug.Variables{Vars: map[string]datatug.VarInfo{
+			"DB_USER":     {Type: "str", Value: "sa"},
+			"DB_PASSWORD": {Type: "str", Value: "secret"},
+		}},
+	}
+	require.NoError(t, ps.SaveEnvDbServer(ctx, "dev", server))
+	require.NoError(t, ps.SaveEnvDbCatalog(ctx, "dev", server.GetID(), "northwind", newTestCatalog()))
+
+	recordset := datatug.RecordsetDefinition{
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "customers", Title: "Customers"}},
+		Type:        "recordset",
+		Columns:     datatug.RecordsetColumnDefs{{Name: "id", Type: "int"}},
+		Files:       []string{"data.json", "more.json"},
+	}
+	writeTestJSON(t, path.Join(projectPath, storage.RecordsetsFolder, storage.JsonFileName("customers", storage.RecordsetFileSuffix)), recordset)
+	writeTestJSON(t, path.Join(projectPath, storage.DataFolder, "customers", "data.json"), []map[string]any{{"id": 1}, {"id": 2}})
+	writeTestJSON(t, path.Join(projectPath, storage.DataFolder, "customers", "more.json"), []map[string]any{{"id": 3}})
+	return store, ps, projectPath
+}
+
+func writeTestJSON(t *testing.T, filePath string, v any) {
+	require.NoError(t, os.MkdirAll(path.Dir(filePath), 0777))
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filePath, data, 0666))
+}
+
+func readTestManifest(t *testing.T, data []byte) Manifest {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, readEntry(zr, ManifestFileName, &manifest))
+	return manifest
+}
+
+func entryKinds(manifest Manifest) map[ItemKind]int {
+	kinds := make(map[ItemKind]int)
+	for _, entry := range manifest.Entries {
+		kinds[entry.Kind]++
+	}
+	return kinds
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	_, source, _ := newTestProject(t, ctx)
+
+	var buf bytes.Buffer
+	require.NoError(t, Export(ctx, source, &buf, ExportOptions{}))
+
+	manifest := readTestManifest(t, buf.Bytes())
+	assert.Equal(t, FormatVersion, manifest.FormatVersion)
+	assert.Equal(t, "sales", manifest.ProjectID)
+	assert.Equal(t, map[ItemKind]int{
+		ItemKindProject:       1,
+		ItemKindQuery:         1,
+		ItemKindBoard:         1,
+		ItemKindEntity:        1,
+		ItemKindEnvironment:   1,
+		ItemKindEnvDbServer:   1,
+		ItemKindEnvDbCatalog:  1,
+		ItemKindRecordset:     1,
+		ItemKindRecordsetData: 2,
+	}, entryKinds(manifest))
+
+	target, err := filestore.NewRepoStore(testStoreID, t.TempDir())
+	require.NoError(t, err)
+	result, err := Import(ctx, bytes.NewReader(buf.Bytes()), target, ImportOptions{StoreID: testStoreID})
+	require.NoError(t, err)
+	assert.Equal(t, "sales", result.ProjectID)
+	assert.Equal(t, "sales", result.SourceProjectID)
+	assert.Equal(t, 1, result.Imported[ItemKindQuery])
+	assert.Equal(t, 1, result.Imported[ItemKindEnvDbCatalog])
+	assert.Equal(t, 1, result.Imported[ItemKindRecordset])
+	assert.Equal(t, 2, result.Imported[ItemKindRecordsetData])
+	assert.Empty(t, result.Skipped)
+
+	imported := target.GetProjectStore(result.ProjectID)
+	projFile, err := imported.LoadProjectFile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Sales", projFile.Title)
+
+	query, err := imported.LoadQuery(ctx, "top_customers")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT TOP 10 * FROM Customers", query.Text)
+	require.Len(t, query.Targets, 1)
+	assert.Equal(t, "secret", query.Targets[0].Password)
+
+	board, err := imported.LoadBoard(ctx, "overview")
+	require.NoError(t, err)
+	assert.Equal(t, "Overview", board.Title)
+	entity, err := imported.LoadEntity(ctx, "customer")
+	require.NoError(t, err)
+	assert.Equal(t, "Customer", entity.Title)
+
+	servers, err := imported.LoadEnvDbServers(ctx, "dev")
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	catalog, err := imported.LoadEnvDbCatalog(ctx, "dev", servers[0].GetID(), "northwind")
+	require.NoError(t, err)
+	require.Len(t, catalog.Schemas, 1)
+	assert.Len(t, catalog.Schemas[0].Tables, 1)
+
+	def, err := imported.LoadRecordsetDefinition(ctx, "customers")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data.json", "more.json"}, def.Files, "data files should be restored one to one")
+	data, err := imported.LoadRecordsetDataFile(ctx, "customers", "data.json")
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(1)}, {int64(2)}}, data.Rows)
+	data, err = imported.LoadRecordsetDataFile(ctx, "customers", "more.json")
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(3)}}, data.Rows)
+
+	t.Run("conflicting_project_id", func(t *testing.T) {
+		result, err := Import(ctx, bytes.NewReader(buf.Bytes()), target, ImportOptions{StoreID: testStoreID})
+		require.NoError(t, err)
+		assert.Equal(t, "sales-2", result.ProjectID)
+		assert.Equal(t, "sales", result.SourceProjectID)
+		_, err = target.GetProjectStore(result.ProjectID).LoadBoard(ctx, "overview")
+		assert.NoError(t, err)
+	})
+}
+
+func TestExport_Options(t *testing.T) {
+	ctx := context.Background()
+	_, source, _ := newTestProject(t, ctx)
+
+	var buf bytes.Buffer
+	opts := ExportOptions{ExcludeCatalogs: true, ExcludeRecordsetData: true, ExcludeCredentials: true}
+	require.NoError(t, Export(ctx, source, &buf, opts))
+
+	manifest := readTestManifest(t, buf.Bytes())
+	assert.Equal(t, opts, manifest.Options)
+	kinds := entryKinds(manifest)
+	assert.Zero(t, kinds[ItemKindEnvDbCatalog])
+	assert.Zero(t, kinds[ItemKindRecordsetData])
+	assert.Equal(t, 1, kinds[ItemKindRecordset])
+	assert.NotContains(t, buf.String(), "secret")
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var archived datatug.QueryDefWithFolderPath
+	require.NoError(t, readEntry(zr, "queries/top_customers.json", &archived))
+	assert.Equal(t, datatug.Credentials{}, archived.Targets[0].Credentials)
+	var server datatug.EnvDbServer
+	require.NoError(t, readEntry(zr, "environments/dev/servers/localhost:1433.json", &server))
+	assert.Nil(t, server.SSHTunnel, "SSH tunnels should not be exported")
+	assert.Equal(t, map[string]datatug.VarInfo{
+		"DB_USER":     {Type: "str", Value: "sa"},
+		"DB_PASSWORD": {Type: "str"},
+	}, server.Vars, "secret variables should be kept without values")
+	var env datatug.Environment
+	require.NoError(t, readEntry(zr, "environments/dev/environment.json", &env))
+	assert.Equal(t, map[string]datatug.VarInfo{"API_TOKEN": {Type: "str"}}, env.Vars)
+
+	target, err := filestore.NewRepoStore(testStoreID, t.TempDir())
+	require.NoError(t, err)
+	_, err = Import(ctx, bytes.NewReader(buf.Bytes()), target, ImportOptions{StoreID: testStoreID})
+	require.NoError(t, err)
+
+	query, err := source.LoadQuery(ctx, "top_customers")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", query.Targets[0].Password, "source query should not be changed")
+}
+
+// writeTestArchive writes an archive with given entries, the manifest is written as is
+func writeTestArchive(t *testing.T, manifest Manifest, items map[string]any) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	items[ManifestFileName] = manifest
+	for name, item := range items {
+		f, err := zw.Create(name)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(f).Encode(item))
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestImport_Errors(t *testing.T) {
+	ctx := context.Background()
+	project := datatug.ProjectFile{
+		Created:     &datatug.ProjectCreated{},
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "p1", Title: "P1"}, Access: "private"},
+	}
+
+	t.Run("missing_store_id", func(t *testing.T) {
+		_, err := Import(ctx, bytes.NewReader(nil), storage.NewNoOpStore(), ImportOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("not_an_archive", func(t *testing.T) {
+		_, err := Import(ctx, bytes.NewReader([]byte("not a zip")), storage.NewNoOpStore(), ImportOptions{StoreID: testStoreID})
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported_version", func(t *testing.T) {
+		data := writeTestArchive(t, Manifest{FormatVersion: FormatVersion + 1, ProjectID: "p1"}, map[string]any{})
+		_, err := Import(ctx, bytes.NewReader(data), storage.NewNoOpStore(), ImportOptions{StoreID: testStoreID})
+		assert.ErrorIs(t, err, ErrUnsupportedFormatVersion)
+	})
+
+	t.Run("invalid_item", func(t *testing.T) {
+		target, err := filestore.NewRepoStore(testStoreID, t.TempDir())
+		require.NoError(t, err)
+		manifest := Manifest{
+			FormatVersion: FormatVersion,
+			ProjectID:     "p1",
+			Entries: []Entry{
+				{Kind: ItemKindProject, Path: "project.json"},
+				{Kind: ItemKindBoard, Path: "boards/b1.json"},
+			},
+		}
+		data := writeTestArchive(t, manifest, map[string]any{
+			"project.json":   project,
+			"boards/b1.json": datatug.Board{}, // no ID
+		})
+		_, err = Import(ctx, bytes.NewReader(data), target, ImportOptions{StoreID: testStoreID})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "boards/b1.json")
+		}
+		projects, err := target.GetProjects(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, projects, "project should not be created for an invalid archive")
+	})
+
+	t.Run("missing_entry", func(t *testing.T) {
+		manifest := Manifest{
+			FormatVersion: FormatVersion,
+			ProjectID:     "p1",
+			Entries:       []Entry{{Kind: ItemKindQuery, Path: "queries/q1.json"}},
+		}
+		data := writeTestArchive(t, manifest, map[string]any{})
+		_, err := Import(ctx, bytes.NewReader(data), storage.NewNoOpStore(), ImportOptions{StoreID: testStoreID})
+		assert.Error(t, err)
+	})
+}
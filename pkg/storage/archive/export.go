@@ -0,0 +1,293 @@
+package archive
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+)
+
+// Export writes all items of a project into a zip archive.
+// Revisions of items are not exported as they are specific to a store.
+func Export(ctx context.Context, store datatug.ProjectStore, w io.Writer, opts ExportOptions) error {
+	projFile, err := store.LoadProjectFile(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load project file: %w", err)
+	}
+	if projFile.ID == "" {
+		projFile.ID = store.ProjectID()
+	}
+	e := exporter{
+		store: store,
+		opts:  opts,
+		zw:    zip.NewWriter(w),
+		manifest: Manifest{
+			FormatVersion: FormatVersion,
+			ProjectID:     projFile.ID,
+			Title:         projFile.Title,
+			ExportedAt:    time.Now().UTC(),
+			Options:       opts,
+		},
+	}
+	projFile.Revision = ""
+	if opts.ExcludeCredentials {
+		projFile.Variables = withoutSecrets(projFile.Variables)
+	}
+	if err = e.add(Entry{Kind: ItemKindProject, Path: "project.json", ID: projFile.ID}, projFile); err != nil {
+		return err
+	}
+	for _, export := range []func(ctx context.Context) error{
+		func(ctx context.Context) error { return e.exportQueries(ctx, "") },
+		e.exportBoards,
+		e.exportEntities,
+		e.exportEnvironments,
+		e.exportDbDrivers,
+		e.exportDbModels,
+		e.exportRecordsets,
+	} {
+		if err = export(ctx); err != nil {
+			return err
+		}
+	}
+	if err = e.add(Entry{Path: ManifestFileName}, e.manifest); err != nil {
+		return err
+	}
+	if err = e.zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return nil
+}
+
+type exporter struct {
+	store    datatug.ProjectStore
+	opts     ExportOptions
+	zw       *zip.Writer
+	manifest Manifest
+}
+
+// add writes an item as JSON & registers the entry in the manifest, the manifest itself is not registered
+func (e *exporter) add(entry Entry, item any) error {
+	if v, ok := item.(interface{ SetRevision(revision string) }); ok {
+		v.SetRevision("")
+	}
+	f, err := e.zw.Create(entry.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create archive entry %s: %w", entry.Path, err)
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "\t")
+	if err = encoder.Encode(item); err != nil {
+		return fmt.Errorf("failed to write archive entry %s: %w", entry.Path, err)
+	}
+	if entry.Path != ManifestFileName {
+		e.manifest.Entries = append(e.manifest.Entries, entry)
+	}
+	return nil
+}
+
+func (e *exporter) exportQueries(ctx context.Context, folderPath string) error {
+	folder, err := e.store.LoadQueries(ctx, folderPath)
+	if err != nil {
+		return fmt.Errorf("failed to load queries of folder %q: %w", folderPath, err)
+	}
+	if folder == nil {
+		return nil
+	}
+	archivedFolderPath := folderPath
+	if archivedFolderPath == "" {
+		archivedFolderPath = datatug.RootSharedFolderName // as a folder path is required for a query
+	}
+	for _, query := range folder.Items {
+		q := &datatug.QueryDefWithFolderPath{FolderPath: archivedFolderPath, QueryDef: *query}
+		if e.opts.ExcludeCredentials {
+			q.Targets = append([]datatug.QueryDefTarget(nil), q.Targets...)
+			for i := range q.Targets {
+				q.Targets[i].Credentials = datatug.Credentials{}
+			}
+		}
+		entry := Entry{
+			Kind:       ItemKindQuery,
+			Path:       path.Join("queries", folderPath, query.ID+".json"),
+			ID:         query.ID,
+			FolderPath: archivedFolderPath,
+		}
+		if err = e.add(entry, q); err != nil {
+			return err
+		}
+	}
+	for _, subFolder := range folder.Folders {
+		if err = e.exportQueries(ctx, path.Join(folderPath, subFolder.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withoutSecrets returns a copy of variables with values of secret variables cleared,
+// the variables are kept so a user of an imported project knows what is to be set
+func withoutSecrets(vars datatug.Variables) datatug.Variables {
+	if len(vars.Vars) == 0 {
+		return vars
+	}
+	result := datatug.Variables{Vars: make(map[string]datatug.VarInfo, len(vars.Vars))}
+	for name, v := range vars.Vars {
+		if storage.IsSecretVariableName(name) {
+			v.Value = ""
+		}
+		result.Vars[name] = v
+	}
+	return result
+}
+
+func (e *exporter) exportBoards(ctx context.Context) error {
+	boards, err := e.store.LoadBoards(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load boards: %w", err)
+	}
+	for _, board := range boards {
+		if err = e.add(Entry{Kind: ItemKindBoard, Path: path.Join("boards", board.ID+".json"), ID: board.ID}, board); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportEntities(ctx context.Context) error {
+	entities, err := e.store.LoadEntities(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load entities: %w", err)
+	}
+	for _, entity := range entities {
+		if err = e.add(Entry{Kind: ItemKindEntity, Path: path.Join("entities", entity.ID+".json"), ID: entity.ID}, entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportEnvironments(ctx context.Context) error {
+	environments, err := e.store.LoadEnvironments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load environments: %w", err)
+	}
+	for _, env := range environments {
+		envDir := path.Join("environments", env.ID)
+		if e.opts.ExcludeCredentials {
+			env.Variables = withoutSecrets(env.Variables)
+		}
+		if err = e.add(Entry{Kind: ItemKindEnvironment, Path: path.Join(envDir, "environment.json"), ID: env.ID}, env); err != nil {
+			return err
+		}
+		servers, err := e.store.LoadEnvDbServers(ctx, env.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load DB servers of environment %s: %w", env.ID, err)
+		}
+		for _, server := range servers {
+			serverID := server.GetID()
+			if e.opts.ExcludeCredentials {
+				server.SSHTunnel = nil // holds a user & a path to a private key
+				server.Variables = withoutSecrets(server.Variables)
+			}
+			entry := Entry{Kind: ItemKindEnvDbServer, Path: path.Join(envDir, "servers", serverID+".json"), ID: serverID, EnvID: env.ID}
+			if err = e.add(entry, server); err != nil {
+				return err
+			}
+			if e.opts.ExcludeCatalogs {
+				continue
+			}
+			for _, catalogID := range server.Catalogs {
+				catalog, err := e.store.LoadEnvDbCatalog(ctx, env.ID, serverID, catalogID)
+				if err != nil {
+					if isNotFound(err) { // a server might list a catalog that has no snapshot
+						continue
+					}
+					return fmt.Errorf("failed to load DB catalog %s of server %s of environment %s: %w", catalogID, serverID, env.ID, err)
+				}
+				entry = Entry{
+					Kind:     ItemKindEnvDbCatalog,
+					Path:     path.Join(envDir, "catalogs", serverID, catalogID+".json"),
+					ID:       catalogID,
+					EnvID:    env.ID,
+					ServerID: serverID,
+				}
+				if err = e.add(entry, &catalog); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportDbDrivers(ctx context.Context) error {
+	drivers, err := e.store.LoadProjDbDrivers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load DB drivers: %w", err)
+	}
+	for _, driver := range drivers {
+		for _, server := range driver.Servers {
+			server.Catalogs = nil
+			if e.opts.ExcludeCatalogs {
+				continue
+			}
+			catalogsStore := e.store.DbServersStore(driver.ID).CatalogsStore(server.Server)
+			if server.Catalogs, err = catalogsStore.LoadDbCatalogs(ctx); err != nil && !isNotFound(err) {
+				return fmt.Errorf("failed to load DB catalogs of server %s: %w", server.ID, err)
+			}
+		}
+		if err = e.add(Entry{Kind: ItemKindDbDriver, Path: path.Join("dbdrivers", driver.ID+".json"), ID: driver.ID}, driver); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportDbModels exports DB models if the store provides them, DB models are not part of datatug.ProjectStore
+func (e *exporter) exportDbModels(ctx context.Context) error {
+	dbModelsStore, ok := e.store.(datatug.DbModelsStore)
+	if !ok {
+		return nil
+	}
+	dbModels, err := dbModelsStore.LoadDbModels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load DB models: %w", err)
+	}
+	for _, dbModel := range dbModels {
+		if err = e.add(Entry{Kind: ItemKindDbModel, Path: path.Join("dbmodels", dbModel.ID+".json"), ID: dbModel.ID}, dbModel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportRecordsets(ctx context.Context) error {
+	recordsets, err := e.store.LoadRecordsetDefinitions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load recordset definitions: %w", err)
+	}
+	for _, def := range recordsets {
+		if err = e.add(Entry{Kind: ItemKindRecordset, Path: path.Join("recordsets", def.ID+".json"), ID: def.ID}, def); err != nil {
+			return err
+		}
+		if e.opts.ExcludeRecordsetData || len(def.Files) == 0 {
+			continue
+		}
+		for _, fileName := range def.Files {
+			data, err := e.store.LoadRecordsetDataFile(ctx, def.ID, fileName)
+			if err != nil {
+				return fmt.Errorf("failed to load data of recordset %s from %s: %w", def.ID, fileName, err)
+			}
+			entry := Entry{Kind: ItemKindRecordsetData, Path: path.Join("recordsets", def.ID, fileName), ID: def.ID, FileName: fileName}
+			if err = e.add(entry, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dto"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/strongo/validation"
+)
+
+// ImportOptions defines where & how an archive is imported
+type ImportOptions struct {
+	StoreID string // ID of the store to create the project in
+	Title   string // if set overrides title of the archived project
+}
+
+// ImportResult describes an imported project
+type ImportResult struct {
+	// ProjectID is assigned by the store and differs from SourceProjectID if the store already has a project with that ID
+	ProjectID       string           `json:"projectID"`
+	SourceProjectID string           `json:"sourceProjectID"`
+	Imported        map[ItemKind]int `json:"imported"`
+	// Skipped lists entries the target store can not save, e.g. DB models if the store does not implement datatug.DbModelsStore
+	Skipped []Entry `json:"skipped,omitempty"`
+}
+
+// Import creates a new project in a store from an archive written by Export.
+// All items are validated before the project is created. If saving of an item fails
+// the created project is deleted.
+func Import(ctx context.Context, r io.Reader, store storage.Store, opts ImportOptions) (*ImportResult, error) {
+	if opts.StoreID == "" {
+		return nil, validation.NewErrRequestIsMissingRequiredField("StoreID")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	var manifest Manifest
+	if err = readEntry(zr, ManifestFileName, &manifest); err != nil {
+		return nil, err
+	}
+	if err = manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid archive manifest: %w", err)
+	}
+	c, err := readContent(zr, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	title := opts.Title
+	if title == "" {
+		if title = c.project.Title; title == "" {
+			title = manifest.ProjectID
+		}
+	}
+	summary, err := store.CreateProject(ctx, dto.CreateProjectRequest{StoreID: opts.StoreID, Title: title})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	result := &ImportResult{
+		ProjectID:       summary.ID,
+		SourceProjectID: manifest.ProjectID,
+		Imported:        make(map[ItemKind]int),
+	}
+	if err = c.save(ctx, store.GetProjectStore(summary.ID), title, result); err != nil {
+		if deleteErr := store.DeleteProject(ctx, summary.ID); deleteErr != nil {
+			return nil, fmt.Errorf("%w (failed to delete partially imported project %s: %v)", err, summary.ID, deleteErr)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+type envDbServer struct {
+	envID  string
+	server *datatug.EnvDbServer
+}
+
+type envDbCatalog struct {
+	envID, serverID string
+	catalog         *datatug.DbCatalog
+}
+
+type recordsetData struct {
+	id, fileName string
+	data         *datatug.Recordset
+}
+
+// content holds decoded & validated items of an archive
+type content struct {
+	project      datatug.ProjectFile
+	queries      []*datatug.QueryDefWithFolderPath
+	boards       datatug.Boards
+	entities     datatug.Entities
+	environments datatug.Environments
+	envServers   []envDbServer
+	envCatalogs  []envDbCatalog
+	dbDrivers    datatug.ProjDbDrivers
+	dbModels     datatug.DbModels
+	recordsets   datatug.RecordsetDefinitions
+	data         []recordsetData
+}
+
+func readContent(zr *zip.Reader, manifest Manifest) (c content, err error) {
+	var errs []error
+	for _, entry := range manifest.Entries {
+		var item interface{ Validate() error }
+		switch entry.Kind {
+		case ItemKindProject:
+			item = &c.project
+		case ItemKindQuery:
+			q := new(datatug.QueryDefWithFolderPath)
+			c.queries, item = append(c.queries, q), q
+		case ItemKindBoard:
+			board := new(datatug.Board)
+			c.boards, item = append(c.boards, board), board
+		case ItemKindEntity:
+			entity := new(datatug.Entity)
+			c.entities, item = append(c.entities, entity), entity
+		case ItemKindEnvironment:
+			env := new(datatug.Environment)
+			c.environments, item = append(c.environments, env), env
+		case ItemKindEnvDbServer:
+			server := new(datatug.EnvDbServer)
+			c.envServers, item = append(c.envServers, envDbServer{envID: entry.EnvID, server: server}), server
+		case ItemKindEnvDbCatalog:
+			catalog := new(datatug.DbCatalog)
+			c.envCatalogs = append(c.envCatalogs, envDbCatalog{envID: entry.EnvID, serverID: entry.ServerID, catalog: catalog})
+			item = catalog
+		case ItemKindDbDriver:
+			driver := new(datatug.ProjDbDriver)
+			c.dbDrivers, item = append(c.dbDrivers, driver), driver
+		case ItemKindDbModel:
+			dbModel := new(datatug.DbModel)
+			c.dbModels, item = append(c.dbModels, dbModel), dbModel
+		case ItemKindRecordset:
+			def := new(datatug.RecordsetDefinition)
+			c.recordsets, item = append(c.recordsets, def), def
+		case ItemKindRecordsetData:
+			data := new(datatug.Recordset)
+			if entry.FileName == "" {
+				errs = append(errs, fmt.Errorf("%s: recordset data entry is missing a file name", entry.Path))
+				continue
+			}
+			c.data, item = append(c.data, recordsetData{id: entry.ID, fileName: entry.FileName, data: data}), data
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown item kind %q", entry.Path, entry.Kind))
+			continue
+		}
+		if err = readEntry(zr, entry.Path, item); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = item.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid %s: %w", entry.Path, entry.Kind, err))
+		}
+	}
+	if c.project.Created == nil {
+		c.project.Created = &datatug.ProjectCreated{At: time.Now()}
+	}
+	return c, errors.Join(errs...)
+}
+
+func readEntry(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open archive entry %s: %w", name, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	decoder := json.NewDecoder(f)
+	if _, isData := v.(*datatug.Recordset); isData {
+		decoder.UseNumber() // see datatug.JSONNumberValue
+	}
+	if err = decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to decode archive entry %s: %w", name, err)
+	}
+	if data, isData := v.(*datatug.Recordset); isData {
+		for _, row := range data.Rows {
+			for i, value := range row {
+				if n, isNumber := value.(json.Number); isNumber {
+					row[i] = datatug.JSONNumberValue(n)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c content) save(ctx context.Context, store datatug.ProjectStore, title string, result *ImportResult) error {
+	project := &datatug.Project{
+		ProjectItem:   c.project.ProjectItem,
+		Created:       c.project.Created,
+		Repository:    c.project.Repository,
+		ItemsFormat:   c.project.ItemsFormat,
+		SQLQueryFiles: c.project.SQLQueryFiles,
+		SecretsPolicy: c.project.SecretsPolicy,
+		Variables:     c.project.Variables,
+		VarSettings:   c.project.VarSettings,
+	}
+	project.ID = store.ProjectID()
+	project.Title = title
+	if err := store.SaveProject(ctx, project); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+	result.Imported[ItemKindProject] = 1
+	for _, env := range c.environments {
+		if err := store.SaveEnvironment(ctx, env); err != nil {
+			return fmt.Errorf("failed to save environment %s: %w", env.ID, err)
+		}
+		result.Imported[ItemKindEnvironment]++
+	}
+	for _, s := range c.envServers {
+		if err := store.SaveEnvDbServer(ctx, s.envID, s.server); err != nil {
+			return fmt.Errorf("failed to save DB server %s of environment %s: %w", s.server.GetID(), s.envID, err)
+		}
+		result.Imported[ItemKindEnvDbServer]++
+	}
+	for _, ec := range c.envCatalogs {
+		if err := store.SaveEnvDbCatalog(ctx, ec.envID, ec.serverID, ec.catalog.ID, ec.catalog); err != nil {
+			return fmt.Errorf("failed to save DB catalog %s of environment %s: %w", ec.catalog.ID, ec.envID, err)
+		}
+		result.Imported[ItemKindEnvDbCatalog]++
+	}
+	for _, driver := range c.dbDrivers {
+		if err := saveDbDriver(ctx, store, driver); err != nil {
+			return err
+		}
+		result.Imported[ItemKindDbDriver]++
+	}
+	if len(c.dbModels) > 0 {
+		if dbModelsStore, ok := store.(datatug.DbModelsStore); ok {
+			for _, dbModel := range c.dbModels {
+				if err := dbModelsStore.SaveDbModel(ctx, dbModel); err != nil {
+					return fmt.Errorf("failed to save DB model %s: %w", dbModel.ID, err)
+				}
+				result.Imported[ItemKindDbModel]++
+			}
+		} else {
+			for _, dbModel := range c.dbModels {
+				result.Skipped = append(result.Skipped, Entry{Kind: ItemKindDbModel, ID: dbModel.ID})
+			}
+		}
+	}
+	for _, entity := range c.entities {
+		if err := store.SaveEntity(ctx, entity); err != nil {
+			return fmt.Errorf("failed to save entity %s: %w", entity.ID, err)
+		}
+		result.Imported[ItemKindEntity]++
+	}
+	for _, query := range c.queries {
+		if err := store.SaveQuery(ctx, query); err != nil {
+			return fmt.Errorf("failed to save query %s: %w", query.ID, err)
+		}
+		result.Imported[ItemKindQuery]++
+	}
+	for _, board := range c.boards {
+		if err := store.SaveBoard(ctx, board); err != nil {
+			return fmt.Errorf("failed to save board %s: %w", board.ID, err)
+		}
+		result.Imported[ItemKindBoard]++
+	}
+	return c.saveRecordsets(ctx, store, result)
+}
+
+// saveRecordsets saves recordset definitions & their data files one to one as they are in the exported project
+func (c content) saveRecordsets(ctx context.Context, store datatug.ProjectStore, result *ImportResult) error {
+	for _, def := range c.recordsets {
+		if err := store.SaveRecordsetDefinition(ctx, def); err != nil {
+			return fmt.Errorf("failed to save recordset %s: %w", def.ID, err)
+		}
+		result.Imported[ItemKindRecordset]++
+		for _, d := range c.data {
+			if d.id != def.ID {
+				continue
+			}
+			if err := store.SaveRecordsetData(ctx, def.ID, d.fileName, *d.data); err != nil {
+				return fmt.Errorf("failed to save data of recordset %s to %s: %w", def.ID, d.fileName, err)
+			}
+			result.Imported[ItemKindRecordsetData]++
+		}
+	}
+	return nil
+}
+
+// saveDbDriver saves a driver, its servers & catalogs of the servers with separate calls
+// as a store is not required to save nested items
+func saveDbDriver(ctx context.Context, store datatug.ProjectStore, driver *datatug.ProjDbDriver) error {
+	servers := driver.Servers
+	driver.Servers = nil
+	if err := store.SaveProjDbDriver(ctx, driver); err != nil {
+		return fmt.Errorf("failed to save DB driver %s: %w", driver.ID, err)
+	}
+	serversStore := store.DbServersStore(driver.ID)
+	for _, server := range servers {
+		catalogs := server.Catalogs
+		server.Catalogs = nil
+		if err := serversStore.SaveProjDbServer(ctx, server); err != nil {
+			return fmt.Errorf("failed to save DB server %s of driver %s: %w", server.ID, driver.ID, err)
+		}
+		catalogsStore := serversStore.CatalogsStore(server.Server)
+		for _, catalog := range catalogs {
+			if err := catalogsStore.SaveDbCatalog(ctx, catalog); err != nil {
+				return fmt.Errorf("failed to save DB catalog %s of server %s: %w", catalog.ID, server.ID, err)
+			}
+		}
+		server.Catalogs = catalogs
+	}
+	driver.Servers = servers
+	return nil
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
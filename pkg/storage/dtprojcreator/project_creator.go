@@ -3,10 +3,12 @@ package dtprojcreator
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"path"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +20,10 @@ import (
 
 var yamlMarshal = yaml.Marshal
 
+var jsonMarshal = func(v any) ([]byte, error) {
+	return json.MarshalIndent(v, "", "\t")
+}
+
 type creator struct {
 	ctx          context.Context
 	p            *datatug.Project
@@ -103,26 +109,56 @@ func (c creator) createProjectReadmeMD() error {
 
 func (c creator) createProjectSummaryFile() error {
 	projectFile := datatug.ProjectFile{
-		ProjectItem: c.p.ProjectItem,
+		FormatVersion: datatug.ProjectFormatVersion,
+		ProjectItem:   c.p.ProjectItem,
 		Created: &datatug.ProjectCreated{
 			At: time.Now().UTC(),
 		},
 	}
-	content, err := yamlMarshal(projectFile)
+	content, err := jsonMarshal(projectFile)
 	if err != nil {
 		return err
 	}
 	return c.writeFile(storage.ProjectSummaryFileName, content)
 }
 
+// addProjectToRootRepoFile registers project path in the .datatug.yaml file at the root of the storage,
+// preserving projects that are already listed there.
 func (c creator) addProjectToRootRepoFile() error {
 	var repoRootFile datatug.RepoRootFile
+	exists, err := c.s.FileExists(c.ctx, storage.RepoRootDataTugFileName)
+	if err != nil {
+		return fmt.Errorf("failed to check if %s exists: %w", storage.RepoRootDataTugFileName, err)
+	}
+	if exists {
+		if err = c.readRepoRootFile(&repoRootFile); err != nil {
+			return err
+		}
+	}
+	if slices.Contains(repoRootFile.Projects, c.projPath) {
+		return nil
+	}
 	repoRootFile.Projects = append(repoRootFile.Projects, c.projPath)
 	content, err := yamlMarshal(repoRootFile)
 	if err != nil {
 		return fmt.Errorf("failed to marshal repoRootFile: %w", err)
 	}
-	return c.writeFile(storage.RepoRootDataTugFileName, content)
+	reader := io.NopCloser(bytes.NewReader(content))
+	return c.s.WriteFile(c.ctx, storage.RepoRootDataTugFileName, reader)
+}
+
+func (c creator) readRepoRootFile(repoRootFile *datatug.RepoRootFile) error {
+	r, err := c.s.OpenFile(c.ctx, storage.RepoRootDataTugFileName)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", storage.RepoRootDataTugFileName, err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	if err = yaml.NewDecoder(r).Decode(repoRootFile); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse %s: %w", storage.RepoRootDataTugFileName, err)
+	}
+	return nil
 }
 
 func (c creator) writeFile(name string, content []byte) error {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/datatug/datatug-core/pkg/datatug"
@@ -44,26 +45,61 @@ func TestCreator_WriteFile(t *testing.T) {
 }
 
 func TestCreator_AddProjectToRootRepoFile(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockStorage := storage.NewMockStorage(ctrl)
 	ctx := context.Background()
-	projPath := "test-proj"
-	c := creator{
-		ctx:      ctx,
-		s:        mockStorage,
-		projPath: projPath,
-	}
+	projPath := "datatug/p2"
 
-	expectedPath := path.Join(projPath, storage.RepoRootDataTugFileName)
+	t.Run("new_file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := storage.NewMockStorage(ctrl)
+		c := creator{ctx: ctx, s: mockStorage, projPath: projPath}
+
+		mockStorage.EXPECT().FileExists(ctx, storage.RepoRootDataTugFileName).Return(false, nil)
+		mockStorage.EXPECT().
+			WriteFile(ctx, storage.RepoRootDataTugFileName, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filePath string, reader io.Reader) error {
+				content, err := io.ReadAll(reader)
+				assert.NoError(t, err)
+				assert.Equal(t, "projects:\n    - datatug/p2\n", string(content))
+				return nil
+			})
+
+		err := c.addProjectToRootRepoFile()
+		assert.NoError(t, err)
+	})
 
-	mockStorage.EXPECT().
-		WriteFile(ctx, expectedPath, gomock.Any()).
-		Return(nil)
+	t.Run("existing_file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := storage.NewMockStorage(ctrl)
+		c := creator{ctx: ctx, s: mockStorage, projPath: projPath}
+
+		mockStorage.EXPECT().FileExists(ctx, storage.RepoRootDataTugFileName).Return(true, nil)
+		mockStorage.EXPECT().OpenFile(ctx, storage.RepoRootDataTugFileName).
+			Return(io.NopCloser(strings.NewReader("projects:\n  - datatug/p1\n")), nil)
+		mockStorage.EXPECT().
+			WriteFile(ctx, storage.RepoRootDataTugFileName, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filePath string, reader io.Reader) error {
+				content, err := io.ReadAll(reader)
+				assert.NoError(t, err)
+				assert.Equal(t, "projects:\n    - datatug/p1\n    - datatug/p2\n", string(content))
+				return nil
+			})
+
+		err := c.addProjectToRootRepoFile()
+		assert.NoError(t, err)
+	})
 
-	err := c.addProjectToRootRepoFile()
-	assert.NoError(t, err)
+	t.Run("already_registered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStorage := storage.NewMockStorage(ctrl)
+		c := creator{ctx: ctx, s: mockStorage, projPath: projPath}
+
+		mockStorage.EXPECT().FileExists(ctx, storage.RepoRootDataTugFileName).Return(true, nil)
+		mockStorage.EXPECT().OpenFile(ctx, storage.RepoRootDataTugFileName).
+			Return(io.NopCloser(strings.NewReader("projects:\n  - datatug/p2\n")), nil)
+
+		err := c.addProjectToRootRepoFile()
+		assert.NoError(t, err)
+	})
 }
 
 func TestCreator_CreateProjectSummaryFile(t *testing.T) {
@@ -104,16 +140,20 @@ func TestCreator_AddProjectToRootRepoFile_Error(t *testing.T) {
 		return nil, errors.New("marshal error")
 	}
 
-	c := creator{}
+	ctrl := gomock.NewController(t)
+	mockStorage := storage.NewMockStorage(ctrl)
+	mockStorage.EXPECT().FileExists(gomock.Any(), storage.RepoRootDataTugFileName).Return(false, nil)
+
+	c := creator{s: mockStorage}
 	err := c.addProjectToRootRepoFile()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "marshal error")
 }
 
 func TestCreator_CreateProjectSummaryFile_Error(t *testing.T) {
-	oldYamlMarshal := yamlMarshal
-	defer func() { yamlMarshal = oldYamlMarshal }()
-	yamlMarshal = func(v interface{}) ([]byte, error) {
+	oldJsonMarshal := jsonMarshal
+	defer func() { jsonMarshal = oldJsonMarshal }()
+	jsonMarshal = func(v any) ([]byte, error) {
 		return nil, errors.New("marshal error")
 	}
 
@@ -167,6 +207,7 @@ func TestCreateProjectFiles(t *testing.T) {
 		defer ctrl.Finish()
 		mockStorage := storage.NewMockStorage(ctrl)
 
+		mockStorage.EXPECT().FileExists(ctx, storage.RepoRootDataTugFileName).Return(false, nil)
 		mockStorage.EXPECT().WriteFile(ctx, gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
 		reportStatus := func(step string, status string) {}
 		err := CreateProjectFiles(ctx, p, projPath, mockStorage, reportStatus)
@@ -178,6 +219,7 @@ func TestCreateProjectFiles(t *testing.T) {
 		defer ctrl.Finish()
 		mockStorage := storage.NewMockStorage(ctrl)
 
+		mockStorage.EXPECT().FileExists(ctx, storage.RepoRootDataTugFileName).Return(false, nil)
 		mockStorage.EXPECT().WriteFile(ctx, gomock.Any(), gomock.Any()).AnyTimes().Return(errors.New("write error"))
 		reportStatus := func(step string, status string) {}
 		err := CreateProjectFiles(ctx, p, projPath, mockStorage, reportStatus)
@@ -1,10 +1,114 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrBriefItem is returned on an attempt to save an item loaded with datatug.BriefsOnly option
+var ErrBriefItem = errors.New("an item loaded as a brief can not be saved")
+
+// ErrRevisionConflict is matched by errors.Is() for any RevisionConflictError
+var ErrRevisionConflict = errors.New("revision conflict")
+
+// NewRevisionConflictError creates an error for an item that has been changed since it was loaded
+func NewRevisionConflictError(itemID, expected, actual string) RevisionConflictError {
+	return RevisionConflictError{
+		ItemID:   itemID,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
+// RevisionConflictError is returned on an attempt to save an item with a revision that does not match stored one
+type RevisionConflictError struct {
+	ItemID   string `json:"itemID"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"` // empty if the item has been deleted
+}
+
+func (e RevisionConflictError) Error() string {
+	if e.Actual == "" {
+		return fmt.Sprintf("%v: %v has been deleted, expected revision %v", ErrRevisionConflict, e.ItemID, e.Expected)
+	}
+	return fmt.Sprintf("%v: %v has revision %v, expected %v", ErrRevisionConflict, e.ItemID, e.Actual, e.Expected)
+}
+
+func (e RevisionConflictError) Is(target error) bool {
+	return target == ErrRevisionConflict
+}
+
+// ErrProjectOutdated is matched by errors.Is() for any OutdatedProjectError
+var ErrProjectOutdated = errors.New("project format is outdated")
+
+// ErrProjectFormatNotSupported is returned for projects written by a newer version of DataTug
+var ErrProjectFormatNotSupported = errors.New("project format is not supported")
+
+// NewOutdatedProjectError creates an error for a project that should be migrated before use
+func NewOutdatedProjectError(projectID string, version, currentVersion int) OutdatedProjectError {
+	return OutdatedProjectError{
+		ProjectID:      projectID,
+		Version:        version,
+		CurrentVersion: currentVersion,
+	}
+}
+
+// OutdatedProjectError is returned on an attempt to use a project with an older format version
+type OutdatedProjectError struct {
+	ProjectID      string `json:"projectID"`
+	Version        int    `json:"version"`
+	CurrentVersion int    `json:"currentVersion"`
+}
+
+func (e OutdatedProjectError) Error() string {
+	return fmt.Sprintf("%v: project %v has format version %v, current version is %v", ErrProjectOutdated, e.ProjectID, e.Version, e.CurrentVersion)
+}
+
+func (e OutdatedProjectError) Is(target error) bool {
+	return target == ErrProjectOutdated
+}
+
+// ErrSecretsFound is matched by errors.Is() for any SecretsFoundError
+var ErrSecretsFound = errors.New("secrets found")
+
+// SecretKind is a type of secret found in a project file
+type SecretKind string
+
+const (
+	SecretKindPassword         SecretKind = "password"
+	SecretKindConnectionString SecretKind = "connectionString"
+	SecretKindHTTPHeader       SecretKind = "httpHeader"
+)
+
+// SecretFinding describes a secret found in a project file
+type SecretFinding struct {
+	FilePath string     `json:"filePath"` // relative to the project directory
+	Path     string     `json:"path"`     // path of a property in the file, e.g. "targets[0].password"
+	Kind     SecretKind `json:"kind"`
+}
+
+func (v SecretFinding) String() string {
+	return fmt.Sprintf("%v of %v at %v", v.Kind, v.FilePath, v.Path)
+}
+
+// SecretsFoundError is returned on an attempt to save an item with secrets to a project that refuses them
+type SecretsFoundError struct {
+	Findings []SecretFinding `json:"findings"`
+}
+
+func (e SecretsFoundError) Error() string {
+	findings := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		findings[i] = f.String()
+	}
+	return fmt.Sprintf("%v: %v", ErrSecretsFound, strings.Join(findings, ", "))
+}
+
+func (e SecretsFoundError) Is(target error) bool {
+	return target == ErrSecretsFound
+}
+
 func NewFileLoadError(fileName string, err error) FileLoadError {
 	return FileLoadError{
 		FileName: fileName,
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,3 +30,39 @@ func TestFilesLoadError(t *testing.T) {
 	assert.Equal(t, errs, err.Errors())
 	assert.Equal(t, "2 files failed to load:\n\tfile1: err1\n\tfile2: err2", err.Error())
 }
+
+func TestRevisionConflictError(t *testing.T) {
+	err := NewRevisionConflictError("b1", "r1", "r2")
+	assert.True(t, errors.Is(err, ErrRevisionConflict))
+	assert.Equal(t, "revision conflict: b1 has revision r2, expected r1", err.Error())
+
+	var conflict RevisionConflictError
+	wrapped := fmt.Errorf("failed to save: %w", err)
+	assert.True(t, errors.As(wrapped, &conflict))
+	assert.Equal(t, "r2", conflict.Actual)
+
+	deleted := NewRevisionConflictError("b1", "r1", "")
+	assert.Equal(t, "revision conflict: b1 has been deleted, expected revision r1", deleted.Error())
+}
+
+func TestOutdatedProjectError(t *testing.T) {
+	err := NewOutdatedProjectError("p1", 0, 1)
+	assert.True(t, errors.Is(err, ErrProjectOutdated))
+	assert.False(t, errors.Is(err, ErrProjectFormatNotSupported))
+	assert.Equal(t, "project format is outdated: project p1 has format version 0, current version is 1", err.Error())
+
+	var outdated OutdatedProjectError
+	wrapped := fmt.Errorf("failed to load project: %w", err)
+	if assert.True(t, errors.As(wrapped, &outdated)) {
+		assert.Equal(t, 0, outdated.Version)
+	}
+}
+
+func TestSecretsFoundError(t *testing.T) {
+	err := SecretsFoundError{Findings: []SecretFinding{
+		{FilePath: "queries/q1.query.json", Path: "targets[0].password", Kind: SecretKindPassword},
+		{FilePath: "boards/b1.board.json", Path: "widgets[0].headers[1].value", Kind: SecretKindHTTPHeader},
+	}}
+	assert.True(t, errors.Is(fmt.Errorf("failed to save: %w", err), ErrSecretsFound))
+	assert.Equal(t, "secrets found: password of queries/q1.query.json at targets[0].password, httpHeader of boards/b1.board.json at widgets[0].headers[1].value", err.Error())
+}
@@ -17,6 +17,7 @@ const (
 	EnvironmentsFolder      = "environments"
 	QueriesFolder           = "queries"
 	RecordsetsFolder        = "recordsets"
+	ResultsFolder           = "results"
 	ServersFolder           = "servers"
 	SchemasFolder           = "schemas"
 	//TablesFolder            = "tables"
@@ -40,7 +41,8 @@ func JsonFileName(id, suffix string) string {
 		EntityFileSuffix,
 		ServerFileSuffix,
 		ColumnsFileSuffix,
-		QueryFileSuffix:
+		QueryFileSuffix,
+		QueryResultFileSuffix:
 		// OK
 	default:
 		panic(fmt.Sprintf("unknown JSON file suffix=[%v], id=[%v]", suffix, id))
@@ -67,12 +69,13 @@ const (
 	DbCatalogRefsFileSuffix   = "refs"
 	DbModelFileSuffix         = "dbmodel"
 	//DbSchemaFileSuffix        = "schema"
-	DbServerFileSuffix  = "dbserver"
-	RecordsetFileSuffix = "recordset"
-	EntityFileSuffix    = "entity"
-	ServerFileSuffix    = "server"
-	ColumnsFileSuffix   = "columns"
-	QueryFileSuffix     = "query"
+	DbServerFileSuffix    = "dbserver"
+	RecordsetFileSuffix   = "recordset"
+	EntityFileSuffix      = "entity"
+	ServerFileSuffix      = "server"
+	ColumnsFileSuffix     = "columns"
+	QueryFileSuffix       = "query"
+	QueryResultFileSuffix = "result"
 )
 
 const (
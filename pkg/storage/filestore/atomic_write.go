@@ -0,0 +1,75 @@
+package filestore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomically writes a file via a temporary file in the same directory that is synced to disk
+// and then renamed to the target name, so readers never see a partially written file.
+// If write fails the target file is left untouched.
+func writeFileAtomically(filePath string, write func(w io.Writer) error) (err error) {
+	dir, name := filepath.Split(filePath)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := osCreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpName)
+		}
+	}()
+	if err = write(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file %s: %w", tmpName, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close file %s: %w", tmpName, err)
+	}
+	// A rewritten file keeps its mode, e.g. an executable script or a file readable only by its owner
+	var mode os.FileMode = 0644
+	if info, statErr := os.Stat(filePath); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+	if err = os.Chmod(tmpName, mode); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err = osRename(tmpName, filePath); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", tmpName, filePath, err)
+	}
+	syncDir(dir)
+	return nil
+}
+
+// WriteFileAtomically writes a file the way the store does, e.g. for stores that write to project directories too
+func WriteFileAtomically(filePath string, write func(w io.Writer) error) error {
+	return writeFileAtomically(filePath, write)
+}
+
+// writeBytesAtomically writes content to a file using writeFileAtomically
+func writeBytesAtomically(filePath string, content []byte) error {
+	return writeFileAtomically(filePath, func(w io.Writer) error {
+		_, err := io.Copy(w, bytes.NewReader(content))
+		return err
+	})
+}
+
+// syncDir flushes directory entry changes (e.g. a rename) to disk, it's a best effort as not all platforms support it
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,109 @@
+package filestore
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".tmp-", "temporary file should be removed")
+	}
+}
+
+func TestWriteFileAtomically(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "file.json")
+	require.NoError(t, os.WriteFile(filePath, []byte("original"), 0644))
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, writeBytesAtomically(filePath, []byte("updated")))
+		content, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "updated", string(content))
+		fi, err := os.Stat(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), fi.Mode().Perm())
+		assertNoTempFiles(t, dir)
+	})
+
+	t.Run("keeps_mode", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Windows has no file permission bits")
+		}
+		scriptPath := filepath.Join(dir, "script.sh")
+		require.NoError(t, os.WriteFile(scriptPath, []byte("original"), 0700))
+		require.NoError(t, os.Chmod(scriptPath, 0750))
+		require.NoError(t, writeBytesAtomically(scriptPath, []byte("updated")))
+		fi, err := os.Stat(scriptPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0750), fi.Mode().Perm())
+
+		newPath := filepath.Join(dir, "new.json")
+		require.NoError(t, writeBytesAtomically(newPath, []byte("new")))
+		fi, err = os.Stat(newPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), fi.Mode().Perm(), "a new file")
+		require.NoError(t, os.Remove(scriptPath))
+		require.NoError(t, os.Remove(newPath))
+	})
+
+	t.Run("write_error", func(t *testing.T) {
+		err := writeFileAtomically(filePath, func(w io.Writer) error {
+			_, _ = w.Write([]byte("partial"))
+			return errors.New("crash")
+		})
+		assert.EqualError(t, err, "crash")
+		content, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "updated", string(content), "target should not be touched")
+		assertNoTempFiles(t, dir)
+	})
+
+	t.Run("rename_error", func(t *testing.T) {
+		defer func() { osRename = standardOsRename }()
+		osRename = func(string, string) error {
+			return os.ErrPermission
+		}
+		err := writeBytesAtomically(filePath, []byte("lost"))
+		assert.ErrorIs(t, err, os.ErrPermission)
+		content, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "updated", string(content))
+		assertNoTempFiles(t, dir)
+	})
+
+	t.Run("missing_dir", func(t *testing.T) {
+		err := writeBytesAtomically(filepath.Join(dir, "missing", "file.json"), []byte("x"))
+		assert.Error(t, err)
+	})
+}
+
+func TestSaveJSONFile_EncodeErrorKeepsFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "item.json"), []byte(`{"id":"i1"}`), 0644))
+	err := saveJSONFile(dir, "item.json", unencodable{})
+	assert.Error(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "item.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"i1"}`, string(content))
+	assertNoTempFiles(t, dir)
+}
+
+type unencodable struct {
+	F func() `json:"f"`
+}
+
+func (unencodable) Validate() error {
+	return nil
+}
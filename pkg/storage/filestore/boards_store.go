@@ -14,7 +14,7 @@ func newFsBoardsStore(projectPath string) fsBoardsStore {
 	return fsBoardsStore{
 		fsProjectItemsStore: newFileProjectItemsStore[datatug.Boards, *datatug.Board, datatug.Board](
 			path.Join(projectPath, storage.BoardsFolder), storage.BoardFileSuffix,
-		),
+		).inProjectItemsFormat(projectPath),
 	}
 }
 
@@ -28,7 +28,7 @@ func (s fsBoardsStore) LoadBoards(ctx context.Context, o ...datatug.StoreOption)
 }
 
 func (s fsBoardsStore) LoadBoard(ctx context.Context, id string, o ...datatug.StoreOption) (*datatug.Board, error) {
-	return s.loadProjectItem(ctx, s.dirPath, id, s.itemFileName(id), o...)
+	return s.loadProjectItem(ctx, s.dirPath, id, "", o...)
 }
 
 func (s fsBoardsStore) SaveBoard(ctx context.Context, board *datatug.Board) error {
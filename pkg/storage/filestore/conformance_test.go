@@ -0,0 +1,14 @@
+package filestore
+
+import (
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/test/storetest"
+)
+
+func TestFsProjectStore_Conformance(t *testing.T) {
+	storetest.RunProjectStoreTests(t, func(t *testing.T) datatug.ProjectStore {
+		return newFsProjectStore("p1", t.TempDir())
+	})
+}
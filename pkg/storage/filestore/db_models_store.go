@@ -28,7 +28,7 @@ func (s fsDbModelsStore) LoadDbModels(ctx context.Context, o ...datatug.StoreOpt
 }
 
 func (s fsDbModelsStore) LoadDbModel(ctx context.Context, id string, o ...datatug.StoreOption) (*datatug.DbModel, error) {
-	return s.loadProjectItem(ctx, s.dirPath, id, s.itemFileName(id), o...)
+	return s.loadProjectItem(ctx, s.dirPath, id, "", o...)
 }
 
 func (s fsDbModelsStore) SaveDbModel(ctx context.Context, DbModel *datatug.DbModel) error {
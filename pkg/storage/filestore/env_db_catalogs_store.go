@@ -2,7 +2,11 @@ package filestore
 
 import (
 	"context"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/datatug/datatug-core/pkg/datatug"
 	"github.com/datatug/datatug-core/pkg/storage"
@@ -23,13 +27,30 @@ type fsEnvDbCatalogStore struct {
 	fsProjectItemsStore[datatug.DbCatalogs, *datatug.DbCatalog, datatug.DbCatalog]
 }
 
-func (s fsEnvDbCatalogStore) getDirPath(envID string) string {
-	return filepath.Join(s.dirPath, storage.EnvironmentsFolder, envID, storage.EnvDbCatalogsFolder)
+func (s fsEnvDbCatalogStore) getServersDirPath(envID string) string {
+	return filepath.Join(s.dirPath, storage.EnvironmentsFolder, envID, storage.ServersFolder)
 }
 
-func (s fsEnvDbCatalogStore) LoadEnvDbCatalogs(ctx context.Context, envID string, o ...datatug.StoreOption) (datatug.DbCatalogs, error) {
-	dirPath := s.getDirPath(envID)
-	return s.loadProjectItems(ctx, dirPath, o...)
+// LoadEnvDbCatalogs loads catalogs of all servers of an environment
+func (s fsEnvDbCatalogStore) LoadEnvDbCatalogs(ctx context.Context, envID string, o ...datatug.StoreOption) (catalogs datatug.DbCatalogs, err error) {
+	serversDirPath := s.getServersDirPath(envID)
+	err = loadDir(nil, serversDirPath, "", processDirs, nil, func(f os.FileInfo, i int, mutex *sync.Mutex) error {
+		serverCatalogs, err := s.loadProjectItems(ctx, filepath.Join(serversDirPath, f.Name(), storage.EnvDbCatalogsFolder), o...)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		catalogs = append(catalogs, serverCatalogs...)
+		mutex.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(catalogs, func(a, b *datatug.DbCatalog) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return catalogs, nil
 }
 
 func (s fsEnvDbCatalogStore) LoadEnvDbCatalog(ctx context.Context, envID, serverID, catalogID string, o ...datatug.StoreOption) (datatug.DbCatalog, error) {
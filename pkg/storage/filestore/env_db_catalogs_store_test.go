@@ -58,13 +58,10 @@ func TestFsEnvCatalogStore(t *testing.T) {
 	})
 
 	t.Run("LoadEnvDbCatalogs", func(t *testing.T) {
-		// This uses a different path in the current implementation of LoadEnvDbCatalogs
-		// LoadEnvDbCatalogs uses s.getDirPath(envID) -> filepath.Join(s.dirPath, envID, EnvDbCatalogsFolder)
-		// But SaveEnvDbCatalog uses filepath.Join(s.dirPath, envID, ServersFolder, serverID, EnvDbCatalogsFolder)
-
+		// LoadEnvDbCatalogs collects catalogs saved by SaveEnvDbCatalog for all servers of the environment
 		items, err := store.LoadEnvDbCatalogs(ctx, envID)
 		assert.NoError(t, err)
-		assert.Empty(t, items)
+		assert.Equal(t, []string{catalogID}, items.IDs())
 	})
 
 	t.Run("SaveEnvDbCatalogs", func(t *testing.T) {
@@ -35,14 +35,27 @@ func (f fsEnvDbServersStore) LoadEnvDbServer(ctx context.Context, envID, serverI
 
 func (f fsEnvDbServersStore) SaveEnvServers(ctx context.Context, envID string, servers datatug.EnvDbServers) error {
 	dirPath := filepath.Join(f.dirPath, envID)
+	normalizeServerDrivers(servers)
 	return f.saveProjectItems(ctx, dirPath, servers)
 }
 
 func (f fsEnvDbServersStore) SaveEnvDbServer(ctx context.Context, envID string, server *datatug.EnvDbServer) error {
 	dirPath := filepath.Join(f.dirPath, envID)
+	if server != nil {
+		normalizeServerDrivers(datatug.EnvDbServers{server})
+	}
 	return f.saveProjectItem(ctx, dirPath, server)
 }
 
+// normalizeServerDrivers replaces aliases of drivers with registered names, so a server is stored under one name
+func normalizeServerDrivers(servers datatug.EnvDbServers) {
+	for _, server := range servers {
+		if server != nil {
+			server.Driver = datatug.DriverName(server.Driver)
+		}
+	}
+}
+
 func (f fsEnvDbServersStore) DeleteEnvDbServer(ctx context.Context, envID, serverID string) error {
 	dirPath := filepath.Join(f.dirPath, envID)
 	return f.deleteProjectItem(ctx, dirPath, serverID)
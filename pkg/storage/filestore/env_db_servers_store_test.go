@@ -72,6 +72,15 @@ func TestFsEnvServerStore(t *testing.T) {
 		assert.Len(t, servers, 2)
 	})
 
+	t.Run("driver_alias", func(t *testing.T) {
+		server := &datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "mssql", Host: "aliashost", Port: 1433}}
+		assert.NoError(t, store.SaveEnvDbServer(ctx, envID, server))
+		loadedServer, err := store.LoadEnvDbServer(ctx, envID, "aliashost:1433")
+		assert.NoError(t, err)
+		assert.Equal(t, "sqlserver", loadedServer.Driver)
+		assert.NoError(t, store.DeleteEnvDbServer(ctx, envID, "aliashost:1433"))
+	})
+
 	t.Run("DeleteEnvDbServer", func(t *testing.T) {
 		err := store.DeleteEnvDbServer(ctx, envID, "localhost:1433")
 		assert.NoError(t, err)
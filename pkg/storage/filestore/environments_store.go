@@ -2,7 +2,13 @@ package filestore
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
 	"path"
+	"slices"
 
 	"github.com/datatug/datatug-core/pkg/datatug"
 	"github.com/datatug/datatug-core/pkg/storage"
@@ -23,6 +29,11 @@ type fsEnvironmentsStore struct {
 }
 
 func (s fsEnvironmentsStore) SaveEnvironments(ctx context.Context, envs datatug.Environments) error {
+	for _, env := range envs {
+		if env != nil {
+			normalizeServerDrivers(env.DbServers)
+		}
+	}
 	return s.saveProjectItems(ctx, s.dirPath, envs)
 }
 
@@ -39,6 +50,9 @@ func (s fsEnvironmentsStore) LoadEnvironmentSummary(_ context.Context, id string
 }
 
 func (s fsEnvironmentsStore) SaveEnvironment(ctx context.Context, env *datatug.Environment) error {
+	if env != nil {
+		normalizeServerDrivers(env.DbServers)
+	}
 	return s.saveProjectItem(ctx, s.dirPath, env)
 }
 
@@ -46,6 +60,99 @@ func (s fsEnvironmentsStore) DeleteEnvironment(ctx context.Context, id string) e
 	return s.deleteProjectItem(ctx, s.dirPath, id)
 }
 
+// CloneEnvironment copies an environment with its servers & catalog snapshots of the servers to a new environment.
+// Hosts & ports of servers are changed by request.Rewrites, a partially cloned environment is removed on failure.
+func (s fsEnvironmentsStore) CloneEnvironment(ctx context.Context, request datatug.CloneEnvironmentRequest) (clone *datatug.Environment, err error) {
+	if err = request.Validate(); err != nil {
+		return nil, err
+	}
+	clonePath := path.Join(s.dirPath, request.ID)
+	if _, err = os.Stat(clonePath); err == nil {
+		return nil, fmt.Errorf("%w: %v", datatug.ErrEnvironmentAlreadyExists, request.ID)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	source, err := s.LoadEnvironment(ctx, request.SourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load environment %v: %w", request.SourceID, err)
+	}
+	projectPath := path.Dir(s.dirPath)
+	serversStore := newFsEnvDbServersStore(projectPath)
+	servers, err := serversStore.LoadEnvDbServers(ctx, request.SourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load servers of environment %v: %w", request.SourceID, err)
+	}
+	for _, server := range source.DbServers {
+		if servers.GetByServerRef(server.ServerRef) == nil {
+			servers = append(servers, server)
+		}
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(clonePath)
+		}
+	}()
+
+	clone = &datatug.Environment{ProjectItem: source.ProjectItem}
+	clone.ID = request.ID
+	clone.Revision = ""
+	if request.Title != "" {
+		clone.Title = request.Title
+	}
+	clone.Vars = maps.Clone(source.Vars)
+	clonedServers := make(datatug.EnvDbServers, len(servers))
+	for i, server := range servers {
+		clonedServers[i] = &datatug.EnvDbServer{
+			ServerRef: server.ServerRef,
+			Catalogs:  slices.Clone(server.Catalogs),
+			SSHTunnel: server.SSHTunnel,
+			Variables: datatug.Variables{Vars: maps.Clone(server.Vars)},
+		}
+		clonedServers[i].ServerRef, _ = request.Rewrites.Rewrite(server.ServerRef)
+	}
+	clone.DbServers = clonedServers
+	if err = s.SaveEnvironment(ctx, clone); err != nil {
+		return nil, fmt.Errorf("failed to save environment %v: %w", clone.ID, err)
+	}
+	if err = serversStore.SaveEnvServers(ctx, clone.ID, clonedServers); err != nil {
+		return nil, fmt.Errorf("failed to save servers of environment %v: %w", clone.ID, err)
+	}
+	// Catalog snapshots are kept in directories named by IDs of servers, both "host:port" & "driver:host:port" are used
+	serverDirNames := make(map[string]string, 2*len(servers))
+	for i, server := range servers {
+		serverDirNames[server.GetID()] = clonedServers[i].GetID()
+		serverDirNames[server.ServerRef.GetID()] = clonedServers[i].ServerRef.GetID()
+	}
+	catalogsStore := newFsEnvCatalogsStore(projectPath)
+	serversDirPath := catalogsStore.getServersDirPath(request.SourceID)
+	serverDirs, err := os.ReadDir(serversDirPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, serverDir := range serverDirs {
+		if !serverDir.IsDir() {
+			continue
+		}
+		var catalogs datatug.DbCatalogs
+		catalogsDirPath := path.Join(serversDirPath, serverDir.Name(), storage.EnvDbCatalogsFolder)
+		if catalogs, err = catalogsStore.loadProjectItems(ctx, catalogsDirPath); err != nil {
+			return nil, fmt.Errorf("failed to load catalogs of server %v: %w", serverDir.Name(), err)
+		}
+		for _, catalog := range catalogs {
+			catalog.Revision = ""
+		}
+		serverID, ok := serverDirNames[serverDir.Name()]
+		if !ok {
+			serverID = serverDir.Name()
+		}
+		if err = catalogsStore.SaveEnvDbCatalogs(ctx, clone.ID, serverID, "", catalogs); err != nil {
+			return nil, fmt.Errorf("failed to save catalogs of server %v: %w", serverID, err)
+		}
+	}
+	return clone, nil
+}
+
 //// LoadEnvironmentDbSummary return DB summary for specific environment
 //func (store fsEnvironmentStore) LoadEnvironmentDbSummary(databaseID string) (datatug.DbCatalogSummary, error) {
 //	panic(fmt.Sprintf("implement me: %v, %v, %v", store.projectID, store.envID, databaseID))
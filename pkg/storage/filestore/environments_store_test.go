@@ -9,6 +9,7 @@ import (
 	"github.com/datatug/datatug-core/pkg/datatug"
 	"github.com/datatug/datatug-core/pkg/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFsEnvironmentsStore(t *testing.T) {
@@ -28,6 +29,7 @@ func TestFsEnvironmentsStore(t *testing.T) {
 				Title: "Environment 1",
 			},
 		},
+		Variables: datatug.Variables{Vars: datatug.VarsByID{"schema": {Type: "str", Value: "stage"}}},
 	}
 
 	t.Run("SaveEnvironment", func(t *testing.T) {
@@ -45,6 +47,7 @@ func TestFsEnvironmentsStore(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, env1.ID, loadedEnv.ID)
 		assert.Equal(t, env1.Title, loadedEnv.Title)
+		assert.Equal(t, env1.Vars, loadedEnv.Vars)
 	})
 
 	t.Run("LoadEnvironmentSummary", func(t *testing.T) {
@@ -52,6 +55,7 @@ func TestFsEnvironmentsStore(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, summary)
 		assert.Equal(t, env1.ID, summary.ID)
+		assert.Equal(t, env1.Vars, summary.Vars)
 	})
 
 	t.Run("LoadEnvironments", func(t *testing.T) {
@@ -88,3 +92,96 @@ func TestFsEnvironmentsStore(t *testing.T) {
 		// but currently it seems it tries to delete a file.
 	})
 }
+
+func TestFsEnvironmentsStore_CloneEnvironment(t *testing.T) {
+	ctx := context.Background()
+	projectPath := t.TempDir()
+	store := newFsProjectStore("p1", projectPath)
+	require.NoError(t, store.SaveProject(ctx, newTestProject("p1")))
+
+	server := &datatug.EnvDbServer{
+		ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "dev-db", Port: 1433},
+		Catalogs:  []string{"sales"},
+		Variables: datatug.Variables{Vars: datatug.VarsByID{"schema": {Type: "str", Value: "dev"}}},
+	}
+	require.NoError(t, store.SaveEnvironment(ctx, &datatug.Environment{
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "dev", Title: "Development"}},
+		Variables:   datatug.Variables{Vars: datatug.VarsByID{"tenant": {Type: "int", Value: "1"}}},
+	}))
+	require.NoError(t, store.SaveEnvDbServer(ctx, "dev", server))
+	catalog := &datatug.DbCatalog{DbCatalogBase: datatug.DbCatalogBase{
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "sales", Title: "Sales"}},
+		Driver:      "sqlserver",
+	}}
+	require.NoError(t, store.SaveEnvDbCatalog(ctx, "dev", server.ServerRef.GetID(), "sales", catalog))
+
+	clone, err := store.CloneEnvironment(ctx, datatug.CloneEnvironmentRequest{
+		SourceID: "dev", ID: "stage", Title: "Stage",
+		Rewrites: datatug.ServerRewrites{{FromHost: "dev-db", ToHost: "stage-db"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Stage", clone.Title)
+	assert.Equal(t, datatug.VarsByID{"tenant": {Type: "int", Value: "1"}}, clone.Vars)
+
+	servers, err := store.LoadEnvDbServers(ctx, "stage")
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, datatug.ServerRef{Driver: "sqlserver", Host: "stage-db", Port: 1433}, servers[0].ServerRef)
+	assert.Equal(t, []string{"sales"}, servers[0].Catalogs)
+	assert.Equal(t, server.Vars, servers[0].Vars)
+
+	loadedCatalog, err := store.LoadEnvDbCatalog(ctx, "stage", "sqlserver:stage-db:1433", "sales")
+	require.NoError(t, err)
+	assert.Equal(t, "Sales", loadedCatalog.Title)
+
+	t.Run("failed_clone_is_removed", func(t *testing.T) {
+		writeTestFile(t, projectPath, path.Join(storage.EnvironmentsFolder, "dev", storage.ServersFolder, "broken", storage.EnvDbCatalogsFolder, "x."+storage.DbCatalogFileSuffix+".json"), "{")
+		_, err := store.CloneEnvironment(ctx, datatug.CloneEnvironmentRequest{SourceID: "dev", ID: "qa"})
+		assert.Error(t, err)
+		assert.NoDirExists(t, path.Join(projectPath, storage.EnvironmentsFolder, "qa"))
+	})
+}
+
+func TestPromoteTargets(t *testing.T) {
+	ctx := context.Background()
+	projectPath := t.TempDir()
+	store := newFsProjectStore("p1", projectPath)
+	project := newTestProject("p1")
+	project.SecretsPolicy = datatug.SecretsPolicyAllow
+	require.NoError(t, store.SaveProject(ctx, project))
+	for envID, host := range map[string]string{"dev": "dev-db", "stage": "stage-db"} {
+		require.NoError(t, store.SaveEnvironment(ctx, &datatug.Environment{
+			ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: envID, Title: envID}},
+		}))
+		require.NoError(t, store.SaveEnvDbServer(ctx, envID, &datatug.EnvDbServer{
+			ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: host, Port: 1433},
+		}))
+	}
+	require.NoError(t, store.SaveQuery(ctx, &datatug.QueryDefWithFolderPath{FolderPath: "~", QueryDef: datatug.QueryDef{
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "q1", Title: "Q1"}},
+		Type:        datatug.QueryTypeSQL,
+		Text:        "SELECT 1",
+		Targets:     []datatug.QueryDefTarget{{Driver: "sqlserver", Host: "dev-db", Port: 1433, Catalog: "sales", CredentialsID: "dev"}},
+	}}))
+	request := datatug.PromoteTargetsRequest{FromEnvID: "dev", ToEnvID: "stage", QueryIDs: []string{"q1"}, DryRun: true}
+	expected := datatug.QueryDefTarget{Driver: "sqlserver", Host: "stage-db", Port: 1433, Catalog: "sales"}
+
+	promotions, err := datatug.PromoteTargets(ctx, store, request)
+	require.NoError(t, err)
+	require.Len(t, promotions, 1)
+	assert.Equal(t, datatug.ChangeTypeAdded, promotions[0].Change)
+	assert.Equal(t, expected, promotions[0].Target)
+	query, err := store.LoadQuery(ctx, "q1")
+	require.NoError(t, err)
+	assert.Len(t, query.Targets, 1, "dry run should not change the query")
+
+	request.DryRun = false
+	_, err = datatug.PromoteTargets(ctx, store, request)
+	require.NoError(t, err)
+	query, err = store.LoadQuery(ctx, "q1")
+	require.NoError(t, err)
+	assert.Equal(t, []datatug.QueryDefTarget{
+		{Driver: "sqlserver", Host: "dev-db", Port: 1433, Catalog: "sales", CredentialsID: "dev"},
+		expected,
+	}, query.Targets)
+}
@@ -0,0 +1,271 @@
+package filestore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dtconfig"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// Project item files are read in any supported format and written in the format set by
+// datatug.ProjectFile.ItemsFormat. YAML is converted from & to JSON, so JSON tags of items
+// are the only mapping used for both formats and the conversion is lossless.
+
+const (
+	jsonFileExt = ".json"
+	yamlFileExt = ".yaml"
+	ymlFileExt  = ".yml"
+)
+
+// itemFileExts lists extensions of item files in order of precedence when an item exists in several formats
+var itemFileExts = []string{jsonFileExt, yamlFileExt, ymlFileExt}
+
+// ItemFileNames returns names of files an item can be stored in, in order of precedence,
+// e.g. "q1.query.json", "q1.query.yaml", "q1.query.yml" & "q1.query.sql" for a query
+func ItemFileNames(id, itemFileSuffix string) []string {
+	baseName := strings.TrimSuffix(storage.JsonFileName(id, itemFileSuffix), jsonFileExt)
+	names := make([]string, 0, len(itemFileExts)+1)
+	for _, ext := range itemFileExts {
+		names = append(names, baseName+ext)
+	}
+	if itemFileSuffix == storage.QueryFileSuffix {
+		names = append(names, baseName+sqlFileExt)
+	}
+	return names
+}
+
+func itemFileExt(format dtconfig.Format) string {
+	if format == dtconfig.FormatYaml {
+		return yamlFileExt
+	}
+	return jsonFileExt
+}
+
+// fileFormat returns format of an item file by its extension
+func fileFormat(fileName string) (format dtconfig.Format, ok bool) {
+	switch path.Ext(fileName) {
+	case jsonFileExt:
+		return dtconfig.FormatJson, true
+	case yamlFileExt, ymlFileExt:
+		return dtconfig.FormatYaml, true
+	default:
+		return "", false
+	}
+}
+
+// loadProjectSettings returns a project file that defines how items are stored,
+// defaults are returned for a project without a project file
+func loadProjectSettings(projectPath string) (projFile datatug.ProjectFile, err error) {
+	if projFile, err = cachedProjectFile(projectPath); err != nil && errors.Is(err, datatug.ErrProjectDoesNotExist) {
+		err = nil
+	}
+	if projFile.ItemsFormat == "" {
+		projFile.ItemsFormat = dtconfig.FormatJson
+	}
+	return projFile, err
+}
+
+// projectFiles caches project files read on each write of an item, e.g. for a secrets policy & items format.
+// A cached file is used while its size & modification time are unchanged, SaveProject refreshes it.
+var projectFiles = struct {
+	sync.Mutex
+	byPath map[string]projectFileCacheEntry
+}{byPath: make(map[string]projectFileCacheEntry)}
+
+type projectFileCacheEntry struct {
+	size     int64
+	modTime  time.Time
+	projFile datatug.ProjectFile
+}
+
+// cachedProjectFile returns a project file like LoadProjectFile does, reading it only if it has changed.
+// It returns a deep copy of the cached file, so a caller can modify its variables & other fields.
+func cachedProjectFile(projectPath string) (projFile datatug.ProjectFile, err error) {
+	info, err := os.Stat(path.Join(projectPath, storage.ProjectSummaryFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return projFile, fmt.Errorf("%w: %v", datatug.ErrProjectDoesNotExist, err)
+		}
+		return projFile, err
+	}
+	key := filepath.Clean(projectPath)
+	projectFiles.Lock()
+	cached, ok := projectFiles.byPath[key]
+	projectFiles.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return copyProjectFile(cached.projFile)
+	}
+	// The file is stat-ed before reading, so a change made in between is detected by the next call
+	if projFile, err = LoadProjectFile(projectPath); err != nil {
+		return projFile, err
+	}
+	projectFiles.Lock()
+	projectFiles.byPath[key] = projectFileCacheEntry{size: info.Size(), modTime: info.ModTime(), projFile: projFile}
+	projectFiles.Unlock()
+	return copyProjectFile(projFile)
+}
+
+// copyProjectFile makes a deep copy of a project file by a JSON round trip, the format it's stored in
+func copyProjectFile(projFile datatug.ProjectFile) (projFileCopy datatug.ProjectFile, err error) {
+	data, err := json.Marshal(projFile)
+	if err != nil {
+		return projFileCopy, fmt.Errorf("failed to copy project file: %w", err)
+	}
+	if err = json.Unmarshal(data, &projFileCopy); err != nil {
+		return projFileCopy, fmt.Errorf("failed to copy project file: %w", err)
+	}
+	return projFileCopy, nil
+}
+
+// refreshProjectFile drops a cached project file, so it's read by the next write of an item
+func refreshProjectFile(projectPath string) {
+	projectFiles.Lock()
+	delete(projectFiles.byPath, filepath.Clean(projectPath))
+	projectFiles.Unlock()
+}
+
+func encodeItem(format dtconfig.Format, v any) ([]byte, error) {
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode %T to JSON: %w", v, err)
+	}
+	switch format {
+	case dtconfig.FormatJson:
+		return content.Bytes(), nil
+	case dtconfig.FormatYaml:
+		return jsonToYAML(content.Bytes())
+	default:
+		return nil, fmt.Errorf("unsupported items format: %v", format)
+	}
+}
+
+func decodeItem(format dtconfig.Format, content []byte, v any) (err error) {
+	switch format {
+	case dtconfig.FormatJson:
+	case dtconfig.FormatYaml:
+		if content, err = yamlToJSON(content); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported items format: %v", format)
+	}
+	return json.Unmarshal(content, v)
+}
+
+// jsonToYAML re-encodes JSON in block style keeping order of properties,
+// multi-line strings are written as literal blocks, e.g. SQL text of queries.
+func jsonToYAML(content []byte) ([]byte, error) {
+	node, err := jsonToYAMLNode(content)
+	if err != nil {
+		return nil, err
+	}
+	return encodeYAMLNode(node)
+}
+
+func jsonToYAMLNode(content []byte) (*yaml.Node, error) {
+	node := new(yaml.Node)
+	if err := yaml.Unmarshal(content, node); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	resetYAMLStyle(node)
+	return node, nil
+}
+
+func encodeYAMLNode(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetYAMLStyle drops flow & quoted styles of nodes parsed from JSON, the encoder still quotes
+// strings that would be read back as values of other types, e.g. "true" or "1".
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+func yamlToJSON(content []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(content, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if v == nil { // an empty document
+		return []byte("null"), nil
+	}
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert YAML to JSON: %w", err)
+	}
+	return content, nil
+}
+
+// readItemFile decodes an item file in a format defined by the file extension & returns the raw content
+func readItemFile(filePath string, v any) (content []byte, err error) {
+	format, ok := fileFormat(filePath)
+	if !ok {
+		return nil, fmt.Errorf("unsupported item file extension: %s", filePath)
+	}
+	if content, err = os.ReadFile(filePath); err != nil {
+		return nil, err
+	}
+	if err = decodeItem(format, content, v); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// saveItemFile validates & writes an item in a format defined by the file extension
+func saveItemFile(dirPath, fileName string, v interface{ Validate() error }) (err error) {
+	format, ok := fileFormat(fileName)
+	if !ok {
+		return fmt.Errorf("unsupported item file extension: %s", fileName)
+	}
+	if err = v.Validate(); err != nil {
+		return fmt.Errorf("an attempt to save invalid data %T: %w", v, err)
+	}
+	if err = os.MkdirAll(dirPath, 0777); err != nil {
+		return fmt.Errorf("failed to create folder %s: %w", dirPath, err)
+	}
+	filePath := path.Join(dirPath, fileName)
+	redacted, err := redactSecrets(filePath, v)
+	if err != nil {
+		return err
+	}
+	// Encode before touching the file, so an encoding failure does not leave a truncated file
+	content, err := encodeItem(format, redacted)
+	if err != nil {
+		return err
+	}
+	return writeBytesAtomically(filePath, content)
+}
+
+// itemBaseName returns a file name without an extension of a supported format
+func itemBaseName(fileName string) (baseName string, ok bool) {
+	if _, ok = fileFormat(fileName); !ok {
+		return "", false
+	}
+	return strings.TrimSuffix(fileName, path.Ext(fileName)), true
+}
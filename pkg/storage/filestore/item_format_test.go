@@ -0,0 +1,224 @@
+package filestore
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dtconfig"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQuery(id string) *datatug.QueryDef {
+	return &datatug.QueryDef{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: "Orders: \"open\" & pending"},
+			UserIDs:       []string{"true", "1", "null", "~", "yes", "2020-01-02T03:04:05Z"},
+		},
+		Type: datatug.QueryTypeSQL,
+		Text: "SELECT *\n  FROM orders\n\tWHERE status = 'open' -- trailing space \nORDER BY id\n",
+		Parameters: datatug.Parameters{
+			{ID: "limit", Type: "number", DefaultValue: float64(10)},
+			{ID: "ratio", Type: "number", DefaultValue: 0.5},
+			{ID: "name", Type: "string", DefaultValue: "# not a comment: ünïcode"},
+			{ID: "empty", Type: "string", DefaultValue: ""},
+			{ID: "multi_line", Type: "string", Title: "line 1\nline 2"},
+		},
+		Targets: []datatug.QueryDefTarget{{Driver: "sqlite3", Catalog: "orders", Port: 5432}},
+	}
+}
+
+func TestItemFormat_RoundTrip(t *testing.T) {
+	for _, format := range []dtconfig.Format{dtconfig.FormatJson, dtconfig.FormatYaml} {
+		t.Run(string(format), func(t *testing.T) {
+			query := newTestQuery("q1")
+			content, err := encodeItem(format, query)
+			require.NoError(t, err)
+
+			var decoded datatug.QueryDef
+			require.NoError(t, decodeItem(format, content, &decoded))
+			assert.Equal(t, *query, decoded)
+
+			reEncoded, err := encodeItem(format, &decoded)
+			require.NoError(t, err)
+			assert.Equal(t, string(content), string(reEncoded), "encoding should be stable")
+		})
+	}
+	t.Run("yaml_is_readable", func(t *testing.T) {
+		content, err := encodeItem(dtconfig.FormatYaml, newTestQuery("q1"))
+		require.NoError(t, err)
+		yaml := string(content)
+		assert.True(t, strings.HasPrefix(yaml, "id: q1\n"), "properties should keep order of JSON: %s", yaml)
+		assert.Contains(t, yaml, "userIds:\n  - \"true\"\n", "strings that look like other types should be quoted")
+		assert.NotContains(t, yaml, "{", "flow style should not be used")
+	})
+	t.Run("literal_block", func(t *testing.T) {
+		query := newTestQuery("q1")
+		query.Text = "SELECT *\nFROM orders\nWHERE id = @id"
+		content, err := encodeItem(dtconfig.FormatYaml, query)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "text: |-\n  SELECT *\n  FROM orders\n  WHERE id = @id\n")
+	})
+	t.Run("invalid_yaml", func(t *testing.T) {
+		var query datatug.QueryDef
+		assert.Error(t, decodeItem(dtconfig.FormatYaml, []byte("id: [q1"), &query))
+	})
+	t.Run("unsupported_format", func(t *testing.T) {
+		_, err := encodeItem("xml", newTestQuery("q1"))
+		assert.Error(t, err)
+	})
+}
+
+func writeTestProjectFile(t *testing.T, projectPath string, itemsFormat dtconfig.Format) {
+	t.Helper()
+	require.NoError(t, saveJSONFile(projectPath, storage.ProjectSummaryFileName, datatug.ProjectFile{
+		FormatVersion: datatug.ProjectFormatVersion,
+		Created:       &datatug.ProjectCreated{At: newTestProject("p1").Created.At},
+		ProjectItem:   datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "p1"}, Access: "private"},
+		ItemsFormat:   itemsFormat,
+	}))
+}
+
+func TestCachedProjectFile(t *testing.T) {
+	projectPath := t.TempDir()
+	_, err := cachedProjectFile(projectPath)
+	assert.ErrorIs(t, err, datatug.ErrProjectDoesNotExist)
+
+	filePath := path.Join(projectPath, storage.ProjectSummaryFileName)
+	writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"id":"p1","secretsPolicy":"strip"}`)
+	projFile, err := cachedProjectFile(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, datatug.SecretsPolicyStrip, projFile.SecretsPolicy)
+	info, err := os.Stat(filePath)
+	require.NoError(t, err)
+
+	// Same size & modification time, so the cached file is used
+	writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"id":"p1","secretsPolicy":"allow"}`)
+	require.NoError(t, os.Chtimes(filePath, info.ModTime(), info.ModTime()))
+	projFile, err = cachedProjectFile(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, datatug.SecretsPolicyStrip, projFile.SecretsPolicy)
+
+	refreshProjectFile(projectPath)
+	projFile, err = cachedProjectFile(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, datatug.SecretsPolicyAllow, projFile.SecretsPolicy)
+
+	writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"id":"p1"}`)
+	projFile, err = cachedProjectFile(projectPath)
+	require.NoError(t, err)
+	assert.Empty(t, projFile.SecretsPolicy, "a changed file should be read")
+
+	t.Run("copy", func(t *testing.T) {
+		writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"id":"p1","vars":{"host":{"value":"localhost"}},"varSettings":{"host":{"type":"string"}},"tags":["t1"]}`)
+		projFile, err := cachedProjectFile(projectPath)
+		require.NoError(t, err)
+		projFile.Vars["host"] = datatug.VarInfo{Value: "changed"}
+		projFile.VarSettings["port"] = datatug.VarSetting{Type: "int"}
+		projFile.Tags[0] = "changed"
+		projFile, err = cachedProjectFile(projectPath)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]datatug.VarInfo{"host": {Value: "localhost"}}, projFile.Vars)
+		assert.Equal(t, datatug.VarSettings{"host": {Type: "string"}}, projFile.VarSettings)
+		assert.Equal(t, []string{"t1"}, projFile.Tags)
+	})
+}
+
+func TestFsProjectStore_ItemsFormat(t *testing.T) {
+	ctx := context.Background()
+	board := func(id string) *datatug.Board {
+		return &datatug.Board{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: "Board " + id}}}
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		projectPath := t.TempDir()
+		writeTestProjectFile(t, projectPath, dtconfig.FormatYaml)
+		store := newFsProjectStore("p1", projectPath)
+
+		query := &datatug.QueryDefWithFolderPath{FolderPath: "~", QueryDef: *newTestQuery("q1")}
+		require.NoError(t, store.SaveQuery(ctx, query))
+		assert.FileExists(t, path.Join(projectPath, storage.QueriesFolder, "q1.query.yaml"))
+		assert.NoFileExists(t, path.Join(projectPath, storage.QueriesFolder, "q1.query.json"))
+
+		loaded, err := store.LoadQuery(ctx, "q1")
+		require.NoError(t, err)
+		assert.NotEmpty(t, loaded.Revision)
+		assert.Equal(t, query.Revision, loaded.Revision)
+		loaded.Revision = ""
+		query.Revision = ""
+		assert.Equal(t, query.QueryDef, *loaded)
+
+		recordset := &datatug.RecordsetDefinition{
+			ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "rs1", Title: "Recordset 1"}},
+			Type:        "recordset",
+			Columns:     datatug.RecordsetColumnDefs{{Name: "id", Type: "int", Required: true}},
+		}
+		require.NoError(t, store.fsRecordsetDefinitionsStore.saveProjectItem(ctx, store.fsRecordsetDefinitionsStore.dirPath, recordset))
+		loadedRecordset, err := store.LoadRecordsetDefinition(ctx, "rs1")
+		require.NoError(t, err)
+		assert.Equal(t, recordset, loadedRecordset)
+		assert.FileExists(t, path.Join(projectPath, storage.RecordsetsFolder, "rs1.recordset.yaml"))
+
+		t.Run("hand_edited", func(t *testing.T) {
+			writeTestFile(t, projectPath, path.Join(storage.BoardsFolder, "b1.board.yml"), "id: b1\ntitle: Sales # edited by hand\n")
+			boards, err := store.LoadBoards(ctx)
+			require.NoError(t, err)
+			require.Len(t, boards, 1)
+			assert.Equal(t, "Sales", boards[0].Title)
+		})
+
+		t.Run("delete", func(t *testing.T) {
+			require.NoError(t, store.DeleteQuery(ctx, "q1"))
+			assert.NoFileExists(t, path.Join(projectPath, storage.QueriesFolder, "q1.query.yaml"))
+		})
+	})
+
+	t.Run("conversion", func(t *testing.T) {
+		projectPath := t.TempDir()
+		writeTestProjectFile(t, projectPath, "")
+		store := newFsProjectStore("p1", projectPath)
+		require.NoError(t, store.SaveBoard(ctx, board("b1")))
+		require.NoError(t, store.SaveBoard(ctx, board("b2")))
+		assert.FileExists(t, path.Join(projectPath, storage.BoardsFolder, "b1.board.json"))
+
+		writeTestProjectFile(t, projectPath, dtconfig.FormatYaml)
+		b1, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err, "items in a format other than configured should be read")
+		b1.Title = "Converted"
+		require.NoError(t, store.SaveBoard(ctx, b1), "revision of a JSON file should be accepted when saving as YAML")
+		assert.FileExists(t, path.Join(projectPath, storage.BoardsFolder, "b1.board.yaml"))
+		assert.NoFileExists(t, path.Join(projectPath, storage.BoardsFolder, "b1.board.json"))
+
+		boards, err := store.LoadBoards(ctx)
+		require.NoError(t, err)
+		require.Len(t, boards, 2)
+		assert.Equal(t, "Converted", boards[0].Title)
+		assert.Equal(t, "Board b2", boards[1].Title)
+
+		t.Run("both_formats", func(t *testing.T) {
+			writeTestFile(t, projectPath, path.Join(storage.BoardsFolder, "b1.board.json"), `{"id":"b1","title":"Stale"}`)
+			boards, err := store.LoadBoards(ctx)
+			require.NoError(t, err)
+			require.Len(t, boards, 2, "an item stored in several formats should be listed once")
+			assert.Equal(t, "Converted", boards[0].Title, "the file in the configured format should be read")
+		})
+	})
+
+	t.Run("project_file_keeps_format", func(t *testing.T) {
+		projectPath := t.TempDir()
+		project := newTestProject("p1")
+		project.ItemsFormat = dtconfig.FormatYaml
+		project.Boards = datatug.Boards{board("b1")}
+		require.NoError(t, newFsProjectStore("p1", projectPath).SaveProject(ctx, project))
+		projFile, err := LoadProjectFile(projectPath)
+		require.NoError(t, err)
+		assert.Equal(t, dtconfig.FormatYaml, projFile.ItemsFormat)
+		_, err = os.Stat(path.Join(projectPath, storage.BoardsFolder, "b1.board.yaml"))
+		assert.NoError(t, err)
+	})
+}
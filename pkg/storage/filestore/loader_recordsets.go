@@ -1,8 +1,10 @@
 package filestore
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -113,38 +115,56 @@ func (loader fileSystemLoader) LoadRecordsetData(projectID, datasetName, fileNam
 		return nil, err
 	}
 	filePath := path.Join(projPath, storage.DataFolder, datasetName, fileName)
-	var recordset datatug.Recordset
-	rows := make([]interface{}, 0)
-	if err := readJSONFile(filePath, true, &rows); err != nil {
-		return nil, err
+	recordset := datatug.Recordset{Columns: recordsetColumns(datasetDef)}
+	if recordset.Rows, err = readRecordsetRows(filePath, recordset.Columns); err != nil {
+		return &recordset, err
 	}
+	recordset.Duration = time.Since(started)
+	return &recordset, nil
+}
 
-	recordset.Columns = make([]datatug.RecordsetColumn, len(datasetDef.Columns))
-	for i, field := range datasetDef.Columns {
-		recordset.Columns[i] = datatug.RecordsetColumn{
+// recordsetColumns returns columns of a recordset by its definition
+func recordsetColumns(def *datatug.RecordsetDefinition) []datatug.RecordsetColumn {
+	columns := make([]datatug.RecordsetColumn, len(def.Columns))
+	for i, field := range def.Columns {
+		columns[i] = datatug.RecordsetColumn{
 			Name:   field.Name,
 			DbType: field.Type,
 			Meta:   field.Meta,
 		}
 	}
+	return columns
+}
 
-	recordset.Rows = make([][]interface{}, 0, len(rows))
+// readRecordsetRows reads rows stored as JSON objects & orders values by columns
+func readRecordsetRows(filePath string, columns []datatug.RecordsetColumn) ([][]interface{}, error) {
+	rows := make([]interface{}, 0)
+	err := readFile(filePath, true, &rows, func(r io.Reader) Decoder {
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber() // see datatug.JSONNumberValue
+		return decoder
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]interface{}, 0, len(rows))
 	for i, row := range rows {
 		valuesByName, ok := row.(map[string]interface{})
 		if !ok {
-			return &recordset, fmt.Errorf("unexpected row type at index=%v: %T", i, row)
+			return result, fmt.Errorf("unexpected row type at index=%v: %T", i, row)
 		}
-		values := make([]interface{}, len(recordset.Columns))
-		for i, col := range recordset.Columns {
+		values := make([]interface{}, len(columns))
+		for i, col := range columns {
 			if value, ok := valuesByName[col.Name]; ok {
+				if n, isNumber := value.(json.Number); isNumber {
+					value = datatug.JSONNumberValue(n)
+				}
 				values[i] = value
 			} else {
 				_, _ = fmt.Printf("\t%v: %+v\n", col.Name, nil)
 			}
 		}
-		recordset.Rows = append(recordset.Rows, values)
+		result = append(result, values)
 	}
-
-	recordset.Duration = time.Since(started)
-	return &recordset, nil
+	return result, nil
 }
@@ -28,6 +28,7 @@ func TestLoader(t *testing.T) {
 
 	// Create project file
 	projFile := datatug.ProjectFile{
+		FormatVersion: datatug.ProjectFormatVersion,
 		ProjectItem: datatug.ProjectItem{
 			ProjItemBrief: datatug.ProjItemBrief{
 				ID:    projID,
@@ -0,0 +1,346 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/credentials"
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+)
+
+// ProjectMigration upgrades files of a project from FromVersion to FromVersion+1
+type ProjectMigration struct {
+	FromVersion int
+	Description string
+	// Migrate changes files in a project directory and returns human-readable descriptions of made changes
+	Migrate func(projectPath string, opts MigrateOptions) (changes []string, err error)
+}
+
+var projectMigrations = struct {
+	sync.RWMutex
+	byVersion map[int]ProjectMigration
+}{byVersion: make(map[int]ProjectMigration)}
+
+// RegisterProjectMigration adds a migration step to the registry, there can be only 1 step per version
+func RegisterProjectMigration(m ProjectMigration) {
+	if m.Migrate == nil {
+		panic("migration has no Migrate func")
+	}
+	projectMigrations.Lock()
+	defer projectMigrations.Unlock()
+	if _, ok := projectMigrations.byVersion[m.FromVersion]; ok {
+		panic(fmt.Sprintf("duplicate migration from project format version %v", m.FromVersion))
+	}
+	projectMigrations.byVersion[m.FromVersion] = m
+}
+
+func init() {
+	RegisterProjectMigration(ProjectMigration{
+		FromVersion: 0,
+		Description: "split legacy environment servers files holding lists of servers into a file per server",
+		Migrate:     migrateLegacyEnvServers,
+	})
+	RegisterProjectMigration(ProjectMigration{
+		FromVersion: 1,
+		Description: "strip passwords from query targets, targets reference credentials by ID",
+		Migrate:     migrateQueryTargetCredentials,
+	})
+}
+
+// MigrateOptions defines how MigrateProject runs
+type MigrateOptions struct {
+	// DryRun reports changes without modifying the project, migration steps run on a temporary copy
+	DryRun bool
+	// Backup copies the project directory to a "<dir>.backup-v<version>-<timestamp>" directory in BackupDir before migrating.
+	// The backup is kept outside the project's repository as it holds passwords that a migration moves to Credentials.
+	Backup bool
+	// BackupDir is where backups are created, defaults to "datatug/backups" in the user config directory
+	BackupDir string
+	// Credentials receives passwords stripped from project files,
+	// a migration that strips passwords fails if it's not set, so the passwords are not lost
+	Credentials credentials.Store
+}
+
+// MigrationStepResult describes changes made by a migration step
+type MigrationStepResult struct {
+	FromVersion int      `json:"fromVersion"`
+	Description string   `json:"description"`
+	Changes     []string `json:"changes,omitempty"`
+}
+
+// MigrationResult describes a migrated project
+type MigrationResult struct {
+	FromVersion int                   `json:"fromVersion"`
+	ToVersion   int                   `json:"toVersion"`
+	DryRun      bool                  `json:"dryRun,omitempty"`
+	BackupPath  string                `json:"backupPath,omitempty"`
+	Steps       []MigrationStepResult `json:"steps,omitempty"`
+}
+
+// ReadProjectFormatVersion returns format version recorded in a project file
+func ReadProjectFormatVersion(projectPath string) (int, error) {
+	projFile, err := LoadProjectFile(projectPath)
+	if err != nil {
+		return 0, err
+	}
+	return projFile.FormatVersion, nil
+}
+
+// userConfigDir is replaced by tests, so backups are not written to the user config directory
+var userConfigDir = os.UserConfigDir
+
+func (v MigrateOptions) backupDir() (string, error) {
+	if v.BackupDir != "" {
+		return v.BackupDir, nil
+	}
+	configDir, err := userConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory for a backup: %w", err)
+	}
+	return filepath.Join(configDir, "datatug", "backups"), nil
+}
+
+// MigrateProject upgrades files of a project to datatug.ProjectFormatVersion in place.
+// The version in the project file is updated after each step, so an interrupted migration resumes from the failed step.
+// The project is locked for saves while it's migrated.
+func MigrateProject(projectPath string, opts MigrateOptions) (result *MigrationResult, err error) {
+	lock, err := lockProject(context.Background(), projectPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if unlockErr := lock.unlock(); unlockErr != nil && err == nil {
+			err = unlockErr
+		}
+	}()
+	version, err := ReadProjectFormatVersion(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if version > datatug.ProjectFormatVersion {
+		return nil, fmt.Errorf("%w: format version %v, max supported is %v", storage.ErrProjectFormatNotSupported, version, datatug.ProjectFormatVersion)
+	}
+	result = &MigrationResult{FromVersion: version, ToVersion: version, DryRun: opts.DryRun}
+	steps, err := pendingMigrations(version)
+	if err != nil || len(steps) == 0 {
+		return result, err
+	}
+	if opts.DryRun {
+		tmpDir, err := os.MkdirTemp("", "datatug-migration-")
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = os.RemoveAll(tmpDir)
+		}()
+		projectCopy := filepath.Join(tmpDir, filepath.Base(projectPath))
+		if err = copyPath(projectPath, projectCopy); err != nil {
+			return nil, fmt.Errorf("failed to copy project for a dry run: %w", err)
+		}
+		projectPath = projectCopy
+	} else if opts.Backup {
+		backupDir, err := opts.backupDir()
+		if err != nil {
+			return nil, err
+		}
+		if err = os.MkdirAll(backupDir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create backups directory: %w", err)
+		}
+		name := filepath.Base(filepath.Clean(projectPath))
+		result.BackupPath = filepath.Join(backupDir, fmt.Sprintf("%s.backup-v%d-%s", name, version, time.Now().UTC().Format("20060102T150405.000000000")))
+		if err = copyPath(projectPath, result.BackupPath); err != nil {
+			return nil, fmt.Errorf("failed to backup project: %w", err)
+		}
+	}
+	for _, step := range steps {
+		changes, err := step.Migrate(projectPath, opts)
+		if err != nil {
+			return result, fmt.Errorf("failed to migrate project from format version %v: %w", step.FromVersion, err)
+		}
+		if err = setProjectFormatVersion(projectPath, step.FromVersion+1); err != nil {
+			return result, err
+		}
+		result.ToVersion = step.FromVersion + 1
+		result.Steps = append(result.Steps, MigrationStepResult{
+			FromVersion: step.FromVersion,
+			Description: step.Description,
+			Changes:     changes,
+		})
+	}
+	return result, nil
+}
+
+func pendingMigrations(version int) (steps []ProjectMigration, err error) {
+	projectMigrations.RLock()
+	defer projectMigrations.RUnlock()
+	for v := version; v < datatug.ProjectFormatVersion; v++ {
+		step, ok := projectMigrations.byVersion[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration registered from project format version %v", v)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// setProjectFormatVersion updates version in a project file keeping properties unknown to datatug.ProjectFile
+func setProjectFormatVersion(projectPath string, version int) error {
+	filePath := filepath.Join(projectPath, storage.ProjectSummaryFileName)
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	projFile := make(map[string]any)
+	if err = json.Unmarshal(content, &projFile); err != nil {
+		return fmt.Errorf("failed to parse project file: %w", err)
+	}
+	projFile["formatVersion"] = version
+	if content, err = json.MarshalIndent(projFile, "", "\t"); err != nil {
+		return err
+	}
+	defer refreshProjectFile(projectPath)
+	if err = writeBytesAtomically(filePath, content); err != nil {
+		return fmt.Errorf("failed to update format version in project file: %w", err)
+	}
+	return nil
+}
+
+// autoMigrated holds paths of projects opened by stores with the AutoMigrate option,
+// so item stores of such projects migrate them on save as well
+var autoMigrated = struct {
+	sync.Mutex
+	byPath map[string]*MigrateOptions
+}{byPath: make(map[string]*MigrateOptions)}
+
+func setAutoMigrate(projectPath string, opts *MigrateOptions) {
+	autoMigrated.Lock()
+	autoMigrated.byPath[filepath.Clean(projectPath)] = opts
+	autoMigrated.Unlock()
+}
+
+// autoMigrateOptions returns options to migrate a project on save of an item or nil if it's not migrated automatically
+func autoMigrateOptions(projectPath string) *MigrateOptions {
+	autoMigrated.Lock()
+	defer autoMigrated.Unlock()
+	return autoMigrated.byPath[filepath.Clean(projectPath)]
+}
+
+// checkFormatVersion returns an error if a project should be migrated before use or
+// migrates it if the store is configured to do so. A project without a project file is considered up to date.
+func (s fsProjectStore) checkFormatVersion() error {
+	return checkProjectFormatVersion(s.projectPath, s.projectID, s.autoMigrate)
+}
+
+// checkItemFormatVersion checks format version of a project an item is saved to, see checkFormatVersion.
+// Items saved outside a project are not checked.
+func checkItemFormatVersion(dirPath string) error {
+	projectPath, ok := findProjectPath(dirPath)
+	if !ok {
+		return nil
+	}
+	return checkProjectFormatVersion(projectPath, filepath.Base(projectPath), autoMigrateOptions(projectPath))
+}
+
+// checkProjectFormatVersion migrates an outdated project if autoMigrate is set, otherwise returns an error
+func checkProjectFormatVersion(projectPath, projectID string, autoMigrate *MigrateOptions) error {
+	projFile, err := cachedProjectFile(projectPath)
+	version := projFile.FormatVersion
+	if err != nil {
+		if errors.Is(err, datatug.ErrProjectDoesNotExist) {
+			return nil
+		}
+		return err
+	}
+	switch {
+	case version > datatug.ProjectFormatVersion:
+		return fmt.Errorf("%w: project %v has format version %v, max supported is %v",
+			storage.ErrProjectFormatNotSupported, projectID, version, datatug.ProjectFormatVersion)
+	case version < datatug.ProjectFormatVersion:
+		if autoMigrate == nil {
+			return storage.NewOutdatedProjectError(projectID, version, datatug.ProjectFormatVersion)
+		}
+		// Items are saved in parallel, a migration of the project is done once & others wait for it
+		migrating.Lock()
+		defer migrating.Unlock()
+		if _, err = MigrateProject(projectPath, *autoMigrate); err != nil {
+			return fmt.Errorf("failed to migrate project %v: %w", projectID, err)
+		}
+	}
+	return nil
+}
+
+var migrating sync.Mutex
+
+// migrateLegacyEnvServers moves servers from "environments/{env}/servers/dbs/{host}.server.json" files
+// that hold lists of servers to "environments/{env}/{host}:{port}.server.json" files read by fsEnvDbServersStore
+func migrateLegacyEnvServers(projectPath string, _ MigrateOptions) (changes []string, err error) {
+	envsDirPath := path.Join(projectPath, storage.EnvironmentsFolder)
+	envDirs, err := os.ReadDir(envsDirPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, envDir := range envDirs {
+		if !envDir.IsDir() {
+			continue
+		}
+		envPath := path.Join(envsDirPath, envDir.Name())
+		legacyDirPath := path.Join(envPath, storage.ServersFolder, storage.DbsFolder)
+		if _, err = os.Stat(legacyDirPath); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		env := new(datatug.Environment)
+		if err = loadEnvServers(legacyDirPath, env); err != nil {
+			return changes, fmt.Errorf("failed to load legacy servers of environment %v: %w", envDir.Name(), err)
+		}
+		sort.Slice(env.DbServers, func(i, j int) bool {
+			return env.DbServers[i].GetID() < env.DbServers[j].GetID()
+		})
+		for _, server := range env.DbServers {
+			fileName := storage.JsonFileName(server.GetID(), storage.ServerFileSuffix)
+			if _, err = os.Stat(path.Join(envPath, fileName)); err == nil {
+				changes = append(changes, fmt.Sprintf("skipped server %v of environment %v as %v already exists", server.GetID(), envDir.Name(), fileName))
+				continue
+			}
+			if err = saveJSONFile(envPath, fileName, server); err != nil {
+				return changes, err
+			}
+			changes = append(changes, fmt.Sprintf("moved server %v of environment %v to %v", server.GetID(), envDir.Name(), path.Join(storage.EnvironmentsFolder, envDir.Name(), fileName)))
+		}
+		files, err := os.ReadDir(legacyDirPath)
+		if err != nil {
+			return changes, err
+		}
+		for _, f := range files {
+			if _, suffix := storage.GetProjItemIDFromFileName(f.Name()); f.IsDir() || suffix != storage.ServerFileSuffix {
+				continue
+			}
+			if err = os.Remove(path.Join(legacyDirPath, f.Name())); err != nil {
+				return changes, err
+			}
+			changes = append(changes, "removed "+strings.TrimPrefix(path.Join(legacyDirPath, f.Name()), projectPath+"/"))
+		}
+		removeDirIfEmpty(legacyDirPath)
+		removeDirIfEmpty(path.Dir(legacyDirPath))
+	}
+	return changes, nil
+}
+
+func removeDirIfEmpty(dirPath string) {
+	if entries, err := os.ReadDir(dirPath); err == nil && len(entries) == 0 {
+		_ = os.Remove(dirPath)
+	}
+}
@@ -0,0 +1,144 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/credentials"
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+)
+
+var errNoCredentialsStore = errors.New("a credentials store is required to migrate passwords found in project files")
+
+// migrateQueryTargetCredentials strips passwords from targets of queries & sets credentials IDs of the targets.
+// The stripped credentials are saved to MigrateOptions.Credentials under IDs not yet used for other credentials,
+// usernames are kept in targets.
+// Without a credentials store only a dry run is possible, otherwise the passwords would be lost.
+func migrateQueryTargetCredentials(projectPath string, opts MigrateOptions) (changes []string, err error) {
+	queriesDirPath := path.Join(projectPath, storage.QueriesFolder)
+	if _, err = os.Stat(queriesDirPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	// Same server can be used with different credentials by different queries or projects,
+	// so IDs are assigned per distinct credentials & never overwrite credentials already in the store
+	assigned := make(map[string]datatug.Credentials)
+	isFree := func(id string, creds datatug.Credentials) (bool, error) {
+		if existing, ok := assigned[id]; ok {
+			return existing == creds, nil
+		}
+		if opts.Credentials == nil {
+			return true, nil
+		}
+		existing, err := opts.Credentials.GetCredentials(context.Background(), credentials.Ref{ID: id})
+		if errors.Is(err, credentials.ErrNotFound) {
+			return true, nil
+		} else if err != nil {
+			return false, fmt.Errorf("failed to check stored credentials %v: %w", id, err)
+		}
+		return existing == creds, nil
+	}
+	credentialsID := func(target datatug.QueryDefTarget, queryID string, i int) (string, error) {
+		if id := target.CredentialsID; id != "" {
+			if free, err := isFree(id, target.Credentials); err != nil {
+				return "", err
+			} else if !free {
+				return "", fmt.Errorf("credentials %v are already stored with a different password", id)
+			}
+			assigned[id] = target.Credentials
+			return id, nil
+		}
+		base := credentials.ServerKey(datatug.ServerRef{Driver: target.Driver, Host: target.Host, Port: target.Port})
+		if base == "" {
+			base = fmt.Sprintf("%v#%v", queryID, i)
+		}
+		candidates := []string{base}
+		if target.Username != "" {
+			candidates = append(candidates, target.Username+"@"+base)
+		}
+		for n := 2; ; n++ {
+			for _, id := range candidates {
+				if free, err := isFree(id, target.Credentials); err != nil {
+					return "", err
+				} else if free {
+					assigned[id] = target.Credentials
+					return id, nil
+				}
+			}
+			candidates = []string{fmt.Sprintf("%v-%v", base, n)}
+		}
+	}
+	err = filepath.WalkDir(queriesDirPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath := strings.TrimPrefix(filePath, projectPath+"/")
+		fileName := d.Name()
+		isSQLFile := path.Ext(fileName) == sqlFileExt
+		queryID, suffix := storage.GetProjItemIDFromFileName(fileName)
+		if suffix != storage.QueryFileSuffix {
+			return nil
+		}
+		if _, isItemFile := fileFormat(fileName); !isItemFile && !isSQLFile {
+			return nil
+		}
+		var query datatug.QueryDef
+		if isSQLFile {
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return err
+			}
+			if err = decodeSQLQueryFile(content, &query); err != nil {
+				// A plain SQL file next to an item file holds query text only
+				return nil
+			}
+		} else if _, err = readItemFile(filePath, &query); err != nil {
+			return fmt.Errorf("failed to read query file %v: %w", relPath, err)
+		}
+		var stripped bool
+		for i, target := range query.Targets {
+			if target.Password == "" {
+				continue
+			}
+			if opts.Credentials == nil && !opts.DryRun {
+				return fmt.Errorf("%w: password of target #%v of %v", errNoCredentialsStore, i, relPath)
+			}
+			id, err := credentialsID(target, queryID, i)
+			if err != nil {
+				return fmt.Errorf("failed to assign credentials ID to target #%v of %v: %w", i, relPath, err)
+			}
+			if !opts.DryRun {
+				if err = opts.Credentials.SetCredentials(context.Background(), id, target.Credentials); err != nil {
+					return fmt.Errorf("failed to save credentials %v: %w", id, err)
+				}
+				changes = append(changes, fmt.Sprintf("moved password of target #%v of %v to credentials %v", i, relPath, id))
+			} else {
+				changes = append(changes, fmt.Sprintf("removed password of target #%v of %v, it should be stored as credentials %v", i, relPath, id))
+			}
+			query.Targets[i].CredentialsID = id
+			query.Targets[i].Password = ""
+			stripped = true
+		}
+		if !stripped {
+			return nil
+		}
+		var content []byte
+		if isSQLFile {
+			content, err = encodeSQLQueryFile(query)
+		} else {
+			format, _ := fileFormat(fileName)
+			content, err = encodeItem(format, query)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode query file %v: %w", relPath, err)
+		}
+		return writeBytesAtomically(filePath, content)
+	})
+	return changes, err
+}
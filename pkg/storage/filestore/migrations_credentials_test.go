@@ -0,0 +1,158 @@
+package filestore
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/credentials"
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCredentialsStore map[string]datatug.Credentials
+
+func (s testCredentialsStore) GetCredentials(_ context.Context, ref credentials.Ref) (datatug.Credentials, error) {
+	if c, ok := s[ref.ID]; ok {
+		return c, nil
+	}
+	return datatug.Credentials{}, credentials.ErrNotFound
+}
+
+func (s testCredentialsStore) SetCredentials(_ context.Context, id string, c datatug.Credentials) error {
+	s[id] = c
+	return nil
+}
+
+func (s testCredentialsStore) DeleteCredentials(_ context.Context, id string) error {
+	delete(s, id)
+	return nil
+}
+
+func TestMigrateQueryTargetCredentials(t *testing.T) {
+	newProject := func(t *testing.T) string {
+		projectPath := t.TempDir()
+		queriesDir := path.Join(projectPath, storage.QueriesFolder)
+		writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"id":"p1","formatVersion":1}`)
+		writeTestFile(t, queriesDir, "q1.query.json", `{"id":"q1","type":"SQL","targets":[
+			{"driver":"sqlserver","host":"localhost","port":1433,"username":"sa","password":"p1"},
+			{"driver":"sqlserver","host":"localhost","port":1433,"username":"reader","password":"p2"},
+			{"driver":"sqlserver","host":"localhost","port":1433,"username":"sa"}]}`)
+		writeTestFile(t, queriesDir, "reports/q2.query.yaml", "id: q2\ntype: SQL\ntargets:\n  - driver: sqlserver\n    host: localhost\n    port: 1433\n    username: sa\n    password: p1\n")
+		writeTestFile(t, queriesDir, "q3.query.sql", "/* datatug\ntargets:\n  - driver: sqlite3\n    password: p3\n*/\nSELECT 3")
+		writeTestFile(t, queriesDir, "q4.query.json", `{"id":"q4","type":"SQL"}`)
+		writeTestFile(t, queriesDir, "q4.query.sql", "SELECT 4 /* not a header */")
+		return projectPath
+	}
+
+	t.Run("to_store", func(t *testing.T) {
+		projectPath := newProject(t)
+		store := testCredentialsStore{}
+		result, err := MigrateProject(projectPath, MigrateOptions{Credentials: store})
+		require.NoError(t, err)
+		require.Len(t, result.Steps, 1)
+		assert.Len(t, result.Steps[0].Changes, 4)
+		assert.Equal(t, testCredentialsStore{
+			"sqlserver:localhost:1433":        {Username: "sa", Password: "p1"},
+			"reader@sqlserver:localhost:1433": {Username: "reader", Password: "p2"},
+			"q3#0":                            {Password: "p3"},
+		}, store)
+
+		projectStore := newFsProjectStore("p1", projectPath)
+		q1, err := projectStore.LoadQuery(context.Background(), "q1")
+		require.NoError(t, err)
+		assert.Equal(t, []datatug.QueryDefTarget{
+			{Driver: "sqlserver", Host: "localhost", Port: 1433, CredentialsID: "sqlserver:localhost:1433", Credentials: datatug.Credentials{Username: "sa"}},
+			{Driver: "sqlserver", Host: "localhost", Port: 1433, CredentialsID: "reader@sqlserver:localhost:1433", Credentials: datatug.Credentials{Username: "reader"}},
+			{Driver: "sqlserver", Host: "localhost", Port: 1433, Credentials: datatug.Credentials{Username: "sa"}},
+		}, q1.Targets)
+		assert.Equal(t, "id: q2\ntype: SQL\ntargets:\n  - driver: sqlserver\n    host: localhost\n    port: 1433\n    credentialsId: sqlserver:localhost:1433\n    username: sa\n",
+			readTestFile(t, path.Join(projectPath, storage.QueriesFolder), "reports/q2.query.yaml"))
+		assert.Equal(t, "/* datatug\ntargets:\n  - driver: sqlite3\n    credentialsId: q3#0\n*/\nSELECT 3",
+			readTestFile(t, path.Join(projectPath, storage.QueriesFolder), "q3.query.sql"))
+		assert.Equal(t, "SELECT 4 /* not a header */", readTestFile(t, path.Join(projectPath, storage.QueriesFolder), "q4.query.sql"))
+
+		password, err := credentials.ForTarget(context.Background(), store, "", q1.Targets[1])
+		require.NoError(t, err)
+		assert.Equal(t, datatug.Credentials{Username: "reader", Password: "p2"}, password)
+	})
+
+	t.Run("two_projects", func(t *testing.T) {
+		store := testCredentialsStore{}
+		_, err := MigrateProject(newProject(t), MigrateOptions{Credentials: store})
+		require.NoError(t, err)
+		_, err = MigrateProject(newProject(t), MigrateOptions{Credentials: store})
+		require.NoError(t, err)
+		assert.Len(t, store, 3, "same credentials of another project should reuse stored IDs")
+
+		projectPath := t.TempDir()
+		writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"id":"p2","formatVersion":1}`)
+		writeTestFile(t, path.Join(projectPath, storage.QueriesFolder), "q1.query.json", `{"id":"q1","type":"SQL","targets":[
+			{"driver":"sqlserver","host":"localhost","port":1433,"username":"sa","password":"other"}]}`)
+		result, err := MigrateProject(projectPath, MigrateOptions{Credentials: store})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"moved password of target #0 of queries/q1.query.json to credentials sa@sqlserver:localhost:1433"}, result.Steps[0].Changes)
+		assert.Equal(t, datatug.Credentials{Username: "sa", Password: "p1"}, store["sqlserver:localhost:1433"],
+			"credentials of the first project should not be overwritten")
+		assert.Equal(t, datatug.Credentials{Username: "sa", Password: "other"}, store["sa@sqlserver:localhost:1433"])
+
+		t.Run("suffixed_id", func(t *testing.T) {
+			projectPath := t.TempDir()
+			writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"id":"p3","formatVersion":1}`)
+			writeTestFile(t, path.Join(projectPath, storage.QueriesFolder), "q1.query.json", `{"id":"q1","type":"SQL","targets":[
+				{"driver":"sqlserver","host":"localhost","port":1433,"username":"sa","password":"third"}]}`)
+			_, err := MigrateProject(projectPath, MigrateOptions{Credentials: store})
+			require.NoError(t, err)
+			assert.Equal(t, datatug.Credentials{Username: "sa", Password: "third"}, store["sqlserver:localhost:1433-2"])
+		})
+
+		t.Run("explicit_id", func(t *testing.T) {
+			projectPath := t.TempDir()
+			writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"id":"p4","formatVersion":1}`)
+			writeTestFile(t, path.Join(projectPath, storage.QueriesFolder), "q1.query.json", `{"id":"q1","type":"SQL","targets":[
+				{"driver":"sqlserver","credentialsId":"sqlserver:localhost:1433","username":"sa","password":"third"}]}`)
+			_, err := MigrateProject(projectPath, MigrateOptions{Credentials: store})
+			assert.Error(t, err)
+			assert.Equal(t, "p1", store["sqlserver:localhost:1433"].Password)
+		})
+	})
+
+	t.Run("without_store", func(t *testing.T) {
+		projectPath := newProject(t)
+		_, err := MigrateProject(projectPath, MigrateOptions{})
+		assert.ErrorIs(t, err, errNoCredentialsStore)
+		assert.Contains(t, readTestFile(t, path.Join(projectPath, storage.QueriesFolder), "q1.query.json"), `"password":"p1"`,
+			"passwords should be kept if there is no store to move them to")
+		version, err := ReadProjectFormatVersion(projectPath)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, version)
+
+		t.Run("dry_run", func(t *testing.T) {
+			result, err := MigrateProject(projectPath, MigrateOptions{DryRun: true})
+			require.NoError(t, err)
+			assert.Contains(t, result.Steps[0].Changes,
+				"removed password of target #0 of queries/reports/q2.query.yaml, it should be stored as credentials sqlserver:localhost:1433")
+		})
+	})
+
+	t.Run("auto_migrate", func(t *testing.T) {
+		projectPath := newProject(t)
+		store := testCredentialsStore{}
+		_, err := NewProjectStore("p1", projectPath, AutoMigrate(store)).LoadProject(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, store, 3)
+		assert.NotContains(t, readTestFile(t, path.Join(projectPath, storage.QueriesFolder), "q1.query.json"), "p1")
+	})
+
+	t.Run("dry_run", func(t *testing.T) {
+		projectPath := newProject(t)
+		store := testCredentialsStore{}
+		result, err := MigrateProject(projectPath, MigrateOptions{DryRun: true, Credentials: store})
+		require.NoError(t, err)
+		assert.Len(t, result.Steps[0].Changes, 4)
+		assert.Empty(t, store)
+		assert.Contains(t, readTestFile(t, path.Join(projectPath, storage.QueriesFolder), "q1.query.json"), `"password":"p1"`)
+	})
+}
@@ -0,0 +1,234 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	configDir, err := os.MkdirTemp("", "datatug-config-")
+	if err != nil {
+		panic(err)
+	}
+	userConfigDir = func() (string, error) {
+		return configDir, nil
+	}
+	code := m.Run()
+	_ = os.RemoveAll(configDir)
+	os.Exit(code)
+}
+
+// newLegacyProject creates a project of format version 0 with servers of an environment in a legacy file
+func newLegacyProject(t *testing.T) string {
+	projectPath := path.Join(t.TempDir(), "p1")
+	writeTestFile(t, projectPath, storage.ProjectSummaryFileName,
+		`{"id":"p1","title":"Legacy","access":"private","created":{"at":"2020-01-02T03:04:05Z"},"custom":"kept"}`)
+	writeTestFile(t, projectPath, path.Join(storage.EnvironmentsFolder, "dev", storage.EnvironmentSummaryFileName), `{"id":"dev"}`)
+	writeTestFile(t, projectPath, path.Join(storage.EnvironmentsFolder, "dev", storage.ServersFolder, storage.DbsFolder, "localhost.server.json"),
+		`[{"driver":"sqlserver","port":1433,"catalogs":["northwind"]},{"driver":"sqlserver","port":1434}]`)
+	return projectPath
+}
+
+func newTestProject(id string) *datatug.Project {
+	return &datatug.Project{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: "Test"},
+			Access:        "private",
+		},
+		Created: &datatug.ProjectCreated{At: time.Now()},
+	}
+}
+
+func TestMigrateProject(t *testing.T) {
+	legacyServersFile := path.Join(storage.EnvironmentsFolder, "dev", storage.ServersFolder, storage.DbsFolder, "localhost.server.json")
+
+	t.Run("dry_run", func(t *testing.T) {
+		projectPath := newLegacyProject(t)
+		projectFileBefore := readTestFile(t, projectPath, storage.ProjectSummaryFileName)
+
+		result, err := MigrateProject(projectPath, MigrateOptions{DryRun: true, Backup: true})
+		require.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 0, result.FromVersion)
+		assert.Equal(t, datatug.ProjectFormatVersion, result.ToVersion)
+		assert.Empty(t, result.BackupPath, "no backup is needed for a dry run")
+		require.Len(t, result.Steps, 2)
+		assert.Equal(t, []string{
+			"moved server localhost:1433 of environment dev to environments/dev/localhost:1433.server.json",
+			"moved server localhost:1434 of environment dev to environments/dev/localhost:1434.server.json",
+			"removed " + legacyServersFile,
+		}, result.Steps[0].Changes)
+
+		assert.Equal(t, projectFileBefore, readTestFile(t, projectPath, storage.ProjectSummaryFileName))
+		assert.FileExists(t, path.Join(projectPath, legacyServersFile))
+		assert.NoFileExists(t, path.Join(projectPath, storage.EnvironmentsFolder, "dev", "localhost:1433.server.json"))
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		projectPath := newLegacyProject(t)
+
+		result, err := MigrateProject(projectPath, MigrateOptions{Backup: true})
+		require.NoError(t, err)
+		assert.Equal(t, datatug.ProjectFormatVersion, result.ToVersion)
+		require.NotEmpty(t, result.BackupPath)
+		configDir, _ := userConfigDir()
+		assert.Equal(t, path.Join(configDir, "datatug", "backups"), path.Dir(result.BackupPath),
+			"backup should be kept outside of the project repository")
+		assert.FileExists(t, path.Join(result.BackupPath, legacyServersFile))
+		version, err := ReadProjectFormatVersion(result.BackupPath)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, version)
+
+		version, err = ReadProjectFormatVersion(projectPath)
+		assert.NoError(t, err)
+		assert.Equal(t, datatug.ProjectFormatVersion, version)
+		var projFile map[string]any
+		require.NoError(t, json.Unmarshal([]byte(readTestFile(t, projectPath, storage.ProjectSummaryFileName)), &projFile))
+		assert.Equal(t, "kept", projFile["custom"], "unknown properties of project file should be kept")
+
+		assert.NoDirExists(t, path.Join(projectPath, storage.EnvironmentsFolder, "dev", storage.ServersFolder))
+		servers, err := newFsProjectStore("p1", projectPath).LoadEnvDbServers(context.Background(), "dev")
+		require.NoError(t, err)
+		require.Len(t, servers, 2)
+		assert.Equal(t, "localhost", servers[0].Host)
+		assert.Equal(t, []string{"northwind"}, servers[0].Catalogs)
+
+		t.Run("up_to_date", func(t *testing.T) {
+			result, err := MigrateProject(projectPath, MigrateOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, result.Steps)
+		})
+	})
+
+	t.Run("backup_dir", func(t *testing.T) {
+		backupDir := t.TempDir()
+		result, err := MigrateProject(newLegacyProject(t), MigrateOptions{Backup: true, BackupDir: backupDir})
+		require.NoError(t, err)
+		assert.Equal(t, backupDir, path.Dir(result.BackupPath))
+		assert.NoFileExists(t, path.Join(result.BackupPath, SaveLockFileName))
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		projectPath := newLegacyProject(t)
+		lock, err := lockProject(context.Background(), projectPath)
+		require.NoError(t, err)
+		migrated := make(chan error)
+		go func() {
+			_, err := MigrateProject(projectPath, MigrateOptions{})
+			migrated <- err
+		}()
+		select {
+		case <-migrated:
+			t.Fatal("project should not be migrated while it's locked by a save")
+		case <-time.After(100 * time.Millisecond):
+		}
+		require.NoError(t, lock.unlock())
+		require.NoError(t, <-migrated)
+		assert.NoFileExists(t, path.Join(projectPath, SaveLockFileName))
+	})
+
+	t.Run("newer_version", func(t *testing.T) {
+		projectPath := t.TempDir()
+		writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"formatVersion":999}`)
+		_, err := MigrateProject(projectPath, MigrateOptions{})
+		assert.ErrorIs(t, err, storage.ErrProjectFormatNotSupported)
+	})
+}
+
+func TestFsProjectStore_FormatVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("outdated", func(t *testing.T) {
+		projectPath := newLegacyProject(t)
+		_, err := newFsProjectStore("p1", projectPath).LoadProject(ctx)
+		var outdated storage.OutdatedProjectError
+		if assert.True(t, errors.As(err, &outdated)) {
+			assert.Equal(t, "p1", outdated.ProjectID)
+			assert.Equal(t, 0, outdated.Version)
+			assert.Equal(t, datatug.ProjectFormatVersion, outdated.CurrentVersion)
+		}
+		assert.ErrorIs(t, newFsProjectStore("p1", projectPath).SaveProject(ctx, newTestProject("p1")), storage.ErrProjectOutdated)
+	})
+
+	t.Run("auto_migrate", func(t *testing.T) {
+		projectPath := newLegacyProject(t)
+		project, err := NewProjectStore("p1", projectPath, AutoMigrate(nil)).LoadProject(ctx)
+		require.NoError(t, err)
+		require.Len(t, project.Environments, 1)
+		version, err := ReadProjectFormatVersion(projectPath)
+		assert.NoError(t, err)
+		assert.Equal(t, datatug.ProjectFormatVersion, version)
+		entries, err := os.ReadDir(path.Dir(projectPath))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "backup should not be created next to the project")
+		configDir, _ := userConfigDir()
+		backups, err := filepath.Glob(path.Join(configDir, "datatug", "backups", "p1.backup-v0-*"))
+		require.NoError(t, err)
+		assert.NotEmpty(t, backups)
+	})
+
+	t.Run("newer_version", func(t *testing.T) {
+		projectPath := t.TempDir()
+		writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"formatVersion":999}`)
+		_, err := NewProjectStore("p1", projectPath, AutoMigrate(nil)).LoadProject(ctx)
+		assert.ErrorIs(t, err, storage.ErrProjectFormatNotSupported)
+	})
+
+	t.Run("items", func(t *testing.T) {
+		board := &datatug.Board{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "b1", Title: "Board"}}}
+		query := &datatug.QueryDefWithFolderPath{FolderPath: "~", QueryDef: *newTestQuery("q1")}
+
+		t.Run("outdated", func(t *testing.T) {
+			projectPath := newLegacyProject(t)
+			store := newFsProjectStore("p1", projectPath)
+			assert.ErrorIs(t, store.SaveBoard(ctx, board), storage.ErrProjectOutdated)
+			assert.ErrorIs(t, store.SaveQuery(ctx, query), storage.ErrProjectOutdated)
+			assert.ErrorIs(t, store.SaveEnvironment(ctx, &datatug.Environment{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "prod"}}}), storage.ErrProjectOutdated)
+			assert.NoDirExists(t, path.Join(projectPath, storage.BoardsFolder))
+		})
+
+		t.Run("newer_version", func(t *testing.T) {
+			projectPath := t.TempDir()
+			writeTestFile(t, projectPath, storage.ProjectSummaryFileName, `{"formatVersion":999}`)
+			err := newFsProjectStore("p1", projectPath).SaveBoard(ctx, board)
+			assert.ErrorIs(t, err, storage.ErrProjectFormatNotSupported)
+		})
+
+		t.Run("auto_migrate", func(t *testing.T) {
+			projectPath := newLegacyProject(t)
+			require.NoError(t, NewProjectStore("p1", projectPath, AutoMigrate(nil)).SaveBoard(ctx, board))
+			version, err := ReadProjectFormatVersion(projectPath)
+			assert.NoError(t, err)
+			assert.Equal(t, datatug.ProjectFormatVersion, version)
+			assert.NoError(t, newFsProjectStore("p1", projectPath).SaveQuery(ctx, query), "project is migrated")
+		})
+	})
+
+	t.Run("saved_project_is_current", func(t *testing.T) {
+		projectPath := t.TempDir()
+		require.NoError(t, newFsProjectStore("p1", projectPath).SaveProject(ctx, newTestProject("p1")))
+		version, err := ReadProjectFormatVersion(projectPath)
+		assert.NoError(t, err)
+		assert.Equal(t, datatug.ProjectFormatVersion, version)
+	})
+}
+
+func TestRegisterProjectMigration(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterProjectMigration(ProjectMigration{FromVersion: 0, Migrate: migrateLegacyEnvServers})
+	})
+	assert.Panics(t, func() {
+		RegisterProjectMigration(ProjectMigration{FromVersion: -1})
+	})
+}
@@ -101,7 +101,7 @@ func (v DbCatalogFile) Validate() error {
 	if v.Driver == "" {
 		return validation.NewErrRecordIsMissingRequiredField("driver")
 	}
-	if v.Driver == "sqlite3" && v.Path == "" {
+	if driver, ok := datatug.GetDriver(v.Driver); ok && driver.FileBased && v.Path == "" {
 		return validation.NewErrRecordIsMissingRequiredField("path")
 	}
 	return nil
@@ -5,6 +5,13 @@ import (
 	"os"
 )
 
+// tempFile is a subset of *os.File used for atomic writes
+type tempFile interface {
+	io.WriteCloser
+	Name() string
+	Sync() error
+}
+
 var standardOsOpen = func(name string) (io.ReadCloser, error) {
 	return os.Open(name)
 }
@@ -13,13 +20,16 @@ var standardOsStat = os.Stat
 
 var standardOsMkdirAll = os.MkdirAll
 
-var standardOsCreate = func(name string) (io.WriteCloser, error) {
-	return os.Create(name)
+var standardOsCreateTemp = func(dir, pattern string) (tempFile, error) {
+	return os.CreateTemp(dir, pattern)
 }
 
+var standardOsRename = os.Rename
+
 var (
-	osOpen     = standardOsOpen
-	osStat     = standardOsStat
-	osMkdirAll = standardOsMkdirAll
-	osCreate   = standardOsCreate
+	osOpen       = standardOsOpen
+	osStat       = standardOsStat
+	osMkdirAll   = standardOsMkdirAll
+	osCreateTemp = standardOsCreateTemp
+	osRename     = standardOsRename
 )
@@ -7,11 +7,13 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"slices"
 	"strings"
 	"sync"
 
 	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dtconfig"
 	"github.com/datatug/datatug-core/pkg/storage"
 )
 
@@ -36,6 +38,19 @@ type fsProjectItemsStore[TSlice ~[]TItemPtr, TItemPtr IItemPtr[TItem], TItem IIt
 	dirPath         string
 	itemFileSuffix  string
 	summaryFileName string
+	// projectPath is set for items written in the format configured for the project, otherwise items are written as JSON
+	projectPath string
+	// textFormat is set for items that can be stored in plain text files
+	textFormat *textFormat[TItemPtr]
+}
+
+// textFormat defines how items are read from plain text files, e.g. queries from .sql files.
+// A text file is read as an item only if there is no item file with the same ID.
+type textFormat[TItemPtr any] struct {
+	ext    string
+	decode func(content []byte, item TItemPtr) error
+	// merge adds content of a text file to an item read from an item file next to it
+	merge func(content []byte, item TItemPtr)
 }
 
 func newFileProjectItemsStore[TSlice ~[]TItemPtr, TItemPtr IItemPtr[TItem], TItem IItem](
@@ -58,32 +73,85 @@ func newDirProjectItemsStore[TSlice ~[]TItemPtr, TItemPtr IItemPtr[TItem], TItem
 	}
 }
 
+// isValidItemID checks that an ID can be used as a name of a file or a directory of an item,
+// so an item can't be read or written outside its directory
+func isValidItemID(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`)
+}
+
+// inProjectItemsFormat makes the store write items in the format set by datatug.ProjectFile.ItemsFormat
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) inProjectItemsFormat(projectPath string) fsProjectItemsStore[TSlice, TItemPtr, TItem] {
+	s.projectPath = projectPath
+	return s
+}
+
 func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) loadProjectItem(
 	_ context.Context, dirPath, id, fileName string, o ...datatug.StoreOption,
 ) (
 	item TItemPtr, err error,
 ) {
-	_ = datatug.GetStoreOptions(o...)
+	options := datatug.GetStoreOptions(o...)
 	if fileName == "" {
 		switch s.storedAs {
 		case ProjItemStoredAsFile:
-			if s.itemFileSuffix == "" {
-				fileName = id + ".json"
-			} else {
-				fileName = id + "." + s.itemFileSuffix + ".json"
+			format, err := s.writeFormat()
+			if err != nil {
+				return nil, err
 			}
+			fileName = s.existingItemFileName(dirPath, id, format)
 		case ProjItemStoredAsDir:
 			dirPath = path.Join(dirPath, id)
 			fileName = s.summaryFileName
 		}
 
 	}
-	filePath := path.Join(dirPath, fileName)
 	item = new(TItem)
-	if err = readJSONFile(filePath, true, &item); err != nil {
+	if fileName == "" { // An item stored as a directory without a summary file
+		if _, err = os.Stat(dirPath); err != nil {
+			return item, fmt.Errorf("failed to load %T[%s] from project: %w", item, id, err)
+		}
+		item.SetID(id)
+		return
+	}
+	filePath := path.Join(dirPath, fileName)
+	var itemContent []byte // is kept for a revision of an item with a text file
+	if s.isTextFile(fileName) {
+		var content []byte
+		if content, err = os.ReadFile(filePath); err == nil {
+			err = s.textFormat.decode(content, item)
+		}
+		if err != nil {
+			return item, fmt.Errorf("failed to load %T[%s] from project: %w", item, id, err)
+		}
+		if revItem, ok := any(item).(revisioned); ok {
+			revItem.SetRevision(storage.ContentRevision(content))
+		}
+	} else if revItem, ok := any(item).(revisioned); ok {
+		if itemContent, err = readItemFile(filePath, item); err != nil {
+			return item, fmt.Errorf("failed to load %T[%s] from project: %w", item, id, err)
+		}
+		revItem.SetRevision(itemRevision(itemContent))
+	} else if _, err = readItemFile(filePath, &item); err != nil {
 		return item, fmt.Errorf("failed to load %T[%s] from project: %w", item, id, err)
 	}
+	if s.textFormat != nil && s.textFormat.merge != nil && !s.isTextFile(fileName) {
+		baseName, _ := itemBaseName(fileName)
+		if content, err := os.ReadFile(path.Join(dirPath, baseName+s.textFormat.ext)); err == nil {
+			s.textFormat.merge(content, item)
+			if revItem, ok := any(item).(revisioned); ok {
+				revItem.SetRevision(itemRevision(itemContent, content))
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return item, fmt.Errorf("failed to load text of %T[%s] from project: %w", item, id, err)
+		}
+	}
 	item.SetID(id)
+	if trimmer, ok := any(item).(datatug.BriefTrimmer); ok && options.BriefsOnly() {
+		trimmer.TrimToBrief()
+		if revItem, ok := any(item).(revisioned); ok { // a brief can't be saved back, see saveRevisioned
+			revItem.SetRevision(storage.BriefRevision(revItem.GetRevision()))
+		}
+	}
 	return
 }
 
@@ -92,56 +160,107 @@ func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) loadProjectItems(
 ) (
 	items TSlice, err error,
 ) {
-	_ = datatug.GetStoreOptions(o...)
+	options := datatug.GetStoreOptions(o...)
 
 	var filesMask string
 	var fsObjectType process
-	var loader func(f os.FileInfo, i int, mutex *sync.Mutex) (err error)
+	// candidate returns ID of an item & name of its file to be loaded or false if the item is skipped
+	// by file name, cursor & modification time, without reading the file
+	var candidate func(f os.FileInfo) (id, fileName string, ok bool)
 
 	switch s.storedAs {
 	case ProjItemStoredAsFile:
-		if s.itemFileSuffix == "" {
-			filesMask = "*.json"
-		} else {
-			filesMask = fmt.Sprintf("*.%s.json", s.itemFileSuffix)
+		format, err := s.writeFormat()
+		if err != nil {
+			return nil, err
+		}
+		if s.itemFileSuffix != "" {
+			filesMask = fmt.Sprintf("*.%s.*", s.itemFileSuffix)
 		}
 		fsObjectType = processFiles
-		loader = func(f os.FileInfo, i int, mutex *sync.Mutex) error {
+		candidate = func(f os.FileInfo) (string, string, bool) {
 			if f.IsDir() {
-				return nil
+				return "", "", false
+			}
+			if _, ok := fileFormat(f.Name()); !ok && !s.isTextFile(f.Name()) {
+				return "", "", false
 			}
 			id, suffix := storage.GetProjItemIDFromFileName(f.Name())
-			if suffix != s.itemFileSuffix {
-				return nil
+			if suffix != s.itemFileSuffix || !isAfterCursor(options, id) || !isModifiedSince(options, f) {
+				return "", "", false
 			}
-			item, err := s.loadProjectItem(ctx, dirPath, id, f.Name())
-			if err != nil {
-				return err
+			if f.Name() != s.existingItemFileName(dirPath, id, format) {
+				return "", "", false // the item is stored in several formats & this file is not the one to be read
 			}
-			mutex.Lock()
-			items = append(items, item)
-			mutex.Unlock()
-			return nil
+			return id, f.Name(), true
 		}
 	case ProjItemStoredAsDir:
 		fsObjectType = processDirs
-		loader = func(f os.FileInfo, i int, mutex *sync.Mutex) error {
+		candidate = func(f os.FileInfo) (string, string, bool) {
 			if !f.IsDir() {
-				return nil
+				return "", "", false
 			}
 			id := f.Name()
-			itemDir := path.Join(dirPath, id)
-			var item TItemPtr
-			item, err = s.loadProjectItem(ctx, itemDir, id, fmt.Sprintf(".datatug-%s.json", s.itemFileSuffix))
+			if !isAfterCursor(options, id) {
+				return "", "", false
+			}
+			if !isModifiedSince(options, f) {
+				if s.summaryFileName == "" {
+					return "", "", false
+				}
+				// Directory modification time changes only when entries are added or removed
+				summary, err := os.Stat(path.Join(dirPath, id, s.summaryFileName))
+				if err != nil || !isModifiedSince(options, summary) {
+					return "", "", false
+				}
+			}
+			return id, "", true
+		}
+	}
+
+	load := func(id, fileName string) (item TItemPtr, err error) {
+		item, err = s.loadProjectItem(ctx, dirPath, id, fileName, o...)
+		if s.storedAs == ProjItemStoredAsDir {
 			if err != nil && !errors.Is(err, fs.ErrNotExist) {
-				return err
+				return nil, err
 			}
 			item.SetID(id)
+			return item, nil
+		}
+		return item, err
+	}
+
+	// If every item of a page passes filters, only items of the page are read
+	limit := options.Limit()
+	pageOnly := limit > 0 && !options.HasItemFilters()
+	type itemFile struct {
+		id, fileName string
+		f            os.FileInfo
+	}
+	var pageFiles []itemFile
+
+	loader := func(f os.FileInfo, i int, mutex *sync.Mutex) error {
+		id, fileName, ok := candidate(f)
+		if !ok {
+			return nil
+		}
+		if pageOnly {
 			mutex.Lock()
-			items = append(items, item)
+			pageFiles = append(pageFiles, itemFile{id: id, fileName: fileName, f: f})
 			mutex.Unlock()
 			return nil
 		}
+		item, err := load(id, fileName)
+		if err != nil {
+			return err
+		}
+		if !options.MatchItem(item) {
+			return nil
+		}
+		mutex.Lock()
+		items = append(items, item)
+		mutex.Unlock()
+		return nil
 	}
 
 	err = loadDir(nil, dirPath, filesMask, fsObjectType,
@@ -150,6 +269,27 @@ func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) loadProjectItems(
 		},
 		loader)
 
+	if err == nil && pageOnly {
+		slices.SortFunc(pageFiles, func(a, b itemFile) int {
+			return strings.Compare(a.id, b.id)
+		})
+		if len(pageFiles) > limit {
+			pageFiles = pageFiles[:limit]
+		}
+		var errs []storage.FileLoadError
+		for _, pageFile := range pageFiles {
+			item, loadErr := load(pageFile.id, pageFile.fileName)
+			if loadErr != nil {
+				errs = append(errs, storage.NewFileLoadError(pageFile.f.Name(), loadErr))
+				continue
+			}
+			items = append(items, item)
+		}
+		if len(errs) > 0 {
+			err = storage.NewFilesLoadError(errs)
+		}
+	}
+
 	slices.SortFunc(items, func(a, b TItemPtr) int {
 		return strings.Compare(a.GetID(), b.GetID())
 	})
@@ -157,30 +297,115 @@ func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) loadProjectItems(
 	if err != nil {
 		return nil, err
 	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
 	return
 }
 
-func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) saveProjectItem(_ context.Context, dirPath string, item TItemPtr, o ...datatug.StoreOption) error {
+// isAfterCursor returns true if an item with the given ID belongs to the requested page, items are ordered by ID
+func isAfterCursor(options datatug.StoreOptions, id string) bool {
+	cursor := options.Cursor()
+	return cursor == "" || id > cursor
+}
+
+func isModifiedSince(options datatug.StoreOptions, f os.FileInfo) bool {
+	since := options.ModifiedSince()
+	return since.IsZero() || !f.ModTime().Before(since)
+}
+
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) saveProjectItem(_ context.Context, dirPath string, item TItemPtr, o ...datatug.StoreOption) (err error) {
 	_ = datatug.GetStoreOptions(o...)
 	if item == nil {
 		return fmt.Errorf("an attempt to save a nil %T to %s", item, dirPath)
 	}
+	if err = checkItemFormatVersion(dirPath); err != nil {
+		return err
+	}
 	id := item.GetID()
-	var fileName string
+	var fileName, currentFileName string
 	switch s.storedAs {
 	case ProjItemStoredAsFile:
-		fileName = storage.JsonFileName(id, s.itemFileSuffix)
+		var format dtconfig.Format
+		if format, err = s.writeFormat(); err != nil {
+			return err
+		}
+		fileName = s.itemFileName(id, format)
+		currentFileName = s.existingItemFileName(dirPath, id, format)
+		if currentFileName != fileName {
+			defer func() { // the item is converted to the configured format
+				if err == nil {
+					err = s.removeItemFiles(dirPath, id, fileName)
+				}
+			}()
+		}
 	case ProjItemStoredAsDir:
 		dirPath = path.Join(dirPath, id)
 		fileName = s.summaryFileName
+		currentFileName = fileName
+		if fileName == "" { // Nothing to write, the directory itself represents the item
+			if err := item.Validate(); err != nil {
+				return fmt.Errorf("an attempt to save invalid data %T: %w", item, err)
+			}
+			return os.MkdirAll(dirPath, 0777)
+		}
 	}
 
-	if err := saveJSONFile(dirPath, fileName, item); err != nil {
+	if revItem, ok := any(item).(revisioned); ok {
+		var textFileName string
+		if s.textFormat != nil {
+			textFileName = s.textFileName(id)
+		}
+		return saveRevisionedItemFile(dirPath, fileName, currentFileName, textFileName, id, item, revItem)
+	}
+	if err = saveItemFile(dirPath, fileName, item); err != nil {
 		return fmt.Errorf("failed to save %T file: %w", item, err)
 	}
 	return nil
 }
 
+// journalPaths returns paths relative to the project directory that are written or removed by a save of the items,
+// a directory that does not exist yet is returned instead of files in it, so a rollback removes it
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) journalPaths(projectPath string, items TSlice) ([]string, error) {
+	rel := func(p string) (string, error) {
+		return filepath.Rel(projectPath, p)
+	}
+	if _, err := os.Stat(s.dirPath); errors.Is(err, fs.ErrNotExist) {
+		p, err := rel(s.dirPath)
+		return []string{p}, err
+	}
+	paths := make([]string, 0, len(items))
+	for _, item := range items {
+		id := item.GetID()
+		var itemPaths []string
+		switch s.storedAs {
+		case ProjItemStoredAsDir:
+			itemDir := path.Join(s.dirPath, id)
+			if _, err := os.Stat(itemDir); errors.Is(err, fs.ErrNotExist) || s.summaryFileName == "" {
+				itemPaths = []string{itemDir}
+			} else {
+				itemPaths = []string{path.Join(itemDir, s.summaryFileName)}
+			}
+		case ProjItemStoredAsFile:
+			baseName, _ := itemBaseName(s.itemFileName(id, dtconfig.FormatJson))
+			for _, ext := range itemFileExts {
+				itemPaths = append(itemPaths, path.Join(s.dirPath, baseName+ext))
+			}
+			if s.textFormat != nil {
+				itemPaths = append(itemPaths, path.Join(s.dirPath, baseName+s.textFormat.ext))
+			}
+		}
+		for _, p := range itemPaths {
+			p, err := rel(p)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
 func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) saveProjectItems(ctx context.Context, dirPath string, items TSlice) error {
 	return saveItems(dirPath, len(items), func(i int) func() error {
 		return func() error {
@@ -190,21 +415,72 @@ func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) saveProjectItems(ctx conte
 
 }
 
-func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) itemFileName(id string) string {
-	return storage.JsonFileName(id, s.itemFileSuffix)
+// writeFormat returns format item files are written in
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) writeFormat() (dtconfig.Format, error) {
+	if s.projectPath == "" {
+		return dtconfig.FormatJson, nil
+	}
+	settings, err := loadProjectSettings(s.projectPath)
+	return settings.ItemsFormat, err
 }
 
-func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) itemFilePath(dirPath, id string) string {
-	return path.Join(dirPath, s.itemFileName(id))
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) isTextFile(fileName string) bool {
+	return s.textFormat != nil && path.Ext(fileName) == s.textFormat.ext
 }
 
-func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) deleteProjectItem(_ context.Context, dirPath, id string) error {
-	filePath := s.itemFilePath(dirPath, id)
-	if _, err := os.Stat(filePath); err != nil {
-		if os.IsNotExist(err) {
-			return nil
+// textFileName returns name of a plain text file of an item, the store should have a text format
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) textFileName(id string) string {
+	return strings.TrimSuffix(s.itemFileName(id, dtconfig.FormatJson), jsonFileExt) + s.textFormat.ext
+}
+
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) itemFileName(id string, format dtconfig.Format) string {
+	return strings.TrimSuffix(storage.JsonFileName(id, s.itemFileSuffix), jsonFileExt) + itemFileExt(format)
+}
+
+// existingItemFileName returns name of a file an item is stored in preferring the given format,
+// if there is no file the name of a file in the given format is returned
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) existingItemFileName(dirPath, id string, format dtconfig.Format) string {
+	preferred := s.itemFileName(id, format)
+	if _, err := os.Stat(path.Join(dirPath, preferred)); err == nil {
+		return preferred
+	}
+	baseName := strings.TrimSuffix(preferred, path.Ext(preferred))
+	for _, ext := range itemFileExts {
+		if fileName := baseName + ext; fileName != preferred {
+			if _, err := os.Stat(path.Join(dirPath, fileName)); err == nil {
+				return fileName
+			}
 		}
-		return err
 	}
-	return os.Remove(filePath)
+	if s.textFormat != nil {
+		fileName := baseName + s.textFormat.ext
+		if _, err := os.Stat(path.Join(dirPath, fileName)); err == nil {
+			return fileName
+		}
+	}
+	return preferred
+}
+
+// removeItemFiles removes files of an item in all formats including a text one except the kept file
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) removeItemFiles(dirPath, id, keep string) error {
+	baseName, _ := itemBaseName(s.itemFileName(id, dtconfig.FormatJson))
+	exts := itemFileExts
+	if s.textFormat != nil {
+		exts = append(exts[:len(exts):len(exts)], s.textFormat.ext)
+	}
+	for _, ext := range exts {
+		if fileName := baseName + ext; fileName != keep {
+			if err := os.Remove(path.Join(dirPath, fileName)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %w", fileName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s fsProjectItemsStore[TSlice, TItemPtr, TItem]) deleteProjectItem(_ context.Context, dirPath, id string) error {
+	if s.storedAs == ProjItemStoredAsDir {
+		return os.RemoveAll(path.Join(dirPath, id))
+	}
+	return s.removeItemFiles(dirPath, id, "")
 }
@@ -0,0 +1,101 @@
+package filestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFsProjectItemsStore_ModifiedSince(t *testing.T) {
+	ctx := context.Background()
+	projectPath := t.TempDir()
+	store := newFsProjectStore("p1", projectPath)
+	for _, id := range []string{"e1", "e2"} {
+		entity := &datatug.Entity{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: id}}}
+		require.NoError(t, store.SaveEntity(ctx, entity))
+	}
+	since := time.Now().Add(-time.Hour)
+	old := since.Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(projectPath, storage.EntitiesFolder, storage.JsonFileName("e1", storage.EntityFileSuffix)), old, old))
+
+	entities, err := store.LoadEntities(ctx, datatug.ModifiedSince(since))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"e2"}, entities.IDs())
+
+	entities, err = store.LoadEntities(ctx, datatug.ModifiedSince(old))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"e1", "e2"}, entities.IDs())
+}
+
+func TestFsProjectItemsStore_ModifiedSince_StoredAsDir(t *testing.T) {
+	ctx := context.Background()
+	projectPath := t.TempDir()
+	store := newFsProjectStore("p1", projectPath)
+	for _, id := range []string{"dev", "prod"} {
+		env := &datatug.Environment{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: id}}}
+		require.NoError(t, store.SaveEnvironment(ctx, env))
+	}
+	since := time.Now().Add(-time.Hour)
+	old := since.Add(-time.Hour)
+	devDir := filepath.Join(projectPath, storage.EnvironmentsFolder, "dev")
+	require.NoError(t, os.Chtimes(filepath.Join(devDir, storage.EnvironmentSummaryFileName), old, old))
+	require.NoError(t, os.Chtimes(devDir, old, old))
+	prodDir := filepath.Join(projectPath, storage.EnvironmentsFolder, "prod")
+	require.NoError(t, os.Chtimes(prodDir, old, old)) // but the summary file is new
+
+	envs, err := store.LoadEnvironments(ctx, datatug.ModifiedSince(since))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod"}, envs.IDs())
+}
+
+func TestFsProjectItemsStore_BriefsOnly(t *testing.T) {
+	ctx := context.Background()
+	store := newFsProjectStore("p1", t.TempDir())
+	entity := &datatug.Entity{
+		ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "e1", Title: "Entity 1"}},
+		Fields:      datatug.EntityFields{{ID: "id", Type: datatug.TypeInteger}},
+	}
+	require.NoError(t, store.SaveEntity(ctx, entity))
+
+	entities, err := store.LoadEntities(ctx, datatug.BriefsOnly())
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	assert.Equal(t, "Entity 1", entities[0].Title)
+	assert.Nil(t, entities[0].Fields)
+	assert.Equal(t, storage.BriefRevision(entity.Revision), entities[0].Revision)
+
+	err = store.SaveEntity(ctx, entities[0])
+	assert.ErrorIs(t, err, storage.ErrBriefItem, "saving a brief would lose trimmed payload")
+
+	loaded, err := store.LoadEntity(ctx, "e1")
+	require.NoError(t, err)
+	assert.Len(t, loaded.Fields, 1)
+	assert.Equal(t, entity.Revision, loaded.Revision)
+}
+
+func TestFsProjectItemsStore_Limit(t *testing.T) {
+	ctx := context.Background()
+	projectPath := t.TempDir()
+	store := newFsProjectStore("p1", projectPath)
+	for _, id := range []string{"b1", "b2", "b3"} {
+		require.NoError(t, store.SaveBoard(ctx, &datatug.Board{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: id}}}))
+	}
+	// an invalid file after the page is not read
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, storage.BoardsFolder, "b4.board.json"), []byte("{"), 0644))
+
+	boards, err := store.LoadBoards(ctx, datatug.Limit(2), datatug.Cursor("b1"))
+	require.NoError(t, err)
+	require.Len(t, boards, 2)
+	assert.Equal(t, "b2", boards[0].ID)
+	assert.Equal(t, "b3", boards[1].ID)
+
+	_, err = store.LoadBoards(ctx, datatug.Limit(2), datatug.Cursor("b1"), datatug.TitleContains("b"))
+	assert.Error(t, err, "all items after the cursor are read to be filtered by title")
+}
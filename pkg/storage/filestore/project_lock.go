@@ -0,0 +1,194 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SaveLockFileName is a file that exists in a project directory while a multi-file save holds the project lock
+const SaveLockFileName = ".datatug-save.lock"
+
+// ErrProjectLocked is returned if a project is locked by a save of another process that is still running
+var ErrProjectLocked = errors.New("project is locked by another save")
+
+const (
+	// saveLockLease is how long a lock of a process that can't be checked, e.g. one on another host, is respected
+	saveLockLease = time.Hour
+	// saveLockGrace is how long an unreadable lock file is respected, as it can be being written by its owner
+	saveLockGrace = 10 * time.Second
+	// saveLockRetryInterval is how often a locked project is checked while waiting for the lock
+	saveLockRetryInterval = 50 * time.Millisecond
+)
+
+// projectMutexes serialize saves of a project within the process, keyed by an absolute project path
+var projectMutexes sync.Map
+
+var hostname, _ = os.Hostname()
+
+// saveLockFile is content of a lock file
+type saveLockFile struct {
+	PID      int       `json:"pid"`
+	Host     string    `json:"host"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// projectLock is held by a multi-file save of a project, it is a mutex for saves of this process
+// & a lock file for saves of other processes
+type projectLock struct {
+	projectPath string
+	mutex       chan struct{}
+}
+
+func projectMutex(projectPath string) chan struct{} {
+	key, err := filepath.Abs(projectPath)
+	if err != nil {
+		key = filepath.Clean(projectPath)
+	}
+	mutex, _ := projectMutexes.LoadOrStore(key, make(chan struct{}, 1))
+	return mutex.(chan struct{})
+}
+
+// lockProject waits until a project is not locked by other saves & locks it
+func lockProject(ctx context.Context, projectPath string) (*projectLock, error) {
+	lock := &projectLock{projectPath: projectPath, mutex: projectMutex(projectPath)}
+	select {
+	case lock.mutex <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", ErrProjectLocked, ctx.Err())
+	}
+	for {
+		err := lock.createLockFile()
+		if err == nil {
+			return lock, nil
+		}
+		if !errors.Is(err, ErrProjectLocked) {
+			lock.release()
+			return nil, err
+		}
+		select {
+		case <-time.After(saveLockRetryInterval):
+		case <-ctx.Done():
+			lock.release()
+			return nil, fmt.Errorf("%w: %v", err, ctx.Err())
+		}
+	}
+}
+
+// tryLockProject locks a project or returns ErrProjectLocked without waiting
+func tryLockProject(projectPath string) (*projectLock, error) {
+	lock := &projectLock{projectPath: projectPath, mutex: projectMutex(projectPath)}
+	select {
+	case lock.mutex <- struct{}{}:
+	default:
+		return nil, ErrProjectLocked
+	}
+	if err := lock.createLockFile(); err != nil {
+		lock.release()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// unlock removes the lock file & releases the mutex
+func (l *projectLock) unlock() error {
+	if l == nil {
+		return nil
+	}
+	defer l.release()
+	if err := os.Remove(filepath.Join(l.projectPath, SaveLockFileName)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove save lock: %w", err)
+	}
+	return nil
+}
+
+// release releases the mutex but keeps the lock file, e.g. to simulate a crash in tests
+func (l *projectLock) release() {
+	<-l.mutex
+}
+
+// createLockFile creates a lock file replacing a stale one, returns ErrProjectLocked if the lock is held by a running save
+func (l *projectLock) createLockFile() error {
+	lockPath := filepath.Join(l.projectPath, SaveLockFileName)
+	content, err := json.Marshal(saveLockFile{PID: os.Getpid(), Host: hostname, Acquired: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		var f *os.File
+		if f, err = os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644); err == nil {
+			if _, err = f.Write(content); err == nil {
+				err = f.Sync()
+			}
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(lockPath)
+				return fmt.Errorf("failed to write save lock: %w", err)
+			}
+			return nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("failed to create save lock: %w", err)
+		}
+		if err = removeStaleLockFile(lockPath); err != nil {
+			return err
+		}
+	}
+	return ErrProjectLocked
+}
+
+// removeStaleLockFile removes a lock file if its owner is not running, otherwise returns ErrProjectLocked.
+// The caller holds the mutex of the project, so a lock file of this process is a leftover of a crashed save.
+func removeStaleLockFile(lockPath string) error {
+	stat, err := os.Stat(lockPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	content, err := os.ReadFile(lockPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read save lock: %w", err)
+	}
+	var lock saveLockFile
+	if err = json.Unmarshal(content, &lock); err != nil {
+		if time.Since(stat.ModTime()) < saveLockGrace {
+			return ErrProjectLocked
+		}
+	} else if !isStaleLock(lock) {
+		return fmt.Errorf("%w: process %v on %s since %v", ErrProjectLocked, lock.PID, lock.Host, lock.Acquired)
+	}
+	// The stale lock is moved aside & checked again, so a lock that has just been taken by another process is kept
+	stalePath := lockPath + ".stale-" + strconv.Itoa(os.Getpid())
+	if err = os.Rename(lockPath, stalePath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove stale save lock: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(stalePath)
+	}()
+	if moved, err := os.ReadFile(stalePath); err == nil && string(moved) != string(content) {
+		_ = os.Link(stalePath, lockPath) // fails if yet another process has locked the project
+		return ErrProjectLocked
+	}
+	return nil
+}
+
+func isStaleLock(lock saveLockFile) bool {
+	if lock.Host == hostname && lock.PID > 0 {
+		return lock.PID == os.Getpid() || !processExists(lock.PID)
+	}
+	return time.Since(lock.Acquired) > saveLockLease
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package filestore
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processExists checks if a process is running by sending it the null signal
+func processExists(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package filestore
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+)
+
+// processExists checks if a process is running, a process that has exited can still be opened
+// while other processes hold its handle, so its exit code is checked too
+func processExists(pid int) bool {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// A process of another user can not be opened but it exists
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+	defer func() {
+		_ = syscall.CloseHandle(h)
+	}()
+	var exitCode uint32
+	if err = syscall.GetExitCodeProcess(h, &exitCode); err != nil {
+		return true
+	}
+	return exitCode == stillActive
+}
@@ -1,14 +1,29 @@
 package filestore
 
 import (
+	"github.com/datatug/datatug-core/pkg/credentials"
 	"github.com/datatug/datatug-core/pkg/datatug"
 	"github.com/datatug/datatug-core/pkg/datatug2md"
 )
 
 var _ datatug.ProjectStore = (*fsProjectStore)(nil)
 
-func newFsProjectStore(projectID string, projectPath string) fsProjectStore {
-	return fsProjectStore{
+// ProjectStoreOption configures a file system project store
+type ProjectStoreOption func(s *fsProjectStore)
+
+// AutoMigrate makes a project store migrate outdated projects on load & save instead of
+// returning storage.OutdatedProjectError. A backup of the project is made in the user config directory
+// before migrating, see MigrateOptions.Backup.
+// Items saved to the project by any store migrate it as well once a store with this option is created.
+// Passwords stripped from project files are moved to credentialsStore, see MigrateOptions.Credentials.
+func AutoMigrate(credentialsStore credentials.Store) ProjectStoreOption {
+	return func(s *fsProjectStore) {
+		s.autoMigrate = &MigrateOptions{Backup: true, Credentials: credentialsStore}
+	}
+}
+
+func newFsProjectStore(projectID string, projectPath string, o ...ProjectStoreOption) fsProjectStore {
+	s := fsProjectStore{
 		projectID:                   projectID,
 		projectPath:                 projectPath,
 		readmeEncoder:               datatug2md.NewEncoder(),
@@ -22,12 +37,21 @@ func newFsProjectStore(projectID string, projectPath string) fsProjectStore {
 		fsEnvDbCatalogStore:         newFsEnvCatalogsStore(projectPath),
 		fsProjDbDriversStore:        newFsProjDbDriversStore(projectPath),
 		fsRecordsetDefinitionsStore: newFsRecordsetDefinitionsStore(projectPath),
+		fsQueryResultsStore:         newFsQueryResultsStore(projectPath),
+	}
+	for _, opt := range o {
+		opt(&s)
+	}
+	if s.autoMigrate != nil {
+		setAutoMigrate(projectPath, s.autoMigrate)
 	}
+	return s
 }
 
 type fsProjectStore struct {
 	projectID     string
 	projectPath   string
+	autoMigrate   *MigrateOptions
 	readmeEncoder datatug.ReadmeEncoder
 	fsBoardsStore
 	fsDbModelsStore
@@ -39,6 +63,7 @@ type fsProjectStore struct {
 	fsEnvDbCatalogStore
 	fsProjDbDriversStore
 	fsRecordsetDefinitionsStore
+	fsQueryResultsStore
 }
 
 func (s fsProjectStore) ProjectID() string {
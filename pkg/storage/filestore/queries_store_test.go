@@ -100,7 +100,7 @@ func TestFsQueriesStore(t *testing.T) {
 			}
 			err := store.SaveQuery(ctx, query)
 			assert.NoError(t, err)
-			assert.FileExists(t, filepath.Join(queriesDir, "query2.query.json"))
+			assert.FileExists(t, filepath.Join(queriesDir, folder1, "query2.query.json"))
 		})
 	})
 }
@@ -0,0 +1,163 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/strongo/validation"
+)
+
+var _ datatug.QueryResultsStore = (*fsQueryResultsStore)(nil)
+
+// queryResultTimeFormat is used for names of result files, it sorts chronologically & has no characters disallowed on Windows
+const queryResultTimeFormat = "20060102T150405.000000000Z"
+
+// WithQueryResultsRetention sets which results are kept per query & environment,
+// by default the last datatug.DefaultMaxQueryResults results are kept
+func WithQueryResultsRetention(retention datatug.QueryResultsRetention) ProjectStoreOption {
+	return func(s *fsProjectStore) {
+		s.fsQueryResultsStore.retention = retention
+	}
+}
+
+func newFsQueryResultsStore(projectPath string) fsQueryResultsStore {
+	return fsQueryResultsStore{
+		dirPath: path.Join(projectPath, storage.ResultsFolder),
+		now:     time.Now,
+	}
+}
+
+// fsQueryResultsStore keeps results in files results/{envID}/{queryID}/{created}.result.json,
+// environment goes first as query IDs can have nested folders
+type fsQueryResultsStore struct {
+	dirPath   string
+	retention datatug.QueryResultsRetention
+	now       func() time.Time
+}
+
+// resultsDirPath returns a folder of results of a query in an environment. Both IDs are checked to be names
+// of folders inside the results folder, a query ID can have nested folders separated by "/".
+func (s fsQueryResultsStore) resultsDirPath(queryID, envID string) (string, error) {
+	if envID == "" {
+		return "", validation.NewErrRequestIsMissingRequiredField("envID")
+	}
+	if !isValidItemID(envID) {
+		return "", validation.NewErrBadRequestFieldValue("envID", "should not be a path: "+envID)
+	}
+	if queryID == "" {
+		return "", validation.NewErrRequestIsMissingRequiredField("queryID")
+	}
+	for _, name := range strings.Split(queryID, "/") {
+		if !isValidItemID(name) {
+			return "", validation.NewErrBadRequestFieldValue("queryID", "should be names of folders separated by '/': "+queryID)
+		}
+	}
+	return path.Join(s.dirPath, envID, queryID), nil
+}
+
+func queryResultFileName(created time.Time) string {
+	return storage.JsonFileName(created.UTC().Format(queryResultTimeFormat), storage.QueryResultFileSuffix)
+}
+
+func (s fsQueryResultsStore) SaveQueryResult(_ context.Context, queryID string, result *datatug.QueryResult) error {
+	if err := datatug.ValidateQueryResultToStore(queryID, result); err != nil {
+		return err
+	}
+	if err := s.retention.Validate(); err != nil {
+		return fmt.Errorf("invalid query results retention: %w", err)
+	}
+	dirPath, err := s.resultsDirPath(queryID, result.EnvironmentID)
+	if err != nil {
+		return err
+	}
+	if err = checkItemFormatVersion(dirPath); err != nil {
+		return err
+	}
+	if err = saveJSONFile(dirPath, queryResultFileName(result.Created), result); err != nil {
+		return fmt.Errorf("failed to save result of query %v: %w", queryID, err)
+	}
+	created, err := s.listQueryResults(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, t := range s.retention.Expired(created, s.now()) {
+		if err = os.Remove(path.Join(dirPath, queryResultFileName(t))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete expired result of query %v: %w", queryID, err)
+		}
+	}
+	return nil
+}
+
+func (s fsQueryResultsStore) ListQueryResults(_ context.Context, queryID, envID string) ([]time.Time, error) {
+	dirPath, err := s.resultsDirPath(queryID, envID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listQueryResults(dirPath)
+}
+
+func (s fsQueryResultsStore) listQueryResults(dirPath string) (created []time.Time, err error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read query results folder: %w", err)
+	}
+	suffix := "." + storage.QueryResultFileSuffix + ".json"
+	for _, entry := range entries {
+		name, isResult := strings.CutSuffix(entry.Name(), suffix)
+		if entry.IsDir() || !isResult {
+			continue // sub-folders hold results of nested queries
+		}
+		t, err := time.Parse(queryResultTimeFormat, name)
+		if err != nil {
+			continue // not a result file
+		}
+		created = append(created, t)
+	}
+	sort.Slice(created, func(i, j int) bool { return created[i].After(created[j]) })
+	return created, nil
+}
+
+func (s fsQueryResultsStore) LoadQueryResult(_ context.Context, queryID, envID string, created time.Time) (*datatug.QueryResult, error) {
+	dirPath, err := s.resultsDirPath(queryID, envID)
+	if err != nil {
+		return nil, err
+	}
+	filePath := path.Join(dirPath, queryResultFileName(created))
+	var result datatug.QueryResult
+	if err := readJSONFile(filePath, true, &result); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: query %v, env %v, created %v", datatug.ErrQueryResultNotFound, queryID, envID, created.UTC().Format(time.RFC3339Nano))
+		}
+		return nil, fmt.Errorf("failed to load result of query %v: %w", queryID, err)
+	}
+	return &result, nil
+}
+
+func (s fsQueryResultsStore) DeleteQueryResults(_ context.Context, queryID, envID string) error {
+	dirPath, err := s.resultsDirPath(queryID, envID)
+	if err != nil {
+		return err
+	}
+	created, err := s.listQueryResults(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, t := range created { // the folder itself is kept as it can hold results of nested queries
+		if err = os.Remove(path.Join(dirPath, queryResultFileName(t))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete result of query %v: %w", queryID, err)
+		}
+	}
+	return nil
+}
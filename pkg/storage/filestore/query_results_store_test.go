@@ -0,0 +1,76 @@
+package filestore
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/datatug/datatug-core/pkg/test/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFsQueryResultsStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("file_layout", func(t *testing.T) {
+		projectPath := t.TempDir()
+		store := newFsProjectStore("p1", projectPath)
+		created := time.Date(2024, 3, 1, 10, 30, 15, 5, time.FixedZone("CET", 3600))
+		require.NoError(t, store.SaveQueryResult(ctx, "reports/daily", storetest.NewQueryResult("dev", created)))
+		_, err := os.Stat(path.Join(projectPath, storage.ResultsFolder, "dev", "reports", "daily", "20240301T093015.000000005Z.result.json"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		store := newFsProjectStore("p1", t.TempDir(), WithQueryResultsRetention(datatug.QueryResultsRetention{MaxResults: 3, MaxAge: 2 * time.Hour}))
+		store.fsQueryResultsStore.now = func() time.Time { return now }
+		for _, age := range []time.Duration{5 * time.Hour, 3 * time.Hour, time.Hour, 30 * time.Minute} {
+			require.NoError(t, store.SaveQueryResult(ctx, "q1", storetest.NewQueryResult("dev", now.Add(-age))))
+		}
+		history, err := store.ListQueryResults(ctx, "q1", "dev")
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{now.Add(-30 * time.Minute), now.Add(-time.Hour)}, history)
+	})
+
+	t.Run("invalid_retention", func(t *testing.T) {
+		store := newFsProjectStore("p1", t.TempDir(), WithQueryResultsRetention(datatug.QueryResultsRetention{MaxResults: -1}))
+		assert.Error(t, store.SaveQueryResult(ctx, "q1", storetest.NewQueryResult("dev", now)))
+	})
+
+	t.Run("ignores_unknown_files", func(t *testing.T) {
+		projectPath := t.TempDir()
+		store := newFsProjectStore("p1", projectPath)
+		require.NoError(t, store.SaveQueryResult(ctx, "q1", storetest.NewQueryResult("dev", now)))
+		dirPath := path.Join(projectPath, storage.ResultsFolder, "dev", "q1")
+		require.NoError(t, os.WriteFile(path.Join(dirPath, "notes.result.json"), []byte("{}"), 0666))
+		require.NoError(t, os.WriteFile(path.Join(dirPath, "README.md"), []byte("# Results"), 0666))
+		history, err := store.ListQueryResults(ctx, "q1", "dev")
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("paths_outside_of_results", func(t *testing.T) {
+		projectPath := path.Join(t.TempDir(), "p1")
+		store := newFsProjectStore("p1", projectPath)
+		for _, ids := range [][2]string{
+			{"q1", "../../x"}, {"q1", ".."}, {"q1", `..\x`}, {"../../x", "dev"}, {"reports/../../x", "dev"}, {"reports//q1", "dev"}, {"q1/.", "dev"},
+		} {
+			queryID, envID := ids[0], ids[1]
+			assert.Error(t, store.SaveQueryResult(ctx, queryID, storetest.NewQueryResult(envID, now)), "%v, %v", queryID, envID)
+			_, err := store.ListQueryResults(ctx, queryID, envID)
+			assert.Error(t, err, "%v, %v", queryID, envID)
+			_, err = store.LoadQueryResult(ctx, queryID, envID, now)
+			assert.Error(t, err, "%v, %v", queryID, envID)
+			assert.Error(t, store.DeleteQueryResults(ctx, queryID, envID), "%v, %v", queryID, envID)
+		}
+		entries, err := os.ReadDir(path.Dir(projectPath))
+		require.NoError(t, err)
+		assert.Empty(t, entries, "nothing should be written outside of the project")
+	})
+}
@@ -2,10 +2,14 @@ package filestore
 
 import (
 	"context"
+	"fmt"
 	"path"
+	"slices"
+	"time"
 
 	"github.com/datatug/datatug-core/pkg/datatug"
 	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/strongo/validation"
 )
 
 var _ datatug.RecordsetDefinitionsStore = (*fsRecordsetDefinitionsStore)(nil)
@@ -14,12 +18,14 @@ func newFsRecordsetDefinitionsStore(projectPath string) fsRecordsetDefinitionsSt
 	return fsRecordsetDefinitionsStore{
 		fsProjectItemsStore: newFileProjectItemsStore[datatug.RecordsetDefinitions, *datatug.RecordsetDefinition, datatug.RecordsetDefinition](
 			path.Join(projectPath, storage.RecordsetsFolder), storage.RecordsetFileSuffix,
-		),
+		).inProjectItemsFormat(projectPath),
+		dataDirPath: path.Join(projectPath, storage.DataFolder),
 	}
 }
 
 type fsRecordsetDefinitionsStore struct {
 	fsProjectItemsStore[datatug.RecordsetDefinitions, *datatug.RecordsetDefinition, datatug.RecordsetDefinition]
+	dataDirPath string
 }
 
 func (s fsRecordsetDefinitionsStore) LoadRecordsetDefinitions(ctx context.Context, o ...datatug.StoreOption) ([]*datatug.RecordsetDefinition, error) {
@@ -30,7 +36,109 @@ func (s fsRecordsetDefinitionsStore) LoadRecordsetDefinition(ctx context.Context
 	return s.loadProjectItem(ctx, s.dirPath, id, "", o...)
 }
 
-func (s fsRecordsetDefinitionsStore) LoadRecordsetData(ctx context.Context, id string) (datatug.Recordset, error) {
-	_, _ = ctx, id
-	panic("implement me") //TODO implement LoadRecordsetData
+// LoadRecordsetData loads rows from all data files listed by the recordset definition
+func (s fsRecordsetDefinitionsStore) LoadRecordsetData(ctx context.Context, id string) (recordset datatug.Recordset, err error) {
+	if err = validateRecordsetID(id); err != nil {
+		return recordset, err
+	}
+	started := time.Now()
+	def, err := s.LoadRecordsetDefinition(ctx, id)
+	if err != nil {
+		return recordset, err
+	}
+	recordset.Columns = recordsetColumns(def)
+	for _, fileName := range def.Files {
+		rows, err := readRecordsetRows(path.Join(s.dataDirPath, id, fileName), recordset.Columns)
+		if err != nil {
+			return recordset, fmt.Errorf("failed to load data of recordset %s from %s: %w", id, fileName, err)
+		}
+		recordset.Rows = append(recordset.Rows, rows...)
+	}
+	recordset.Duration = time.Since(started)
+	return recordset, nil
+}
+
+// LoadRecordsetDataFile loads rows from a data file listed by the recordset definition
+func (s fsRecordsetDefinitionsStore) LoadRecordsetDataFile(ctx context.Context, id, fileName string) (recordset datatug.Recordset, err error) {
+	started := time.Now()
+	def, err := s.loadRecordsetDataFileDefinition(ctx, id, fileName)
+	if err != nil {
+		return recordset, err
+	}
+	recordset.Columns = recordsetColumns(def)
+	if recordset.Rows, err = readRecordsetRows(path.Join(s.dataDirPath, id, fileName), recordset.Columns); err != nil {
+		return recordset, fmt.Errorf("failed to load data of recordset %s from %s: %w", id, fileName, err)
+	}
+	recordset.Duration = time.Since(started)
+	return recordset, nil
+}
+
+// loadRecordsetDataFileDefinition validates the ID & the file name of a data file & returns the recordset definition
+// that should list the file
+func (s fsRecordsetDefinitionsStore) loadRecordsetDataFileDefinition(ctx context.Context, id, fileName string) (*datatug.RecordsetDefinition, error) {
+	if err := validateRecordsetID(id); err != nil {
+		return nil, err
+	}
+	if fileName == "" || fileName != path.Base(fileName) || fileName == ".." {
+		return nil, validation.NewErrBadRequestFieldValue("fileName", "should be a name of a file without a folder: "+fileName)
+	}
+	def, err := s.LoadRecordsetDefinition(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load definition of recordset %s: %w", id, err)
+	}
+	if !slices.Contains(def.Files, fileName) {
+		return nil, validation.NewErrBadRequestFieldValue("fileName", fmt.Sprintf("is not listed in files of recordset %s: %s", id, fileName))
+	}
+	return def, nil
+}
+
+func (s fsRecordsetDefinitionsStore) SaveRecordsetDefinition(ctx context.Context, def *datatug.RecordsetDefinition) error {
+	return s.saveProjectItem(ctx, s.dirPath, def)
+}
+
+// SaveRecordsetData writes rows as JSON objects, the format read by LoadRecordsetData.
+// The file should be listed by the recordset definition, so the definition is saved first.
+func (s fsRecordsetDefinitionsStore) SaveRecordsetData(ctx context.Context, id, fileName string, recordset datatug.Recordset) error {
+	if err := recordset.Validate(); err != nil {
+		return fmt.Errorf("an attempt to save invalid data of recordset %s: %w", id, err)
+	}
+	if _, err := s.loadRecordsetDataFileDefinition(ctx, id, fileName); err != nil {
+		return err
+	}
+	dirPath := path.Join(s.dataDirPath, id)
+	if err := checkItemFormatVersion(dirPath); err != nil {
+		return err
+	}
+	rows := make(recordsetDataFile, len(recordset.Rows))
+	for i, values := range recordset.Rows {
+		row := make(map[string]interface{}, len(recordset.Columns))
+		for j, column := range recordset.Columns {
+			if j < len(values) {
+				row[column.Name] = values[j]
+			}
+		}
+		rows[i] = row
+	}
+	if err := saveJSONFile(dirPath, fileName, rows); err != nil {
+		return fmt.Errorf("failed to save data of recordset %s to %s: %w", id, fileName, err)
+	}
+	return nil
+}
+
+// validateRecordsetID checks that an ID of a recordset can be used as a name of its data directory
+func validateRecordsetID(id string) error {
+	if id == "" {
+		return validation.NewErrRequestIsMissingRequiredField("id")
+	}
+	if !isValidItemID(id) {
+		return validation.NewErrBadRequestFieldValue("id", "should not be a path: "+id)
+	}
+	return nil
+}
+
+// recordsetDataFile holds rows of a recordset data file
+type recordsetDataFile []map[string]interface{}
+
+func (recordsetDataFile) Validate() error {
+	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/datatug/datatug-core/pkg/datatug"
 	"github.com/datatug/datatug-core/pkg/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFsRecordsetDefinitionsStore(t *testing.T) {
@@ -58,9 +59,55 @@ func TestFsRecordsetDefinitionsStore(t *testing.T) {
 		assert.Equal(t, rsID, rs.ID)
 	})
 
-	t.Run("LoadRecordsetData_panic", func(t *testing.T) {
-		assert.Panics(t, func() {
-			_, _ = store.LoadRecordsetData(ctx, "rs1")
-		})
+	t.Run("LoadRecordsetData", func(t *testing.T) {
+		rsID := "rs2"
+		rsDef := datatug.RecordsetDefinition{
+			ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: rsID}},
+			Columns:     datatug.RecordsetColumnDefs{{Name: "id", Type: "int"}, {Name: "name", Type: "string"}},
+			Files:       []string{"a.json", "b.json"},
+		}
+		data, _ := json.Marshal(rsDef)
+		err = os.WriteFile(path.Join(projectPath, storage.RecordsetsFolder, rsID+"."+storage.RecordsetFileSuffix+".json"), data, 0666)
+		assert.NoError(t, err)
+		dataDir := path.Join(projectPath, storage.DataFolder, rsID)
+		assert.NoError(t, os.MkdirAll(dataDir, 0777))
+		assert.NoError(t, os.WriteFile(path.Join(dataDir, "a.json"), []byte(`[{"id":1,"name":"one"}]`), 0666))
+		assert.NoError(t, os.WriteFile(path.Join(dataDir, "b.json"), []byte(`[{"name":"two","id":2}]`), 0666))
+
+		rs, err := store.LoadRecordsetData(ctx, rsID)
+		assert.NoError(t, err)
+		assert.Len(t, rs.Columns, 2)
+		assert.Equal(t, [][]interface{}{{int64(1), "one"}, {int64(2), "two"}}, rs.Rows)
+	})
+
+	t.Run("LoadRecordsetData_missing_file", func(t *testing.T) {
+		assert.NoError(t, os.Remove(path.Join(projectPath, storage.DataFolder, "rs2", "b.json")))
+		_, err := store.LoadRecordsetData(ctx, "rs2")
+		assert.Error(t, err)
+	})
+
+	t.Run("SaveRecordsetData_invalid_path", func(t *testing.T) {
+		rs := datatug.Recordset{Columns: []datatug.RecordsetColumn{{Name: "id"}}}
+		assert.Error(t, store.SaveRecordsetData(ctx, "../..", "a.json", rs))
+		assert.Error(t, store.SaveRecordsetData(ctx, "rs2", "c.json", rs), "file is not listed in the definition")
+		_, err := os.Stat(path.Join(projectPath, storage.DataFolder, "rs2", "c.json"))
+		assert.True(t, os.IsNotExist(err))
+		_, err = store.LoadRecordsetData(ctx, "..")
+		assert.Error(t, err)
+	})
+
+	t.Run("SaveRecordsetData_big_integers", func(t *testing.T) {
+		const big = int64(1<<53 + 1)
+		require.NoError(t, store.SaveRecordsetData(ctx, "rs2", "a.json", datatug.Recordset{
+			Columns: []datatug.RecordsetColumn{{Name: "id"}, {Name: "name"}},
+			Rows:    [][]interface{}{{big, "big"}},
+		}))
+		require.NoError(t, store.SaveRecordsetData(ctx, "rs2", "b.json", datatug.Recordset{
+			Columns: []datatug.RecordsetColumn{{Name: "id"}, {Name: "name"}},
+			Rows:    [][]interface{}{{1.5, "fraction"}},
+		}))
+		rs, err := store.LoadRecordsetData(ctx, "rs2")
+		require.NoError(t, err)
+		assert.Equal(t, [][]interface{}{{big, "big"}, {1.5, "fraction"}}, rs.Rows)
 	})
 }
@@ -0,0 +1,162 @@
+package filestore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sync"
+
+	"github.com/datatug/datatug-core/pkg/storage"
+)
+
+// revisioned is implemented by project items that support optimistic concurrency checks
+type revisioned interface {
+	GetRevision() string
+	SetRevision(revision string)
+}
+
+// fileLocks serializes revision checks & writes of the same file within a process,
+// a lock is removed when it has no users, so the map does not grow with number of saved files
+var fileLocks = struct {
+	sync.Mutex
+	byPath map[string]*fileLock
+}{byPath: make(map[string]*fileLock)}
+
+type fileLock struct {
+	sync.Mutex
+	users int
+}
+
+// lockFiles locks files in a sorted order, so saves locking the same files do not deadlock
+func lockFiles(filePaths ...string) (unlock func()) {
+	filePaths = slices.Compact(slices.Sorted(slices.Values(filePaths)))
+	locks := make([]*fileLock, len(filePaths))
+	fileLocks.Lock()
+	for i, filePath := range filePaths {
+		lock := fileLocks.byPath[filePath]
+		if lock == nil {
+			lock = new(fileLock)
+			fileLocks.byPath[filePath] = lock
+		}
+		lock.users++
+		locks[i] = lock
+	}
+	fileLocks.Unlock()
+	for _, lock := range locks {
+		lock.Lock()
+	}
+	return func() {
+		fileLocks.Lock()
+		defer fileLocks.Unlock()
+		for i, lock := range locks {
+			lock.Unlock()
+			if lock.users--; lock.users == 0 {
+				delete(fileLocks.byPath, filePaths[i])
+			}
+		}
+	}
+}
+
+// fileRevision returns revision of a file content or an empty string if the file does not exist
+func fileRevision(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return storage.ContentRevision(content), nil
+}
+
+// itemRevision returns revision of an item stored in one or more files,
+// e.g. of a query stored in an item file & a SQL file with its text
+func itemRevision(contents ...[]byte) string {
+	if len(contents) == 1 {
+		return storage.ContentRevision(contents[0])
+	}
+	return storage.ContentRevision(bytes.Join(contents, []byte{0}))
+}
+
+// filesRevision returns revision of an item stored in files of a directory, see itemRevision.
+// Files that do not exist are skipped, an empty string is returned if none of the files exists.
+func filesRevision(dirPath string, fileNames ...string) (string, error) {
+	var read []string
+	var contents [][]byte
+	for _, fileName := range fileNames {
+		if fileName == "" || slices.Contains(read, fileName) {
+			continue
+		}
+		read = append(read, fileName)
+		content, err := os.ReadFile(path.Join(dirPath, fileName))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		contents = append(contents, content)
+	}
+	if len(contents) == 0 {
+		return "", nil
+	}
+	return itemRevision(contents...), nil
+}
+
+// saveRevisionedItemFile saves an item if its revision matches the stored one or is empty
+// and sets the revision of the saved content to the item. The revision is checked against
+// currentFileName that differs from fileName when an item is converted to another format.
+// A text file of the item is outdated once the item is saved to the item file, so it is removed.
+func saveRevisionedItemFile(dirPath, fileName, currentFileName, textFileName, id string, item interface{ Validate() error }, revItem revisioned) error {
+	return saveRevisioned(dirPath, fileName, currentFileName, textFileName, id, item, revItem, func() error {
+		if err := saveItemFile(dirPath, fileName, item); err != nil {
+			return err
+		}
+		if textFileName == "" || textFileName == fileName {
+			return nil
+		}
+		if err := os.Remove(path.Join(dirPath, textFileName)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// saveRevisioned checks revision of an item & calls save that writes the item to fileName.
+// An item with a text file next to its item file, e.g. a query with a SQL file, has revision of both files.
+// The file whose revision is checked, the text file & the written file are locked.
+func saveRevisioned(dirPath, fileName, currentFileName, textFileName, id string, item any, revItem revisioned, save func() error) error {
+	lockedPaths := []string{path.Join(dirPath, fileName), path.Join(dirPath, currentFileName)}
+	if textFileName != "" {
+		lockedPaths = append(lockedPaths, path.Join(dirPath, textFileName))
+	}
+	unlock := lockFiles(lockedPaths...)
+	defer unlock()
+
+	expected := revItem.GetRevision()
+	if storage.IsBriefRevision(expected) {
+		return fmt.Errorf("%w: %T[%s]", storage.ErrBriefItem, item, id)
+	}
+	if expected != "" {
+		actual, err := filesRevision(dirPath, currentFileName, textFileName)
+		if err != nil {
+			return fmt.Errorf("failed to get current revision of %T[%s]: %w", item, id, err)
+		}
+		if actual != expected {
+			return storage.NewRevisionConflictError(id, expected, actual)
+		}
+	}
+	revItem.SetRevision("") // revision is not persisted
+	if err := save(); err != nil {
+		revItem.SetRevision(expected)
+		return fmt.Errorf("failed to save %T file: %w", item, err)
+	}
+	revision, err := filesRevision(dirPath, fileName, textFileName)
+	if err != nil {
+		return err
+	}
+	revItem.SetRevision(revision)
+	return nil
+}
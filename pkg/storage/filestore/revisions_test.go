@@ -0,0 +1,88 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRevision(t *testing.T) {
+	dir := t.TempDir()
+	revision, err := fileRevision(path.Join(dir, "missing.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, revision)
+
+	filePath := path.Join(dir, "item.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"id":"i1"}`), 0644))
+	revision, err = fileRevision(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, storage.ContentRevision([]byte(`{"id":"i1"}`)), revision)
+}
+
+func TestLockFiles(t *testing.T) {
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			paths := []string{"dir/a.json", "dir/a.yaml"}
+			if i%2 == 0 { // locked in a different order, e.g. by conversions to different formats
+				paths[0], paths[1] = paths[1], paths[0]
+			}
+			unlock := lockFiles(paths...)
+			defer unlock()
+			counter++
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 20, counter)
+	fileLocks.Lock()
+	defer fileLocks.Unlock()
+	assert.Empty(t, fileLocks.byPath, "unused locks should be removed")
+}
+
+func TestRevisionNotPersisted(t *testing.T) {
+	ctx := context.Background()
+	projDir := t.TempDir()
+	store := newFsProjectStore("p1", projDir)
+
+	server := &datatug.EnvDbServer{ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "localhost", Port: 1433}}
+	require.NoError(t, store.SaveEnvDbServer(ctx, "dev", server))
+	require.NotEmpty(t, server.Revision)
+
+	content, err := os.ReadFile(path.Join(projDir, storage.EnvironmentsFolder, "dev", "localhost:1433.server.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "revision")
+
+	loaded, err := store.LoadEnvDbServer(ctx, "dev", "localhost:1433")
+	require.NoError(t, err)
+	assert.Equal(t, server.Revision, loaded.Revision)
+}
+
+func TestSaveFolder_RevisionConflict(t *testing.T) {
+	ctx := context.Background()
+	store := newFsProjectStore("p1", t.TempDir())
+	folder := &datatug.Folder{Name: "f1"}
+	require.NoError(t, store.SaveFolder(ctx, "", folder))
+	stale := &datatug.Folder{Name: "f1", Revision: folder.Revision}
+
+	folder.Note = "changed"
+	require.NoError(t, store.SaveFolder(ctx, "", folder))
+
+	stale.Note = "stale"
+	err := store.SaveFolder(ctx, "", stale)
+	var conflict storage.RevisionConflictError
+	require.True(t, errors.As(err, &conflict), err)
+	assert.Equal(t, "f1", conflict.ItemID)
+	assert.Equal(t, folder.Revision, conflict.Actual)
+	assert.Equal(t, stale.Revision, conflict.Expected)
+}
@@ -31,3 +31,15 @@ func LoadRootDatatugFile(dir string) (repoRootFile *datatug.RepoRootFile, err er
 	}
 	return
 }
+
+func saveRootDatatugFile(dir string, repoRootFile *datatug.RepoRootFile) error {
+	content, err := yaml.Marshal(repoRootFile)
+	if err != nil {
+		return fmt.Errorf("failed to marshal .datatug.yaml file: %w", err)
+	}
+	filePath := path.Join(dir, storage.RepoRootDataTugFileName)
+	if err = writeBytesAtomically(filePath, content); err != nil {
+		return fmt.Errorf("failed to write .datatug.yaml file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	// SaveJournalFileName is a file that exists in a project directory while a multi-file save is in progress
+	SaveJournalFileName = ".datatug-save-journal.json"
+	// saveBackupDirName keeps copies of files & directories that are overwritten by a multi-file save
+	saveBackupDirName = ".datatug-save-backup"
+)
+
+// saveJournal records project paths changed by a multi-file save, so an interrupted save can be rolled back
+type saveJournal struct {
+	projectPath string
+	lock        *projectLock // nil for a journal of an interrupted save
+	Started     time.Time    `json:"started"`
+	Paths       []string     `json:"paths"` // relative to project directory
+	Existing    []string     `json:"existing,omitempty"`
+}
+
+// beginSave locks the project, backs up given project paths and writes a journal. Once the journal is written
+// a crash leaves the project in a state that is detected & rolled back by RecoverProject.
+// The lock is released by commit or rollback.
+func beginSave(ctx context.Context, projectPath string, paths ...string) (j *saveJournal, err error) {
+	lock, err := lockProject(ctx, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = lock.unlock()
+		}
+	}()
+	if _, err = recoverJournal(projectPath); err != nil {
+		return nil, fmt.Errorf("failed to recover previously interrupted save: %w", err)
+	}
+	backupDir := filepath.Join(projectPath, saveBackupDirName)
+	if err = os.RemoveAll(backupDir); err != nil { // a leftover of a save that crashed before writing the journal
+		return nil, fmt.Errorf("failed to remove stale backup: %w", err)
+	}
+	j = &saveJournal{projectPath: projectPath, lock: lock, Started: time.Now().UTC(), Paths: paths}
+	for _, p := range paths {
+		src := filepath.Join(projectPath, p)
+		if _, err = os.Lstat(src); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if err = copyPath(src, filepath.Join(backupDir, p)); err != nil {
+			return nil, fmt.Errorf("failed to backup %s: %w", p, err)
+		}
+		j.Existing = append(j.Existing, p)
+	}
+	content, err := json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeBytesAtomically(filepath.Join(projectPath, SaveJournalFileName), content); err != nil {
+		return nil, fmt.Errorf("failed to write save journal: %w", err)
+	}
+	return j, nil
+}
+
+// commit completes the save by removing the journal & the backup and unlocks the project
+func (j *saveJournal) commit() error {
+	if err := os.Remove(filepath.Join(j.projectPath, SaveJournalFileName)); err != nil {
+		return fmt.Errorf("failed to remove save journal: %w", err)
+	}
+	syncDir(j.projectPath)
+	if err := os.RemoveAll(filepath.Join(j.projectPath, saveBackupDirName)); err != nil {
+		log.Printf("failed to remove save backup of project at %s: %v", j.projectPath, err)
+	}
+	return j.lock.unlock()
+}
+
+// rollback restores journaled paths from the backup, removes the journal and unlocks the project.
+// A path is restored only while its backup exists, so a rollback that failed halfway can be retried.
+// If it fails the lock file is kept, so the rollback is retried by the next save or RecoverProject.
+func (j *saveJournal) rollback() error {
+	if err := j.restore(); err != nil {
+		if j.lock != nil {
+			j.lock.release()
+		}
+		return err
+	}
+	return j.commit()
+}
+
+func (j *saveJournal) restore() error {
+	backupDir := filepath.Join(j.projectPath, saveBackupDirName)
+	for _, p := range j.Paths {
+		target, backup := filepath.Join(j.projectPath, p), filepath.Join(backupDir, p)
+		_, err := os.Lstat(backup)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		hasBackup := err == nil
+		if !hasBackup && slices.Contains(j.Existing, p) {
+			continue // restored by a previous attempt
+		}
+		if err = os.RemoveAll(target); err != nil {
+			return fmt.Errorf("failed to remove partially saved %s: %w", p, err)
+		}
+		if hasBackup {
+			if err = os.Rename(backup, target); err != nil {
+				return fmt.Errorf("failed to restore %s from backup: %w", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RecoverProject checks if a previous multi-file save of a project has been interrupted
+// (e.g. by a crash) and if so restores the project to the state before that save.
+// A save that is still in progress, in this or another running process, is not rolled back.
+func RecoverProject(projectPath string) (recovered bool, err error) {
+	if _, err = os.Stat(filepath.Join(projectPath, SaveJournalFileName)); errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	lock, err := tryLockProject(projectPath)
+	if err != nil {
+		if errors.Is(err, ErrProjectLocked) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() {
+		if unlockErr := lock.unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+	return recoverJournal(projectPath)
+}
+
+// recoverJournal rolls back a save which journal is left in a project, the caller should hold the project lock
+func recoverJournal(projectPath string) (recovered bool, err error) {
+	content, err := os.ReadFile(filepath.Join(projectPath, SaveJournalFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read save journal: %w", err)
+	}
+	j := saveJournal{projectPath: projectPath}
+	if err = json.Unmarshal(content, &j); err != nil {
+		return false, fmt.Errorf("failed to parse save journal: %w", err)
+	}
+	if err = j.rollback(); err != nil {
+		return false, fmt.Errorf("failed to roll back save started at %v: %w", j.Started, err)
+	}
+	log.Printf("Rolled back interrupted save of project at %s started at %v", projectPath, j.Started)
+	return true, nil
+}
+
+// copyPath recursively copies a file or a directory, a save lock file is not copied
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.Name() == SaveLockFileName {
+			return nil // the lock is held by the process that copies
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0777)
+		}
+		if err = os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+			return err
+		}
+		return copyFile(p, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err = out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,230 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0777))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+}
+
+func readTestFile(t *testing.T, dir, rel string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestRecoverProject(t *testing.T) {
+	t.Run("nothing_to_recover", func(t *testing.T) {
+		recovered, err := RecoverProject(t.TempDir())
+		assert.NoError(t, err)
+		assert.False(t, recovered)
+	})
+
+	t.Run("interrupted_save", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFile(t, dir, "datatug-project.json", "before")
+		writeTestFile(t, dir, "boards/b1.board.json", "b1 before")
+
+		j, err := beginSave(context.Background(), dir, "datatug-project.json", "boards", "entities")
+		require.NoError(t, err)
+		// Simulate a crash in the middle of a save: the lock file is left but its owner is gone
+		j.lock.release()
+		writeTestFile(t, dir, "datatug-project.json", "after")
+		writeTestFile(t, dir, "boards/b1.board.json", "b1 after")
+		writeTestFile(t, dir, "boards/b2.board.json", "b2 new")
+		writeTestFile(t, dir, "entities/e1.entity.json", "e1 new")
+
+		recovered, err := RecoverProject(dir)
+		require.NoError(t, err)
+		assert.True(t, recovered)
+		assert.Equal(t, "before", readTestFile(t, dir, "datatug-project.json"))
+		assert.Equal(t, "b1 before", readTestFile(t, dir, "boards/b1.board.json"))
+		assert.NoFileExists(t, filepath.Join(dir, "boards", "b2.board.json"))
+		assert.NoDirExists(t, filepath.Join(dir, "entities"))
+		assert.NoFileExists(t, filepath.Join(dir, SaveJournalFileName))
+		assert.NoDirExists(t, filepath.Join(dir, saveBackupDirName))
+		assert.NoFileExists(t, filepath.Join(dir, SaveLockFileName))
+
+		recovered, err = RecoverProject(dir)
+		assert.NoError(t, err)
+		assert.False(t, recovered)
+	})
+
+	t.Run("corrupted_journal", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFile(t, dir, SaveJournalFileName, "{")
+		_, err := RecoverProject(dir)
+		assert.Error(t, err)
+	})
+}
+
+func TestSaveJournal_Commit(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "boards/b1.board.json", "b1")
+	writeTestFile(t, dir, saveBackupDirName+"/stale.json", "stale")
+	j, err := beginSave(context.Background(), dir, "boards")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"boards"}, j.Existing)
+	assert.NoFileExists(t, filepath.Join(dir, saveBackupDirName, "stale.json"))
+	require.NoError(t, j.commit())
+	assert.NoFileExists(t, filepath.Join(dir, SaveJournalFileName))
+	assert.NoFileExists(t, filepath.Join(dir, SaveLockFileName))
+	assert.NoDirExists(t, filepath.Join(dir, saveBackupDirName))
+	assert.Equal(t, "b1", readTestFile(t, dir, "boards/b1.board.json"))
+}
+
+func TestSaveProject_RollbackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := newFsProjectStore("p1", dir)
+	project := &datatug.Project{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: "p1", Title: "Before"},
+			Access:        "private",
+		},
+		Created: &datatug.ProjectCreated{At: time.Now()},
+	}
+	require.NoError(t, store.SaveProject(ctx, project))
+	projectFileBefore := readTestFile(t, dir, storage.ProjectSummaryFileName)
+
+	project.Title = "After"
+	project.Boards = datatug.Boards{{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "b1", Title: "B1"}}}}
+	defer func() { osRename = standardOsRename }()
+	osRename = func(oldPath, newPath string) error {
+		if strings.HasSuffix(newPath, "b1.board.json") {
+			return os.ErrPermission
+		}
+		return os.Rename(oldPath, newPath)
+	}
+	err := store.SaveProject(ctx, project)
+	assert.Error(t, err)
+	osRename = standardOsRename
+
+	assert.Equal(t, projectFileBefore, readTestFile(t, dir, storage.ProjectSummaryFileName))
+	assert.NoDirExists(t, filepath.Join(dir, storage.BoardsFolder))
+	assert.NoFileExists(t, filepath.Join(dir, SaveJournalFileName))
+
+	loaded, err := store.LoadProject(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Before", loaded.Title)
+}
+
+func TestLoadProject_RecoversInterruptedSave(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := newFsProjectStore("p1", dir)
+	project := &datatug.Project{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: "p1", Title: "Saved"},
+			Access:        "private",
+		},
+		Created: &datatug.ProjectCreated{At: time.Now()},
+	}
+	require.NoError(t, store.SaveProject(ctx, project))
+
+	j, err := beginSave(ctx, dir, storage.ProjectSummaryFileName)
+	require.NoError(t, err)
+	j.lock.release()
+	writeTestFile(t, dir, storage.ProjectSummaryFileName, `{"id":"p1","title":"trunc`)
+
+	loaded, err := store.LoadProject(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Saved", loaded.Title)
+}
+
+func TestRecoverProject_SaveInProgress(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeTestFile(t, dir, "boards/b1.board.json", "b1 before")
+	j, err := beginSave(ctx, dir, "boards")
+	require.NoError(t, err)
+	writeTestFile(t, dir, "boards/b1.board.json", "b1 saving")
+
+	recovered, err := RecoverProject(dir)
+	require.NoError(t, err)
+	assert.False(t, recovered, "a save in progress should not be rolled back")
+	assert.Equal(t, "b1 saving", readTestFile(t, dir, "boards/b1.board.json"))
+
+	t.Run("second_save_waits", func(t *testing.T) {
+		waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err := beginSave(waitCtx, dir, "boards")
+		assert.ErrorIs(t, err, ErrProjectLocked)
+	})
+
+	require.NoError(t, j.commit())
+	assert.Equal(t, "b1 saving", readTestFile(t, dir, "boards/b1.board.json"))
+}
+
+func TestRecoverProject_LockedByOtherProcess(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "boards/b1.board.json", "b1 before")
+	j, err := beginSave(context.Background(), dir, "boards")
+	require.NoError(t, err)
+	j.lock.release()
+	writeTestFile(t, dir, "boards/b1.board.json", "b1 saving")
+
+	lock, err := json.Marshal(saveLockFile{PID: os.Getppid(), Host: hostname, Acquired: time.Now()})
+	require.NoError(t, err)
+	writeTestFile(t, dir, SaveLockFileName, string(lock))
+	recovered, err := RecoverProject(dir)
+	require.NoError(t, err)
+	assert.False(t, recovered, "a save of a running process should not be rolled back")
+
+	lock, err = json.Marshal(saveLockFile{PID: os.Getppid(), Host: "other-host", Acquired: time.Now().Add(-2 * saveLockLease)})
+	require.NoError(t, err)
+	writeTestFile(t, dir, SaveLockFileName, string(lock))
+	recovered, err = RecoverProject(dir)
+	require.NoError(t, err)
+	assert.True(t, recovered, "a save with an expired lease should be rolled back")
+	assert.Equal(t, "b1 before", readTestFile(t, dir, "boards/b1.board.json"))
+	assert.NoFileExists(t, filepath.Join(dir, SaveLockFileName))
+}
+
+func TestProcessExists(t *testing.T) {
+	assert.True(t, processExists(os.Getpid()))
+	assert.True(t, processExists(os.Getppid()))
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	assert.False(t, processExists(cmd.Process.Pid), "a process that has exited")
+}
+
+func TestSaveProject_BacksUpOnlySavedItems(t *testing.T) {
+	dir := t.TempDir()
+	store := newFsProjectStore("p1", dir)
+	writeTestFile(t, dir, "environments/prod/servers/db.json", "untouched")
+	project := &datatug.Project{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: "p1", Title: "P1"},
+			Access:        "private",
+		},
+		Boards: datatug.Boards{{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "b1", Title: "B1"}}}},
+	}
+	paths, err := store.saveProjectJournalPaths(project)
+	require.NoError(t, err)
+	assert.Equal(t, []string{storage.ProjectSummaryFileName, "README.md", storage.EntitiesFolder, storage.BoardsFolder}, paths,
+		"missing folders should be journaled instead of their items & environments that are not saved should not be backed up")
+
+	writeTestFile(t, dir, "boards/b2.board.json", "b2")
+	paths, err = store.saveProjectJournalPaths(project)
+	require.NoError(t, err)
+	assert.Equal(t, []string{storage.ProjectSummaryFileName, "README.md", storage.EntitiesFolder,
+		"boards/b1.board.json", "boards/b1.board.yaml", "boards/b1.board.yml"}, paths)
+}
@@ -1,6 +1,7 @@
 package filestore
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -36,18 +37,19 @@ func saveJSONFile(dirPath, fileName string, v interface{ Validate() error }) (er
 	}
 
 	fullFileName := path.Join(dirPath, fileName)
-	//log.Printf("Saving file: %v\n%+v", fullFileName, v)
-	file, _ := os.Create(fullFileName)
-	defer func() {
-		_ = file.Close()
-	}()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "\t")
-	if err = encoder.Encode(v); err != nil {
+	redacted, err := redactSecrets(fullFileName, v)
+	if err != nil {
 		return err
 	}
-	return err
+	// Encode before touching the file, so an encoding failure does not leave a truncated file
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	encoder.SetIndent("", "\t")
+	if err = encoder.Encode(redacted); err != nil {
+		return fmt.Errorf("failed to encode %T to JSON: %w", v, err)
+	}
+	//log.Printf("Saving file: %v\n%+v", fullFileName, v)
+	return writeBytesAtomically(fullFileName, content.Bytes())
 }
 
 // Saves each item in a parallel
@@ -1,7 +1,7 @@
 package filestore
 
 import (
-	"os"
+	"io"
 	"path"
 
 	"github.com/datatug/datatug-core/pkg/datatug"
@@ -9,9 +9,7 @@ import (
 
 func (s fsProjectStore) writeProjectReadme(project datatug.Project) error {
 	filePath := path.Join(s.projectPath, "README.md")
-	file, _ := os.Create(filePath)
-	defer func() {
-		_ = file.Close()
-	}()
-	return s.readmeEncoder.ProjectSummaryToReadme(file, project)
+	return writeFileAtomically(filePath, func(w io.Writer) error {
+		return s.readmeEncoder.ProjectSummaryToReadme(w, project)
+	})
 }
@@ -0,0 +1,155 @@
+package filestore
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/dtconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// A SQL query can be stored as a plain "{id}.query.sql" file, so editors & diff tools handle it natively.
+// Metadata of the query is kept as YAML in a leading comment block, e.g.:
+//
+//	/* datatug
+//	title: Active users
+//	tags:
+//	  - users
+//	parameters:
+//	  - id: status
+//	    type: string
+//	*/
+//	SELECT * FROM users WHERE status = @status
+//
+// Properties of the metadata are named as in JSON of datatug.QueryDef.
+// ID of the query is defined by the file name and the type is always SQL.
+
+const (
+	sqlFileExt           = ".sql"
+	sqlQueryHeaderPrefix = "/* datatug"
+	sqlQueryHeaderSuffix = "*/"
+)
+
+// encodeSQLQueryFile writes query text prefixed with a metadata header, the header is omitted if there is no metadata
+func encodeSQLQueryFile(query datatug.QueryDef) ([]byte, error) {
+	text := query.Text
+	query.ID, query.Type, query.Text, query.Revision = "", "", "", ""
+	content, err := encodeItem(dtconfig.FormatJson, query)
+	if err != nil {
+		return nil, err
+	}
+	node, err := jsonToYAMLNode(content)
+	if err != nil {
+		return nil, err
+	}
+	mapping := node.Content[0]
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == "type" { // has no omitempty
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			break
+		}
+	}
+	removeEmptyMappings(mapping)
+	if len(mapping.Content) == 0 {
+		return []byte(text), nil
+	}
+	header, err := encodeYAMLNode(node)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(header, []byte(sqlQueryHeaderSuffix)) {
+		return nil, fmt.Errorf("metadata of query %s can not contain %q", query.Title, sqlQueryHeaderSuffix)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(sqlQueryHeaderPrefix + "\n")
+	buf.Write(header)
+	buf.WriteString(sqlQueryHeaderSuffix + "\n")
+	buf.WriteString(text)
+	return buf.Bytes(), nil
+}
+
+// removeEmptyMappings drops properties with empty objects, e.g. structs without omitempty,
+// as they are decoded to the same zero values and only clutter the header
+func removeEmptyMappings(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if value := node.Content[i+1]; value.Kind == yaml.MappingNode && len(value.Content) == 0 {
+				continue
+			}
+			content = append(content, node.Content[i], node.Content[i+1])
+		}
+		node.Content = content
+	}
+	for _, child := range node.Content {
+		removeEmptyMappings(child)
+	}
+}
+
+// decodeSQLQueryFile reads a query from content of a SQL file
+func decodeSQLQueryFile(content []byte, query *datatug.QueryDef) error {
+	header, text, err := splitSQLQueryFile(string(content))
+	if err != nil {
+		return err
+	}
+	*query = datatug.QueryDef{}
+	if header != "" {
+		if err = decodeItem(dtconfig.FormatYaml, []byte(header), query); err != nil {
+			return fmt.Errorf("failed to decode metadata of SQL query: %w", err)
+		}
+	}
+	query.Type = datatug.QueryTypeSQL
+	query.Text = text
+	return nil
+}
+
+// mergeSQLQueryText sets text of a query stored in an item file with text in a separate SQL file
+func mergeSQLQueryText(content []byte, query *datatug.QueryDef) {
+	if query.Text != "" {
+		return
+	}
+	if _, text, err := splitSQLQueryFile(string(content)); err == nil {
+		query.Text = text
+	} else {
+		query.Text = string(content)
+	}
+}
+
+// splitSQLQueryFile separates the metadata header from the query text
+func splitSQLQueryFile(content string) (header, text string, err error) {
+	rest, ok := cutLine(content, sqlQueryHeaderPrefix)
+	if !ok {
+		return "", content, nil
+	}
+	for offset := 0; offset < len(rest); {
+		lineEnd := strings.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if lineEnd >= 0 {
+			line = rest[offset : offset+lineEnd+1]
+		}
+		if after, isEnd := cutLine(line, sqlQueryHeaderSuffix); isEnd && after == "" {
+			return rest[:offset], rest[offset+len(line):], nil
+		}
+		offset += len(line)
+	}
+	return "", "", fmt.Errorf("metadata header of SQL query is not closed with %q", sqlQueryHeaderSuffix)
+}
+
+// cutLine returns the rest of s if it starts with a line that consists of the prefix only
+func cutLine(s, prefix string) (rest string, ok bool) {
+	if rest, ok = strings.CutPrefix(s, prefix); !ok {
+		return s, false
+	}
+	switch {
+	case rest == "":
+		return "", true
+	case strings.HasPrefix(rest, "\n"):
+		return rest[1:], true
+	case strings.HasPrefix(rest, "\r\n"):
+		return rest[2:], true
+	default:
+		return s, false
+	}
+}
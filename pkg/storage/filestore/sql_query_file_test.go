@@ -0,0 +1,235 @@
+package filestore
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLQuery(id string) datatug.QueryDef {
+	return datatug.QueryDef{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: "Active users", ListOfTags: datatug.ListOfTags{Tags: []string{"users", "reports"}}},
+		},
+		Type:       datatug.QueryTypeSQL,
+		Text:       "SELECT *\nFROM users\nWHERE status = @status\n",
+		Parameters: datatug.Parameters{{ID: "status", Type: "string", DefaultValue: "active"}},
+		Targets:    []datatug.QueryDefTarget{{Driver: "postgres", Catalog: "crm"}},
+		Recordsets: []datatug.RecordsetDefinition{{
+			Type:    "recordset",
+			Columns: datatug.RecordsetColumnDefs{{Name: "id", Type: "int"}, {Name: "email", Type: "string"}},
+		}},
+	}
+}
+
+func TestSQLQueryFile(t *testing.T) {
+	t.Run("round_trip", func(t *testing.T) {
+		query := newTestSQLQuery("q1")
+		content, err := encodeSQLQueryFile(query)
+		require.NoError(t, err)
+		assert.Equal(t, `/* datatug
+title: Active users
+tags:
+  - users
+  - reports
+parameters:
+  - id: status
+    type: string
+    defaultValue: active
+targets:
+  - driver: postgres
+    catalog: crm
+recordsets:
+  - columns:
+      - name: id
+        type: int
+      - name: email
+        type: string
+    type: recordset
+*/
+SELECT *
+FROM users
+WHERE status = @status
+`, string(content))
+
+		var decoded datatug.QueryDef
+		require.NoError(t, decodeSQLQueryFile(content, &decoded))
+		query.ID = ""
+		assert.Equal(t, query, decoded)
+	})
+
+	t.Run("no_metadata", func(t *testing.T) {
+		query := datatug.QueryDef{Type: datatug.QueryTypeSQL, Text: "SELECT 1"}
+		content, err := encodeSQLQueryFile(query)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT 1", string(content))
+	})
+
+	t.Run("hand_written", func(t *testing.T) {
+		var query datatug.QueryDef
+		content := "/* datatug\r\ntitle: Orders\r\ntags: [sales]\r\n*/\r\n/* a regular comment */\r\nSELECT 1"
+		require.NoError(t, decodeSQLQueryFile([]byte(content), &query))
+		assert.Equal(t, "Orders", query.Title)
+		assert.Equal(t, []string{"sales"}, query.Tags)
+		assert.Equal(t, datatug.QueryTypeSQL, query.Type)
+		assert.Equal(t, "/* a regular comment */\r\nSELECT 1", query.Text)
+	})
+
+	t.Run("regular_comment", func(t *testing.T) {
+		var query datatug.QueryDef
+		content := "/* title: not metadata */\nSELECT 1"
+		require.NoError(t, decodeSQLQueryFile([]byte(content), &query))
+		assert.Empty(t, query.Title)
+		assert.Equal(t, content, query.Text)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var query datatug.QueryDef
+		assert.Error(t, decodeSQLQueryFile([]byte("/* datatug\ntitle: Orders\nSELECT 1"), &query), "header is not closed")
+		assert.Error(t, decodeSQLQueryFile([]byte("/* datatug\n- not a mapping\n*/\nSELECT 1"), &query))
+
+		q := newTestSQLQuery("q1")
+		q.Title = "Comments end with */"
+		_, err := encodeSQLQueryFile(q)
+		assert.Error(t, err)
+	})
+}
+
+func TestFsQueriesStore_SQLFiles(t *testing.T) {
+	ctx := context.Background()
+	queriesDir := func(projectPath string, folder ...string) string {
+		return path.Join(append([]string{projectPath, storage.QueriesFolder}, folder...)...)
+	}
+
+	t.Run("project_setting", func(t *testing.T) {
+		projectPath := t.TempDir()
+		project := newTestProject("p1")
+		project.SQLQueryFiles = true
+		require.NoError(t, newFsProjectStore("p1", projectPath).SaveProject(ctx, project))
+		store := newFsProjectStore("p1", projectPath)
+
+		query := &datatug.QueryDefWithFolderPath{FolderPath: "~/reports", QueryDef: newTestSQLQuery("active_users")}
+		require.NoError(t, store.SaveQuery(ctx, query))
+		assert.FileExists(t, path.Join(queriesDir(projectPath, "reports"), "active_users.query.sql"))
+		assert.NoFileExists(t, path.Join(queriesDir(projectPath, "reports"), "active_users.query.json"))
+		assert.NotEmpty(t, query.Revision)
+
+		loaded, err := store.LoadQuery(ctx, "reports/active_users")
+		require.NoError(t, err)
+		assert.Equal(t, query.QueryDef, *loaded)
+
+		t.Run("revision_conflict", func(t *testing.T) {
+			stale := *loaded
+			loaded.Text = "SELECT 1"
+			require.NoError(t, store.SaveQuery(ctx, &datatug.QueryDefWithFolderPath{FolderPath: "reports", QueryDef: *loaded}))
+			err := store.SaveQuery(ctx, &datatug.QueryDefWithFolderPath{FolderPath: "reports", QueryDef: stale})
+			assert.ErrorIs(t, err, storage.ErrRevisionConflict)
+		})
+
+		t.Run("http_query_is_not_sql_file", func(t *testing.T) {
+			httpQuery := &datatug.QueryDefWithFolderPath{FolderPath: "~", QueryDef: datatug.QueryDef{
+				ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "ping", Title: "Ping"}},
+				Type:        datatug.QueryTypeHTTP,
+				Text:        "GET /ping",
+			}}
+			require.NoError(t, store.SaveQuery(ctx, httpQuery))
+			assert.FileExists(t, path.Join(queriesDir(projectPath), "ping.query.json"))
+		})
+	})
+
+	t.Run("both_forms", func(t *testing.T) {
+		projectPath := t.TempDir()
+		writeTestProjectFile(t, projectPath, "")
+		store := newFsProjectStore("p1", projectPath)
+
+		writeTestFile(t, queriesDir(projectPath), "q1.query.json", `{"id":"q1","title":"JSON query","type":"SQL","text":"SELECT 1"}`)
+		writeTestFile(t, queriesDir(projectPath), "reports/q2.query.sql", "/* datatug\ntitle: SQL query\n*/\nSELECT 2\n")
+		writeTestFile(t, queriesDir(projectPath), "reports/daily/q3.query.sql", "SELECT 3")
+		writeTestFile(t, queriesDir(projectPath), "reports/README.md", "# Reports")
+
+		folder, err := store.LoadQueries(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, folder.Items, 1)
+		assert.Equal(t, "JSON query", folder.Items[0].Title)
+		require.Len(t, folder.Folders, 1)
+		reports := folder.Folders[0]
+		assert.Equal(t, "reports", reports.ID)
+		require.Len(t, reports.Items, 1)
+		assert.Equal(t, "q2", reports.Items[0].ID)
+		assert.Equal(t, "SQL query", reports.Items[0].Title)
+		assert.Equal(t, "SELECT 2\n", reports.Items[0].Text)
+		require.Len(t, reports.Folders, 1)
+		require.Len(t, reports.Folders[0].Items, 1)
+		assert.Equal(t, "q3", reports.Folders[0].Items[0].ID)
+
+		t.Run("briefs_only", func(t *testing.T) {
+			folder, err := store.LoadQueries(ctx, "reports", datatug.BriefsOnly())
+			require.NoError(t, err)
+			require.Len(t, folder.Items, 1)
+			assert.Empty(t, folder.Items[0].Text)
+		})
+
+		t.Run("existing_sql_file_is_kept", func(t *testing.T) {
+			q2 := reports.Items[0]
+			q2.Title = "Renamed"
+			require.NoError(t, store.SaveQuery(ctx, &datatug.QueryDefWithFolderPath{FolderPath: "reports", QueryDef: *q2}))
+			assert.Equal(t, "/* datatug\ntitle: Renamed\n*/\nSELECT 2\n", readTestFile(t, queriesDir(projectPath, "reports"), "q2.query.sql"))
+			assert.NoFileExists(t, path.Join(queriesDir(projectPath, "reports"), "q2.query.json"))
+		})
+
+		t.Run("converted_to_item_file", func(t *testing.T) {
+			q3 := reports.Folders[0].Items[0]
+			q3.Title = "Over HTTP"
+			q3.Type = datatug.QueryTypeHTTP
+			require.NoError(t, store.SaveQuery(ctx, &datatug.QueryDefWithFolderPath{FolderPath: "reports/daily", QueryDef: *q3}))
+			assert.FileExists(t, path.Join(queriesDir(projectPath, "reports", "daily"), "q3.query.json"))
+			assert.NoFileExists(t, path.Join(queriesDir(projectPath, "reports", "daily"), "q3.query.sql"))
+		})
+
+		t.Run("delete", func(t *testing.T) {
+			require.NoError(t, store.DeleteQuery(ctx, "reports/q2"))
+			assert.NoFileExists(t, path.Join(queriesDir(projectPath, "reports"), "q2.query.sql"))
+		})
+	})
+
+	t.Run("text_of_created_query", func(t *testing.T) {
+		projectPath := t.TempDir()
+		store := newFsProjectStore("p1", projectPath)
+		query := datatug.QueryDefWithFolderPath{FolderPath: "~", QueryDef: newTestSQLQuery("q1")}
+		created, err := store.CreateQuery(ctx, query)
+		require.NoError(t, err)
+		assert.FileExists(t, path.Join(queriesDir(projectPath), "q1.query.json"))
+		assert.FileExists(t, path.Join(queriesDir(projectPath), "q1.query.sql"))
+		assert.NotEmpty(t, created.Revision)
+
+		loaded, err := store.LoadQuery(ctx, "q1")
+		require.NoError(t, err)
+		assert.Equal(t, query.Text, loaded.Text, "text should be read from the SQL file next to the item file")
+		assert.Equal(t, created.Revision, loaded.Revision)
+		folder, err := store.LoadQueries(ctx, "")
+		require.NoError(t, err)
+		assert.Len(t, folder.Items, 1, "the SQL file of the query should not be listed as a separate query")
+
+		t.Run("revision_conflict", func(t *testing.T) {
+			writeTestFile(t, queriesDir(projectPath), "q1.query.sql", "SELECT 2")
+			loaded.Title = "Changed"
+			err := store.SaveQuery(ctx, &datatug.QueryDefWithFolderPath{FolderPath: "~", QueryDef: *loaded})
+			assert.ErrorIs(t, err, storage.ErrRevisionConflict, "a change of the SQL file should be detected")
+			_, err = store.CreateQuery(ctx, datatug.QueryDefWithFolderPath{FolderPath: "~", QueryDef: *loaded})
+			assert.ErrorIs(t, err, storage.ErrRevisionConflict)
+			assert.Equal(t, "SELECT 2", readTestFile(t, queriesDir(projectPath), "q1.query.sql"))
+
+			reloaded, err := store.LoadQuery(ctx, "q1")
+			require.NoError(t, err)
+			reloaded.Text = ""
+			_, err = store.CreateQuery(ctx, datatug.QueryDefWithFolderPath{FolderPath: "~", QueryDef: *reloaded})
+			require.NoError(t, err)
+			assert.NoFileExists(t, path.Join(queriesDir(projectPath), "q1.query.sql"), "an outdated SQL file should be removed")
+		})
+	})
+}
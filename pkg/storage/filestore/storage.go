@@ -48,16 +48,10 @@ func (f fsStorage) WriteFile(_ context.Context, filePath string, reader io.Reade
 	if err := osMkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
-	file, err := osCreate(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer func() {
-		_ = file.Close()
-	}()
-	_, err = io.Copy(file, reader)
-	if err != nil {
-		return fmt.Errorf("failed to write to file: %w", err)
-	}
-	return nil
+	return writeFileAtomically(filePath, func(w io.Writer) error {
+		if _, err := io.Copy(w, reader); err != nil {
+			return fmt.Errorf("failed to write to file: %w", err)
+		}
+		return nil
+	})
 }
@@ -98,9 +98,9 @@ func TestFsStorage(t *testing.T) {
 	})
 
 	t.Run("WriteFile_CreateError", func(t *testing.T) {
-		oldOsCreate := osCreate
-		defer func() { osCreate = oldOsCreate }()
-		osCreate = func(name string) (io.WriteCloser, error) {
+		oldOsCreateTemp := osCreateTemp
+		defer func() { osCreateTemp = oldOsCreateTemp }()
+		osCreateTemp = func(dir, pattern string) (tempFile, error) {
 			return nil, os.ErrPermission
 		}
 		err := storage.WriteFile(ctx, "test.txt", strings.NewReader("content"))
@@ -2,43 +2,227 @@ package filestore
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/datatug/datatug-core/pkg/datatug"
 	"github.com/datatug/datatug-core/pkg/dto"
 	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/datatug/datatug-core/pkg/storage/dtprojcreator"
+	"github.com/strongo/validation"
 )
 
+// ProjectsDirName is a name of a directory in a repository root where new projects are created
+const ProjectsDirName = "datatug"
+
 // NewStore create a storage for multiple projects by their dir paths
 func NewStore(id string, pathsByID map[string]string) (fsStore storage.Store, err error) {
 	return newStore(id, pathsByID), nil
 }
 
+// NewRepoStore creates a storage for projects listed in the .datatug.yaml file at the repository root.
+// New projects are created in the "datatug" subdirectory of the repository root.
+// Projects are identified by names of their directories, so the names should be unique within the repository.
+func NewRepoStore(id, rootDir string) (*FsStore, error) {
+	pathByID := make(map[string]string)
+	repoRootFile, err := LoadRootDatatugFile(rootDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if repoRootFile != nil {
+		for _, projPath := range repoRootFile.Projects {
+			projID, fullPath := path.Base(projPath), path.Join(rootDir, projPath)
+			if existing, ok := pathByID[projID]; ok && existing != fullPath {
+				return nil, fmt.Errorf("projects %v & %v listed in %v have the same ID %v, rename a directory of one of them",
+					existing, fullPath, storage.RepoRootDataTugFileName, projID)
+			}
+			pathByID[projID] = fullPath
+		}
+	}
+	store := newStore(id, pathByID)
+	store.rootDir = rootDir
+	return store, nil
+}
+
 var _ storage.Store = (*FsStore)(nil)
 
 // FsStore provides implementation of file system storage
 type FsStore struct {
-	id               string
-	pathByID         map[string]string
-	fileSystemLoader // TODO: To be deleted
+	id       string
+	rootDir  string // repository root, required to create projects
+	mutex    *sync.RWMutex
+	pathByID map[string]string
+	// options of project stores returned by GetProjectStore
+	projectStoreOptions []ProjectStoreOption
+	fileSystemLoader    // TODO: To be deleted
 	//storeSaver       // TODO: To be deleted
 }
 
-func (store FsStore) CreateProject(_ context.Context, _ dto.CreateProjectRequest) (*datatug.ProjectSummary, error) {
-	panic("not implemented")
+// CreateProject creates project files in a new directory under the repository root
+// and registers the project in the .datatug.yaml file
+func (store FsStore) CreateProject(ctx context.Context, request dto.CreateProjectRequest) (*datatug.ProjectSummary, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if store.rootDir == "" {
+		return nil, errors.New("store has no repository root directory to create projects in")
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	projID, err := store.newProjectID(request.Title)
+	if err != nil {
+		return nil, err
+	}
+	relPath := path.Join(ProjectsDirName, projID)
+	project := &datatug.Project{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{
+				ID:    projID,
+				Title: request.Title,
+			},
+			Access: "private",
+		},
+	}
+	reportStatus := func(string, string) {}
+	if err = dtprojcreator.CreateProjectFiles(ctx, project, relPath, NewStorage(store.rootDir), reportStatus); err != nil {
+		return nil, fmt.Errorf("failed to create project files: %w", err)
+	}
+	projPath := path.Join(store.rootDir, relPath)
+	projFile, err := LoadProjectFile(projPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load created project file: %w", err)
+	}
+	store.pathByID[projID] = projPath
+	return &datatug.ProjectSummary{ProjectFile: projFile}, nil
+}
+
+// newProjectID generates a project ID from a title that is not used by a registered project or an existing directory
+func (store FsStore) newProjectID(title string) (string, error) {
+	baseID := titleToID(title)
+	if baseID == "" {
+		return "", validation.NewErrBadRequestFieldValue("title", "should contain at least 1 letter or digit")
+	}
+	for i := 1; ; i++ {
+		id := baseID
+		if i > 1 {
+			id += "-" + strconv.Itoa(i)
+		}
+		if _, registered := store.pathByID[id]; registered {
+			continue
+		}
+		if _, err := os.Stat(path.Join(store.rootDir, ProjectsDirName, id)); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		return id, nil
+	}
+}
+
+func titleToID(title string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && sb.Len() > 0 {
+				sb.WriteRune('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return sb.String()
 }
 
 func (store FsStore) GetProjectStore(id string) datatug.ProjectStore {
+	store.mutex.RLock()
 	path := store.pathByID[id]
-	return newFsProjectStore(id, path)
+	store.mutex.RUnlock()
+	return newFsProjectStore(id, path, store.projectStoreOptions...)
+}
+
+// SetProjectStoreOptions sets options of project stores returned by GetProjectStore, e.g. AutoMigrate
+func (store *FsStore) SetProjectStoreOptions(o ...ProjectStoreOption) {
+	store.projectStoreOptions = o
 }
 
+// DeleteProject archives project directory by renaming it to a hidden ".<dir>.deleted-<timestamp>" sibling
+// and removes the project from the store and from the .datatug.yaml file at the repository root.
+// For safety, the directory is not touched if it does not contain a project file.
 func (store FsStore) DeleteProject(_ context.Context, id string) error {
-	return fmt.Errorf("not implemented yet, id=%s", id)
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	projPath, ok := store.pathByID[id]
+	if !ok {
+		return fmt.Errorf("%w: id=%s", datatug.ErrProjectDoesNotExist, id)
+	}
+	if err := store.checkProjectDirCanBeDeleted(projPath); err != nil {
+		return fmt.Errorf("refusing to delete project %s at %s: %w", id, projPath, err)
+	}
+	dir, name := path.Split(projPath)
+	archivePath := path.Join(dir, fmt.Sprintf(".%s.deleted-%s", name, time.Now().UTC().Format("20060102T150405.000000000")))
+	if err := os.Rename(projPath, archivePath); err != nil {
+		return fmt.Errorf("failed to archive project directory: %w", err)
+	}
+	delete(store.pathByID, id)
+	if store.rootDir != "" {
+		if err := store.removeFromRepoRootFile(projPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store FsStore) checkProjectDirCanBeDeleted(projPath string) error {
+	cleanPath := filepath.Clean(projPath)
+	if cleanPath == "/" || cleanPath == "." || cleanPath == filepath.Clean(store.rootDir) {
+		return errors.New("not a project directory")
+	}
+	if store.rootDir != "" {
+		if rel, err := filepath.Rel(store.rootDir, cleanPath); err != nil || strings.HasPrefix(rel, "..") {
+			return errors.New("project directory is outside of the repository root")
+		}
+	}
+	if _, err := LoadProjectFile(projPath); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (store FsStore) removeFromRepoRootFile(projPath string) error {
+	repoRootFile, err := LoadRootDatatugFile(store.rootDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	rel, err := filepath.Rel(store.rootDir, projPath)
+	if err != nil {
+		return err
+	}
+	repoRootFile.Projects = slices.DeleteFunc(repoRootFile.Projects, func(p string) bool {
+		return path.Clean(p) == filepath.ToSlash(rel)
+	})
+	return saveRootDatatugFile(store.rootDir, repoRootFile)
 }
 
 // GetProjects returns list of projects
 func (store FsStore) GetProjects(context.Context) (projectBriefs []datatug.ProjectBrief, err error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
 	projectBriefs = make([]datatug.ProjectBrief, len(store.pathByID))
 	var i int
 	for id, path := range store.pathByID {
@@ -57,14 +241,18 @@ func (store FsStore) GetProjects(context.Context) (projectBriefs []datatug.Proje
 
 // newStore creates an instance of storage that implements storage.Store
 func newStore(id string, pathByID map[string]string) *FsStore {
+	if pathByID == nil {
+		pathByID = make(map[string]string)
+	}
 	return &FsStore{
 		id:       id,
+		mutex:    new(sync.RWMutex),
 		pathByID: pathByID,
 	}
 }
 
-func NewProjectStore(id, path string) datatug.ProjectStore {
-	return newFsProjectStore(id, path)
+func NewProjectStore(id, path string, o ...ProjectStoreOption) datatug.ProjectStore {
+	return newFsProjectStore(id, path, o...)
 }
 
 // NewSingleProjectStore creates an instance of storage that implements storage.Store for a single project
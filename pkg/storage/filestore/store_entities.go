@@ -15,7 +15,7 @@ func newFsEntitiesStore(projectPath string) fsEntitiesStore {
 	return fsEntitiesStore{
 		fsProjectItemsStore: newFileProjectItemsStore[datatug.Entities, *datatug.Entity, datatug.Entity](
 			path.Join(projectPath, storage.EntitiesFolder), storage.EntityFileSuffix,
-		),
+		).inProjectItemsFormat(projectPath),
 	}
 }
 
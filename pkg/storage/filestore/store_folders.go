@@ -42,7 +42,5 @@ func (s fsFoldersStore) SaveFolders(ctx context.Context, folderPath string, fold
 }
 
 func (s fsFoldersStore) DeleteFolder(ctx context.Context, id string) (err error) {
-	folderPath := path.Base(id)
-	dirPath := path.Join(s.dirPath, folderPath)
-	return s.deleteProjectItem(ctx, dirPath, id)
+	return s.deleteProjectItem(ctx, s.dirPath, id)
 }
@@ -20,6 +20,12 @@ type fileSystemLoader struct {
 // LoadProject loads project
 func (s fsProjectStore) LoadProject(ctx context.Context, o ...datatug.StoreOption) (*datatug.Project, error) {
 	opts := datatug.GetStoreOptions(o...)
+	if _, err := RecoverProject(s.projectPath); err != nil {
+		return nil, fmt.Errorf("failed to recover partially saved project: %w", err)
+	}
+	if err := s.checkFormatVersion(); err != nil {
+		return nil, err
+	}
 	project := datatug.NewProjectWithStore(s.projectID, s)
 	if err := loadProjectFile(s.projectPath, project); err != nil {
 		return nil, fmt.Errorf("failed to load project file: %w", err)
@@ -56,6 +56,7 @@ func TestFsProjectStore_LoadProjectSummary(t *testing.T) {
 	}()
 
 	projFile := datatug.ProjectFile{
+		FormatVersion: datatug.ProjectFormatVersion,
 		ProjectItem: datatug.ProjectItem{
 			ProjItemBrief: datatug.ProjItemBrief{
 				Title: "Test Project",
@@ -123,6 +124,7 @@ func TestFsProjectStore_LoadProject(t *testing.T) {
 	const projectID = "p1"
 
 	projFile := datatug.ProjectFile{
+		FormatVersion: datatug.ProjectFormatVersion,
 		ProjectItem: datatug.ProjectItem{
 			ProjItemBrief: datatug.ProjItemBrief{
 				Title: "Test Project",
@@ -2,6 +2,7 @@ package filestore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -18,18 +19,32 @@ func (s fsProjectStore) SaveProject(ctx context.Context, project *datatug.Projec
 		return fmt.Errorf("project validation failed: %w", err)
 	}
 	//log.Println("GetProjectStore is valid")
+	if err = s.checkFormatVersion(); err != nil {
+		return err
+	}
 	if err = os.MkdirAll(s.projectPath, 0777); err != nil {
 		return fmt.Errorf("failed to create datatug folder: %w", err)
 	}
+	journalPaths, err := s.saveProjectJournalPaths(project)
+	if err != nil {
+		return fmt.Errorf("failed to list paths written by project save: %w", err)
+	}
+	journal, err := beginSave(ctx, s.projectPath, journalPaths...)
+	if err != nil {
+		return fmt.Errorf("failed to start project save: %w", err)
+	}
+	defer func() {
+		if err == nil {
+			err = journal.commit()
+		} else if rollbackErr := journal.rollback(); rollbackErr != nil {
+			err = fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+	}()
+	// The project file is saved before items as it defines the format items are written in
+	if err = s.saveProjectFile(project); err != nil {
+		return fmt.Errorf("failed to save project file: %w", err)
+	}
 	if err = parallel.Run(
-		func() (err error) {
-			//log.Println("Saving project file...")
-			if err = s.saveProjectFile(project); err != nil {
-				return fmt.Errorf("failed to save project file: %w", err)
-			}
-			//log.Println("Saved project file.")
-			return
-		},
 		func() (err error) {
 			if len(project.Entities) > 0 {
 				//log.Printf("Saving %v entities...\n", len(project.Entities))
@@ -89,10 +104,29 @@ func (s fsProjectStore) SaveProject(ctx context.Context, project *datatug.Projec
 	return nil
 }
 
+// saveProjectJournalPaths returns paths written by SaveProject, so only files of saved items are backed up
+func (s fsProjectStore) saveProjectJournalPaths(project *datatug.Project) (paths []string, err error) {
+	paths = []string{storage.ProjectSummaryFileName, "README.md"}
+	var itemPaths []string
+	if itemPaths, err = s.fsEntitiesStore.journalPaths(s.projectPath, project.Entities); err != nil {
+		return nil, err
+	}
+	paths = append(paths, itemPaths...)
+	if itemPaths, err = s.fsEnvironmentsStore.journalPaths(s.projectPath, project.Environments); err != nil {
+		return nil, err
+	}
+	paths = append(paths, itemPaths...)
+	if itemPaths, err = s.fsBoardsStore.journalPaths(s.projectPath, project.Boards); err != nil {
+		return nil, err
+	}
+	return append(paths, itemPaths...), nil
+}
+
 func (s fsProjectStore) putProjectFile(projFile datatug.ProjectFile) error {
 	if err := projFile.Validate(); err != nil {
 		return fmt.Errorf("invalid project file: %w", err)
 	}
+	defer refreshProjectFile(s.projectPath)
 	return saveJSONFile(s.projectPath, storage.ProjectSummaryFileName, projFile)
 }
 
@@ -110,16 +144,35 @@ func (s fsProjectStore) saveProjectFile(project *datatug.Project) error {
 	//	return err
 	//}
 	projFile := datatug.ProjectFile{
+		FormatVersion: datatug.ProjectFormatVersion,
 		ProjectItem: datatug.ProjectItem{
 			ProjItemBrief: datatug.ProjItemBrief{
-				ID: project.ID,
+				ID:    project.ID,
+				Title: project.Title,
 			},
 			Access: project.Access,
 		},
-		Repository: project.Repository,
+		Repository:    project.Repository,
+		ItemsFormat:   project.ItemsFormat,
+		SQLQueryFiles: project.SQLQueryFiles,
+		SecretsPolicy: project.SecretsPolicy,
+		Variables:     project.Variables,
+		VarSettings:   project.VarSettings,
 		//UUID:    project.UUID,
 		Created: project.Created,
 	}
+	if projFile.SecretsPolicy == "" {
+		// A new project refuses secrets, an existing one keeps its policy, so its saves do not start failing
+		existing, err := cachedProjectFile(s.projectPath)
+		switch {
+		case err == nil:
+			projFile.SecretsPolicy = existing.SecretsPolicy
+		case errors.Is(err, datatug.ErrProjectDoesNotExist):
+			projFile.SecretsPolicy = datatug.SecretsPolicyRefuse
+		default:
+			return err
+		}
+	}
 	//if existingProject.UUID == uuid.Nil {
 	//	projFile.UUID = project.UUID
 	//} else {
@@ -3,6 +3,8 @@ package filestore
 import (
 	"context"
 	"errors"
+	"io/fs"
+	"os"
 	"path"
 	"strings"
 
@@ -11,11 +13,17 @@ import (
 )
 
 func newFsQueriesStore(projectPath string) fsQueriesStore {
-	return fsQueriesStore{
+	s := fsQueriesStore{
 		fsProjectItemsStore: newFileProjectItemsStore[datatug.QueryDefs, *datatug.QueryDef, datatug.QueryDef](
 			path.Join(projectPath, storage.QueriesFolder), storage.QueryFileSuffix,
-		),
+		).inProjectItemsFormat(projectPath),
 	}
+	s.textFormat = &textFormat[*datatug.QueryDef]{
+		ext:    sqlFileExt,
+		decode: decodeSQLQueryFile,
+		merge:  mergeSQLQueryText,
+	}
+	return s
 }
 
 var _ datatug.QueriesStore = (*fsQueriesStore)(nil)
@@ -24,10 +32,10 @@ type fsQueriesStore struct {
 	fsProjectItemsStore[datatug.QueryDefs, *datatug.QueryDef, datatug.QueryDef]
 }
 
+// LoadQueries loads queries of a folder with its sub-folders, queries are read from item & SQL files
 func (s fsQueriesStore) LoadQueries(ctx context.Context, folderPath string, o ...datatug.StoreOption) (folder *datatug.QueriesFolder, err error) {
-	_ = datatug.GetStoreOptions(o...)
-	dirPath := path.Join(s.dirPath, folderPath)
-	items, err := s.loadProjectItems(ctx, dirPath)
+	dirPath := s.queriesDirPath(folderPath)
+	items, err := s.loadProjectItems(ctx, dirPath, o...)
 	if err != nil {
 		return nil, err
 	}
@@ -35,9 +43,35 @@ func (s fsQueriesStore) LoadQueries(ctx context.Context, folderPath string, o ..
 		Items: make(datatug.QueryDefs, len(items)),
 	}
 	copy(folder.Items, items)
+	entries, err := os.ReadDir(dirPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		subFolder, err := s.LoadQueries(ctx, path.Join(folderPath, entry.Name()), o...)
+		if err != nil {
+			return nil, err
+		}
+		subFolder.ID = entry.Name()
+		folder.Folders = append(folder.Folders, subFolder)
+	}
 	return folder, nil
 }
 
+// queriesDirPath returns path to a directory of a queries folder, both "" & "~" refer to the root folder
+func (s fsQueriesStore) queriesDirPath(folderPath string) string {
+	folderPath = strings.Trim(folderPath, "/")
+	if folderPath == datatug.RootSharedFolderName {
+		folderPath = ""
+	} else {
+		folderPath = strings.TrimPrefix(folderPath, datatug.RootSharedFolderName+"/")
+	}
+	return path.Join(s.dirPath, folderPath)
+}
+
 func (s fsQueriesStore) LoadQuery(ctx context.Context, id string, o ...datatug.StoreOption) (query *datatug.QueryDef, err error) {
 	ids := strings.Split(id, "/")
 	folder := path.Join(ids[:len(ids)-1]...)
@@ -48,13 +82,11 @@ func (s fsQueriesStore) LoadQuery(ctx context.Context, id string, o ...datatug.S
 }
 
 func (s fsQueriesStore) UpdateQuery(ctx context.Context, query datatug.QueryDef) (q *datatug.QueryDefWithFolderPath, err error) {
-	err = s.saveProjectItem(ctx, s.dirPath, &query)
-	if err != nil {
+	q = &datatug.QueryDefWithFolderPath{QueryDef: query}
+	if err = s.SaveQuery(ctx, q); err != nil {
 		return nil, err
 	}
-	return &datatug.QueryDefWithFolderPath{
-		QueryDef: query,
-	}, nil
+	return q, nil
 }
 
 func (s fsQueriesStore) DeleteQuery(ctx context.Context, id string) (err error) {
@@ -67,6 +99,17 @@ func (s fsQueriesStore) DeleteQueryFolder(_ context.Context, folderPath string)
 	return errors.New("not implemented yet")
 }
 
+// SaveQuery saves a query to the folder set by query.FolderPath. A SQL query is saved as a SQL file
+// if it's already stored as a SQL file or if the project is configured to store SQL queries as SQL files.
 func (s fsQueriesStore) SaveQuery(ctx context.Context, query *datatug.QueryDefWithFolderPath) error {
-	return s.saveProjectItem(ctx, s.dirPath, &query.QueryDef)
+	dirPath := s.queriesDirPath(query.FolderPath)
+	asSQLFile, err := s.isSavedAsSQLFile(dirPath, &query.QueryDef)
+	if err != nil {
+		return err
+	}
+	if asSQLFile {
+		return s.saveSQLQueryFile(dirPath, &query.QueryDef)
+	}
+	// The text is saved to the item file, so a SQL file written by CreateQuery is removed
+	return s.saveProjectItem(ctx, dirPath, &query.QueryDef)
 }
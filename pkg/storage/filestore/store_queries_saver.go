@@ -24,7 +24,7 @@ func (s fsQueriesStore) CreateQueryFolder(_ context.Context, parentPath, name st
 			err = fmt.Errorf("failed to check README.md: %w", err)
 			return
 		}
-		if err = os.WriteFile(readmePath, []byte(fmt.Sprintf("# %v", name)), 0644); err != nil {
+		if err = writeBytesAtomically(readmePath, []byte(fmt.Sprintf("# %v", name))); err != nil {
 			err = fmt.Errorf("failed to write to README.md file: %w", err)
 			return
 		}
@@ -33,35 +33,102 @@ func (s fsQueriesStore) CreateQueryFolder(_ context.Context, parentPath, name st
 }
 
 func (s fsQueriesStore) CreateQuery(_ context.Context, query datatug.QueryDefWithFolderPath) (*datatug.QueryDefWithFolderPath, error) {
-	return &query, s.saveQuery(query.FolderPath, query.QueryDef, true)
+	return &query, s.saveQuery(query.FolderPath, &query.QueryDef, true)
 }
 
-func (s fsQueriesStore) saveQuery(folderPath string, query datatug.QueryDef, isNew bool) (err error) {
+func (s fsQueriesStore) saveQuery(folderPath string, query *datatug.QueryDef, isNew bool) (err error) {
 	if err = query.Validate(); err != nil {
 		return fmt.Errorf("invalid query (isNew=%v): %w", isNew, err)
 	}
+	queryDirPath := s.queriesDirPath(folderPath)
+	if err = checkItemFormatVersion(queryDirPath); err != nil {
+		return err
+	}
+	var asSQLFile bool
+	if asSQLFile, err = s.isSavedAsSQLFile(queryDirPath, query); err != nil {
+		return err
+	} else if asSQLFile {
+		return s.saveSQLQueryFile(queryDirPath, query)
+	}
 
-	queryText := query.Text
-	query.Text = ""
-	defer func() {
-		query.Text = queryText
-	}()
+	format, err := s.writeFormat()
+	if err != nil {
+		return err
+	}
+	fileName := s.itemFileName(query.ID, format)
+	currentFileName := s.existingItemFileName(queryDirPath, query.ID, format)
 
-	queryDirPath := path.Join(s.dirPath, folderPath)
+	// The revision covers both the item file & the SQL file with the text
+	return saveRevisioned(queryDirPath, fileName, currentFileName, s.textFileName(query.ID), query.ID, query, query, func() error {
+		// Text is written to a separate file, so secrets are checked before splitting it out
+		redacted, err := redactSecrets(path.Join(queryDirPath, fileName), query)
+		if err != nil {
+			return err
+		}
+		itemFile := *redacted.(*datatug.QueryDef)
+		queryText := itemFile.Text
+		itemFile.Text = ""
 
-	jsonFileName := fmt.Sprintf("%s.%s.json", query.ID, storage.QueryFileSuffix)
-	if err = saveJSONFile(queryDirPath, jsonFileName, query); err != nil {
-		return fmt.Errorf("failed to save query to json file: %w", err)
-	}
+		if err = saveItemFile(queryDirPath, fileName, itemFile); err != nil {
+			return fmt.Errorf("failed to save query to %s file: %w", format, err)
+		}
+		if err = s.removeItemFiles(queryDirPath, query.ID, fileName); err != nil {
+			return err
+		}
 
-	if queryText != "" {
-		fileExt := strings.ToLower(string(query.Type))
-		fileName := fmt.Sprintf("%s.%s.%s", query.ID, storage.QueryFileSuffix, fileExt)
-		filePath := path.Join(queryDirPath, fileName)
+		if queryText != "" {
+			fileExt := strings.ToLower(string(query.Type))
+			fileName := fmt.Sprintf("%s.%s.%s", query.ID, storage.QueryFileSuffix, fileExt)
+			filePath := path.Join(queryDirPath, fileName)
 
-		if err = os.WriteFile(filePath, []byte(queryText), 0644); err != nil {
-			return fmt.Errorf("failed to write query text to file %s: %w", filePath, err)
+			if err = writeBytesAtomically(filePath, []byte(queryText)); err != nil {
+				return fmt.Errorf("failed to write query text to file %s: %w", filePath, err)
+			}
 		}
+		return nil
+	})
+}
+
+// isSavedAsSQLFile returns true if a query should be saved as a SQL file with a metadata header
+func (s fsQueriesStore) isSavedAsSQLFile(dirPath string, query *datatug.QueryDef) (bool, error) {
+	if query.Type != datatug.QueryTypeSQL {
+		return false, nil
+	}
+	settings, err := loadProjectSettings(s.projectPath)
+	if err != nil || settings.SQLQueryFiles {
+		return settings.SQLQueryFiles, err
 	}
-	return nil
+	return s.existingItemFileName(dirPath, query.ID, settings.ItemsFormat) == s.textFileName(query.ID), nil
+}
+
+func (s fsQueriesStore) saveSQLQueryFile(dirPath string, query *datatug.QueryDef) error {
+	if err := query.Validate(); err != nil {
+		return fmt.Errorf("an attempt to save invalid data %T: %w", query, err)
+	}
+	if err := checkItemFormatVersion(dirPath); err != nil {
+		return err
+	}
+	format, err := s.writeFormat()
+	if err != nil {
+		return err
+	}
+	fileName := s.textFileName(query.ID)
+	currentFileName := s.existingItemFileName(dirPath, query.ID, format)
+	return saveRevisioned(dirPath, fileName, currentFileName, fileName, query.ID, query, query, func() error {
+		redacted, err := redactSecrets(path.Join(dirPath, fileName), query)
+		if err != nil {
+			return err
+		}
+		content, err := encodeSQLQueryFile(*redacted.(*datatug.QueryDef))
+		if err != nil {
+			return err
+		}
+		if err = os.MkdirAll(dirPath, 0777); err != nil {
+			return fmt.Errorf("failed to create folder %s: %w", dirPath, err)
+		}
+		if err = writeBytesAtomically(path.Join(dirPath, fileName), content); err != nil {
+			return err
+		}
+		return s.removeItemFiles(dirPath, query.ID, fileName)
+	})
 }
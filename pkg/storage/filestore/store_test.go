@@ -6,6 +6,7 @@ import (
 	"errors"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/datatug/datatug-core/pkg/dto"
 	"github.com/datatug/datatug-core/pkg/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFsProjectStore_ProjectID(t *testing.T) {
@@ -68,14 +70,127 @@ func TestFsStore_Methods(t *testing.T) {
 
 	t.Run("DeleteProject", func(t *testing.T) {
 		err := store.DeleteProject(context.Background(), projectID)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "not implemented yet")
+		assert.NoError(t, err)
+		_, err = os.Stat(projectPath)
+		assert.True(t, os.IsNotExist(err))
+		projects, err := store.GetProjects(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, projects)
 	})
 
 	t.Run("CreateProject", func(t *testing.T) {
-		assert.Panics(t, func() {
-			_, _ = store.CreateProject(context.Background(), dto.CreateProjectRequest{})
-		})
+		_, err := store.CreateProject(context.Background(), dto.CreateProjectRequest{})
+		assert.Error(t, err)
+		_, err = store.CreateProject(context.Background(), dto.CreateProjectRequest{StoreID: "test_store", Title: "New Project"})
+		assert.Error(t, err, "store without a root dir can not create projects")
+	})
+}
+
+func TestFsStore_CreateProject(t *testing.T) {
+	ctx := context.Background()
+	rootDir := t.TempDir()
+	store, err := NewRepoStore("test_store", rootDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	request := dto.CreateProjectRequest{StoreID: "test_store", Title: "My First Project"}
+	summary, err := store.CreateProject(ctx, request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "my-first-project", summary.ID)
+	assert.Equal(t, "My First Project", summary.Title)
+	assert.NotNil(t, summary.Created)
+
+	summary2, err := store.CreateProject(ctx, request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "my-first-project-2", summary2.ID)
+
+	repoRootFile, err := LoadRootDatatugFile(rootDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"datatug/my-first-project", "datatug/my-first-project-2"}, repoRootFile.Projects)
+
+	projects, err := store.GetProjects(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, projects, 2)
+
+	t.Run("reopen", func(t *testing.T) {
+		reopened, err := NewRepoStore("test_store", rootDir)
+		assert.NoError(t, err)
+		projFile, err := reopened.GetProjectStore("my-first-project").LoadProjectFile(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "My First Project", projFile.Title)
+	})
+
+	t.Run("bad_title", func(t *testing.T) {
+		_, err := store.CreateProject(ctx, dto.CreateProjectRequest{StoreID: "test_store", Title: "!!!"})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewRepoStore_SameProjectNames(t *testing.T) {
+	rootDir := t.TempDir()
+	writeTestFile(t, rootDir, storage.RepoRootDataTugFileName, "projects:\n  - team1/db\n  - ./team1/db\n")
+	store, err := NewRepoStore("test_store", rootDir)
+	require.NoError(t, err, "the same project listed twice")
+	assert.Equal(t, map[string]string{"db": path.Join(rootDir, "team1/db")}, store.pathByID)
+
+	writeTestFile(t, rootDir, storage.RepoRootDataTugFileName, "projects:\n  - team1/db\n  - team2/db\n")
+	_, err = NewRepoStore("test_store", rootDir)
+	assert.ErrorContains(t, err, "have the same ID db")
+}
+
+func TestFsStore_DeleteProject(t *testing.T) {
+	ctx := context.Background()
+	rootDir := t.TempDir()
+	store, err := NewRepoStore("test_store", rootDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	summary, err := store.CreateProject(ctx, dto.CreateProjectRequest{StoreID: "test_store", Title: "p1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		err := store.DeleteProject(ctx, "unknown")
+		assert.True(t, errors.Is(err, datatug.ErrProjectDoesNotExist), err)
+	})
+
+	t.Run("not_a_project_dir", func(t *testing.T) {
+		notProjDir := path.Join(rootDir, ProjectsDirName, "not-a-project")
+		assert.NoError(t, os.MkdirAll(notProjDir, 0777))
+		store.pathByID["not-a-project"] = notProjDir
+		err := store.DeleteProject(ctx, "not-a-project")
+		assert.Error(t, err)
+		_, err = os.Stat(notProjDir)
+		assert.NoError(t, err, "directory should not be deleted")
+	})
+
+	t.Run("outside_of_root", func(t *testing.T) {
+		store.pathByID["outside"] = t.TempDir()
+		err := store.DeleteProject(ctx, "outside")
+		assert.Error(t, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		err := store.DeleteProject(ctx, summary.ID)
+		assert.NoError(t, err)
+		_, err = os.Stat(path.Join(rootDir, ProjectsDirName, summary.ID))
+		assert.True(t, os.IsNotExist(err))
+		entries, err := os.ReadDir(path.Join(rootDir, ProjectsDirName))
+		assert.NoError(t, err)
+		var archived bool
+		for _, entry := range entries {
+			archived = archived || strings.HasPrefix(entry.Name(), ".p1.deleted-")
+		}
+		assert.True(t, archived, "project directory should be archived")
+		repoRootFile, err := LoadRootDatatugFile(rootDir)
+		assert.NoError(t, err)
+		assert.Empty(t, repoRootFile.Projects)
 	})
 }
 
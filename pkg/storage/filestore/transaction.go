@@ -0,0 +1,34 @@
+package filestore
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+)
+
+var _ datatug.ProjectTransactor = (*fsProjectStore)(nil)
+
+// RunInTransaction journals queries, boards, entities & folders of the project before running f,
+// so if f fails or the process crashes the changes are rolled back (on crash by the next LoadProject).
+// The project is locked for other saves until f returns. As f can write any of the items, whole folders are backed up.
+// SaveProject must not be called from f as it starts its own journal.
+func (s fsProjectStore) RunInTransaction(ctx context.Context, f func(ctx context.Context) error) (err error) {
+	if err = os.MkdirAll(s.projectPath, 0777); err != nil {
+		return fmt.Errorf("failed to create project folder: %w", err)
+	}
+	journal, err := beginSave(ctx, s.projectPath, storage.QueriesFolder, storage.BoardsFolder, storage.EntitiesFolder, FoldersDir)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err == nil {
+			err = journal.commit()
+		} else if rollbackErr := journal.rollback(); rollbackErr != nil {
+			err = fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+	}()
+	return f(ctx)
+}
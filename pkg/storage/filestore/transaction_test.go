@@ -0,0 +1,70 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFsProjectStore_RunInTransaction(t *testing.T) {
+	ctx := context.Background()
+	newBoard := func(id, title string) *datatug.Board {
+		return &datatug.Board{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: title}}}
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		dir := t.TempDir()
+		store := newFsProjectStore("p1", dir)
+		err := store.RunInTransaction(ctx, func(ctx context.Context) error {
+			return store.SaveBoard(ctx, newBoard("b1", "Board 1"))
+		})
+		require.NoError(t, err)
+		_, err = store.LoadBoard(ctx, "b1")
+		assert.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(dir, SaveJournalFileName))
+		assert.NoDirExists(t, filepath.Join(dir, saveBackupDirName))
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		dir := t.TempDir()
+		store := newFsProjectStore("p1", dir)
+		require.NoError(t, store.SaveBoard(ctx, newBoard("b1", "Before")))
+		failure := errors.New("failure")
+		err := store.RunInTransaction(ctx, func(ctx context.Context) error {
+			require.NoError(t, store.SaveBoard(ctx, newBoard("b1", "After")))
+			require.NoError(t, store.SaveBoard(ctx, newBoard("b2", "New")))
+			entity := &datatug.Entity{ProjectItem: datatug.ProjectItem{ProjItemBrief: datatug.ProjItemBrief{ID: "e1", Title: "E1"}}}
+			require.NoError(t, store.SaveEntity(ctx, entity))
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+		board, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, "Before", board.Title)
+		_, err = store.LoadBoard(ctx, "b2")
+		assert.Error(t, err)
+		assert.NoDirExists(t, filepath.Join(dir, storage.EntitiesFolder))
+		assert.NoFileExists(t, filepath.Join(dir, SaveJournalFileName))
+	})
+	t.Run("not_recovered_while_running", func(t *testing.T) {
+		dir := t.TempDir()
+		store := newFsProjectStore("p1", dir)
+		err := store.RunInTransaction(ctx, func(ctx context.Context) error {
+			require.NoError(t, store.SaveBoard(ctx, newBoard("b1", "In transaction")))
+			recovered, err := RecoverProject(dir) // e.g. by a concurrent LoadProject
+			require.NoError(t, err)
+			assert.False(t, recovered, "a running transaction should not be rolled back")
+			return nil
+		})
+		require.NoError(t, err)
+		board, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, "In transaction", board.Title)
+	})
+}
@@ -0,0 +1,355 @@
+package filestore
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/fsnotify/fsnotify"
+)
+
+// ProjectItemKind defines kind of project item reported by ProjectWatcher
+type ProjectItemKind string
+
+const (
+	ProjectItemProject     ProjectItemKind = "project"
+	ProjectItemQuery       ProjectItemKind = "query"
+	ProjectItemBoard       ProjectItemKind = "board"
+	ProjectItemEntity      ProjectItemKind = "entity"
+	ProjectItemEnvironment ProjectItemKind = "environment"
+	ProjectItemEnvServer   ProjectItemKind = "server"
+	ProjectItemEnvCatalog  ProjectItemKind = "catalog"
+)
+
+// DefaultWatchDebounce is a quiet period after which accumulated file system events are reported
+const DefaultWatchDebounce = 200 * time.Millisecond
+
+// ProjectItemChange describes an added, changed or removed project item
+type ProjectItemChange struct {
+	Kind       ProjectItemKind
+	ChangeType datatug.ChangeType
+	ID         string // for queries includes folder path, e.g. "reports/daily"
+	EnvID      string // set for environment scoped items: servers & catalogs
+	ServerID   string // set for catalogs
+	Path       string // slash separated path of the changed file relative to the project directory
+}
+
+// ProjectWatcher watches project directory and reports changes of project items in batches
+type ProjectWatcher struct {
+	projectPath string
+	debounce    time.Duration
+	watcher     *fsnotify.Watcher
+	known       map[string]bool // project item files that exist, by relative path
+	changes     chan []ProjectItemChange
+	errors      chan error
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// WatchProject starts watching project files. File system events are accumulated
+// until there are no new events for the debounce period and then reported as a single batch,
+// so a burst of writes to the same file (e.g. by an IDE) results in a single change.
+// While a batch is not received from Changes() new changes are coalesced into it, so a slow consumer
+// does not block watching and gets the latest state of each item rather than every intermediate change.
+// If debounce is 0 the DefaultWatchDebounce is used.
+func WatchProject(projectPath string, debounce time.Duration) (*ProjectWatcher, error) {
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file system watcher: %w", err)
+	}
+	w := &ProjectWatcher{
+		projectPath: projectPath,
+		debounce:    debounce,
+		watcher:     watcher,
+		known:       make(map[string]bool),
+		changes:     make(chan []ProjectItemChange),
+		errors:      make(chan error, 1),
+		done:        make(chan struct{}),
+	}
+	if err = w.addDir(projectPath, nil); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// Changes returns channel of batches of project item changes, it's closed when the watcher is closed
+func (w *ProjectWatcher) Changes() <-chan []ProjectItemChange {
+	return w.changes
+}
+
+// Errors returns channel of watching errors
+func (w *ProjectWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops watching
+func (w *ProjectWatcher) Close() (err error) {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.watcher.Close()
+	})
+	return
+}
+
+// addDir adds watches for a directory and its subdirectories.
+// Item files found are registered as known or, if pending is not nil, added to pending.
+func (w *ProjectWatcher) addDir(dirPath string, pending map[string]struct{}) error {
+	return filepath.WalkDir(dirPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p != dirPath { // removed while walking
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if p != dirPath && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if err = w.watcher.Add(p); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", p, err)
+			}
+			return nil
+		}
+		rel := w.relPath(p)
+		if _, ok := classifyProjectFile(rel); !ok {
+			return nil
+		}
+		if pending == nil {
+			w.known[rel] = true
+		} else {
+			pending[rel] = struct{}{}
+		}
+		return nil
+	})
+}
+
+func (w *ProjectWatcher) relPath(p string) string {
+	rel, err := filepath.Rel(w.projectPath, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (w *ProjectWatcher) run() {
+	defer close(w.changes)
+	pending := make(map[string]struct{})
+	var unsent []ProjectItemChange // a batch the consumer has not received yet
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		var changes chan<- []ProjectItemChange // nil, so nothing is sent while there are no changes
+		if len(unsent) > 0 {
+			changes = w.changes
+		}
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(event, pending)
+			timer.Reset(w.debounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			select {
+			case w.errors <- err:
+			default: // previous error has not been consumed yet
+			}
+		case <-timer.C:
+			unsent = coalesceChanges(unsent, w.flush(pending))
+			pending = make(map[string]struct{})
+		case changes <- unsent:
+			unsent = nil
+		}
+	}
+}
+
+// coalesceChanges merges new changes into a batch that has not been sent yet, so there is a single change per file.
+// E.g. a file that was added & then deleted is not reported, & a deleted file that was created again is altered.
+func coalesceChanges(unsent, changes []ProjectItemChange) []ProjectItemChange {
+	if len(unsent) == 0 {
+		return changes
+	}
+	merged := unsent[:0:0]
+	byPath := make(map[string]int, len(unsent))
+	for _, change := range unsent {
+		byPath[change.Path] = len(merged)
+		merged = append(merged, change)
+	}
+	removed := false
+	for _, change := range changes {
+		i, ok := byPath[change.Path]
+		if !ok {
+			byPath[change.Path] = len(merged)
+			merged = append(merged, change)
+			continue
+		}
+		switch prev := merged[i].ChangeType; {
+		case prev == datatug.ChangeTypeAdded && change.ChangeType == datatug.ChangeTypeDeleted:
+			merged[i].ChangeType = datatug.ChangeTypeUnchanged // removed below
+			removed = true
+		case prev == datatug.ChangeTypeAdded: // added & altered
+		case prev == datatug.ChangeTypeDeleted && change.ChangeType == datatug.ChangeTypeAdded:
+			merged[i].ChangeType = datatug.ChangeTypeAltered
+		default:
+			merged[i].ChangeType = change.ChangeType
+		}
+	}
+	if removed {
+		result := merged[:0]
+		for _, change := range merged {
+			if change.ChangeType != datatug.ChangeTypeUnchanged {
+				result = append(result, change)
+			}
+		}
+		merged = result
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Path < merged[j].Path
+	})
+	return merged
+}
+
+func (w *ProjectWatcher) handleEvent(event fsnotify.Event, pending map[string]struct{}) {
+	rel := w.relPath(event.Name)
+	if event.Has(fsnotify.Create) {
+		if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+			if err = w.addDir(event.Name, pending); err != nil {
+				select {
+				case w.errors <- err:
+				default:
+				}
+			}
+			return
+		}
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		// A removed directory does not always produce events for its files
+		prefix := rel + "/"
+		for known := range w.known {
+			if strings.HasPrefix(known, prefix) {
+				pending[known] = struct{}{}
+			}
+		}
+	}
+	if _, ok := classifyProjectFile(rel); ok {
+		pending[rel] = struct{}{}
+	}
+}
+
+// flush compares pending files with known state, so a burst of events for a file results in a single change
+// and a file that was created & removed within the debounce period is not reported at all.
+func (w *ProjectWatcher) flush(pending map[string]struct{}) (changes []ProjectItemChange) {
+	paths := make([]string, 0, len(pending))
+	for p := range pending {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, rel := range paths {
+		change, ok := classifyProjectFile(rel)
+		if !ok {
+			continue
+		}
+		fi, err := os.Stat(filepath.Join(w.projectPath, filepath.FromSlash(rel)))
+		exists := err == nil && !fi.IsDir()
+		switch {
+		case exists && w.known[rel]:
+			change.ChangeType = datatug.ChangeTypeAltered
+		case exists:
+			change.ChangeType = datatug.ChangeTypeAdded
+			w.known[rel] = true
+		case w.known[rel]:
+			change.ChangeType = datatug.ChangeTypeDeleted
+			delete(w.known, rel)
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// classifyProjectFile identifies project item by a slash separated file path relative to the project directory
+func classifyProjectFile(rel string) (change ProjectItemChange, ok bool) {
+	change.Path = rel
+	if rel == storage.ProjectSummaryFileName {
+		change.Kind = ProjectItemProject
+		return change, true
+	}
+	parts := strings.Split(rel, "/")
+	fileName := parts[len(parts)-1]
+	isSQLQuery := parts[0] == storage.QueriesFolder && path.Ext(fileName) == sqlFileExt
+	if _, ok := fileFormat(fileName); !ok && !isSQLQuery || len(parts) < 2 {
+		return change, false
+	}
+	id, suffix := storage.GetProjItemIDFromFileName(fileName)
+	switch parts[0] {
+	case storage.QueriesFolder:
+		if suffix == storage.QueryFileSuffix && id != "" {
+			change.Kind = ProjectItemQuery
+			change.ID = path.Join(append(parts[1:len(parts)-1], id)...)
+			return change, true
+		}
+	case storage.BoardsFolder:
+		if suffix == storage.BoardFileSuffix && id != "" && len(parts) == 2 {
+			change.Kind = ProjectItemBoard
+			change.ID = id
+			return change, true
+		}
+	case storage.EntitiesFolder:
+		if suffix == storage.EntityFileSuffix && id != "" && len(parts) == 2 {
+			change.Kind = ProjectItemEntity
+			change.ID = id
+			return change, true
+		}
+	case storage.EnvironmentsFolder:
+		return classifyEnvironmentFile(change, parts, id, suffix)
+	}
+	return change, false
+}
+
+func classifyEnvironmentFile(change ProjectItemChange, parts []string, id, suffix string) (ProjectItemChange, bool) {
+	switch len(parts) {
+	case 3: // environments/{env}/...
+		change.EnvID = parts[1]
+		if parts[2] == storage.EnvironmentSummaryFileName {
+			change.Kind = ProjectItemEnvironment
+			change.ID = parts[1]
+			change.EnvID = ""
+			return change, true
+		}
+		if suffix == storage.ServerFileSuffix && id != "" {
+			change.Kind = ProjectItemEnvServer
+			change.ID = id
+			return change, true
+		}
+	case 6: // environments/{env}/servers/{server}/catalogs/{catalog}.db.json
+		if parts[2] == storage.ServersFolder && parts[4] == storage.EnvDbCatalogsFolder &&
+			suffix == storage.DbCatalogFileSuffix && id != "" {
+			change.Kind = ProjectItemEnvCatalog
+			change.ID = id
+			change.EnvID = parts[1]
+			change.ServerID = parts[3]
+			return change, true
+		}
+	}
+	return change, false
+}
@@ -0,0 +1,199 @@
+package filestore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyProjectFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want *ProjectItemChange
+	}{
+		{path: "datatug-project.json", want: &ProjectItemChange{Kind: ProjectItemProject}},
+		{path: "queries/q1.query.json", want: &ProjectItemChange{Kind: ProjectItemQuery, ID: "q1"}},
+		{path: "queries/reports/daily.query.json", want: &ProjectItemChange{Kind: ProjectItemQuery, ID: "reports/daily"}},
+		{path: "queries/reports/weekly.query.sql", want: &ProjectItemChange{Kind: ProjectItemQuery, ID: "reports/weekly"}},
+		{path: "boards/b1.board.json", want: &ProjectItemChange{Kind: ProjectItemBoard, ID: "b1"}},
+		{path: "boards/b1.board.yaml", want: &ProjectItemChange{Kind: ProjectItemBoard, ID: "b1"}},
+		{path: "entities/e1.entity.json", want: &ProjectItemChange{Kind: ProjectItemEntity, ID: "e1"}},
+		{path: "environments/dev/environment-summary.json", want: &ProjectItemChange{Kind: ProjectItemEnvironment, ID: "dev"}},
+		{path: "environments/dev/db1.server.json", want: &ProjectItemChange{Kind: ProjectItemEnvServer, ID: "db1", EnvID: "dev"}},
+		{
+			path: "environments/dev/servers/db1/catalogs/northwind.db.json",
+			want: &ProjectItemChange{Kind: ProjectItemEnvCatalog, ID: "northwind", EnvID: "dev", ServerID: "db1"},
+		},
+		{path: "README.md"},
+		{path: "boards/b1.board.json.swp"},
+		{path: "boards/b1.query.json"},
+		{path: "boards/nested/b1.board.json"},
+		{path: "queries/.query.json"},
+		{path: "boards/b1.board.sql"},
+		{path: "environments/dev/servers/db1/db1.server.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := classifyProjectFile(tt.path)
+			if tt.want == nil {
+				assert.False(t, ok)
+				return
+			}
+			assert.True(t, ok)
+			tt.want.Path = tt.path
+			assert.Equal(t, *tt.want, got)
+		})
+	}
+}
+
+func TestWatchProject(t *testing.T) {
+	projDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projDir, "boards"), 0777))
+	writeFile := func(rel, content string) {
+		t.Helper()
+		p := filepath.Join(projDir, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0777))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+	writeFile("boards/existing.board.json", `{"id":"existing"}`)
+
+	w, err := WatchProject(projDir, 50*time.Millisecond)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, w.Close())
+	}()
+
+	next := func(t *testing.T) []ProjectItemChange {
+		t.Helper()
+		select {
+		case changes := <-w.Changes():
+			return changes
+		case err := <-w.Errors():
+			t.Fatalf("unexpected watcher error: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for changes")
+		}
+		return nil
+	}
+
+	t.Run("added_burst", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			writeFile("boards/b1.board.json", `{"id":"b1"}`)
+		}
+		assert.Equal(t, []ProjectItemChange{
+			{Kind: ProjectItemBoard, ChangeType: datatug.ChangeTypeAdded, ID: "b1", Path: "boards/b1.board.json"},
+		}, next(t))
+	})
+
+	t.Run("altered", func(t *testing.T) {
+		writeFile("boards/existing.board.json", `{"id":"existing","title":"changed"}`)
+		assert.Equal(t, []ProjectItemChange{
+			{Kind: ProjectItemBoard, ChangeType: datatug.ChangeTypeAltered, ID: "existing", Path: "boards/existing.board.json"},
+		}, next(t))
+	})
+
+	t.Run("new_dir", func(t *testing.T) {
+		writeFile("queries/reports/daily.query.json", `{"id":"daily"}`)
+		changes := next(t)
+		require.NotEmpty(t, changes)
+		assert.Equal(t, ProjectItemQuery, changes[0].Kind)
+		assert.Equal(t, "reports/daily", changes[0].ID)
+		assert.Equal(t, datatug.ChangeTypeAdded, changes[0].ChangeType)
+	})
+
+	t.Run("removed_dir", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(filepath.Join(projDir, "queries")))
+		assert.Equal(t, []ProjectItemChange{
+			{Kind: ProjectItemQuery, ChangeType: datatug.ChangeTypeDeleted, ID: "reports/daily", Path: "queries/reports/daily.query.json"},
+		}, next(t))
+	})
+
+	t.Run("ignored_files", func(t *testing.T) {
+		writeFile("README.md", "# Project")
+		writeFile("boards/b1.board.json.tmp", "{}")
+		require.NoError(t, os.Remove(filepath.Join(projDir, "boards", "b1.board.json")))
+		assert.Equal(t, []ProjectItemChange{
+			{Kind: ProjectItemBoard, ChangeType: datatug.ChangeTypeDeleted, ID: "b1", Path: "boards/b1.board.json"},
+		}, next(t))
+	})
+}
+
+func TestWatchProject_SlowConsumer(t *testing.T) {
+	projDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projDir, "boards"), 0777))
+	w, err := WatchProject(projDir, 20*time.Millisecond)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, w.Close())
+	}()
+	boardPath := func(id string) string {
+		return filepath.Join(projDir, "boards", id+".board.json")
+	}
+	require.NoError(t, os.WriteFile(boardPath("b1"), []byte(`{"id":"b1"}`), 0644))
+	time.Sleep(100 * time.Millisecond) // a batch is ready but is not received
+	require.NoError(t, os.WriteFile(boardPath("b2"), []byte(`{"id":"b2"}`), 0644))
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, os.Remove(boardPath("b1")))
+	time.Sleep(100 * time.Millisecond)
+
+	select {
+	case changes := <-w.Changes():
+		assert.Equal(t, []ProjectItemChange{
+			{Kind: ProjectItemBoard, ChangeType: datatug.ChangeTypeAdded, ID: "b2", Path: "boards/b2.board.json"},
+		}, changes, "batches should be coalesced while not received")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for changes")
+	}
+}
+
+func TestCoalesceChanges(t *testing.T) {
+	change := func(path string, changeType datatug.ChangeType) ProjectItemChange {
+		return ProjectItemChange{Kind: ProjectItemBoard, Path: path, ChangeType: changeType}
+	}
+	assert.Equal(t, []ProjectItemChange{change("a", datatug.ChangeTypeAdded)},
+		coalesceChanges(nil, []ProjectItemChange{change("a", datatug.ChangeTypeAdded)}))
+	assert.Equal(t, []ProjectItemChange{
+		change("a", datatug.ChangeTypeAdded),
+		change("c", datatug.ChangeTypeAltered),
+		change("d", datatug.ChangeTypeDeleted),
+		change("e", datatug.ChangeTypeAltered),
+	}, coalesceChanges(
+		[]ProjectItemChange{
+			change("a", datatug.ChangeTypeAdded),
+			change("b", datatug.ChangeTypeAdded),
+			change("d", datatug.ChangeTypeAltered),
+			change("e", datatug.ChangeTypeDeleted),
+		},
+		[]ProjectItemChange{
+			change("a", datatug.ChangeTypeAltered),
+			change("b", datatug.ChangeTypeDeleted),
+			change("c", datatug.ChangeTypeAltered),
+			change("d", datatug.ChangeTypeDeleted),
+			change("e", datatug.ChangeTypeAdded),
+		},
+	))
+}
+
+func TestProjectWatcher_Close(t *testing.T) {
+	w, err := WatchProject(t.TempDir(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWatchDebounce, w.debounce)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Close(), "second close is a no-op")
+	select {
+	case _, ok := <-w.Changes():
+		assert.False(t, ok, "changes channel should be closed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("changes channel is not closed")
+	}
+}
+
+func TestWatchProject_NotExistingDir(t *testing.T) {
+	_, err := WatchProject(filepath.Join(t.TempDir(), "missing"), 0)
+	assert.Error(t, err)
+}
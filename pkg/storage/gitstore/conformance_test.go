@@ -0,0 +1,14 @@
+package gitstore
+
+import (
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/test/storetest"
+)
+
+func TestProjectStore_Conformance(t *testing.T) {
+	storetest.RunProjectStoreTests(t, func(t *testing.T) datatug.ProjectStore {
+		return newTestProjectStore(t)
+	})
+}
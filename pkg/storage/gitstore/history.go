@@ -0,0 +1,246 @@
+package gitstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/datatug/datatug-core/pkg/storage/filestore"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/strongo/validation"
+)
+
+// ErrNotFoundInRevision is returned when a project item does not exist in a requested revision
+var ErrNotFoundInRevision = errors.New("project item not found in revision")
+
+// ItemKind defines kind of project item that has history
+type ItemKind string
+
+const (
+	ItemKindProject     ItemKind = "project"
+	ItemKindBoard       ItemKind = "board"
+	ItemKindQuery       ItemKind = "query"
+	ItemKindEntity      ItemKind = "entity"
+	ItemKindFolder      ItemKind = "folder"
+	ItemKindEnvironment ItemKind = "environment"
+)
+
+// ItemPaths returns paths of files a project item can be stored in relative to the project directory,
+// e.g. JSON & YAML files or a .sql file of a query, so an item is tracked when it's converted to another format.
+// For items stored as directories the path of the directory is returned.
+func ItemPaths(kind ItemKind, id string) ([]string, error) {
+	if id == "" && kind != ItemKindProject {
+		return nil, validation.NewErrRequestIsMissingRequiredField("id")
+	}
+	itemFiles := func(dir, id, suffix string) []string {
+		names := filestore.ItemFileNames(id, suffix)
+		for i, name := range names {
+			names[i] = path.Join(dir, name)
+		}
+		return names
+	}
+	switch kind {
+	case ItemKindProject:
+		return []string{storage.ProjectSummaryFileName}, nil
+	case ItemKindBoard:
+		return itemFiles(storage.BoardsFolder, id, storage.BoardFileSuffix), nil
+	case ItemKindQuery:
+		dir, name := path.Split(id)
+		return itemFiles(path.Join(storage.QueriesFolder, dir), name, storage.QueryFileSuffix), nil
+	case ItemKindEntity:
+		return itemFiles(storage.EntitiesFolder, id, storage.EntityFileSuffix), nil
+	case ItemKindFolder:
+		return []string{path.Join(filestore.FoldersDir, id)}, nil
+	case ItemKindEnvironment:
+		return []string{path.Join(storage.EnvironmentsFolder, id)}, nil
+	default:
+		return nil, validation.NewErrBadRequestFieldValue("kind", fmt.Sprintf("unsupported project item kind: %s", kind))
+	}
+}
+
+// Revision describes a commit that changed a project item
+type Revision struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// repoItemPaths returns paths of files of a project item relative to the repository root
+func (s *ProjectStore) repoItemPaths(kind ItemKind, id string) ([]string, error) {
+	itemPaths, err := ItemPaths(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	for i, itemPath := range itemPaths {
+		itemPaths[i] = path.Join(s.relPath, itemPath)
+	}
+	return itemPaths, nil
+}
+
+func isInPaths(filePath string, itemPaths []string) bool {
+	for _, itemPath := range itemPaths {
+		if filePath == itemPath || strings.HasPrefix(filePath, itemPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// History returns revisions that changed a project item, most recent first
+func (s *ProjectStore) History(_ context.Context, kind ItemKind, id string) (revisions []Revision, err error) {
+	itemPaths, err := s.repoItemPaths(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := s.repo.repo.Log(&git.LogOptions{
+		PathFilter: func(filePath string) bool {
+			return isInPaths(filePath, itemPaths)
+		},
+	})
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) { // repository has no commits yet
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get git log for %s %s: %w", kind, id, err)
+	}
+	err = commits.ForEach(func(c *object.Commit) error {
+		revisions = append(revisions, Revision{
+			Hash:    c.Hash.String(),
+			Message: strings.TrimSpace(c.Message),
+			Author:  c.Author.Name,
+			Email:   c.Author.Email,
+			Time:    c.Author.When,
+		})
+		return nil
+	})
+	return revisions, err
+}
+
+func (s *ProjectStore) revisionTree(revision string) (*object.Tree, *object.Commit, error) {
+	if revision == "" {
+		revision = string(plumbing.HEAD)
+	}
+	hash, err := s.repo.repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+	commit, err := s.repo.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tree of commit %s: %w", hash, err)
+	}
+	return tree, commit, nil
+}
+
+// Diff returns unified diff of a project item between 2 revisions.
+// An empty toRevision stands for HEAD.
+func (s *ProjectStore) Diff(_ context.Context, kind ItemKind, id, fromRevision, toRevision string) (string, error) {
+	if fromRevision == "" {
+		return "", validation.NewErrRequestIsMissingRequiredField("fromRevision")
+	}
+	itemPaths, err := s.repoItemPaths(kind, id)
+	if err != nil {
+		return "", err
+	}
+	fromTree, _, err := s.revisionTree(fromRevision)
+	if err != nil {
+		return "", err
+	}
+	toTree, _, err := s.revisionTree(toRevision)
+	if err != nil {
+		return "", err
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff revisions: %w", err)
+	}
+	var itemChanges object.Changes
+	for _, change := range changes {
+		if isInPaths(change.From.Name, itemPaths) || isInPaths(change.To.Name, itemPaths) {
+			itemChanges = append(itemChanges, change)
+		}
+	}
+	if len(itemChanges) == 0 {
+		return "", nil
+	}
+	patch, err := itemChanges.Patch()
+	if err != nil {
+		return "", fmt.Errorf("failed to create patch: %w", err)
+	}
+	return patch.String(), nil
+}
+
+// Restore restores a project item to the state it had in the given revision and commits the change
+func (s *ProjectStore) Restore(_ context.Context, kind ItemKind, id, revision string) error {
+	if revision == "" {
+		return validation.NewErrRequestIsMissingRequiredField("revision")
+	}
+	itemPaths, err := s.repoItemPaths(kind, id)
+	if err != nil {
+		return err
+	}
+	tree, commit, err := s.revisionTree(revision)
+	if err != nil {
+		return err
+	}
+	var files []*object.File
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if isInPaths(f.Name, itemPaths) {
+			files = append(files, f)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read files of revision %s: %w", revision, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("%w: %s %s at %s", ErrNotFoundInRevision, kind, id, revision)
+	}
+	for _, itemPath := range itemPaths { // the item can be in another format now
+		if err = os.RemoveAll(filepath.Join(s.repo.rootDir, filepath.FromSlash(itemPath))); err != nil {
+			return fmt.Errorf("failed to remove current version of %s: %w", itemPath, err)
+		}
+	}
+	for _, f := range files {
+		if err = restoreFile(s.repo.rootDir, f); err != nil {
+			return err
+		}
+	}
+	return s.commitf("Restore %s %s to revision %s", kind, id, commit.Hash.String()[:7])
+}
+
+// restoreFile writes a file from git atomically, like the file store does
+func restoreFile(rootDir string, f *object.File) error {
+	filePath := filepath.Join(rootDir, filepath.FromSlash(f.Name))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+		return err
+	}
+	reader, err := f.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to read %s from git: %w", f.Name, err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	if err = filestore.WriteFileAtomically(filePath, func(w io.Writer) error {
+		_, err := io.Copy(w, reader)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", f.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package gitstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/datatug/datatug-core/pkg/test/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemPaths(t *testing.T) {
+	tests := []struct {
+		kind    ItemKind
+		id      string
+		want    []string
+		wantErr bool
+	}{
+		{kind: ItemKindProject, want: []string{"datatug-project.json"}},
+		{kind: ItemKindBoard, id: "b1", want: []string{"boards/b1.board.json", "boards/b1.board.yaml", "boards/b1.board.yml"}},
+		{kind: ItemKindQuery, id: "q1", want: []string{
+			"queries/q1.query.json", "queries/q1.query.yaml", "queries/q1.query.yml", "queries/q1.query.sql",
+		}},
+		{kind: ItemKindQuery, id: "reports/daily", want: []string{
+			"queries/reports/daily.query.json", "queries/reports/daily.query.yaml", "queries/reports/daily.query.yml", "queries/reports/daily.query.sql",
+		}},
+		{kind: ItemKindEntity, id: "e1", want: []string{"entities/e1.entity.json", "entities/e1.entity.yaml", "entities/e1.entity.yml"}},
+		{kind: ItemKindFolder, id: "f1", want: []string{"folders/f1"}},
+		{kind: ItemKindEnvironment, id: "dev", want: []string{"environments/dev"}},
+		{kind: ItemKindBoard, wantErr: true},
+		{kind: "unknown", id: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind)+"/"+tt.id, func(t *testing.T) {
+			got, err := ItemPaths(tt.kind, tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProjectStore_History(t *testing.T) {
+	ctx := context.Background()
+	store := newTestProjectStore(t)
+
+	revisions, err := store.History(ctx, ItemKindBoard, "b1")
+	assert.NoError(t, err)
+	assert.Empty(t, revisions, "no commits yet")
+
+	require.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b1", "Version 1")))
+	require.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b2", "Other board")))
+	require.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b1", "Version 2")))
+
+	revisions, err = store.History(ctx, ItemKindBoard, "b1")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "Update board b1", revisions[0].Message)
+	assert.Equal(t, "Tester", revisions[0].Author)
+	assert.NotEmpty(t, revisions[0].Hash)
+	assert.False(t, revisions[0].Time.IsZero())
+
+	t.Run("Diff", func(t *testing.T) {
+		diff, err := store.Diff(ctx, ItemKindBoard, "b1", revisions[1].Hash, revisions[0].Hash)
+		require.NoError(t, err)
+		assert.Contains(t, diff, `-	"title": "Version 1"`)
+		assert.Contains(t, diff, `+	"title": "Version 2"`)
+		assert.NotContains(t, diff, "b2")
+
+		diff, err = store.Diff(ctx, ItemKindBoard, "b2", revisions[0].Hash, "")
+		assert.NoError(t, err)
+		assert.Empty(t, diff)
+
+		_, err = store.Diff(ctx, ItemKindBoard, "b1", "", "")
+		assert.Error(t, err)
+		_, err = store.Diff(ctx, ItemKindBoard, "b1", "unknown-revision", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		require.NoError(t, store.Restore(ctx, ItemKindBoard, "b1", revisions[1].Hash))
+		board, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, "Version 1", board.Title)
+
+		history, err := store.History(ctx, ItemKindBoard, "b1")
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.Equal(t, "Restore board b1 to revision "+revisions[1].Hash[:7], history[0].Message)
+	})
+
+	t.Run("Restore_deleted", func(t *testing.T) {
+		require.NoError(t, store.DeleteBoard(ctx, "b2"))
+		b2History, err := store.History(ctx, ItemKindBoard, "b2")
+		require.NoError(t, err)
+		require.Len(t, b2History, 2)
+		require.NoError(t, store.Restore(ctx, ItemKindBoard, "b2", b2History[1].Hash))
+		board, err := store.LoadBoard(ctx, "b2")
+		require.NoError(t, err)
+		assert.Equal(t, "Other board", board.Title)
+	})
+
+	t.Run("Restore_not_found", func(t *testing.T) {
+		err := store.Restore(ctx, ItemKindBoard, "b3", revisions[0].Hash)
+		assert.True(t, errors.Is(err, ErrNotFoundInRevision), err)
+	})
+}
+
+func TestProjectStore_History_FileFormatChanged(t *testing.T) {
+	ctx := context.Background()
+	store := newTestProjectStore(t)
+	project := storetest.NewProject("p1", "Project 1")
+	require.NoError(t, store.SaveProject(ctx, project))
+
+	require.NoError(t, store.SaveQuery(ctx, storetest.NewQuery("q1", "Query 1", "SELECT 1")))
+	project.SQLQueryFiles = true
+	require.NoError(t, store.SaveProject(ctx, project))
+	require.NoError(t, store.SaveQuery(ctx, storetest.NewQuery("q1", "Query 1", "SELECT 2")))
+	queriesDir := filepath.Join(store.repo.rootDir, "p1", storage.QueriesFolder)
+	require.FileExists(t, filepath.Join(queriesDir, "q1.query.sql"))
+
+	revisions, err := store.History(ctx, ItemKindQuery, "q1")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2, "history should include both JSON & SQL files")
+
+	diff, err := store.Diff(ctx, ItemKindQuery, "q1", revisions[1].Hash, revisions[0].Hash)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "q1.query.json")
+	assert.Contains(t, diff, "+SELECT 2")
+
+	require.NoError(t, store.Restore(ctx, ItemKindQuery, "q1", revisions[1].Hash))
+	assert.FileExists(t, filepath.Join(queriesDir, "q1.query.json"))
+	assert.NoFileExists(t, filepath.Join(queriesDir, "q1.query.sql"), "file of the other format should be removed")
+	tmpFiles, err := filepath.Glob(filepath.Join(queriesDir, ".*.tmp-*"))
+	require.NoError(t, err)
+	assert.Empty(t, tmpFiles)
+}
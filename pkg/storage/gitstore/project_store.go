@@ -0,0 +1,292 @@
+package gitstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/datatug/datatug-core/pkg/storage/filestore"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+var _ datatug.ProjectStore = (*ProjectStore)(nil)
+
+// NewProjectStore creates a project store that keeps project files in a local git repository
+// and commits every change. The projectPath must be inside the working tree of a git repository.
+// Query results are not committed as they are a local history of executions rather than a part of the project.
+func NewProjectStore(projectID, projectPath string, o ...filestore.ProjectStoreOption) (*ProjectStore, error) {
+	r, err := openRepo(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	relPath, err := r.relPath(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project path relative to git repository root: %w", err)
+	}
+	var domain []string
+	if relPath != "." {
+		domain = strings.Split(relPath, "/")
+	}
+	r.worktree.Excludes = append(r.worktree.Excludes, gitignore.ParsePattern("/"+storage.ResultsFolder, domain))
+	return &ProjectStore{
+		ProjectStore: filestore.NewProjectStore(projectID, projectPath, o...),
+		repo:         r,
+		projectPath:  projectPath,
+		relPath:      relPath,
+	}, nil
+}
+
+// ProjectStore implements datatug.ProjectStore on top of a file store and commits on each save
+type ProjectStore struct {
+	datatug.ProjectStore
+	repo        *gitRepo
+	projectPath string
+	relPath     string // project path relative to the repository root
+}
+
+// SetAuthor sets author of commits, by default it's taken from global git config
+func (s *ProjectStore) SetAuthor(name, email string) {
+	s.repo.author.Name = name
+	s.repo.author.Email = email
+}
+
+// commit commits changes of the project, it's not an error if there is nothing to commit
+func (s *ProjectStore) commit(message string) error {
+	if err := s.repo.commit(s.relPath, message); err != nil && !errors.Is(err, ErrNothingToCommit) {
+		return err
+	}
+	return nil
+}
+
+func (s *ProjectStore) commitf(format string, args ...any) error {
+	return s.commit(fmt.Sprintf(format, args...))
+}
+
+func queryRef(id string) string {
+	return "~/" + id
+}
+
+func (s *ProjectStore) SaveProject(ctx context.Context, p *datatug.Project) error {
+	if err := s.ProjectStore.SaveProject(ctx, p); err != nil {
+		return err
+	}
+	return s.commitf("Update project %s", p.ID)
+}
+
+func (s *ProjectStore) SaveQuery(ctx context.Context, query *datatug.QueryDefWithFolderPath) error {
+	if err := s.ProjectStore.SaveQuery(ctx, query); err != nil {
+		return err
+	}
+	return s.commitf("Update query %s", queryRef(query.ID))
+}
+
+func (s *ProjectStore) DeleteQuery(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteQuery(ctx, id); err != nil {
+		return err
+	}
+	return s.commitf("Delete query %s", queryRef(id))
+}
+
+func (s *ProjectStore) SaveBoard(ctx context.Context, board *datatug.Board) error {
+	if err := s.ProjectStore.SaveBoard(ctx, board); err != nil {
+		return err
+	}
+	return s.commitf("Update board %s", board.ID)
+}
+
+func (s *ProjectStore) DeleteBoard(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteBoard(ctx, id); err != nil {
+		return err
+	}
+	return s.commitf("Delete board %s", id)
+}
+
+func (s *ProjectStore) SaveFolder(ctx context.Context, folderPath string, folder *datatug.Folder) error {
+	if err := s.ProjectStore.SaveFolder(ctx, folderPath, folder); err != nil {
+		return err
+	}
+	return s.commitf("Update folder %s", path.Join(folderPath, folder.GetID()))
+}
+
+func (s *ProjectStore) SaveFolders(ctx context.Context, folderPath string, folders datatug.Folders) error {
+	if err := s.ProjectStore.SaveFolders(ctx, folderPath, folders); err != nil {
+		return err
+	}
+	return s.commitf("Update %d folders", len(folders))
+}
+
+func (s *ProjectStore) DeleteFolder(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteFolder(ctx, id); err != nil {
+		return err
+	}
+	return s.commitf("Delete folder %s", id)
+}
+
+func (s *ProjectStore) SaveEntity(ctx context.Context, entity *datatug.Entity) error {
+	if err := s.ProjectStore.SaveEntity(ctx, entity); err != nil {
+		return err
+	}
+	return s.commitf("Update entity %s", entity.ID)
+}
+
+func (s *ProjectStore) DeleteEntity(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteEntity(ctx, id); err != nil {
+		return err
+	}
+	return s.commitf("Delete entity %s", id)
+}
+
+func (s *ProjectStore) SaveRecordsetDefinition(ctx context.Context, def *datatug.RecordsetDefinition) error {
+	if err := s.ProjectStore.SaveRecordsetDefinition(ctx, def); err != nil {
+		return err
+	}
+	return s.commitf("Update recordset %s", def.ID)
+}
+
+func (s *ProjectStore) SaveRecordsetData(ctx context.Context, id, fileName string, recordset datatug.Recordset) error {
+	if err := s.ProjectStore.SaveRecordsetData(ctx, id, fileName, recordset); err != nil {
+		return err
+	}
+	return s.commitf("Update data of recordset %s: %s", id, fileName)
+}
+
+func (s *ProjectStore) SaveEnvironment(ctx context.Context, env *datatug.Environment) error {
+	if err := s.ProjectStore.SaveEnvironment(ctx, env); err != nil {
+		return err
+	}
+	return s.commitf("Update environment %s", env.ID)
+}
+
+func (s *ProjectStore) SaveEnvironments(ctx context.Context, envs datatug.Environments) error {
+	if err := s.ProjectStore.SaveEnvironments(ctx, envs); err != nil {
+		return err
+	}
+	return s.commitf("Update %d environments", len(envs))
+}
+
+func (s *ProjectStore) DeleteEnvironment(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteEnvironment(ctx, id); err != nil {
+		return err
+	}
+	return s.commitf("Delete environment %s", id)
+}
+
+func (s *ProjectStore) CloneEnvironment(ctx context.Context, request datatug.CloneEnvironmentRequest) (*datatug.Environment, error) {
+	env, err := s.ProjectStore.CloneEnvironment(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return env, s.commitf("Clone environment %s to %s", request.SourceID, request.ID)
+}
+
+func (s *ProjectStore) SaveEnvDbServer(ctx context.Context, envID string, server *datatug.EnvDbServer) error {
+	if err := s.ProjectStore.SaveEnvDbServer(ctx, envID, server); err != nil {
+		return err
+	}
+	return s.commitf("Update server %s/%s", envID, server.GetID())
+}
+
+func (s *ProjectStore) SaveEnvServers(ctx context.Context, envID string, servers datatug.EnvDbServers) error {
+	if err := s.ProjectStore.SaveEnvServers(ctx, envID, servers); err != nil {
+		return err
+	}
+	return s.commitf("Update %d servers of environment %s", len(servers), envID)
+}
+
+func (s *ProjectStore) DeleteEnvDbServer(ctx context.Context, envID, serverID string) error {
+	if err := s.ProjectStore.DeleteEnvDbServer(ctx, envID, serverID); err != nil {
+		return err
+	}
+	return s.commitf("Delete server %s/%s", envID, serverID)
+}
+
+func (s *ProjectStore) SaveEnvDbCatalog(ctx context.Context, envID, serverID, catalogID string, catalog *datatug.DbCatalog) error {
+	if err := s.ProjectStore.SaveEnvDbCatalog(ctx, envID, serverID, catalogID, catalog); err != nil {
+		return err
+	}
+	return s.commitf("Update catalog %s/%s/%s", envID, serverID, catalogID)
+}
+
+func (s *ProjectStore) SaveEnvDbCatalogs(ctx context.Context, envID, serverID, catalogID string, catalogs datatug.DbCatalogs) error {
+	if err := s.ProjectStore.SaveEnvDbCatalogs(ctx, envID, serverID, catalogID, catalogs); err != nil {
+		return err
+	}
+	return s.commitf("Update %d catalogs of server %s/%s", len(catalogs), envID, serverID)
+}
+
+func (s *ProjectStore) DeleteEnvDbCatalog(ctx context.Context, envID, serverID, catalogID string) error {
+	if err := s.ProjectStore.DeleteEnvDbCatalog(ctx, envID, serverID, catalogID); err != nil {
+		return err
+	}
+	return s.commitf("Delete catalog %s/%s/%s", envID, serverID, catalogID)
+}
+
+func (s *ProjectStore) SaveProjDbDriver(ctx context.Context, dbDriver *datatug.ProjDbDriver, o ...datatug.StoreOption) error {
+	if err := s.ProjectStore.SaveProjDbDriver(ctx, dbDriver, o...); err != nil {
+		return err
+	}
+	return s.commitf("Update DB driver %s", dbDriver.ID)
+}
+
+func (s *ProjectStore) DeleteProjDbDriver(ctx context.Context, id string) error {
+	if err := s.ProjectStore.DeleteProjDbDriver(ctx, id); err != nil {
+		return err
+	}
+	return s.commitf("Delete DB driver %s", id)
+}
+
+func (s *ProjectStore) DbServersStore(dbDriver string) datatug.ProjDbServersStore {
+	return projDbServersStore{
+		ProjDbServersStore: s.ProjectStore.DbServersStore(dbDriver),
+		projectStore:       s,
+	}
+}
+
+type projDbServersStore struct {
+	datatug.ProjDbServersStore
+	projectStore *ProjectStore
+}
+
+func (s projDbServersStore) SaveProjDbServer(ctx context.Context, server *datatug.ProjDbServer, o ...datatug.StoreOption) error {
+	if err := s.ProjDbServersStore.SaveProjDbServer(ctx, server, o...); err != nil {
+		return err
+	}
+	return s.projectStore.commitf("Update DB server %s", server.ID)
+}
+
+func (s projDbServersStore) DeleteProjDbServer(ctx context.Context, serverID string) error {
+	if err := s.ProjDbServersStore.DeleteProjDbServer(ctx, serverID); err != nil {
+		return err
+	}
+	return s.projectStore.commitf("Delete DB server %s", serverID)
+}
+
+func (s projDbServersStore) CatalogsStore(serverRef datatug.ServerRef) datatug.DbCatalogsStore {
+	return dbCatalogsStore{
+		DbCatalogsStore: s.ProjDbServersStore.CatalogsStore(serverRef),
+		projectStore:    s.projectStore,
+	}
+}
+
+type dbCatalogsStore struct {
+	datatug.DbCatalogsStore
+	projectStore *ProjectStore
+}
+
+func (s dbCatalogsStore) SaveDbCatalog(ctx context.Context, dbCatalog *datatug.DbCatalog) error {
+	if err := s.DbCatalogsStore.SaveDbCatalog(ctx, dbCatalog); err != nil {
+		return err
+	}
+	return s.projectStore.commitf("Update DB catalog %s/%s", s.Server().GetID(), dbCatalog.ID)
+}
+
+func (s dbCatalogsStore) DeleteDbCatalog(ctx context.Context, id string) error {
+	if err := s.DbCatalogsStore.DeleteDbCatalog(ctx, id); err != nil {
+		return err
+	}
+	return s.projectStore.commitf("Delete DB catalog %s/%s", s.Server().GetID(), id)
+}
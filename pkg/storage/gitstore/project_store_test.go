@@ -0,0 +1,201 @@
+package gitstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/test/storetest"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProjectStore creates a project store in "p1" subdirectory of a new git repository
+func newTestProjectStore(t *testing.T) *ProjectStore {
+	t.Helper()
+	repoDir := t.TempDir()
+	_, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	store, err := NewProjectStore("p1", filepath.Join(repoDir, "p1"))
+	require.NoError(t, err)
+	store.SetAuthor("Tester", "tester@example.com")
+	return store
+}
+
+func commitMessages(t *testing.T, store *ProjectStore) (messages []string) {
+	t.Helper()
+	commits, err := store.repo.repo.Log(&git.LogOptions{})
+	require.NoError(t, err)
+	require.NoError(t, commits.ForEach(func(c *object.Commit) error {
+		messages = append(messages, c.Message)
+		return nil
+	}))
+	return
+}
+
+func TestNewProjectStore(t *testing.T) {
+	t.Run("not_a_repository", func(t *testing.T) {
+		_, err := NewProjectStore("p1", t.TempDir())
+		assert.Error(t, err)
+	})
+	t.Run("nested_project_dir", func(t *testing.T) {
+		store := newTestProjectStore(t)
+		assert.Equal(t, "p1", store.relPath)
+		assert.Equal(t, "p1", store.ProjectID())
+	})
+}
+
+func TestProjectStore_CommitsOnSave(t *testing.T) {
+	ctx := context.Background()
+	store := newTestProjectStore(t)
+
+	require.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b1", "Board 1")))
+	require.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b1", "Board 1")))
+	require.NoError(t, store.SaveQuery(ctx, storetest.NewQuery("daily", "Daily", "SELECT 1")))
+	require.NoError(t, store.SaveEntity(ctx, storetest.NewEntity("e1", "Entity 1")))
+	require.NoError(t, store.DeleteBoard(ctx, "b1"))
+	require.NoError(t, store.DbServersStore("sqlserver").SaveProjDbServer(ctx, storetest.NewProjDbServer("sqlserver", "localhost", 1433)))
+
+	commit, err := store.repo.repo.Log(&git.LogOptions{})
+	require.NoError(t, err)
+	head, err := commit.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "Tester", head.Author.Name)
+	assert.Equal(t, "tester@example.com", head.Author.Email)
+
+	assert.Equal(t, []string{
+		"Update DB server sqlserver:localhost:1433",
+		"Delete board b1",
+		"Update entity e1",
+		"Update query ~/daily",
+		"Update board b1", // saving unchanged board does not create an empty commit
+	}, commitMessages(t, store))
+}
+
+func TestProjectStore_DoesNotCommitChangesOutsideProject(t *testing.T) {
+	ctx := context.Background()
+	store := newTestProjectStore(t)
+	notesPath := filepath.Join(store.repo.rootDir, "notes.txt")
+	require.NoError(t, os.WriteFile(notesPath, []byte("draft"), 0644))
+	require.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b1", "Board 1")))
+
+	head, err := store.repo.repo.Head()
+	require.NoError(t, err)
+	commit, err := store.repo.repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	_, err = commit.File("notes.txt")
+	assert.ErrorIs(t, err, object.ErrFileNotFound, "changes outside of the project should not be committed")
+
+	status, err := store.repo.worktree.Status()
+	require.NoError(t, err)
+	assert.Equal(t, git.Untracked, status.File("notes.txt").Worktree)
+	assert.Equal(t, git.Untracked, status.File("notes.txt").Staging, "changes outside of the project should not be staged")
+
+	assert.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b1", "Board 1")))
+	assert.Len(t, commitMessages(t, store), 1, "changes outside of the project should not be committed on unchanged save")
+}
+
+func TestProjectStore_DoesNotCommitStagedChangesOutsideProject(t *testing.T) {
+	ctx := context.Background()
+	store := newTestProjectStore(t)
+	readmePath := filepath.Join(store.repo.rootDir, "README.md")
+	require.NoError(t, os.WriteFile(readmePath, []byte("v1"), 0644))
+	_, err := store.repo.worktree.Add("README.md")
+	require.NoError(t, err)
+	require.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b1", "Board 1")))
+	head, err := store.repo.repo.Head()
+	require.NoError(t, err)
+	commit, err := store.repo.repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	_, err = commit.File("README.md")
+	assert.ErrorIs(t, err, object.ErrFileNotFound, "staged file outside of the project should not be committed")
+
+	status, err := store.repo.worktree.Status()
+	require.NoError(t, err)
+	assert.Equal(t, git.Added, status.File("README.md").Staging, "changes staged by the user should stay staged")
+	assert.Len(t, status, 1, "committed files of the project should have no changes")
+
+	require.NoError(t, store.SaveBoard(ctx, storetest.NewBoard("b2", "Board 2")))
+	head, err = store.repo.repo.Head()
+	require.NoError(t, err)
+	commit, err = store.repo.repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	_, err = commit.File("p1/boards/b1.board.json")
+	assert.NoError(t, err, "files of the project committed before should be kept")
+	_, err = commit.File("README.md")
+	assert.ErrorIs(t, err, object.ErrFileNotFound)
+}
+
+func TestProjectStore_SharesLockOfRepository(t *testing.T) {
+	store := newTestProjectStore(t)
+	other, err := NewProjectStore("p2", filepath.Join(store.repo.rootDir, "p2"))
+	require.NoError(t, err)
+	assert.Same(t, store.repo.mutex, other.repo.mutex, "stores of the same repository should be serialized")
+	another := newTestProjectStore(t)
+	assert.NotSame(t, store.repo.mutex, another.repo.mutex)
+}
+
+func TestProjectStore_NoCommitOnError(t *testing.T) {
+	ctx := context.Background()
+	store := newTestProjectStore(t)
+	assert.Error(t, store.SaveBoard(ctx, &datatug.Board{}))
+	_, err := store.repo.repo.Head()
+	assert.Error(t, err, "repository should have no commits")
+}
+
+func TestProjectStore_QueryResultsAreNotCommitted(t *testing.T) {
+	ctx := context.Background()
+	store := newTestProjectStore(t)
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveQueryResult(ctx, "daily", storetest.NewQueryResult("dev", created)))
+	require.NoError(t, store.SaveQuery(ctx, storetest.NewQuery("daily", "Daily", "SELECT 1")))
+
+	head, err := store.repo.repo.Head()
+	require.NoError(t, err)
+	commit, err := store.repo.repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	files, err := commit.Files()
+	require.NoError(t, err)
+	var committed []string
+	require.NoError(t, files.ForEach(func(f *object.File) error {
+		committed = append(committed, f.Name)
+		return nil
+	}))
+	assert.NotEmpty(t, committed)
+	for _, name := range committed {
+		assert.NotContains(t, name, "results/", "query results should not be committed")
+	}
+
+	loaded, err := store.LoadQueryResult(ctx, "daily", "dev", created)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", loaded.EnvironmentID)
+}
+
+func TestProjectStore_QueryResultsOfOtherProjectsAreNotCommitted(t *testing.T) {
+	ctx := context.Background()
+	store := newTestProjectStore(t)
+	other, err := NewProjectStore("p2", filepath.Join(store.repo.rootDir, "p2"))
+	require.NoError(t, err)
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, other.SaveQueryResult(ctx, "daily", storetest.NewQueryResult("dev", created)))
+
+	require.NoError(t, store.SaveQuery(ctx, storetest.NewQuery("daily", "Daily", "SELECT 1")))
+	require.NoError(t, other.SaveQuery(ctx, storetest.NewQuery("daily", "Daily", "SELECT 1")))
+
+	head, err := store.repo.repo.Head()
+	require.NoError(t, err)
+	commit, err := store.repo.repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	files, err := commit.Files()
+	require.NoError(t, err)
+	require.NoError(t, files.ForEach(func(f *object.File) error {
+		assert.NotContains(t, f.Name, "results/", "query results should not be committed")
+		return nil
+	}))
+	assert.Len(t, commitMessages(t, store), 2)
+}
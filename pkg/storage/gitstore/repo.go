@@ -0,0 +1,222 @@
+package gitstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DefaultAuthorName is used for commits if git config has no user name
+const DefaultAuthorName = "DataTug"
+
+// DefaultAuthorEmail is used for commits if git config has no user email
+const DefaultAuthorEmail = "datatug@localhost"
+
+// ErrNothingToCommit is returned by commit if there are no changes to commit
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+// repoLocks holds a mutex per root directory of a repository, so commits of all stores opened
+// on the same repository are serialized
+var repoLocks sync.Map
+
+func repoLock(rootDir string) *sync.Mutex {
+	mutex, _ := repoLocks.LoadOrStore(filepath.Clean(rootDir), new(sync.Mutex))
+	return mutex.(*sync.Mutex)
+}
+
+// gitRepo wraps a local git repository with a working tree
+type gitRepo struct {
+	mutex    *sync.Mutex
+	repo     *git.Repository
+	rootDir  string
+	author   object.Signature
+	nowUTC   func() time.Time
+	worktree *git.Worktree
+}
+
+// openRepo opens a local git repository that contains the given directory.
+// Only repositories on the local file system are supported, there is no fetching or pushing.
+func openRepo(dir string) (*gitRepo, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", dir, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("git repository at %s has no working tree: %w", dir, err)
+	}
+	// Temporary files of atomic writes in progress by concurrent saves must not be staged
+	worktree.Excludes = append(worktree.Excludes, gitignore.ParsePattern(".*.tmp-*", nil))
+	r := &gitRepo{
+		mutex:    repoLock(worktree.Filesystem.Root()),
+		repo:     repo,
+		rootDir:  worktree.Filesystem.Root(),
+		worktree: worktree,
+		nowUTC: func() time.Time {
+			return time.Now().UTC()
+		},
+		author: object.Signature{
+			Name:  DefaultAuthorName,
+			Email: DefaultAuthorEmail,
+		},
+	}
+	if cfg, err := repo.ConfigScoped(config.GlobalScope); err == nil {
+		if cfg.User.Name != "" {
+			r.author.Name = cfg.User.Name
+		}
+		if cfg.User.Email != "" {
+			r.author.Email = cfg.User.Email
+		}
+	}
+	return r, nil
+}
+
+// relPath returns slash separated path relative to the repository root
+func (r *gitRepo) relPath(absPath string) (string, error) {
+	rel, err := filepath.Rel(r.rootDir, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// commit stages changes under the given directory (relative to the repository root) and commits them.
+// Only the directory is committed: changes outside of it are left unstaged & changes the user has staged
+// outside of it stay staged, so unrelated edits in the repository are not committed.
+// Returns ErrNothingToCommit if the directory has no changes.
+func (r *gitRepo) commit(dir, message string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	status, err := r.worktree.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get status of git working tree: %w", err)
+	}
+	var hasStagedChanges bool
+	for filePath, fileStatus := range status {
+		if !isInDir(filePath, dir) {
+			continue
+		}
+		switch fileStatus.Worktree {
+		case git.Unmodified:
+		case git.Deleted:
+			if _, err = r.worktree.Remove(filePath); err != nil {
+				return fmt.Errorf("failed to stage deletion of %s: %w", filePath, err)
+			}
+		default:
+			if _, err = r.worktree.Add(filePath); err != nil {
+				return fmt.Errorf("failed to stage %s: %w", filePath, err)
+			}
+		}
+		if fileStatus.Worktree != git.Unmodified || fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			hasStagedChanges = true
+		}
+	}
+	if !hasStagedChanges {
+		return ErrNothingToCommit
+	}
+	staged, err := r.repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read git index: %w", err)
+	}
+	// Commit consumes the whole index, so it is replaced with an index that has changes of the directory only
+	dirIndex, err := r.indexOfDir(staged, dir)
+	if err != nil {
+		return err
+	}
+	if err = r.repo.Storer.SetIndex(dirIndex); err != nil {
+		return fmt.Errorf("failed to write git index: %w", err)
+	}
+	author := r.author
+	author.When = r.nowUTC()
+	_, err = r.worktree.Commit(message, &git.CommitOptions{Author: &author})
+	if restoreErr := r.repo.Storer.SetIndex(mergeIndexes(dirIndex, staged, dir)); restoreErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to restore staged changes outside of %s: %w", dir, restoreErr))
+	}
+	if errors.Is(err, git.ErrEmptyCommit) {
+		return ErrNothingToCommit
+	} else if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// indexOfDir returns a copy of the index where entries outside of the directory are as in HEAD
+func (r *gitRepo) indexOfDir(idx *index.Index, dir string) (*index.Index, error) {
+	result := &index.Index{Version: idx.Version}
+	for _, entry := range idx.Entries {
+		if isInDir(entry.Name, dir) {
+			result.Entries = append(result.Entries, entry)
+		}
+	}
+	head, err := r.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return result, nil // nothing is committed yet
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD of git repository: %w", err)
+	}
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of HEAD commit: %w", err)
+	}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read tree of HEAD commit: %w", err)
+		}
+		if entry.Mode != filemode.Dir && !isInDir(name, dir) {
+			result.Entries = append(result.Entries, &index.Entry{Name: name, Hash: entry.Hash, Mode: entry.Mode})
+		}
+	}
+	sortIndexEntries(result)
+	return result, nil
+}
+
+// mergeIndexes returns an index with entries of the directory from dirIndex & other entries from staged
+func mergeIndexes(dirIndex, staged *index.Index, dir string) *index.Index {
+	result := &index.Index{Version: staged.Version}
+	for _, entry := range dirIndex.Entries {
+		if isInDir(entry.Name, dir) {
+			result.Entries = append(result.Entries, entry)
+		}
+	}
+	for _, entry := range staged.Entries {
+		if !isInDir(entry.Name, dir) {
+			result.Entries = append(result.Entries, entry)
+		}
+	}
+	sortIndexEntries(result)
+	return result
+}
+
+func sortIndexEntries(idx *index.Index) {
+	sort.Slice(idx.Entries, func(i, j int) bool {
+		return idx.Entries[i].Name < idx.Entries[j].Name
+	})
+}
+
+// isInDir checks if a slash separated path relative to the repository root is inside of a directory
+func isInDir(filePath, dir string) bool {
+	dir = strings.Trim(dir, "/")
+	return dir == "" || dir == "." || filePath == dir || strings.HasPrefix(filePath, dir+"/")
+}
@@ -0,0 +1,50 @@
+package gitstore
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/datatug/datatug-core/pkg/storage/dtprojcreator"
+	"github.com/datatug/datatug-core/pkg/storage/filestore"
+)
+
+var _ dtprojcreator.Storage = (*gitStorage)(nil)
+
+// NewStorage creates a dtprojcreator.Storage that writes files to the working tree
+// of a local git repository at repoDir and commits them on Commit()
+func NewStorage(repoDir string) (dtprojcreator.Storage, error) {
+	r, err := openRepo(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	return gitStorage{
+		gitRepo: r,
+		files:   filestore.NewStorage(r.rootDir),
+	}, nil
+}
+
+type gitStorage struct {
+	*gitRepo
+	files dtprojcreator.Storage
+}
+
+func (s gitStorage) FileExists(ctx context.Context, filePath string) (bool, error) {
+	return s.files.FileExists(ctx, filePath)
+}
+
+func (s gitStorage) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	return s.files.OpenFile(ctx, filePath)
+}
+
+func (s gitStorage) WriteFile(ctx context.Context, filePath string, reader io.Reader) error {
+	return s.files.WriteFile(ctx, filePath, reader)
+}
+
+// Commit commits all changes in the working tree, does nothing if there are no changes
+func (s gitStorage) Commit(_ context.Context, message string) error {
+	if err := s.commit(".", message); err != nil && !errors.Is(err, ErrNothingToCommit) {
+		return err
+	}
+	return nil
+}
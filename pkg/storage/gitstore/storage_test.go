@@ -0,0 +1,53 @@
+package gitstore
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStorage(t *testing.T) {
+	t.Run("not_a_repository", func(t *testing.T) {
+		_, err := NewStorage(t.TempDir())
+		assert.Error(t, err)
+	})
+
+	t.Run("write_and_commit", func(t *testing.T) {
+		ctx := context.Background()
+		repoDir := t.TempDir()
+		repo, err := git.PlainInit(repoDir, false)
+		require.NoError(t, err)
+
+		s, err := NewStorage(repoDir)
+		require.NoError(t, err)
+
+		require.NoError(t, s.WriteFile(ctx, "p1/README.md", strings.NewReader("# P1")))
+		exists, err := s.FileExists(ctx, "p1/README.md")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		f, err := s.OpenFile(ctx, "p1/README.md")
+		require.NoError(t, err)
+		content, err := io.ReadAll(f)
+		assert.NoError(t, err)
+		assert.Equal(t, "# P1", string(content))
+		_ = f.Close()
+
+		require.NoError(t, s.Commit(ctx, "Create project p1"))
+		head, err := repo.Head()
+		require.NoError(t, err)
+		commit, err := repo.CommitObject(head.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, "Create project p1", commit.Message)
+
+		assert.NoError(t, s.Commit(ctx, "Nothing changed"), "commit without changes is a no-op")
+		head2, err := repo.Head()
+		require.NoError(t, err)
+		assert.Equal(t, head.Hash(), head2.Hash())
+	})
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// RevisionLength is a number of hex characters in a revision
+const RevisionLength = 16
+
+// ContentRevision returns a revision (ETag) of a stored item derived from its content
+func ContentRevision(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])[:RevisionLength]
+}
+
+// briefRevisionSuffix marks revisions of items loaded with datatug.BriefsOnly option
+const briefRevisionSuffix = "-brief"
+
+// BriefRevision returns a revision of an item trimmed to a brief, a store rejects saving an item with such revision,
+// as the trimmed payload would be lost
+func BriefRevision(revision string) string {
+	if revision == "" || IsBriefRevision(revision) {
+		return revision
+	}
+	return revision + briefRevisionSuffix
+}
+
+// IsBriefRevision returns true if a revision has been set to an item trimmed to a brief
+func IsBriefRevision(revision string) bool {
+	return strings.HasSuffix(revision, briefRevisionSuffix)
+}
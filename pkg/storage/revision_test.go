@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentRevision(t *testing.T) {
+	r1 := ContentRevision([]byte(`{"id":"b1"}`))
+	assert.Len(t, r1, RevisionLength)
+	assert.Equal(t, r1, ContentRevision([]byte(`{"id":"b1"}`)))
+	assert.NotEqual(t, r1, ContentRevision([]byte(`{"id":"b2"}`)))
+}
+
+func TestBriefRevision(t *testing.T) {
+	r1 := ContentRevision([]byte(`{"id":"b1"}`))
+	assert.False(t, IsBriefRevision(r1))
+	brief := BriefRevision(r1)
+	assert.True(t, IsBriefRevision(brief))
+	assert.Equal(t, brief, BriefRevision(brief))
+	assert.Empty(t, BriefRevision(""))
+}
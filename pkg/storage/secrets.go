@@ -0,0 +1,39 @@
+package storage
+
+import "strings"
+
+var secretPropertyNames = map[string]bool{
+	"password":     true,
+	"passwd":       true,
+	"pwd":          true,
+	"secret":       true,
+	"clientsecret": true,
+	"apikey":       true,
+	"apisecret":    true,
+	"token":        true,
+	"accesstoken":  true,
+	"refreshtoken": true,
+	"authtoken":    true,
+	"privatekey":   true,
+}
+
+// IsSecretPropertyName returns true if a property with the given name holds a secret, e.g. "password" or "apiKey"
+func IsSecretPropertyName(name string) bool {
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	return secretPropertyNames[name]
+}
+
+// IsSecretVariableName returns true if a variable holds a secret: its name or the part of its name
+// after a "_" or "-" separator is a secret property name, e.g. "DB_PASSWORD" or "GITHUB_API_KEY"
+func IsSecretVariableName(name string) bool {
+	for {
+		if IsSecretPropertyName(name) {
+			return true
+		}
+		i := strings.IndexAny(name, "_-")
+		if i < 0 {
+			return false
+		}
+		name = name[i+1:]
+	}
+}
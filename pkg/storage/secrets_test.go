@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSecretPropertyName(t *testing.T) {
+	for _, name := range []string{"password", "Password", "api_key", "apiKey", "client-secret"} {
+		assert.True(t, IsSecretPropertyName(name), name)
+	}
+	for _, name := range []string{"user", "host", "DB_PASSWORD", ""} {
+		assert.False(t, IsSecretPropertyName(name), name)
+	}
+}
+
+func TestIsSecretVariableName(t *testing.T) {
+	for _, name := range []string{"password", "DB_PASSWORD", "GITHUB_API_KEY", "auth-token", "pwd"} {
+		assert.True(t, IsSecretVariableName(name), name)
+	}
+	for _, name := range []string{"DB_USER", "PASSWORD_POLICY", "TOKENS_LIMIT", "DB_HOST"} {
+		assert.False(t, IsSecretVariableName(name), name)
+	}
+}
@@ -0,0 +1,271 @@
+// Package tags implements listing & management of tags of project items on top of datatug.ProjectStore
+package tags
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/strongo/validation"
+)
+
+// ItemType defines type of tagged project item
+type ItemType string
+
+const (
+	ItemTypeQuery  ItemType = "query"
+	ItemTypeBoard  ItemType = "board"
+	ItemTypeEntity ItemType = "entity"
+)
+
+// ItemRef identifies a tagged project item, for queries ID includes folder path
+type ItemRef struct {
+	Type ItemType `json:"type"`
+	ID   string   `json:"id"`
+}
+
+// Usage holds number of project items that have a tag
+type Usage struct {
+	Tag    string           `json:"tag"`
+	Count  int              `json:"count"`
+	ByType map[ItemType]int `json:"byType"`
+}
+
+// Service lists, renames & merges tags across queries, boards & entities of a project
+type Service struct {
+	store datatug.ProjectStore
+}
+
+// NewService creates tags service. If the store implements datatug.ProjectTransactor
+// changes of multiple items are applied all-or-nothing by the store,
+// otherwise already saved items are reverted if a save fails.
+func NewService(store datatug.ProjectStore) *Service {
+	return &Service{store: store}
+}
+
+// taggedItem gives access to tags of a loaded project item
+type taggedItem struct {
+	ref  ItemRef
+	tags *datatug.ListOfTags
+	save func(ctx context.Context) error
+}
+
+func (s *Service) loadItems(ctx context.Context, o ...datatug.StoreOption) (items []taggedItem, err error) {
+	if items, err = s.loadQueries(ctx, "", items, o...); err != nil {
+		return nil, err
+	}
+	boards, err := s.store.LoadBoards(ctx, o...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load boards: %w", err)
+	}
+	for _, board := range boards {
+		items = append(items, taggedItem{
+			ref:  ItemRef{Type: ItemTypeBoard, ID: board.ID},
+			tags: &board.ListOfTags,
+			save: func(ctx context.Context) error {
+				return s.store.SaveBoard(ctx, board)
+			},
+		})
+	}
+	entities, err := s.store.LoadEntities(ctx, o...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load entities: %w", err)
+	}
+	for _, entity := range entities {
+		items = append(items, taggedItem{
+			ref:  ItemRef{Type: ItemTypeEntity, ID: entity.ID},
+			tags: &entity.ListOfTags,
+			save: func(ctx context.Context) error {
+				return s.store.SaveEntity(ctx, entity)
+			},
+		})
+	}
+	return items, nil
+}
+
+func (s *Service) loadQueries(ctx context.Context, folderPath string, items []taggedItem, o ...datatug.StoreOption) ([]taggedItem, error) {
+	folder, err := s.store.LoadQueries(ctx, folderPath, o...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load queries of folder %q: %w", folderPath, err)
+	}
+	if folder == nil {
+		return items, nil
+	}
+	for _, query := range folder.Items {
+		items = append(items, taggedItem{
+			ref:  ItemRef{Type: ItemTypeQuery, ID: path.Join(folderPath, query.ID)},
+			tags: &query.ListOfTags,
+			save: func(ctx context.Context) error {
+				toSave := &datatug.QueryDefWithFolderPath{FolderPath: folderPath, QueryDef: *query}
+				if err := s.store.SaveQuery(ctx, toSave); err != nil {
+					return err
+				}
+				query.Revision = toSave.Revision // so the query can be saved again
+				return nil
+			},
+		})
+	}
+	for _, subFolder := range folder.Folders {
+		if items, err = s.loadQueries(ctx, path.Join(folderPath, subFolder.ID), items, o...); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// List returns all tags used in the project with usage counts, ordered by tag.
+// Tags are matched ignoring case, a tag is listed with the spelling it has in the first item.
+func (s *Service) List(ctx context.Context) ([]Usage, error) {
+	items, err := s.loadItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byTag := make(map[string]*Usage)
+	for _, item := range items {
+		for _, tag := range item.tags.Tags {
+			usage, ok := byTag[strings.ToLower(tag)]
+			if !ok {
+				usage = &Usage{Tag: tag, ByType: make(map[ItemType]int)}
+				byTag[strings.ToLower(tag)] = usage
+			}
+			usage.Count++
+			usage.ByType[item.ref.Type]++
+		}
+	}
+	usages := make([]Usage, 0, len(byTag))
+	for _, usage := range byTag {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Tag < usages[j].Tag
+	})
+	return usages, nil
+}
+
+// Find returns project items that have all given tags
+func (s *Service) Find(ctx context.Context, tags ...string) ([]ItemRef, error) {
+	if len(tags) == 0 {
+		return nil, validation.NewErrRequestIsMissingRequiredField("tags")
+	}
+	items, err := s.loadItems(ctx, datatug.Tags(tags...))
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]ItemRef, 0, len(items))
+	for _, item := range items {
+		if item.tags.HasTags(tags...) { // in case a store ignores the tags filter
+			refs = append(refs, item.ref)
+		}
+	}
+	return refs, nil
+}
+
+// Rename renames a tag in all project items. If an item already has the new tag the old one is removed.
+// Returns items that have been changed.
+func (s *Service) Rename(ctx context.Context, from, to string) ([]ItemRef, error) {
+	if from == "" {
+		return nil, validation.NewErrRequestIsMissingRequiredField("from")
+	}
+	return s.Merge(ctx, to, from)
+}
+
+// Merge replaces given tags with the target tag in all project items.
+// Returns items that have been changed.
+func (s *Service) Merge(ctx context.Context, into string, tags ...string) ([]ItemRef, error) {
+	if err := validateTag("into", into); err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, validation.NewErrRequestIsMissingRequiredField("tags")
+	}
+	items, err := s.loadItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var changes []change
+	for _, item := range items {
+		merged, changed := mergeTags(item.tags.Tags, into, tags)
+		if changed {
+			changes = append(changes, change{item: item, before: item.tags.Tags, after: merged})
+		}
+	}
+	if err = s.apply(ctx, changes); err != nil {
+		return nil, err
+	}
+	refs := make([]ItemRef, len(changes))
+	for i, c := range changes {
+		refs[i] = c.item.ref
+	}
+	return refs, nil
+}
+
+func validateTag(field, tag string) error {
+	if tag == "" {
+		return validation.NewErrRequestIsMissingRequiredField(field)
+	}
+	if len(tag) > datatug.MaxTagLength {
+		return validation.NewErrBadRequestFieldValue(field, fmt.Sprintf("too long tag (max %v, got %v)", datatug.MaxTagLength, len(tag)))
+	}
+	return nil
+}
+
+// mergeTags replaces tags to be merged with the target tag keeping order & removing duplicates.
+// Tags are matched ignoring case, so merging can change case of a tag, e.g. "finance" into "Finance".
+func mergeTags(itemTags []string, into string, tags []string) (merged []string, changed bool) {
+	merged = make([]string, 0, len(itemTags))
+	for _, tag := range itemTags {
+		for _, t := range tags {
+			if strings.EqualFold(tag, t) {
+				changed = changed || tag != into
+				tag = into
+				break
+			}
+		}
+		if !(datatug.ListOfTags{Tags: merged}.HasTags(tag)) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged, changed
+}
+
+type change struct {
+	item   taggedItem
+	before []string
+	after  []string
+}
+
+// apply saves changed items in a store transaction or, if the store does not support transactions,
+// reverts already saved items on failure
+func (s *Service) apply(ctx context.Context, changes []change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var saved int
+	saveAll := func(ctx context.Context) error {
+		for _, c := range changes {
+			c.item.tags.Tags = c.after
+			if err := c.item.save(ctx); err != nil {
+				return fmt.Errorf("failed to save %s %s: %w", c.item.ref.Type, c.item.ref.ID, err)
+			}
+			saved++
+		}
+		return nil
+	}
+	if transactor, ok := s.store.(datatug.ProjectTransactor); ok {
+		return transactor.RunInTransaction(ctx, saveAll)
+	}
+	err := saveAll(ctx)
+	if err == nil {
+		return nil
+	}
+	for _, c := range changes[:saved] {
+		c.item.tags.Tags = c.before
+		if revertErr := c.item.save(ctx); revertErr != nil {
+			return fmt.Errorf("%w (failed to revert %s %s: %v)", err, c.item.ref.Type, c.item.ref.ID, revertErr)
+		}
+	}
+	return err
+}
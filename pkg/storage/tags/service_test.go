@@ -0,0 +1,176 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage/filestore"
+	"github.com/datatug/datatug-core/pkg/test/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) datatug.ProjectStore {
+	t.Helper()
+	ctx := context.Background()
+	store := filestore.NewProjectStore("p1", t.TempDir())
+
+	q1 := storetest.NewQuery("q1", "Query 1", "SELECT 1")
+	q1.Tags = []string{"reports", "sales"}
+	require.NoError(t, store.SaveQuery(ctx, q1))
+	require.NoError(t, store.SaveQuery(ctx, storetest.NewQuery("q2", "Query 2", "SELECT 2")))
+
+	b1 := storetest.NewBoard("b1", "Board 1")
+	b1.Tags = []string{"sales"}
+	require.NoError(t, store.SaveBoard(ctx, b1))
+	b2 := storetest.NewBoard("b2", "Board 2")
+	b2.Tags = []string{"revenue", "sales"}
+	require.NoError(t, store.SaveBoard(ctx, b2))
+
+	e1 := storetest.NewEntity("e1", "Entity 1")
+	e1.ListOfTags = datatug.ListOfTags{Tags: []string{"crm", "revenue"}}
+	require.NoError(t, store.SaveEntity(ctx, e1))
+	return store
+}
+
+func TestService_List(t *testing.T) {
+	usages, err := NewService(newTestStore(t)).List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Usage{
+		{Tag: "crm", Count: 1, ByType: map[ItemType]int{ItemTypeEntity: 1}},
+		{Tag: "reports", Count: 1, ByType: map[ItemType]int{ItemTypeQuery: 1}},
+		{Tag: "revenue", Count: 2, ByType: map[ItemType]int{ItemTypeBoard: 1, ItemTypeEntity: 1}},
+		{Tag: "sales", Count: 3, ByType: map[ItemType]int{ItemTypeQuery: 1, ItemTypeBoard: 2}},
+	}, usages)
+}
+
+func TestService_Find(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newTestStore(t))
+
+	refs, err := service.Find(ctx, "sales")
+	require.NoError(t, err)
+	assert.Equal(t, []ItemRef{{ItemTypeQuery, "q1"}, {ItemTypeBoard, "b1"}, {ItemTypeBoard, "b2"}}, refs)
+
+	refs, err = service.Find(ctx, "sales", "revenue")
+	require.NoError(t, err)
+	assert.Equal(t, []ItemRef{{ItemTypeBoard, "b2"}}, refs)
+
+	refs, err = service.Find(ctx, "Sales")
+	require.NoError(t, err)
+	assert.Len(t, refs, 3, "tags should be matched ignoring case")
+
+	_, err = service.Find(ctx)
+	assert.Error(t, err)
+}
+
+func TestService_Rename(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	service := NewService(store)
+
+	changed, err := service.Rename(ctx, "sales", "revenue")
+	require.NoError(t, err)
+	assert.Equal(t, []ItemRef{{ItemTypeQuery, "q1"}, {ItemTypeBoard, "b1"}, {ItemTypeBoard, "b2"}}, changed)
+
+	q1, err := store.LoadQuery(ctx, "q1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reports", "revenue"}, q1.Tags)
+	b2, err := store.LoadBoard(ctx, "b2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"revenue"}, b2.Tags, "duplicate should be removed")
+
+	changed, err = service.Rename(ctx, "unknown", "other")
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	changed, err = service.Rename(ctx, "revenue", "revenue")
+	require.NoError(t, err)
+	assert.Empty(t, changed, "renaming to the same tag is a no-op")
+
+	changed, err = service.Rename(ctx, "REPORTS", "Reports")
+	require.NoError(t, err)
+	assert.Equal(t, []ItemRef{{ItemTypeQuery, "q1"}}, changed, "case of a tag can be changed")
+	q1, err = store.LoadQuery(ctx, "q1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Reports", "revenue"}, q1.Tags)
+
+	_, err = service.Rename(ctx, "", "x")
+	assert.Error(t, err)
+	_, err = service.Rename(ctx, "x", "")
+	assert.Error(t, err)
+}
+
+func TestService_Merge(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	changed, err := NewService(store).Merge(ctx, "finance", "sales", "revenue")
+	require.NoError(t, err)
+	assert.Len(t, changed, 4)
+	usages, err := NewService(store).List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Usage{
+		{Tag: "crm", Count: 1, ByType: map[ItemType]int{ItemTypeEntity: 1}},
+		{Tag: "finance", Count: 4, ByType: map[ItemType]int{ItemTypeQuery: 1, ItemTypeBoard: 2, ItemTypeEntity: 1}},
+		{Tag: "reports", Count: 1, ByType: map[ItemType]int{ItemTypeQuery: 1}},
+	}, usages)
+}
+
+// failingStore does not implement datatug.ProjectTransactor and fails to save a board
+type failingStore struct {
+	datatug.ProjectStore
+	failBoardID string
+}
+
+func (s failingStore) SaveBoard(ctx context.Context, board *datatug.Board) error {
+	if board.ID == s.failBoardID {
+		return errors.New("disk is full")
+	}
+	return s.ProjectStore.SaveBoard(ctx, board)
+}
+
+func TestService_Rename_Failure(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		store func(store datatug.ProjectStore) datatug.ProjectStore
+	}{
+		{
+			name: "transaction",
+			store: func(store datatug.ProjectStore) datatug.ProjectStore {
+				return transactionalFailingStore{failingStore{ProjectStore: store, failBoardID: "b2"}}
+			},
+		},
+		{
+			name: "revert",
+			store: func(store datatug.ProjectStore) datatug.ProjectStore {
+				return failingStore{ProjectStore: store, failBoardID: "b2"}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			_, err := NewService(tt.store(store)).Rename(ctx, "sales", "deals")
+			assert.ErrorContains(t, err, "disk is full")
+
+			q1, err := store.LoadQuery(ctx, "q1")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"reports", "sales"}, q1.Tags)
+			b1, err := store.LoadBoard(ctx, "b1")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"sales"}, b1.Tags)
+		})
+	}
+}
+
+// transactionalFailingStore delegates transactions to the underlying file store
+type transactionalFailingStore struct {
+	failingStore
+}
+
+func (s transactionalFailingStore) RunInTransaction(ctx context.Context, f func(ctx context.Context) error) error {
+	return s.ProjectStore.(datatug.ProjectTransactor).RunInTransaction(ctx, f)
+}
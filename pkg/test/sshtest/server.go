@@ -0,0 +1,192 @@
+// Package sshtest provides an in-process SSH bastion to test connections through SSH tunnels
+package sshtest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Server is an SSH server that authenticates users by public keys & forwards "direct-tcpip" channels
+type Server struct {
+	Addr    string
+	HostKey ssh.Signer
+
+	listener   net.Listener
+	authorized []ssh.PublicKey
+
+	mutex     sync.Mutex
+	forwarded []string
+	conns     []net.Conn
+	wg        sync.WaitGroup
+}
+
+// NewServer starts a server that accepts the keys, the server is stopped on a test cleanup
+func NewServer(t testing.TB, authorized ...ssh.PublicKey) *Server {
+	t.Helper()
+	hostKey, _ := NewKey(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &Server{Addr: listener.Addr().String(), HostKey: hostKey, listener: listener, authorized: authorized}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		s.mutex.Lock()
+		for _, conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.mutex.Unlock()
+		s.wg.Wait()
+	})
+	return s
+}
+
+// Host returns a host the server listens on
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.Addr)
+	return host
+}
+
+// Port returns a port the server listens on
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.Addr)
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// Forwarded returns "host:port" addresses of forwarded channels
+func (s *Server) Forwarded() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.forwarded...)
+}
+
+// WriteKnownHosts writes a known hosts file with a key of the server & returns its path
+func (s *Server) WriteKnownHosts(t testing.TB) string {
+	t.Helper()
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.Addr)}, s.HostKey.PublicKey())
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(path, []byte(line+"\n"), 0600))
+	return path
+}
+
+// NewKey generates an ed25519 key & writes it to an OpenSSH private key file
+func NewKey(t testing.TB) (signer ssh.Signer, keyPath string) {
+	t.Helper()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err = ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	require.NoError(t, err)
+	keyPath = filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600))
+	return signer, keyPath
+}
+
+// NewEchoServer starts a TCP server that writes back what it reads & returns its address
+func NewEchoServer(t testing.TB) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			for _, authorized := range s.authorized {
+				if string(authorized.Marshal()) == string(key.Marshal()) {
+					return nil, nil
+				}
+			}
+			return nil, errors.New("unauthorized key")
+		},
+	}
+	config.AddHostKey(s.HostKey)
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		s.conns = append(s.conns, conn)
+		s.mutex.Unlock()
+		s.wg.Add(1)
+		go s.handle(conn, config)
+	}
+}
+
+func (s *Server) handle(conn net.Conn, config *ssh.ServerConfig) {
+	defer s.wg.Done()
+	defer func() { _ = conn.Close() }()
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only direct-tcpip channels are supported")
+			continue
+		}
+		var target struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err = ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		address := net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port)))
+		s.mutex.Lock()
+		s.forwarded = append(s.forwarded, address)
+		s.mutex.Unlock()
+		remote, err := net.Dial("tcp", address)
+		if err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			_ = remote.Close()
+			continue
+		}
+		go ssh.DiscardRequests(channelRequests)
+		go func() {
+			defer func() { _ = channel.Close() }()
+			defer func() { _ = remote.Close() }()
+			go func() { _, _ = io.Copy(remote, channel) }()
+			_, _ = io.Copy(channel, remote)
+		}()
+	}
+}
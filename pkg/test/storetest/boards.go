@@ -0,0 +1,191 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/datatug/datatug-core/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunBoardsStoreTests runs conformance tests for datatug.BoardsStore
+func RunBoardsStoreTests(t *testing.T, newStore func(t *testing.T) datatug.BoardsStore) {
+	ctx := context.Background()
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		board := NewBoard("b1", "Board 1")
+		require.NoError(t, store.SaveBoard(ctx, board))
+
+		loaded, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, "b1", loaded.ID)
+		assert.Equal(t, "Board 1", loaded.Title)
+
+		boards, err := store.LoadBoards(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b1"}, datatug.ProjectItems[*datatug.Board](boards).IDs())
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveBoard(ctx, NewBoard("b1", "Before")))
+		require.NoError(t, store.SaveBoard(ctx, NewBoard("b1", "After")))
+		loaded, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, "After", loaded.Title)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadBoard(ctx, "unknown")
+		assert.Error(t, err)
+		boards, err := store.LoadBoards(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, boards)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t)
+		assert.Error(t, store.SaveBoard(ctx, NewBoard("b1", "")))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveBoard(ctx, NewBoard("b1", "Board 1")))
+		require.NoError(t, store.DeleteBoard(ctx, "b1"))
+		_, err := store.LoadBoard(ctx, "b1")
+		assert.Error(t, err)
+		assert.NoError(t, store.DeleteBoard(ctx, "b1"), "deleting a missing board should not fail")
+	})
+
+	t.Run("revision_conflict", func(t *testing.T) {
+		store := newStore(t)
+		board := NewBoard("b1", "Board 1")
+		require.NoError(t, store.SaveBoard(ctx, board))
+		assert.NotEmpty(t, board.Revision, "save should set revision of the saved item")
+
+		mine, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, board.Revision, mine.Revision)
+		theirs, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+
+		theirs.Title = "Their change"
+		require.NoError(t, store.SaveBoard(ctx, theirs))
+		assert.NotEqual(t, mine.Revision, theirs.Revision)
+
+		mine.Title = "My change"
+		err = store.SaveBoard(ctx, mine)
+		assert.True(t, errors.Is(err, storage.ErrRevisionConflict), err)
+		loaded, err := store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, "Their change", loaded.Title)
+
+		mine.Revision = "" // saving without a revision overwrites unconditionally
+		require.NoError(t, store.SaveBoard(ctx, mine))
+		loaded, err = store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Equal(t, "My change", loaded.Title)
+		assert.Equal(t, mine.Revision, loaded.Revision)
+
+		require.NoError(t, store.DeleteBoard(ctx, "b1"))
+		err = store.SaveBoard(ctx, loaded)
+		assert.True(t, errors.Is(err, storage.ErrRevisionConflict), "saving a deleted item with a revision should fail: %v", err)
+	})
+
+	t.Run("tags_filter", func(t *testing.T) {
+		store := newStore(t)
+		for id, tags := range map[string][]string{"b1": {"sales"}, "b2": {"sales", "daily"}, "b3": nil} {
+			board := NewBoard(id, "Board "+id)
+			board.Tags = tags
+			require.NoError(t, store.SaveBoard(ctx, board))
+		}
+		boards, err := store.LoadBoards(ctx, datatug.Tags("sales"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b1", "b2"}, datatug.ProjectItems[*datatug.Board](boards).IDs())
+		boards, err = store.LoadBoards(ctx, datatug.Tags("sales", "daily"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b2"}, datatug.ProjectItems[*datatug.Board](boards).IDs())
+		boards, err = store.LoadBoards(ctx, datatug.Tags("unknown"))
+		require.NoError(t, err)
+		assert.Empty(t, boards)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		store := newStore(t)
+		for i, folder := range []string{"~/sales", "~/sales/daily", "~/hr", ""} {
+			board := NewBoard(fmt.Sprintf("b%d", i+1), fmt.Sprintf("Board %d of %s", i+1, folder))
+			board.Folder = folder
+			board.Rows = datatug.BoardRows{{MinHeight: "100px"}}
+			require.NoError(t, store.SaveBoard(ctx, board))
+		}
+		load := func(o ...datatug.StoreOption) []string {
+			t.Helper()
+			boards, err := store.LoadBoards(ctx, o...)
+			require.NoError(t, err)
+			return datatug.ProjectItems[*datatug.Board](boards).IDs()
+		}
+		assert.Equal(t, []string{"b1", "b2"}, load(datatug.Limit(2)))
+		assert.Equal(t, []string{"b3", "b4"}, load(datatug.Limit(2), datatug.Cursor("b2")))
+		assert.Empty(t, load(datatug.Limit(2), datatug.Cursor("b4")))
+		assert.Equal(t, []string{"b1", "b2"}, load(datatug.FolderPrefix("~/sales")))
+		assert.Equal(t, []string{"b2"}, load(datatug.FolderPrefix("~/sales/daily")))
+		assert.Empty(t, load(datatug.FolderPrefix("~/sale")), "folder prefix should match whole folder names")
+		assert.Equal(t, []string{"b2", "b3"}, load(datatug.TitleContains("OF ~/"), datatug.Cursor("b1")))
+
+		boards, err := store.LoadBoards(ctx, datatug.BriefsOnly())
+		require.NoError(t, err)
+		require.Len(t, boards, 4)
+		assert.Empty(t, boards[0].Rows)
+		assert.Equal(t, "Board 1 of ~/sales", boards[0].Title)
+		board, err := store.LoadBoard(ctx, "b1", datatug.BriefsOnly())
+		require.NoError(t, err)
+		assert.Empty(t, board.Rows)
+		board, err = store.LoadBoard(ctx, "b1")
+		require.NoError(t, err)
+		assert.Len(t, board.Rows, 1)
+	})
+
+	t.Run("concurrent_writes", func(t *testing.T) {
+		store := newStore(t)
+		const count = 20
+		runConcurrently(t, count, func(i int) error {
+			return store.SaveBoard(ctx, NewBoard(fmt.Sprintf("b%02d", i), fmt.Sprintf("Board %d", i)))
+		})
+		boards, err := store.LoadBoards(ctx)
+		require.NoError(t, err)
+		assert.Len(t, boards, count)
+	})
+}
+
+// NewBoard creates a minimal valid board
+func NewBoard(id, title string) *datatug.Board {
+	return &datatug.Board{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: title},
+		},
+	}
+}
+
+func runConcurrently(t *testing.T, count int, worker func(i int) error) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make([]error, count)
+	wg.Add(count)
+	for i := 0; i < count; i++ {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = worker(i)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		assert.NoError(t, err, "worker #%d failed", i)
+	}
+}
@@ -0,0 +1,93 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunEntitiesStoreTests runs conformance tests for datatug.EntitiesStore
+func RunEntitiesStoreTests(t *testing.T, newStore func(t *testing.T) datatug.EntitiesStore) {
+	ctx := context.Background()
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		entity := NewEntity("e1", "Entity 1")
+		entity.Fields = datatug.EntityFields{
+			{ID: "id", Type: datatug.TypeInteger, IsKeyField: true},
+		}
+		require.NoError(t, store.SaveEntity(ctx, entity))
+
+		loaded, err := store.LoadEntity(ctx, "e1")
+		require.NoError(t, err)
+		assert.Equal(t, "e1", loaded.ID)
+		assert.Equal(t, "Entity 1", loaded.Title)
+		if assert.Len(t, loaded.Fields, 1) {
+			assert.Equal(t, "id", loaded.Fields[0].ID)
+			assert.True(t, loaded.Fields[0].IsKeyField)
+		}
+
+		entities, err := store.LoadEntities(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e1"}, entities.IDs())
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadEntity(ctx, "unknown")
+		assert.Error(t, err)
+		entities, err := store.LoadEntities(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, entities)
+	})
+
+	t.Run("tags_filter", func(t *testing.T) {
+		store := newStore(t)
+		e1 := NewEntity("e1", "Entity 1")
+		e1.ListOfTags = datatug.ListOfTags{Tags: []string{"crm"}}
+		require.NoError(t, store.SaveEntity(ctx, e1))
+		require.NoError(t, store.SaveEntity(ctx, NewEntity("e2", "Entity 2")))
+		entities, err := store.LoadEntities(ctx, datatug.Tags("crm"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e1"}, entities.IDs())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t)
+		assert.Error(t, store.SaveEntity(ctx, nil))
+		assert.Error(t, store.SaveEntity(ctx, NewEntity("", "No ID")))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveEntity(ctx, NewEntity("e1", "Entity 1")))
+		require.NoError(t, store.DeleteEntity(ctx, "e1"))
+		_, err := store.LoadEntity(ctx, "e1")
+		assert.Error(t, err)
+		assert.NoError(t, store.DeleteEntity(ctx, "e1"), "deleting a missing entity should not fail")
+	})
+
+	t.Run("concurrent_writes", func(t *testing.T) {
+		store := newStore(t)
+		const count = 20
+		runConcurrently(t, count, func(i int) error {
+			return store.SaveEntity(ctx, NewEntity(fmt.Sprintf("e%02d", i), fmt.Sprintf("Entity %d", i)))
+		})
+		entities, err := store.LoadEntities(ctx)
+		require.NoError(t, err)
+		assert.Len(t, entities, count)
+	})
+}
+
+// NewEntity creates a minimal valid entity
+func NewEntity(id, title string) *datatug.Entity {
+	return &datatug.Entity{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: title},
+		},
+	}
+}
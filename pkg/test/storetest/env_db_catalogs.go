@@ -0,0 +1,75 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunEnvDbCatalogStoreTests runs conformance tests for datatug.EnvDbCatalogStore
+func RunEnvDbCatalogStoreTests(t *testing.T, newStore func(t *testing.T) datatug.EnvDbCatalogStore) {
+	ctx := context.Background()
+	const envID, serverID = "dev", "db1:1433"
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		catalog := NewDbCatalog("sales", "sqlserver")
+		require.NoError(t, store.SaveEnvDbCatalog(ctx, envID, serverID, catalog.ID, catalog))
+
+		loaded, err := store.LoadEnvDbCatalog(ctx, envID, serverID, "sales")
+		require.NoError(t, err)
+		assert.Equal(t, "sales", loaded.ID)
+		assert.Equal(t, "sqlserver", loaded.Driver)
+
+		catalogs, err := store.LoadEnvDbCatalogs(ctx, envID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sales"}, catalogs.IDs())
+	})
+
+	t.Run("SaveEnvDbCatalogs", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveEnvDbCatalogs(ctx, envID, serverID, "", datatug.DbCatalogs{
+			NewDbCatalog("hr", "sqlserver"),
+			NewDbCatalog("sales", "sqlserver"),
+		}))
+		catalogs, err := store.LoadEnvDbCatalogs(ctx, envID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hr", "sales"}, catalogs.IDs())
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadEnvDbCatalog(ctx, envID, serverID, "unknown")
+		assert.Error(t, err)
+		catalogs, err := store.LoadEnvDbCatalogs(ctx, envID)
+		assert.NoError(t, err)
+		assert.Empty(t, catalogs)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t)
+		catalog := NewDbCatalog("sales", "")
+		assert.Error(t, store.SaveEnvDbCatalog(ctx, envID, serverID, catalog.ID, catalog))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		catalog := NewDbCatalog("sales", "sqlserver")
+		require.NoError(t, store.SaveEnvDbCatalog(ctx, envID, serverID, catalog.ID, catalog))
+		require.NoError(t, store.DeleteEnvDbCatalog(ctx, envID, serverID, "sales"))
+		_, err := store.LoadEnvDbCatalog(ctx, envID, serverID, "sales")
+		assert.Error(t, err)
+		assert.NoError(t, store.DeleteEnvDbCatalog(ctx, envID, serverID, "sales"), "deleting a missing catalog should not fail")
+	})
+}
+
+// NewDbCatalog creates a DB catalog without schemas
+func NewDbCatalog(id, driver string) *datatug.DbCatalog {
+	catalog := new(datatug.DbCatalog)
+	catalog.ID = id
+	catalog.Driver = driver
+	return catalog
+}
@@ -0,0 +1,76 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunEnvDbServersStoreTests runs conformance tests for datatug.EnvDbServersStore
+func RunEnvDbServersStoreTests(t *testing.T, newStore func(t *testing.T) datatug.EnvDbServersStore) {
+	ctx := context.Background()
+	const envID = "dev"
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		server := NewEnvDbServer("sqlserver", "db1", 1433, "sales")
+		require.NoError(t, store.SaveEnvDbServer(ctx, envID, server))
+
+		loaded, err := store.LoadEnvDbServer(ctx, envID, server.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, server.ServerRef, loaded.ServerRef)
+		assert.Equal(t, []string{"sales"}, loaded.Catalogs)
+
+		servers, err := store.LoadEnvDbServers(ctx, envID)
+		require.NoError(t, err)
+		if assert.Len(t, servers, 1) {
+			assert.Equal(t, server.GetID(), servers[0].GetID())
+		}
+	})
+
+	t.Run("SaveEnvServers", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveEnvServers(ctx, envID, datatug.EnvDbServers{
+			NewEnvDbServer("sqlserver", "db1", 1433),
+			NewEnvDbServer("sqlserver", "db2", 1433),
+		}))
+		servers, err := store.LoadEnvDbServers(ctx, envID)
+		require.NoError(t, err)
+		assert.Len(t, servers, 2)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadEnvDbServer(ctx, envID, "unknown:1433")
+		assert.Error(t, err)
+		servers, err := store.LoadEnvDbServers(ctx, envID)
+		assert.NoError(t, err)
+		assert.Empty(t, servers)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t)
+		assert.Error(t, store.SaveEnvDbServer(ctx, envID, NewEnvDbServer("", "db1", 1433)))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		server := NewEnvDbServer("sqlserver", "db1", 1433)
+		require.NoError(t, store.SaveEnvDbServer(ctx, envID, server))
+		require.NoError(t, store.DeleteEnvDbServer(ctx, envID, server.GetID()))
+		_, err := store.LoadEnvDbServer(ctx, envID, server.GetID())
+		assert.Error(t, err)
+		assert.NoError(t, store.DeleteEnvDbServer(ctx, envID, server.GetID()), "deleting a missing server should not fail")
+	})
+}
+
+// NewEnvDbServer creates an environment DB server
+func NewEnvDbServer(driver, host string, port int, catalogs ...string) *datatug.EnvDbServer {
+	return &datatug.EnvDbServer{
+		ServerRef: datatug.ServerRef{Driver: driver, Host: host, Port: port},
+		Catalogs:  catalogs,
+	}
+}
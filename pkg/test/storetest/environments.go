@@ -0,0 +1,118 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunEnvironmentsStoreTests runs conformance tests for datatug.EnvironmentsStore
+func RunEnvironmentsStoreTests(t *testing.T, newStore func(t *testing.T) datatug.EnvironmentsStore) {
+	ctx := context.Background()
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		env := NewEnvironment("dev", "Development")
+		require.NoError(t, store.SaveEnvironment(ctx, env))
+
+		loaded, err := store.LoadEnvironment(ctx, "dev")
+		require.NoError(t, err)
+		assert.Equal(t, "dev", loaded.ID)
+		assert.Equal(t, "Development", loaded.Title)
+
+		envs, err := store.LoadEnvironments(ctx)
+		require.NoError(t, err)
+		if assert.Len(t, envs, 1) {
+			assert.Equal(t, "dev", envs[0].ID)
+			assert.Equal(t, "Development", envs[0].Title)
+		}
+	})
+
+	t.Run("SaveEnvironments", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveEnvironments(ctx, datatug.Environments{
+			NewEnvironment("dev", "Development"),
+			NewEnvironment("prod", "Production"),
+		}))
+		envs, err := store.LoadEnvironments(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dev", "prod"}, envs.IDs())
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadEnvironment(ctx, "unknown")
+		assert.Error(t, err)
+		envs, err := store.LoadEnvironments(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, envs)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveEnvironment(ctx, NewEnvironment("dev", "Development")))
+		require.NoError(t, store.DeleteEnvironment(ctx, "dev"))
+		_, err := store.LoadEnvironment(ctx, "dev")
+		assert.Error(t, err)
+		envs, err := store.LoadEnvironments(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, envs)
+		assert.NoError(t, store.DeleteEnvironment(ctx, "dev"), "deleting a missing environment should not fail")
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		store := newStore(t)
+		source := NewEnvironment("dev", "Development")
+		source.DbServers = datatug.EnvDbServers{
+			{ServerRef: datatug.ServerRef{Driver: "sqlserver", Host: "dev-db", Port: 1433}, Catalogs: []string{"sales"}},
+		}
+		require.NoError(t, store.SaveEnvironment(ctx, source))
+
+		clone, err := store.CloneEnvironment(ctx, datatug.CloneEnvironmentRequest{
+			SourceID: "dev", ID: "stage",
+			Rewrites: datatug.ServerRewrites{{FromHost: "dev-db", ToHost: "stage-db", ToPort: 1434}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "stage", clone.ID)
+		assert.Equal(t, "Development", clone.Title)
+
+		loaded, err := store.LoadEnvironment(ctx, "stage")
+		require.NoError(t, err)
+		if assert.Len(t, loaded.DbServers, 1) {
+			assert.Equal(t, datatug.ServerRef{Driver: "sqlserver", Host: "stage-db", Port: 1434}, loaded.DbServers[0].ServerRef)
+			assert.Equal(t, []string{"sales"}, loaded.DbServers[0].Catalogs)
+		}
+		loaded, err = store.LoadEnvironment(ctx, "dev")
+		require.NoError(t, err)
+		assert.Equal(t, "dev-db", loaded.DbServers[0].Host, "source environment should not be changed")
+
+		_, err = store.CloneEnvironment(ctx, datatug.CloneEnvironmentRequest{SourceID: "dev", ID: "stage"})
+		assert.ErrorIs(t, err, datatug.ErrEnvironmentAlreadyExists)
+		_, err = store.CloneEnvironment(ctx, datatug.CloneEnvironmentRequest{SourceID: "unknown", ID: "qa"})
+		assert.Error(t, err)
+	})
+
+	t.Run("concurrent_writes", func(t *testing.T) {
+		store := newStore(t)
+		const count = 10
+		runConcurrently(t, count, func(i int) error {
+			return store.SaveEnvironment(ctx, NewEnvironment(fmt.Sprintf("env%02d", i), ""))
+		})
+		envs, err := store.LoadEnvironments(ctx)
+		require.NoError(t, err)
+		assert.Len(t, envs, count)
+	})
+}
+
+// NewEnvironment creates a minimal valid environment
+func NewEnvironment(id, title string) *datatug.Environment {
+	return &datatug.Environment{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: title},
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunFoldersStoreTests runs conformance tests for datatug.FoldersStore
+func RunFoldersStoreTests(t *testing.T, newStore func(t *testing.T) datatug.FoldersStore) {
+	ctx := context.Background()
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		folder := &datatug.Folder{Name: "reports", Note: "Daily reports"}
+		require.NoError(t, store.SaveFolder(ctx, "", folder))
+
+		loaded, err := store.LoadFolder(ctx, "reports")
+		require.NoError(t, err)
+		assert.Equal(t, "reports", loaded.Name)
+		assert.Equal(t, "Daily reports", loaded.Note)
+
+		folders, err := store.LoadFolders(ctx)
+		require.NoError(t, err)
+		if assert.Len(t, folders, 1) {
+			assert.Equal(t, "reports", folders[0].Name)
+			assert.Equal(t, "Daily reports", folders[0].Note)
+		}
+	})
+
+	t.Run("SaveFolders", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveFolders(ctx, "", datatug.Folders{
+			{Name: "f1"},
+			{Name: "f2"},
+		}))
+		folders, err := store.LoadFolders(ctx)
+		require.NoError(t, err)
+		assert.Len(t, folders, 2)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadFolder(ctx, "unknown")
+		assert.Error(t, err)
+		folders, err := store.LoadFolders(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, folders)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t)
+		assert.Error(t, store.SaveFolder(ctx, "", &datatug.Folder{Name: " spaces "}))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveFolder(ctx, "", &datatug.Folder{Name: "f1"}))
+		require.NoError(t, store.DeleteFolder(ctx, "f1"))
+		_, err := store.LoadFolder(ctx, "f1")
+		assert.Error(t, err)
+		folders, err := store.LoadFolders(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, folders)
+		assert.NoError(t, store.DeleteFolder(ctx, "f1"), "deleting a missing folder should not fail")
+	})
+}
@@ -0,0 +1,86 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunProjDbDriversStoreTests runs conformance tests for datatug.ProjDbDriversStore and its DB servers sub-stores
+func RunProjDbDriversStoreTests(t *testing.T, newStore func(t *testing.T) datatug.ProjDbDriversStore) {
+	ctx := context.Background()
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		driver := &datatug.ProjDbDriver{}
+		driver.ID = "sqlserver"
+		require.NoError(t, store.SaveProjDbDriver(ctx, driver))
+
+		loaded, err := store.LoadProjDbDriver(ctx, "sqlserver")
+		require.NoError(t, err)
+		assert.Equal(t, "sqlserver", loaded.ID)
+
+		drivers, err := store.LoadProjDbDrivers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sqlserver"}, drivers.IDs())
+	})
+
+	t.Run("servers", func(t *testing.T) {
+		store := newStore(t)
+		servers := store.DbServersStore("sqlserver")
+		assert.Equal(t, "sqlserver", servers.DriverID())
+
+		server := NewProjDbServer("sqlserver", "db1", 1433)
+		require.NoError(t, servers.SaveProjDbServer(ctx, server))
+
+		loaded, err := servers.LoadProjDbServer(ctx, server.ID)
+		require.NoError(t, err)
+		assert.Equal(t, server.Server, loaded.Server)
+
+		all, err := servers.LoadProjDbServers(ctx)
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+
+		drivers, err := store.LoadProjDbDrivers(ctx)
+		require.NoError(t, err)
+		if assert.Len(t, drivers, 1) {
+			assert.Len(t, drivers[0].Servers, 1)
+		}
+
+		require.NoError(t, servers.DeleteProjDbServer(ctx, server.ID))
+		_, err = servers.LoadProjDbServer(ctx, server.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadProjDbDriver(ctx, "unknown")
+		assert.Error(t, err)
+		drivers, err := store.LoadProjDbDrivers(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, drivers)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		driver := &datatug.ProjDbDriver{}
+		driver.ID = "sqlserver"
+		require.NoError(t, store.SaveProjDbDriver(ctx, driver))
+		require.NoError(t, store.DeleteProjDbDriver(ctx, "sqlserver"))
+		_, err := store.LoadProjDbDriver(ctx, "sqlserver")
+		assert.Error(t, err)
+		assert.NoError(t, store.DeleteProjDbDriver(ctx, "sqlserver"), "deleting a missing driver should not fail")
+	})
+}
+
+// NewProjDbServer creates a project DB server with ID matching its server reference
+func NewProjDbServer(driver, host string, port int) *datatug.ProjDbServer {
+	server := &datatug.ProjDbServer{
+		Server: datatug.ServerRef{Driver: driver, Host: host, Port: port},
+	}
+	server.ID = server.Server.GetID()
+	return server
+}
@@ -0,0 +1,129 @@
+// Package storetest provides a conformance test suite for datatug.ProjectStore implementations.
+//
+// Alternative storage backends are expected to pass the same suite as the file system store:
+//
+//	func TestMyProjectStore(t *testing.T) {
+//		storetest.RunProjectStoreTests(t, func(t *testing.T) datatug.ProjectStore {
+//			return newMyProjectStore(t.TempDir())
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ProjectStoreFactory creates a new, empty project store for a single test
+type ProjectStoreFactory func(t *testing.T) datatug.ProjectStore
+
+// RunProjectStoreTests runs all conformance tests against stores created by newStore.
+// Each subtest gets its own store, so implementations do not need to support cleanup between tests.
+func RunProjectStoreTests(t *testing.T, newStore ProjectStoreFactory) {
+	t.Run("Project", func(t *testing.T) {
+		RunProjectTests(t, newStore)
+	})
+	t.Run("QueriesStore", func(t *testing.T) {
+		RunQueriesStoreTests(t, func(t *testing.T) datatug.QueriesStore { return newStore(t) })
+	})
+	t.Run("BoardsStore", func(t *testing.T) {
+		RunBoardsStoreTests(t, func(t *testing.T) datatug.BoardsStore { return newStore(t) })
+	})
+	t.Run("FoldersStore", func(t *testing.T) {
+		RunFoldersStoreTests(t, func(t *testing.T) datatug.FoldersStore { return newStore(t) })
+	})
+	t.Run("EntitiesStore", func(t *testing.T) {
+		RunEntitiesStoreTests(t, func(t *testing.T) datatug.EntitiesStore { return newStore(t) })
+	})
+	t.Run("EnvironmentsStore", func(t *testing.T) {
+		RunEnvironmentsStoreTests(t, func(t *testing.T) datatug.EnvironmentsStore { return newStore(t) })
+	})
+	t.Run("EnvDbServersStore", func(t *testing.T) {
+		RunEnvDbServersStoreTests(t, func(t *testing.T) datatug.EnvDbServersStore { return newStore(t) })
+	})
+	t.Run("EnvDbCatalogStore", func(t *testing.T) {
+		RunEnvDbCatalogStoreTests(t, func(t *testing.T) datatug.EnvDbCatalogStore { return newStore(t) })
+	})
+	t.Run("ProjDbDriversStore", func(t *testing.T) {
+		RunProjDbDriversStoreTests(t, func(t *testing.T) datatug.ProjDbDriversStore { return newStore(t) })
+	})
+	t.Run("RecordsetDefinitionsStore", func(t *testing.T) {
+		RunRecordsetDefinitionsStoreTests(t, func(t *testing.T) datatug.RecordsetDefinitionsStore { return newStore(t) })
+	})
+	t.Run("QueryResultsStore", func(t *testing.T) {
+		RunQueryResultsStoreTests(t, func(t *testing.T) datatug.QueryResultsStore { return newStore(t) })
+	})
+}
+
+// RunProjectTests checks saving & loading of a project as a whole, including Depth option handling
+func RunProjectTests(t *testing.T, newStore ProjectStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("LoadProjectFile_not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadProjectFile(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("SaveProject_round_trip", func(t *testing.T) {
+		store := newStore(t)
+		project := NewProject(store.ProjectID(), "Conformance project")
+		require.NoError(t, store.SaveProject(ctx, project))
+
+		projFile, err := store.LoadProjectFile(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, store.ProjectID(), projFile.ID)
+		assert.Equal(t, project.Title, projFile.Title)
+		assert.Equal(t, project.Access, projFile.Access)
+
+		loaded, err := store.LoadProject(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, store.ProjectID(), loaded.ID)
+		assert.Equal(t, project.Title, loaded.Title)
+	})
+
+	t.Run("LoadProject_Depth", func(t *testing.T) {
+		store := newStore(t)
+		project := NewProject(store.ProjectID(), "Conformance project")
+		project.Boards = datatug.Boards{NewBoard("b1", "Board 1")}
+		project.Entities = datatug.Entities{NewEntity("e1", "Entity 1")}
+		require.NoError(t, store.SaveProject(ctx, project))
+
+		shallow, err := store.LoadProject(ctx, datatug.Depth(1))
+		require.NoError(t, err)
+		assert.Empty(t, shallow.Boards, "Depth(1) should load project file only")
+		assert.Empty(t, shallow.Entities, "Depth(1) should load project file only")
+
+		deep, err := store.LoadProject(ctx)
+		require.NoError(t, err)
+		if assert.Len(t, deep.Boards, 1) {
+			assert.Equal(t, "b1", deep.Boards[0].ID)
+		}
+		if assert.Len(t, deep.Entities, 1) {
+			assert.Equal(t, "e1", deep.Entities[0].ID)
+		}
+	})
+
+	t.Run("SaveProject_invalid", func(t *testing.T) {
+		store := newStore(t)
+		project := NewProject(store.ProjectID(), "Conformance project")
+		project.Access = ""
+		assert.Error(t, store.SaveProject(ctx, project))
+	})
+}
+
+// NewProject creates a minimal valid project
+func NewProject(id, title string) *datatug.Project {
+	return &datatug.Project{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: title},
+			Access:        "private",
+		},
+		Created: &datatug.ProjectCreated{At: time.Now().UTC()},
+	}
+}
@@ -0,0 +1,99 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunQueriesStoreTests runs conformance tests for datatug.QueriesStore
+func RunQueriesStoreTests(t *testing.T, newStore func(t *testing.T) datatug.QueriesStore) {
+	ctx := context.Background()
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		query := NewQuery("q1", "Query 1", "SELECT 1")
+		require.NoError(t, store.SaveQuery(ctx, query))
+
+		loaded, err := store.LoadQuery(ctx, "q1")
+		require.NoError(t, err)
+		assert.Equal(t, "q1", loaded.ID)
+		assert.Equal(t, "Query 1", loaded.Title)
+		assert.Equal(t, datatug.QueryTypeSQL, loaded.Type)
+		assert.Equal(t, "SELECT 1", loaded.Text)
+
+		folder, err := store.LoadQueries(ctx, "")
+		require.NoError(t, err)
+		require.NotNil(t, folder)
+		if assert.Len(t, folder.Items, 1) {
+			assert.Equal(t, "q1", folder.Items[0].ID)
+		}
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadQuery(ctx, "unknown")
+		assert.Error(t, err)
+		folder, err := store.LoadQueries(ctx, "")
+		assert.NoError(t, err)
+		if folder != nil {
+			assert.Empty(t, folder.Items)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t)
+		query := NewQuery("q1", "Query 1", "SELECT 1")
+		query.Type = ""
+		assert.Error(t, store.SaveQuery(ctx, query))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveQuery(ctx, NewQuery("q1", "Query 1", "SELECT 1")))
+		require.NoError(t, store.DeleteQuery(ctx, "q1"))
+		_, err := store.LoadQuery(ctx, "q1")
+		assert.Error(t, err)
+		assert.NoError(t, store.DeleteQuery(ctx, "q1"), "deleting a missing query should not fail")
+	})
+
+	t.Run("tags_filter", func(t *testing.T) {
+		store := newStore(t)
+		q1 := NewQuery("q1", "Query 1", "SELECT 1")
+		q1.Tags = []string{"reports"}
+		require.NoError(t, store.SaveQuery(ctx, q1))
+		require.NoError(t, store.SaveQuery(ctx, NewQuery("q2", "Query 2", "SELECT 2")))
+		folder, err := store.LoadQueries(ctx, "", datatug.Tags("reports"))
+		require.NoError(t, err)
+		require.NotNil(t, folder)
+		assert.Equal(t, []string{"q1"}, datatug.ProjectItems[*datatug.QueryDef](folder.Items).IDs())
+	})
+
+	t.Run("concurrent_writes", func(t *testing.T) {
+		store := newStore(t)
+		const count = 20
+		runConcurrently(t, count, func(i int) error {
+			return store.SaveQuery(ctx, NewQuery(fmt.Sprintf("q%02d", i), fmt.Sprintf("Query %d", i), "SELECT 1"))
+		})
+		folder, err := store.LoadQueries(ctx, "")
+		require.NoError(t, err)
+		assert.Len(t, folder.Items, count)
+	})
+}
+
+// NewQuery creates a minimal valid SQL query stored at the root of queries folder
+func NewQuery(id, title, text string) *datatug.QueryDefWithFolderPath {
+	return &datatug.QueryDefWithFolderPath{
+		QueryDef: datatug.QueryDef{
+			ProjectItem: datatug.ProjectItem{
+				ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: title},
+			},
+			Type: datatug.QueryTypeSQL,
+			Text: text,
+		},
+	}
+}
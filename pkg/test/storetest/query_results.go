@@ -0,0 +1,112 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewQueryResult creates a valid query result with a single recordset of rows with "id" & "name" columns
+func NewQueryResult(envID string, created time.Time, rows ...[]interface{}) *datatug.QueryResult {
+	return &datatug.QueryResult{
+		Created:       created,
+		EnvironmentID: envID,
+		Driver:        "sqlite3",
+		Target:        "main",
+		Recordsets: []datatug.Recordset{
+			{
+				Columns: []datatug.RecordsetColumn{{Name: "id", DbType: "INTEGER"}, {Name: "name", DbType: "TEXT"}},
+				Rows:    rows,
+			},
+		},
+	}
+}
+
+// RunQueryResultsStoreTests runs conformance tests for datatug.QueryResultsStore
+// with the default retention of datatug.DefaultMaxQueryResults results
+func RunQueryResultsStoreTests(t *testing.T, newStore func(t *testing.T) datatug.QueryResultsStore) {
+	ctx := context.Background()
+	started := time.Date(2024, 3, 1, 10, 0, 0, 123456789, time.UTC)
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		result := NewQueryResult("dev", started, []interface{}{1, "one"})
+		require.NoError(t, store.SaveQueryResult(ctx, "reports/daily", result))
+
+		loaded, err := store.LoadQueryResult(ctx, "reports/daily", "dev", started)
+		require.NoError(t, err)
+		assert.True(t, started.Equal(loaded.Created))
+		assert.Equal(t, "dev", loaded.EnvironmentID)
+		require.Len(t, loaded.Recordsets, 1)
+		assert.Equal(t, result.Recordsets[0].Columns, loaded.Recordsets[0].Columns)
+		require.Len(t, loaded.Recordsets[0].Rows, 1)
+		assert.EqualValues(t, 1, loaded.Recordsets[0].Rows[0][0])
+		assert.Equal(t, "one", loaded.Recordsets[0].Rows[0][1])
+	})
+
+	t.Run("history_per_query_and_env", func(t *testing.T) {
+		store := newStore(t)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, store.SaveQueryResult(ctx, "reports/daily", NewQueryResult("dev", started.Add(time.Duration(i)*time.Minute))))
+		}
+		require.NoError(t, store.SaveQueryResult(ctx, "reports/daily", NewQueryResult("prod", started)))
+		require.NoError(t, store.SaveQueryResult(ctx, "reports", NewQueryResult("dev", started)))
+		require.NoError(t, store.SaveQueryResult(ctx, "reports/daily/details", NewQueryResult("dev", started)))
+
+		history, err := store.ListQueryResults(ctx, "reports/daily", "dev")
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.True(t, history[0].Equal(started.Add(2*time.Minute)), "newest result should be first")
+		assert.True(t, history[2].Equal(started))
+
+		history, err = store.ListQueryResults(ctx, "reports/daily", "prod")
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+		history, err = store.ListQueryResults(ctx, "reports", "dev")
+		require.NoError(t, err)
+		assert.Len(t, history, 1, "results of nested queries should not be listed")
+
+		require.NoError(t, store.DeleteQueryResults(ctx, "reports", "dev"))
+		history, err = store.ListQueryResults(ctx, "reports", "dev")
+		require.NoError(t, err)
+		assert.Empty(t, history)
+		history, err = store.ListQueryResults(ctx, "reports/daily", "dev")
+		require.NoError(t, err)
+		assert.Len(t, history, 3, "results of nested queries should not be deleted")
+	})
+
+	t.Run("default_retention", func(t *testing.T) {
+		store := newStore(t)
+		count := datatug.DefaultMaxQueryResults + 2
+		for i := 0; i < count; i++ {
+			require.NoError(t, store.SaveQueryResult(ctx, "q1", NewQueryResult("dev", started.Add(time.Duration(i)*time.Second))))
+		}
+		history, err := store.ListQueryResults(ctx, "q1", "dev")
+		require.NoError(t, err)
+		require.Len(t, history, datatug.DefaultMaxQueryResults)
+		assert.True(t, history[0].Equal(started.Add(time.Duration(count-1)*time.Second)))
+		_, err = store.LoadQueryResult(ctx, "q1", "dev", started)
+		assert.ErrorIs(t, err, datatug.ErrQueryResultNotFound, "the oldest result should be dropped")
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadQueryResult(ctx, "unknown", "dev", started)
+		assert.ErrorIs(t, err, datatug.ErrQueryResultNotFound)
+		history, err := store.ListQueryResults(ctx, "unknown", "dev")
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+		assert.NoError(t, store.DeleteQueryResults(ctx, "unknown", "dev"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t)
+		assert.Error(t, store.SaveQueryResult(ctx, "", NewQueryResult("dev", started)))
+		assert.Error(t, store.SaveQueryResult(ctx, "q1", NewQueryResult("", started)))
+		assert.Error(t, store.SaveQueryResult(ctx, "q1", &datatug.QueryResult{EnvironmentID: "dev"}))
+	})
+}
@@ -0,0 +1,79 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatug/datatug-core/pkg/datatug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewRecordsetDefinition creates a valid recordset definition with "id" & "name" columns & a single data file
+func NewRecordsetDefinition(id, title string) *datatug.RecordsetDefinition {
+	return &datatug.RecordsetDefinition{
+		ProjectItem: datatug.ProjectItem{
+			ProjItemBrief: datatug.ProjItemBrief{ID: id, Title: title},
+		},
+		RecordsetBaseDef: datatug.RecordsetBaseDef{
+			PrimaryKey: &datatug.UniqueKey{Name: "PK_" + id, Columns: []string{"id"}},
+		},
+		Columns: datatug.RecordsetColumnDefs{{Name: "id", Type: "int", Required: true}, {Name: "name", Type: "string"}},
+		Type:    "recordset",
+		Files:   []string{"data.json"},
+	}
+}
+
+// RunRecordsetDefinitionsStoreTests runs conformance tests for datatug.RecordsetDefinitionsStore
+func RunRecordsetDefinitionsStoreTests(t *testing.T, newStore func(t *testing.T) datatug.RecordsetDefinitionsStore) {
+	ctx := context.Background()
+
+	t.Run("not_found", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.LoadRecordsetDefinition(ctx, "unknown")
+		assert.Error(t, err)
+		defs, err := store.LoadRecordsetDefinitions(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, defs)
+	})
+
+	t.Run("round_trip", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.SaveRecordsetDefinition(ctx, NewRecordsetDefinition("rs1", "Recordset 1")))
+		require.NoError(t, store.SaveRecordsetData(ctx, "rs1", "data.json", datatug.Recordset{
+			Columns: []datatug.RecordsetColumn{{Name: "name"}, {Name: "id"}},
+			Rows:    [][]interface{}{{"one", 1}, {nil, 2}},
+		}))
+
+		def, err := store.LoadRecordsetDefinition(ctx, "rs1")
+		require.NoError(t, err)
+		assert.Equal(t, "Recordset 1", def.Title)
+		assert.Equal(t, []string{"id"}, def.PrimaryKey.Columns)
+		defs, err := store.LoadRecordsetDefinitions(ctx)
+		require.NoError(t, err)
+		assert.Len(t, defs, 1)
+
+		data, err := store.LoadRecordsetData(ctx, "rs1")
+		require.NoError(t, err)
+		require.Len(t, data.Rows, 2)
+		assert.Equal(t, "id", data.Columns[0].Name, "values should be ordered by columns of the definition")
+		assert.EqualValues(t, 1, data.Rows[0][0])
+		assert.Equal(t, "one", data.Rows[0][1])
+		assert.Nil(t, data.Rows[1][1])
+
+		file, err := store.LoadRecordsetDataFile(ctx, "rs1", "data.json")
+		require.NoError(t, err)
+		assert.Equal(t, data.Rows, file.Rows)
+		_, err = store.LoadRecordsetDataFile(ctx, "rs1", "other.json")
+		assert.Error(t, err, "file should be listed by the definition")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t)
+		assert.Error(t, store.SaveRecordsetDefinition(ctx, &datatug.RecordsetDefinition{}))
+		rs := datatug.Recordset{Columns: []datatug.RecordsetColumn{{Name: "id"}}}
+		assert.Error(t, store.SaveRecordsetData(ctx, "", "data.json", rs))
+		assert.Error(t, store.SaveRecordsetData(ctx, "rs1", "", rs))
+		assert.Error(t, store.SaveRecordsetData(ctx, "rs1", "../data.json", rs))
+	})
+}